@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/apresai/gimage/internal/imaging"
+	"github.com/spf13/cobra"
+)
+
+// addFrameFlag adds the flag for picking one frame of an animated input
+func addFrameFlag(cmd *cobra.Command) {
+	cmd.Flags().Int("frame", 0, "write only frame N (counting from 0) of an animated GIF or WebP (default: all frames)")
+}
+
+// frameOption sets opts.Frame to the --frame flag, for the imaging file
+// functions. Without it, it warns when an animated input is written to a
+// format that keeps only the first frame.
+func frameOption(cmd *cobra.Command, opts *imaging.FileOptions, inputPath, outputPath string) error {
+	if cmd.Flags().Changed("frame") {
+		frame, _ := cmd.Flags().GetInt("frame")
+		if frame < 0 {
+			return fmt.Errorf("--frame must be 0 or more")
+		}
+		printVerbose("Frame: %d", frame)
+		opts.Frame = &frame
+		return nil
+	}
+
+	format := imaging.ExtractFormatFromPath(outputPath)
+	if !imaging.SupportsAnimation(format) {
+		if data, err := os.ReadFile(inputPath); err == nil {
+			if frames := imaging.CountFrames(data); frames > 1 {
+				printWarning("%s is animated (%d frames) but %s keeps a single frame; writing the first (use --frame N to pick another)",
+					inputPath, frames, format)
+			}
+		}
+	}
+	return nil
+}
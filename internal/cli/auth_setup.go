@@ -228,6 +228,20 @@ func runAuthSetup(cmd *cobra.Command, args []string) error {
 				cfg.AWSAccessKeyID = value
 			case "aws_secret_access_key":
 				cfg.AWSSecretAccessKey = value
+			case "openai_api_key":
+				cfg.OpenAIAPIKey = value
+			case "openai_base_url":
+				cfg.OpenAIBaseURL = value
+			case "openai_image_model":
+				cfg.OpenAIImageModel = value
+			case "a1111_base_url":
+				cfg.A1111BaseURL = value
+			case "a1111_api_auth":
+				cfg.A1111Auth = value
+			case "comfyui_base_url":
+				cfg.ComfyUIBaseURL = value
+			case "comfyui_checkpoint":
+				cfg.ComfyUICheckpoint = value
 			}
 		}
 
@@ -268,6 +282,20 @@ func getExistingValue(env generate.EnvVar, cfg *config.Config) string {
 		return cfg.AWSAccessKeyID
 	case "aws_secret_access_key":
 		return cfg.AWSSecretAccessKey
+	case "openai_api_key":
+		return cfg.OpenAIAPIKey
+	case "openai_base_url":
+		return cfg.OpenAIBaseURL
+	case "openai_image_model":
+		return cfg.OpenAIImageModel
+	case "a1111_base_url":
+		return cfg.A1111BaseURL
+	case "a1111_api_auth":
+		return cfg.A1111Auth
+	case "comfyui_base_url":
+		return cfg.ComfyUIBaseURL
+	case "comfyui_checkpoint":
+		return cfg.ComfyUICheckpoint
 	}
 
 	return ""
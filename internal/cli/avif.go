@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/apresai/gimage/internal/imaging"
+	"github.com/spf13/cobra"
+)
+
+// addSpeedFlag adds the AVIF encoder speed flag
+func addSpeedFlag(cmd *cobra.Command) {
+	cmd.Flags().Int("speed", 0, fmt.Sprintf("AVIF encoder speed 1-%d, slower gives smaller files (default: %d)",
+		imaging.MaxAVIFSpeed, imaging.DefaultAVIFSpeed))
+}
+
+// speedOption sets opts.Speed to the --speed flag, for the imaging file
+// functions
+func speedOption(cmd *cobra.Command, opts *imaging.FileOptions) error {
+	speed, _ := cmd.Flags().GetInt("speed")
+	if speed < 0 || speed > imaging.MaxAVIFSpeed {
+		return fmt.Errorf("--speed must be between 1 and %d", imaging.MaxAVIFSpeed)
+	}
+	if speed > 0 {
+		printVerbose("AVIF speed: %d", speed)
+	}
+	opts.Speed = speed
+	return nil
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/apresai/gimage/internal/imaging"
+	"github.com/spf13/cobra"
+)
+
+// compareCmd represents the compare command
+var compareCmd = &cobra.Command{
+	Use:   "compare <image-a> <image-b>",
+	Short: "Measure how similar two images are (PSNR and SSIM)",
+	Long: `Compare two images of the same dimensions and print their PSNR and SSIM.
+
+METRICS:
+  • PSNR - peak signal-to-noise ratio in dB over RGB; "inf" for identical
+           images, above ~40 dB is hard to tell apart, below ~30 dB visibly lossy
+  • SSIM - structural similarity from 0 to 1; 1 for identical images, above
+           ~0.98 is usually visually lossless
+
+The metrics are printed to stdout, one per line, so they are easy to parse.
+With --min-ssim or --min-psnr the command exits with an error when a metric
+falls below the threshold, which makes it usable as a regression check for
+compression settings.
+
+Examples:
+  # Compare an original with its compressed copy
+  gimage compare photo.png photo.webp
+
+  # Fail when the compressed copy drifts too far from the original
+  gimage compare photo.png photo.jpg --min-ssim 0.98 --min-psnr 38`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		minSSIM, _ := cmd.Flags().GetFloat64("min-ssim")
+		minPSNR, _ := cmd.Flags().GetFloat64("min-psnr")
+
+		printVerbose("Comparing %s with %s", args[0], args[1])
+		result, err := imaging.CompareFiles(args[0], args[1])
+		if err != nil {
+			return fmt.Errorf("compare failed: %w", err)
+		}
+
+		psnr := "inf"
+		if !math.IsInf(result.PSNR, 1) {
+			psnr = fmt.Sprintf("%.2f dB", result.PSNR)
+		}
+		fmt.Printf("PSNR: %s\n", psnr)
+		fmt.Printf("SSIM: %.4f\n", result.SSIM)
+
+		if minSSIM > 0 && result.SSIM < minSSIM {
+			return fmt.Errorf("SSIM %.4f is below the minimum %.4f", result.SSIM, minSSIM)
+		}
+		if minPSNR > 0 && result.PSNR < minPSNR {
+			return fmt.Errorf("PSNR %.2f dB is below the minimum %.2f dB", result.PSNR, minPSNR)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+
+	compareCmd.Flags().Float64("min-ssim", 0, "fail when SSIM is below this value (0-1)")
+	compareCmd.Flags().Float64("min-psnr", 0, "fail when PSNR is below this value in dB")
+}
@@ -18,7 +18,12 @@ var compressCmd = &cobra.Command{
 
 SUPPORTED FORMATS (with quality control):
   • JPEG/JPG  - Lossy compression with quality 1-100
-  • WebP      - Lossy compression with quality 1-100
+  • WebP      - Lossy compression with quality 1-100 (transparency is kept)
+  • AVIF      - Lossy compression with quality 1-100 (transparency is kept);
+                --speed trades encoding time for file size
+
+The output format follows the output file extension, so any input can be
+compressed to JPEG, WebP or AVIF (e.g. --input shot.png --output shot.avif).
 
 UNSUPPORTED FORMATS (copy only):
   • PNG       - Already compressed losslessly (copy only)
@@ -26,6 +31,20 @@ UNSUPPORTED FORMATS (copy only):
   • TIFF      - Typically uncompressed (copy only)
   • BMP       - Uncompressed format (copy only)
 
+TARGET FILE SIZE:
+  --max-size caps the output size instead of fixing the quality: the highest
+  quality that fits is found by binary search (between --min-quality and
+  --quality, when given). With --allow-resize the dimensions are also
+  stepped down if even the minimum quality is too large. Sizes accept B, KB,
+  MB (powers of 1000) and KiB, MiB (powers of 1024).
+
+TARGET SIMILARITY:
+  --target-ssim keeps the output perceptually close to the original instead:
+  the lowest quality whose decoded result still reaches the given SSIM
+  (structural similarity, 0-1) is found by binary search. 0.98 is usually
+  visually lossless, 0.95 fine for the web. Use 'gimage compare' to check
+  the result.
+
 Quality Guide:
   • 90-100: Excellent quality, larger file size
   • 85-90:  Very good quality, balanced (default: 85)
@@ -41,12 +60,28 @@ Examples:
   gimage compress --input image.jpg --quality 90 --output high-quality.jpg
 
   # Compress WebP with lower quality for web
-  gimage compress --input photo.webp --quality 75 --output web-optimized.webp`,
+  gimage compress --input photo.webp --quality 75 --output web-optimized.webp
+
+  # Turn a JPEG or PNG into a smaller lossy WebP
+  gimage compress --input photo.jpg --quality 80 --output photo.webp
+
+  # Fit an upload limit, shrinking the image if quality alone is not enough
+  gimage compress --input photo.jpg --max-size 200KB --allow-resize
+
+  # Smallest WebP that is still visually lossless
+  gimage compress --input photo.png --target-ssim 0.98 --output photo.webp
+
+  # AVIF at a slower, more thorough encoder speed
+  gimage compress --input photo.jpg --quality 55 --speed 4 --output photo.avif`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get flag values
 		inputPath, _ := cmd.Flags().GetString("input")
 		outputPath, _ := cmd.Flags().GetString("output")
 		quality, _ := cmd.Flags().GetInt("quality")
+		maxSizeFlag, _ := cmd.Flags().GetString("max-size")
+		minQuality, _ := cmd.Flags().GetInt("min-quality")
+		allowResize, _ := cmd.Flags().GetBool("allow-resize")
+		targetSSIM, _ := cmd.Flags().GetFloat64("target-ssim")
 
 		// Validate input
 		if inputPath == "" {
@@ -65,14 +100,62 @@ Examples:
 			outputPath = fmt.Sprintf("%s_compressed%s", base, ext)
 		}
 
-		// Get file extension to check format
-		ext := filepath.Ext(inputPath)
-		format := ext[1:] // Remove leading dot
+		if maxSizeFlag != "" && targetSSIM != 0 {
+			return fmt.Errorf("--max-size and --target-ssim cannot be used together")
+		}
+		var fileOpts imaging.FileOptions
+		if err := metadataOption(cmd, &fileOpts); err != nil {
+			return err
+		}
+		if err := speedOption(cmd, &fileOpts); err != nil {
+			return err
+		}
+		var maxBytes int64
+		if maxSizeFlag != "" {
+			var err error
+			if maxBytes, err = imaging.ParseByteSize(maxSizeFlag); err != nil {
+				return fmt.Errorf("invalid --max-size: %w", err)
+			}
+		}
+
+		// The arguments are valid: a failure from here on is not a usage
+		// error, so don't print the usage after it
+		cmd.SilenceUsage = true
+		ctx := context.Background()
+
+		if targetSSIM != 0 {
+			target := imaging.SSIMTarget{
+				MinSSIM:    targetSSIM,
+				MinQuality: minQuality,
+			}
+			// An explicit --quality caps the search
+			if cmd.Flags().Changed("quality") {
+				target.MaxQuality = quality
+			}
+			return compressToSSIM(ctx, inputPath, outputPath, target, fileOpts)
+		}
 
-		// Check if format supports compression
-		if format != "jpg" && format != "jpeg" && format != "webp" {
+		if maxSizeFlag != "" {
+			limit := imaging.SizeLimit{
+				MaxBytes:    maxBytes,
+				MinQuality:  minQuality,
+				AllowResize: allowResize,
+			}
+			// An explicit --quality caps the search
+			if cmd.Flags().Changed("quality") {
+				limit.MaxQuality = quality
+			}
+			return compressToSize(ctx, inputPath, outputPath, maxSizeFlag, limit, fileOpts)
+		}
+
+		// The output extension decides the encoding, so PNG in and WebP
+		// out compresses; only same-format lossless files are copied, unless
+		// metadata has to be stripped
+		format := imaging.ExtractFormatFromPath(outputPath)
+		stripping := fileOpts.Metadata == imaging.MetadataStrip || fileOpts.Metadata == imaging.MetadataStripGPS
+		if format != "jpeg" && format != "webp" && format != "avif" && format == imaging.ExtractFormatFromPath(inputPath) && !stripping {
 			printWarning("Format '%s' does not support quality-based compression", format)
-			printInfo("Supported formats: JPG, JPEG, WebP")
+			printInfo("Supported formats: JPG, JPEG, WebP, AVIF")
 			printInfo("The file will be copied without compression")
 			printInfo("")
 
@@ -87,8 +170,7 @@ Examples:
 		printVerbose("Format: %s", format)
 		printVerbose("Quality: %d", quality)
 
-		ctx := context.Background()
-		err := imaging.CompressImage(ctx, inputPath, outputPath, quality)
+		err := imaging.CompressImage(ctx, inputPath, outputPath, quality, fileOpts)
 		if err != nil {
 			return fmt.Errorf("compression failed: %w", err)
 		}
@@ -125,10 +207,66 @@ func init() {
 	// Flags for compress command
 	compressCmd.Flags().StringP("input", "i", "", "input image file path (required)")
 	compressCmd.Flags().StringP("output", "o", "", "output file path (default: input_compressed.ext)")
-	compressCmd.Flags().IntP("quality", "q", 85, "compression quality 1-100 (default: 85)")
+	compressCmd.Flags().IntP("quality", "q", 85, "compression quality 1-100 (default: 85); caps the search with --max-size or --target-ssim")
+	compressCmd.Flags().String("max-size", "", "largest output size, e.g. 200KB or 1.5MB; searches for the highest quality that fits")
+	compressCmd.Flags().Int("min-quality", imaging.DefaultMinQuality, "lowest quality to try with --max-size or --target-ssim")
+	compressCmd.Flags().Bool("allow-resize", false, "with --max-size, step dimensions down when the minimum quality is still too large")
+	compressCmd.Flags().Float64("target-ssim", 0, "lowest acceptable SSIM against the original, e.g. 0.98; searches for the lowest quality that meets it")
+	addSpeedFlag(compressCmd)
+	addMetadataFlags(compressCmd)
 	compressCmd.MarkFlagRequired("input")
 }
 
+// compressToSize runs the --max-size mode and reports the chosen settings
+func compressToSize(ctx context.Context, inputPath, outputPath, maxSize string, limit imaging.SizeLimit, fileOpts imaging.FileOptions) error {
+	printInfo("Compressing image to at most %s (%d bytes)...", maxSize, limit.MaxBytes)
+	printVerbose("Input: %s", inputPath)
+	printVerbose("Output: %s", outputPath)
+
+	result, err := imaging.CompressImageToSize(ctx, inputPath, outputPath, limit, fileOpts)
+	if err != nil {
+		return fmt.Errorf("compression failed: %w", err)
+	}
+
+	printSuccess("Image compressed successfully!")
+	if originalSize, err := getFileSize(inputPath); err == nil {
+		printInfo("Original size:   %s", formatFileSize(originalSize))
+	}
+	printInfo("Compressed size: %s (%d bytes)", formatFileSize(result.Bytes), result.Bytes)
+	if result.Quality > 0 {
+		printInfo("Quality:         %d", result.Quality)
+	}
+	if result.Resized {
+		printInfo("Resized to:      %dx%d", result.Width, result.Height)
+	}
+	printVerbose("Encodes tried: %d", result.Attempts)
+	printInfo("Output: %s", outputPath)
+	return nil
+}
+
+// compressToSSIM runs the --target-ssim mode and reports the chosen settings
+func compressToSSIM(ctx context.Context, inputPath, outputPath string, target imaging.SSIMTarget, fileOpts imaging.FileOptions) error {
+	printInfo("Compressing image to SSIM %.4f...", target.MinSSIM)
+	printVerbose("Input: %s", inputPath)
+	printVerbose("Output: %s", outputPath)
+
+	result, err := imaging.CompressImageToSSIM(ctx, inputPath, outputPath, target, fileOpts)
+	if err != nil {
+		return fmt.Errorf("compression failed: %w", err)
+	}
+
+	printSuccess("Image compressed successfully!")
+	if originalSize, err := getFileSize(inputPath); err == nil {
+		printInfo("Original size:   %s", formatFileSize(originalSize))
+	}
+	printInfo("Compressed size: %s (%d bytes)", formatFileSize(result.Bytes), result.Bytes)
+	printInfo("Quality:         %d", result.Quality)
+	printInfo("SSIM:            %.4f", result.SSIM)
+	printVerbose("Encodes tried: %d", result.Attempts)
+	printInfo("Output: %s", outputPath)
+	return nil
+}
+
 // copyFile copies a file from src to dst
 func copyFile(src, dst string) error {
 	data, err := os.ReadFile(src)
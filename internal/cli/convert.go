@@ -14,24 +14,40 @@ import (
 var convertCmd = &cobra.Command{
 	Use:   "convert",
 	Short: "Convert an image to a different format",
-	Long: `Convert an image to a different format (PNG, JPG, WebP, GIF, TIFF, BMP).
+	Long: `Convert an image to a different format (PNG, JPG, WebP, AVIF, GIF, TIFF, BMP).
 
 Supported formats:
   • PNG  - Lossless, transparency support
   • JPG  - Lossy, best for photos
   • WebP - Modern format, smaller files
+  • AVIF - Newest format, smallest lossy files, transparency support
   • GIF  - Animated images, limited colors
   • TIFF - High quality, large files
   • BMP  - Uncompressed, largest files
 
+HEIC/HEIF photos (e.g. from iPhones) and AVIF files can be read as input.
+
+Animated GIF and WebP files stay animated when converted to GIF or WebP
+(e.g. an animated GIF to a much smaller animated WebP). Other formats get the
+first frame; --frame N picks another, counting from 0.
+
+--quality sets the lossy quality (1-100) for JPG, WebP and AVIF; without it
+JPG uses 90, WebP is lossless and AVIF uses 60. --speed trades AVIF encoding
+time for file size.
+
 Examples:
   gimage convert --input input.png --format jpg
-  gimage convert -i input.jpg -f webp --output converted.webp`,
+  gimage convert -i input.jpg -f webp --output converted.webp
+  gimage convert -i IMG_0042.HEIC -f jpg
+  gimage convert -i photo.jpg -f avif --quality 50 --speed 4
+  gimage convert -i banner.gif -f webp --quality 80
+  gimage convert -i banner.gif -f png --frame 3`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get flag values
 		inputPath, _ := cmd.Flags().GetString("input")
 		targetFormat, _ := cmd.Flags().GetString("format")
 		outputPath, _ := cmd.Flags().GetString("output")
+		quality, _ := cmd.Flags().GetInt("quality")
 
 		// Validate required flags
 		if inputPath == "" {
@@ -40,6 +56,9 @@ Examples:
 		if targetFormat == "" {
 			return fmt.Errorf("--format flag is required")
 		}
+		if quality < 0 || quality > 100 {
+			return fmt.Errorf("--quality must be between 1 and 100")
+		}
 
 		// Generate output path if not provided
 		if outputPath == "" {
@@ -58,7 +77,26 @@ Examples:
 		printVerbose("Input: %s", inputPath)
 		printVerbose("Output: %s", outputPath)
 		printVerbose("Target format: %s", targetFormat)
-		err := imaging.ConvertImageFile(context.Background(), inputPath, outputPath)
+		var fileOpts imaging.FileOptions
+		if err := metadataOption(cmd, &fileOpts); err != nil {
+			return err
+		}
+		if err := frameOption(cmd, &fileOpts, inputPath, outputPath); err != nil {
+			return err
+		}
+		if err := speedOption(cmd, &fileOpts); err != nil {
+			return err
+		}
+		ctx := context.Background()
+		var err error
+		if quality > 0 {
+			printVerbose("Quality: %d", quality)
+			err = imaging.ProcessFile(ctx, inputPath, outputPath, fileOpts,
+				imaging.Step{Op: imaging.StepConvert, Format: imaging.ExtractFormatFromPath(outputPath)},
+				imaging.Step{Op: imaging.StepCompress, Quality: quality})
+		} else {
+			err = imaging.ConvertImageFile(ctx, inputPath, outputPath, fileOpts)
+		}
 		if err != nil {
 			return fmt.Errorf("conversion failed: %w", err)
 		}
@@ -82,8 +120,12 @@ func init() {
 
 	// Flags for convert command
 	convertCmd.Flags().StringP("input", "i", "", "input image file path (required)")
-	convertCmd.Flags().StringP("format", "f", "", "target format: png, jpg, webp, gif, tiff, bmp (required)")
+	convertCmd.Flags().StringP("format", "f", "", "target format: png, jpg, webp, avif, gif, tiff, bmp (required)")
 	convertCmd.Flags().StringP("output", "o", "", "output file path (default: input_converted.FORMAT)")
+	convertCmd.Flags().IntP("quality", "q", 0, "lossy quality 1-100 for jpg, webp and avif (default: format default)")
+	addSpeedFlag(convertCmd)
+	addFrameFlag(convertCmd)
+	addMetadataFlags(convertCmd)
 
 	convertCmd.MarkFlagRequired("input")
 	convertCmd.MarkFlagRequired("format")
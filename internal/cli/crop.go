@@ -16,9 +16,20 @@ var cropCmd = &cobra.Command{
 	Short: "Crop an image to a specific region",
 	Long: `Crop an image to a specific region defined by x, y coordinates and dimensions.
 
+--smart picks the region from the image content instead: of all regions with
+the aspect ratio of --width x --height, as large as the image allows, it keeps
+the one with the most detail, skin tones and color, and scales it to exactly
+--width x --height. Thumbnails then keep the faces or the product that a
+center crop would cut off. --x and --y are ignored, and --filter sets the
+resampling filter of the scaling.
+
+Animated GIF and WebP files are cropped frame by frame; --frame N writes a
+single frame instead.
+
 Examples:
   gimage crop --input input.jpg --x 100 --y 100 --width 800 --height 600
-  gimage crop -i input.png --x 0 --y 0 -w 1920 -h 1080 --output cropped.png`,
+  gimage crop -i input.png --x 0 --y 0 -w 1920 -h 1080 --output cropped.png
+  gimage crop -i portrait.jpg --smart --width 400 --height 400`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get flag values
 		inputPath, _ := cmd.Flags().GetString("input")
@@ -27,6 +38,7 @@ Examples:
 		width, _ := cmd.Flags().GetInt("width")
 		height, _ := cmd.Flags().GetInt("height")
 		outputPath, _ := cmd.Flags().GetString("output")
+		smart, _ := cmd.Flags().GetBool("smart")
 
 		// Validate required flags
 		if inputPath == "" {
@@ -52,11 +64,33 @@ Examples:
 		}
 
 		// Call imaging function
-		printInfo("Cropping %s to region (%d,%d) %dx%d...", inputPath, x, y, width, height)
+		if smart {
+			printInfo("Smart cropping %s to %dx%d...", inputPath, width, height)
+		} else {
+			printInfo("Cropping %s to region (%d,%d) %dx%d...", inputPath, x, y, width, height)
+		}
 		printVerbose("Input: %s", inputPath)
 		printVerbose("Output: %s", outputPath)
-		printVerbose("Region: x=%d, y=%d, width=%d, height=%d", x, y, width, height)
-		err := imaging.CropImage(context.Background(), inputPath, outputPath, x, y, width, height)
+		if !smart {
+			printVerbose("Region: x=%d, y=%d, width=%d, height=%d", x, y, width, height)
+		}
+		var fileOpts imaging.FileOptions
+		if err := metadataOption(cmd, &fileOpts); err != nil {
+			return err
+		}
+		if err := frameOption(cmd, &fileOpts, inputPath, outputPath); err != nil {
+			return err
+		}
+		ctx := context.Background()
+		var err error
+		if smart {
+			if fileOpts.Filter, err = filterFlag(cmd); err != nil {
+				return err
+			}
+			err = imaging.SmartCrop(ctx, inputPath, outputPath, width, height, fileOpts)
+		} else {
+			err = imaging.CropImage(ctx, inputPath, outputPath, x, y, width, height, fileOpts)
+		}
 		if err != nil {
 			return fmt.Errorf("crop failed: %w", err)
 		}
@@ -81,6 +115,10 @@ func init() {
 	cropCmd.Flags().Int("width", 0, "width of crop region in pixels (required)")
 	cropCmd.Flags().Int("height", 0, "height of crop region in pixels (required)")
 	cropCmd.Flags().StringP("output", "o", "", "output file path (default: input_cropped_WxH.ext)")
+	cropCmd.Flags().Bool("smart", false, "pick the most interesting region with the aspect ratio of --width x --height and scale it to that size (ignores --x and --y)")
+	addFilterFlag(cropCmd)
+	addFrameFlag(cropCmd)
+	addMetadataFlags(cropCmd)
 
 	cropCmd.MarkFlagRequired("input")
 	cropCmd.MarkFlagRequired("width")
@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apresai/gimage/internal/config"
+	"github.com/apresai/gimage/internal/generate"
+	"github.com/apresai/gimage/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+// editCmd represents the edit command
+var editCmd = &cobra.Command{
+	Use:   "edit [instruction]",
+	Short: "Edit an existing image from a text instruction using AI",
+	Long: `Edit an existing image by describing the change you want.
+
+Supported providers:
+  • gemini/flash-2.5      - Conversational editing
+  • bedrock/nova-canvas   - Image variation or inpainting (mask image or mask prompt)
+
+Without --provider the provider is picked like generate does: the configured
+default_api/default_model, or the only API with credentials.
+
+Examples:
+  gimage edit --input photo.png "make the sky purple"
+  gimage edit -i photo.png "add a hot air balloon" --output edited.png
+  gimage edit -i photo.png "turn it into a watercolor painting" --provider nova-canvas --strength 0.6
+  gimage edit -i photo.png "a red sports car" --provider nova-canvas --mode inpainting --mask-prompt "the blue car"
+  gimage edit -i photo.png "a wooden bench" --provider nova-canvas --mode inpainting --mask mask.png`,
+	Args: cobra.ArbitraryArgs,
+	RunE: runEdit,
+}
+
+func runEdit(cmd *cobra.Command, args []string) error {
+	// Get flag values
+	inputPath, _ := cmd.Flags().GetString("input")
+	outputPath, _ := cmd.Flags().GetString("output")
+	providerID, _ := cmd.Flags().GetString("provider")
+	prompt, _ := cmd.Flags().GetString("prompt")
+	mode, _ := cmd.Flags().GetString("mode")
+	maskPath, _ := cmd.Flags().GetString("mask")
+	maskPrompt, _ := cmd.Flags().GetString("mask-prompt")
+	strength, _ := cmd.Flags().GetFloat64("strength")
+	style, _ := cmd.Flags().GetString("style")
+	negative, _ := cmd.Flags().GetString("negative")
+	seed, _ := cmd.Flags().GetInt64("seed")
+
+	// Positional instruction takes precedence over --prompt
+	if len(args) > 0 {
+		prompt = strings.Join(args, " ")
+	}
+	if prompt == "" {
+		return fmt.Errorf("edit instruction is required (provide as argument or use --prompt flag)\nExample:\n  gimage edit --input photo.png \"make the sky purple\"")
+	}
+
+	// Validate input file exists
+	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
+		return fmt.Errorf("input file does not exist: %s", inputPath)
+	}
+
+	imageData, err := os.ReadFile(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %w", err)
+	}
+
+	edit := models.EditOptions{
+		Image:      imageData,
+		Mode:       mode,
+		MaskPrompt: maskPrompt,
+		Strength:   strength,
+	}
+
+	if maskPath != "" {
+		edit.Mask, err = os.ReadFile(maskPath)
+		if err != nil {
+			return fmt.Errorf("failed to read mask file: %w", err)
+		}
+	}
+
+	// A mask implies inpainting
+	if edit.Mode == "" && (len(edit.Mask) > 0 || edit.MaskPrompt != "") {
+		edit.Mode = generate.EditModeInpainting
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Resolve provider the same way as generate (empty = auto)
+	client, provider, err := generate.GetProviderRegistry().ResolveEditor(providerID, cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	editor := client.(generate.ImageEditor)
+
+	printInfo("Using provider: %s", provider.Name)
+
+	options := models.GenerateOptions{
+		Model:          provider.ModelID,
+		Style:          style,
+		NegativePrompt: negative,
+		Seed:           seed,
+	}
+
+	printInfo("Editing %s...", inputPath)
+	printVerbose("Instruction: %s", prompt)
+	printVerbose("Mode: %s", edit.Mode)
+
+	ctx := context.Background()
+	startTime := time.Now()
+
+	editedImage, err := editor.EditImage(ctx, prompt, edit, options)
+	if err != nil {
+		return fmt.Errorf("failed to edit image: %w", err)
+	}
+
+	printInfo("Edit completed in %.2fs", time.Since(startTime).Seconds())
+
+	// Get output path or generate default: input_edited.ext
+	if outputPath == "" {
+		ext := filepath.Ext(inputPath)
+		base := inputPath[:len(inputPath)-len(ext)]
+		outputPath = fmt.Sprintf("%s_edited%s", base, ext)
+	}
+
+	// Save image
+	if err := generate.SaveImage(editedImage, outputPath); err != nil {
+		return fmt.Errorf("failed to save image: %w", err)
+	}
+
+	printSuccess("Image edited successfully!")
+	printInfo("  Provider: %s", provider.Name)
+	printInfo("  File: %s", outputPath)
+	printInfo("  Size: %s", formatImageSize(int64(len(editedImage.Data))))
+	printInfo("  Dimensions: %dx%d", editedImage.Width, editedImage.Height)
+
+	if provider.Pricing.FreeTier {
+		printInfo("  Cost: FREE (within daily limit)")
+	} else if provider.Pricing.CostPerImage != nil {
+		printInfo("  Cost: $%.4f", *provider.Pricing.CostPerImage)
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+
+	// Flags for edit command
+	editCmd.Flags().StringP("input", "i", "", "input image file path (required)")
+	editCmd.Flags().StringP("output", "o", "", "output file path (default: input_edited.ext)")
+	editCmd.Flags().StringP("prompt", "p", "", "edit instruction (alternative to positional argument)")
+	editCmd.Flags().String("provider", "", "provider to use: gemini/flash-2.5, bedrock/nova-canvas or auto (default: configured provider)")
+	editCmd.Flags().String("mode", "", "edit mode: variation or inpainting (Nova Canvas only, default: variation)")
+	editCmd.Flags().String("mask", "", "mask image path for inpainting (black = area to edit)")
+	editCmd.Flags().String("mask-prompt", "", "describe the area to edit instead of providing a mask")
+	editCmd.Flags().Float64("strength", 0, "similarity to the source image for variations, 0.2-1.0 (Nova Canvas only)")
+	editCmd.Flags().String("style", "", "quality hint: premium for Nova Canvas premium quality")
+	editCmd.Flags().String("negative", "", "negative prompt to avoid certain features")
+	editCmd.Flags().Int64("seed", 0, "random seed for reproducibility (0 for random)")
+
+	editCmd.MarkFlagRequired("input")
+}
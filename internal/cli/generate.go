@@ -120,7 +120,19 @@ Examples:
   gimage generate "abstract art" --api vertex --model imagen-4
 
   # Use negative prompts and seed for reproducibility
-  gimage generate "forest scene" --negative "people, buildings" --seed 12345`,
+  gimage generate "forest scene" --negative "people, buildings" --seed 12345
+
+  # Generate four candidates (logo_1.png ... logo_4.png) and pick the best
+  gimage generate "minimal fox logo" --count 4 --output logo.png
+
+  # Widescreen at the provider's native 16:9 size
+  gimage generate "mountain panorama" --aspect 16:9
+
+  # Exact 1920x1080 hero image from any provider (native size, then center-cropped)
+  gimage generate "mountain panorama" --size 1920x1080 --provider vertex/imagen-4
+
+  # Pass provider-specific options (see --list-providers for each provider's keys)
+  gimage generate "product shot" --provider bedrock/nova-canvas --param cfgScale=8 --param quality=premium`,
 	Args: cobra.ArbitraryArgs,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
 		// Check if --list-models flag is set
@@ -142,6 +154,16 @@ Examples:
 		}
 
 		// Validate flags
+		count, _ := cmd.Flags().GetInt("count")
+		if count < 1 || count > generate.MaxImageCount {
+			return fmt.Errorf("invalid count: %d (must be 1-%d)", count, generate.MaxImageCount)
+		}
+
+		params, _ := cmd.Flags().GetStringArray("param")
+		if _, err := generate.ParseOptionParams(params); err != nil {
+			return err
+		}
+
 		size, _ := cmd.Flags().GetString("size")
 		if size != "" {
 			parts := strings.Split(size, "x")
@@ -149,7 +171,16 @@ Examples:
 				return fmt.Errorf("invalid size format: %s (expected format: WIDTHxHEIGHT, e.g., 1024x1024)", size)
 			}
 		}
-		return nil
+
+		aspect, _ := cmd.Flags().GetString("aspect")
+		if aspect != "" {
+			if _, err := generate.ParseAspectRatio(aspect); err != nil {
+				return err
+			}
+		}
+
+		fit, _ := cmd.Flags().GetString("fit")
+		return generate.ValidateFitMode(fit)
 	},
 	RunE: runGenerate,
 }
@@ -160,23 +191,17 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	providerID, _ := cmd.Flags().GetString("provider")
 	api, _ := cmd.Flags().GetString("api")
 	apiKey, _ := cmd.Flags().GetString("api-key")
-	// project, _ := cmd.Flags().GetString("project") // TODO: Enable when Vertex is implemented
-	// location, _ := cmd.Flags().GetString("location") // TODO: Enable when Vertex is implemented
+	project, _ := cmd.Flags().GetString("project")
+	location, _ := cmd.Flags().GetString("location")
 	model, _ := cmd.Flags().GetString("model")
-
-	// Resolve model aliases to exact names (e.g., "gemini" -> "gemini-2.5-flash-image")
-	originalModel := model
-	if model != "" {
-		model = generate.ResolveModelName(model)
-		if originalModel != model {
-			printVerbose("Resolved model '%s' to '%s'", originalModel, model)
-		}
-	}
-
 	size, _ := cmd.Flags().GetString("size")
+	aspect, _ := cmd.Flags().GetString("aspect")
+	fit, _ := cmd.Flags().GetString("fit")
 	style, _ := cmd.Flags().GetString("style")
 	negative, _ := cmd.Flags().GetString("negative")
 	seed, _ := cmd.Flags().GetInt64("seed")
+	count, _ := cmd.Flags().GetInt("count")
+	params, _ := cmd.Flags().GetStringArray("param")
 	listModels, _ := cmd.Flags().GetBool("list-models")
 	listProviders, _ := cmd.Flags().GetBool("list-providers")
 
@@ -200,450 +225,215 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 
 	printVerbose("Generating image with prompt: %s", prompt)
 
-	// Handle new provider system if --provider is specified
-	if providerID != "" {
-		return runGenerateWithProvider(cmd, prompt, providerID, output, size, style, negative, seed)
-	}
-
-	// Build generate options
-	options := models.GenerateOptions{
-		Model:          model,
-		Size:           size,
-		Style:          style,
-		NegativePrompt: negative,
-		Seed:           seed,
-	}
-
-	// Determine which API to use
-	// Priority: 1. --api flag, 2. Auto-detect from model, 3. Auto-detect from available credentials
-	selectedAPI := api
-	if selectedAPI == "" {
-		// Auto-detect API from model
-		if model != "" {
-			detectedAPI, err := generate.DetectAPIFromModel(model)
-			if err != nil {
-				return fmt.Errorf("invalid model: %w\nUse --list-models to see available models", err)
-			}
-			selectedAPI = detectedAPI
-		} else {
-			// Auto-detect from available credentials
-			hasGemini := config.HasGeminiCredentials()
-			hasVertex := config.HasVertexCredentials()
-			hasBedrock := config.HasBedrockCredentials()
-
-			// Count available credentials
-			availableCount := 0
-			if hasGemini {
-				availableCount++
-			}
-			if hasVertex {
-				availableCount++
-			}
-			if hasBedrock {
-				availableCount++
-			}
+	registry := generate.GetProviderRegistry()
 
-			if availableCount == 0 {
-				// No credentials found
-				return fmt.Errorf("no API credentials found. Please set up credentials using:\n" +
-					"  Gemini:  gimage auth gemini\n" +
-					"  Vertex:  gimage auth vertex\n" +
-					"  Bedrock: gimage auth bedrock")
-			} else if availableCount == 1 {
-				// Only one API available
-				if hasGemini {
-					selectedAPI = "gemini"
-					printVerbose("Auto-detected Gemini API (found credentials)")
-				} else if hasVertex {
-					selectedAPI = "vertex"
-					printVerbose("Auto-detected Vertex API (found credentials)")
-				} else {
-					selectedAPI = "bedrock"
-					printVerbose("Auto-detected AWS Bedrock API (found credentials)")
-				}
-			} else {
-				// Multiple APIs available - check default_api in config, or default to gemini
-				cfg, err := config.LoadConfig()
-				if err == nil && cfg.DefaultAPI != "" {
-					selectedAPI = cfg.DefaultAPI
-					printVerbose("Using default API from config: %s", selectedAPI)
-				} else {
-					selectedAPI = "gemini"
-					printVerbose("Multiple APIs available, defaulting to Gemini")
-				}
+	// Determine what to resolve
+	// Priority: 1. --provider, 2. --model (checked against --api), 3. --api default, 4. auto-detect from credentials
+	input := providerID
+	if input == "" && model != "" {
+		if api != "" {
+			if err := generate.ValidateModelForAPI(model, api); err != nil {
+				return err
 			}
 		}
+		input = model
 	}
-
-	// Validate model is compatible with selected API
-	if model != "" {
-		if err := generate.ValidateModelForAPI(model, selectedAPI); err != nil {
+	if input == "" && api != "" {
+		p, err := registry.DefaultProviderForAPI(api)
+		if err != nil {
 			return err
 		}
+		input = p.ID
 	}
-
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
-
-	var generatedImage *models.GeneratedImage
-	var err error
-
-	// Generate based on API selection
-	if selectedAPI == "gemini" {
-		// Use Gemini API (REST implementation)
-		key, err := config.GetGeminiAPIKey(apiKey)
-		if err != nil {
-			return fmt.Errorf("failed to get Gemini API key: %w\nHint: Set GEMINI_API_KEY environment variable or use --api-key flag", err)
-		}
-
-		modelName := model
-		if modelName == "" {
-			modelName = generate.DefaultModel
-		}
-
-		// Get provider info and announce selection
-		registry := generate.GetProviderRegistry()
-		provider, _ := registry.ResolveProvider(modelName)
-		if provider != nil {
-			printInfo("Using: %s (%s API)", provider.Name, provider.API)
-			pricingDisplay := "Variable"
-			if provider.Pricing.FreeTier {
-				pricingDisplay = fmt.Sprintf("FREE (%s)", provider.Pricing.FreeTierLimit)
-			} else if provider.Pricing.CostPerImage != nil {
-				pricingDisplay = fmt.Sprintf("$%.4f/image", *provider.Pricing.CostPerImage)
-			}
-			printInfo("Pricing: %s", pricingDisplay)
-
-			// Warn if expensive (cost > $0.05)
-			if provider.Pricing.CostPerImage != nil && *provider.Pricing.CostPerImage > 0.05 {
-				fmt.Fprintf(os.Stderr, "⚠️  %s costs $%.4f/image\n", provider.Name, *provider.Pricing.CostPerImage)
-			}
-		}
-
-		printInfo("Generating image...")
-
-		// Use REST client instead of SDK client
-		client, err := generate.NewGeminiRESTClient(key)
-		if err != nil {
-			return fmt.Errorf("failed to create Gemini client: %w", err)
-		}
-		defer client.Close()
-
-		generatedImage, err = client.GenerateImage(ctx, prompt, options)
-	} else if selectedAPI == "vertex" {
-		// Use Vertex AI - check for Express Mode (API key) or Full Mode (service account)
-
-		// Get project and location from flags or environment
-		project, _ := cmd.Flags().GetString("project")
-		if project == "" {
-			project = os.Getenv("VERTEX_PROJECT")
-		}
-		location, _ := cmd.Flags().GetString("location")
-		if location == "" {
-			location = os.Getenv("VERTEX_LOCATION")
-			if location == "" {
-				location = "us-central1"
-			}
-		}
-
-		// Check for Vertex API key (Express Mode)
-		vertexAPIKey, err := config.GetVertexAPIKey(apiKey)
-		if err != nil {
-			return fmt.Errorf("failed to get Vertex API key: %w", err)
-		}
-
-		modelName := model
-		if modelName == "" {
-			modelName = "imagen-4.0-generate-001" // Default Imagen model
-		}
-
-		// Get provider info and announce selection
-		registry := generate.GetProviderRegistry()
-		provider, _ := registry.ResolveProvider(modelName)
-		if provider != nil {
-			printInfo("Using: %s (%s API)", provider.Name, provider.API)
-			pricingDisplay := "Variable"
-			if provider.Pricing.FreeTier {
-				pricingDisplay = fmt.Sprintf("FREE (%s)", provider.Pricing.FreeTierLimit)
-			} else if provider.Pricing.CostPerImage != nil {
-				pricingDisplay = fmt.Sprintf("$%.4f/image", *provider.Pricing.CostPerImage)
-			}
-			printInfo("Pricing: %s", pricingDisplay)
-
-			// Warn if expensive (cost > $0.05)
-			if provider.Pricing.CostPerImage != nil && *provider.Pricing.CostPerImage > 0.05 {
-				fmt.Fprintf(os.Stderr, "⚠️  %s costs $%.4f/image\n", provider.Name, *provider.Pricing.CostPerImage)
-			}
-		}
-
-		printInfo("Generating image...")
-
-		// Choose client based on authentication mode
-		if vertexAPIKey != "" {
-			// Express Mode - Use REST client with API key
-			printVerbose("Using Vertex AI Express Mode (API key authentication)")
-
-			// Load project and location from config if not provided
-			if project == "" {
-				cfg, err := config.LoadConfig()
-				if err == nil && cfg.VertexProject != "" {
-					project = cfg.VertexProject
-				}
-			}
-			if location == "" {
-				cfg, err := config.LoadConfig()
-				if err == nil && cfg.VertexLocation != "" {
-					location = cfg.VertexLocation
-				} else {
-					location = "us-central1"
-				}
-			}
-
-			client, err := generate.NewVertexRESTClient(vertexAPIKey, project, location)
-			if err != nil {
-				return fmt.Errorf("failed to create Vertex AI REST client: %w", err)
-			}
-			defer client.Close()
-
-			generatedImage, err = client.GenerateImage(ctx, prompt, options)
-		} else {
-			// Full Mode - Use SDK client with service account
-			printVerbose("Using Vertex AI Full Mode (service account authentication)")
-
-			client, err := generate.NewVertexSDKClient(ctx, project, location)
-			if err != nil {
-				return fmt.Errorf("failed to create Vertex AI client: %w", err)
-			}
-			defer client.Close()
-
-			generatedImage, err = client.GenerateImage(ctx, prompt, options)
-		}
-	} else if selectedAPI == "bedrock" {
-		// Use AWS Bedrock API - choose between REST (bearer token) or SDK (IAM/keys)
-		region := config.GetAWSRegion("")
-		printVerbose("Using AWS Bedrock region: %s", region)
-
-		modelName := model
-		if modelName == "" {
-			modelName = generate.ModelNovaCanvas
-		}
-
-		printVerbose("Using model: %s", modelName)
-
-		// Resolve provider alias and show info
-		registry := generate.GetProviderRegistry()
-		provider, err := registry.ResolveProvider(modelName)
-		if err != nil {
-			return fmt.Errorf("unknown provider/model: %s", modelName)
-		}
-
-		// Use the resolved full model ID
-		resolvedModelID := provider.ModelID
-		printVerbose("Resolved model ID: %s (Provider: %s)", resolvedModelID, provider.ID)
-
-		printVerbose("Provider: %s", provider.Name)
-		printVerbose("Model ID: %s", provider.ModelID)
-
-		// Show pricing info
-		if !provider.Pricing.FreeTier && provider.Pricing.CostPerImage != nil {
-			cost := *provider.Pricing.CostPerImage
-			printVerbose("Cost: $%.4f/image", cost)
-
-			// Warn if expensive (cost > $0.05)
-			if cost > 0.05 {
-				fmt.Fprintf(os.Stderr, "⚠️  %s costs $%.4f/image\n", provider.Name, cost)
-			}
-		}
-
-		// Update options to use resolved model ID (not alias)
-		bedrockOptions := options
-		bedrockOptions.Model = resolvedModelID
-
-		// Determine which authentication method to use
-		// Priority: Bearer token (REST) > AWS SDK (keys/profile/IAM)
-		cfg, _ := config.LoadConfig()
-		bearerToken := os.Getenv("AWS_BEARER_TOKEN_BEDROCK")
-		if bearerToken == "" && cfg != nil {
-			bearerToken = cfg.AWSBedrockAPIKey
-		}
-
-		if bearerToken != "" {
-			// Use REST client with bearer token
-			printVerbose("Using Bedrock REST API with bearer token authentication")
-			printInfo("Generating image with AWS Bedrock (REST API)...")
-
-			client, err := generate.NewBedrockRESTClient(bearerToken, region)
-			if err != nil {
-				return fmt.Errorf("failed to create AWS Bedrock REST client: %w", err)
-			}
-			defer client.Close()
-
-			generatedImage, err = client.GenerateImage(ctx, prompt, bedrockOptions)
-		} else {
-			// Use SDK client with IAM/keys/profile
-			printVerbose("Using Bedrock SDK with IAM/keys/profile authentication")
-			printInfo("Generating image with AWS Bedrock (SDK)...")
-
-			client, err := generate.NewBedrockSDKClient(ctx, region)
-			if err != nil {
-				return fmt.Errorf("failed to create AWS Bedrock SDK client: %w", err)
-			}
-			defer client.Close()
-
-			generatedImage, err = client.GenerateImage(ctx, prompt, bedrockOptions)
-		}
-	} else {
-		return fmt.Errorf("invalid API: %s (must be 'gemini', 'vertex', or 'bedrock')", selectedAPI)
+	if input == "" {
+		input = generate.AutoProvider
 	}
 
+	// Load config and apply credential flags on top of it
+	cfg, err := config.LoadConfig()
 	if err != nil {
-		return fmt.Errorf("failed to generate image: %w", err)
+		return fmt.Errorf("failed to load config: %w", err)
 	}
-
-	// Defensive nil check (should never happen if error handling is correct)
-	if generatedImage == nil {
-		return fmt.Errorf("internal error: generated image is nil but no error was returned - please report this bug")
+	if project != "" {
+		cfg.VertexProject = project
 	}
-
-	// Determine output path
-	if output == "" {
-		output = generate.GenerateOutputPath(generatedImage.Format)
+	if cmd.Flags().Changed("location") {
+		cfg.VertexLocation = location
 	}
-
-	// Save image
-	printInfo("Saving image to: %s", output)
-	if err := generate.SaveImage(generatedImage, output); err != nil {
-		return fmt.Errorf("failed to save image: %w", err)
+	if noFallback, _ := cmd.Flags().GetBool("no-fallback"); noFallback {
+		cfg.FallbackProviders = nil
 	}
 
-	// Print success with cost tracking
-	printSuccess("Image generated successfully!")
-	printInfo("  File: %s", output)
-	printInfo("  Size: %s", formatImageSize(int64(len(generatedImage.Data))))
-	printInfo("  Dimensions: %dx%d", generatedImage.Width, generatedImage.Height)
-
-	// Log cost and token usage
-	modelNameUsed := model
-	if modelNameUsed == "" {
-		if selectedAPI == "gemini" {
-			modelNameUsed = generate.DefaultModel
-		} else {
-			modelNameUsed = "imagen-4.0-generate-001"
+	provider, err := registry.SelectProvider(input, cfg)
+	if err != nil {
+		if providerID != "" {
+			// Show available providers on error
+			fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+			printAvailableProviders()
+			return fmt.Errorf("unknown provider: %s", providerID)
 		}
+		return err
+	}
+	if input != provider.ID {
+		printVerbose("Resolved '%s' to provider %s", input, provider.ID)
 	}
 
-	registry := generate.GetProviderRegistry()
-	if provider, err := registry.ResolveProvider(modelNameUsed); err == nil {
-		if provider.Pricing.FreeTier {
-			printInfo("  Cost: FREE (within %s)", provider.Pricing.FreeTierLimit)
-		} else if provider.Pricing.CostPerImage != nil {
-			printInfo("  Cost: $%.4f", *provider.Pricing.CostPerImage)
+	// --api-key applies to whichever API the provider uses
+	if apiKey != "" {
+		switch provider.API {
+		case "gemini":
+			if err := config.ValidateGeminiAPIKey(apiKey); err != nil {
+				return fmt.Errorf("invalid API key: %w", err)
+			}
+			cfg.GeminiAPIKey = apiKey
+		case "vertex":
+			cfg.VertexAPIKey = apiKey
+		case "bedrock":
+			cfg.AWSBedrockAPIKey = apiKey
+		case "openai":
+			cfg.OpenAIAPIKey = apiKey
 		}
 	}
 
-	return nil
-}
-
-// runGenerateWithProvider handles image generation using the new provider system
-func runGenerateWithProvider(cmd *cobra.Command, prompt, providerID, output, size, style, negative string, seed int64) error {
-	registry := generate.GetProviderRegistry()
-
-	// Resolve provider
-	provider, err := registry.ResolveProvider(providerID)
+	// Provider-specific --param key=value options, checked before any request
+	extra, err := generate.ParseOptionParams(params)
 	if err != nil {
-		// Show available providers on error
-		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
-		printAvailableProviders()
-		return fmt.Errorf("unknown provider: %s", providerID)
+		return err
 	}
-
-	// Check authentication
-	hasAuth, missing, err := registry.CheckAuth(provider)
+	extra, err = provider.ValidateOptions(extra)
 	if err != nil {
-		return fmt.Errorf("failed to check authentication: %w", err)
-	}
-
-	if !hasAuth {
-		fmt.Fprintf(os.Stderr, "Provider '%s' is not configured.\n", provider.ID)
-		fmt.Fprintf(os.Stderr, "Missing credentials: %v\n\n", missing)
-		fmt.Fprintf(os.Stderr, "To set up authentication:\n")
-		fmt.Fprintf(os.Stderr, "  gimage auth setup %s\n", provider.ID)
-		return fmt.Errorf("authentication required")
+		return err
 	}
 
 	// Show provider info
-	printInfo("Using provider: %s", provider.Name)
+	printInfo("Using: %s (%s API)", provider.Name, provider.API)
 	if provider.Pricing.FreeTier {
 		printInfo("Pricing: FREE (%s)", provider.Pricing.FreeTierLimit)
 	} else if provider.Pricing.CostPerImage != nil {
 		cost := *provider.Pricing.CostPerImage
-		printInfo("Pricing: $%.4f per image", cost)
+		printInfo("Pricing: $%.4f/image", cost)
 
-		// Warn if expensive
+		// Warn if expensive (cost > $0.05)
 		if cost > 0.05 {
-			fmt.Fprintf(os.Stderr, "⚠️  This will cost $%.4f per image\n", cost)
+			fmt.Fprintf(os.Stderr, "⚠️  %s costs $%.4f/image\n", provider.Name, cost)
 		}
+	} else {
+		printInfo("Pricing: Variable")
 	}
 
-	// Create client
-	printInfo("Creating client for %s...", provider.API)
-	client, err := registry.CreateClient(provider.ID)
+	// Create client (wrapped in a failover chain when fallback_providers is configured)
+	printVerbose("Creating client for %s...", provider.API)
+	client, _, err := registry.ResolveClient(provider.ID, cfg)
 	if err != nil {
 		return fmt.Errorf("failed to create client: %w", err)
 	}
 	defer client.Close()
+	if failover, ok := client.(*generate.FailoverGenerator); ok {
+		ids := make([]string, 0, len(failover.Providers()))
+		for _, p := range failover.Providers() {
+			ids = append(ids, p.ID)
+		}
+		printVerbose("Failover chain: %s", strings.Join(ids, " -> "))
+	}
+
+	// --aspect alone picks the provider's native size for that ratio
+	if aspect != "" && !cmd.Flags().Changed("size") {
+		size = ""
+	}
 
 	// Prepare options
 	options := models.GenerateOptions{
 		Model:          provider.ModelID,
 		Size:           size,
+		AspectRatio:    aspect,
 		Style:          style,
 		NegativePrompt: negative,
 		Seed:           seed,
+		Count:          count,
+		Extra:          extra,
 	}
 
-	// Generate image
-	printInfo("Generating image...")
-	ctx := context.Background()
+	// Request the closest native size; --fit brings the result to the exact size
+	targetWidth, targetHeight, err := generate.RequestedSize(options)
+	if err != nil {
+		return err
+	}
+	options, err = provider.NativeOptions(options)
+	if err != nil {
+		return err
+	}
+	if options.Size != size {
+		printVerbose("Requesting native size %s (aspect %s)", options.Size, options.AspectRatio)
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
 
+	printInfo("Generating image...")
 	startTime := time.Now()
-	generatedImage, err := client.GenerateImage(ctx, prompt, options)
+	generatedImages, err := generate.GenerateImages(ctx, client, prompt, options)
 	if err != nil {
 		return fmt.Errorf("failed to generate image: %w", err)
 	}
+	printVerbose("Generation completed in %.2fs", time.Since(startTime).Seconds())
 
-	elapsed := time.Since(startTime)
-	printInfo("Generation completed in %.2fs", elapsed.Seconds())
+	if err := generate.FitImages(generatedImages, targetWidth, targetHeight, fit); err != nil {
+		return err
+	}
+
+	// Defensive check (should never happen if error handling is correct)
+	if len(generatedImages) == 0 {
+		return fmt.Errorf("internal error: no images generated but no error was returned - please report this bug")
+	}
 
 	// Determine output path
 	if output == "" {
-		output = generate.GenerateOutputPath(generatedImage.Format)
+		output = generate.GenerateOutputPath(generatedImages[0].Format)
 	}
 
-	// Save image
+	// Save images
 	printInfo("Saving image to: %s", output)
-	if err := generate.SaveImage(generatedImage, output); err != nil {
+	paths, err := generate.SaveImages(generatedImages, output)
+	if err != nil {
 		return fmt.Errorf("failed to save image: %w", err)
 	}
 
-	// Print success with details
-	printSuccess("Image generated successfully!")
-	printInfo("  Provider: %s", provider.Name)
-	printInfo("  File: %s", output)
-	printInfo("  Size: %s", formatImageSize(int64(len(generatedImage.Data))))
-	printInfo("  Dimensions: %dx%d", generatedImage.Width, generatedImage.Height)
+	// Report the provider that actually served the request after a failover
+	if servedBy := generatedImages[0].Metadata["provider"]; servedBy != "" && servedBy != provider.ID {
+		if served, err := registry.Get(servedBy); err == nil {
+			printWarning("%s unavailable, served by %s", provider.ID, served.ID)
+			provider = served
+		}
+	}
 
-	// Show cost info
+	// Print success with cost tracking
+	printSavedImages(generatedImages, paths)
 	if provider.Pricing.FreeTier {
-		printInfo("  Cost: FREE (within daily limit)")
+		printInfo("  Cost: FREE (within %s)", provider.Pricing.FreeTierLimit)
 	} else if provider.Pricing.CostPerImage != nil {
-		printInfo("  Cost: $%.4f", *provider.Pricing.CostPerImage)
+		printInfo("  Cost: $%.4f", *provider.Pricing.CostPerImage*float64(len(generatedImages)))
 	}
 
 	return nil
 }
 
+// printSavedImages prints the success summary for one or more saved images
+func printSavedImages(images []*models.GeneratedImage, paths []string) {
+	if len(images) == 1 {
+		printSuccess("Image generated successfully!")
+		printInfo("  File: %s", paths[0])
+		printInfo("  Size: %s", formatImageSize(int64(len(images[0].Data))))
+		printInfo("  Dimensions: %dx%d", images[0].Width, images[0].Height)
+		return
+	}
+
+	printSuccess("%d images generated successfully!", len(images))
+	for i, image := range images {
+		printInfo("  %d. %s (%s, %dx%d)", i+1, paths[i], formatImageSize(int64(len(image.Data))), image.Width, image.Height)
+	}
+}
+
 // printAvailableProviders prints the list of available providers with auth status
 func printAvailableProviders() error {
 	registry := generate.GetProviderRegistry()
@@ -657,6 +447,9 @@ func printAvailableProviders() error {
 	geminiProviders := []generate.AuthStatus{}
 	vertexProviders := []generate.AuthStatus{}
 	bedrockProviders := []generate.AuthStatus{}
+	openaiProviders := []generate.AuthStatus{}
+	sdProviders := []generate.AuthStatus{}
+	localProviders := []generate.AuthStatus{}
 
 	for _, status := range statuses {
 		switch status.Provider.API {
@@ -666,6 +459,12 @@ func printAvailableProviders() error {
 			vertexProviders = append(vertexProviders, status)
 		case "bedrock":
 			bedrockProviders = append(bedrockProviders, status)
+		case "openai":
+			openaiProviders = append(openaiProviders, status)
+		case "sd":
+			sdProviders = append(sdProviders, status)
+		case "local":
+			localProviders = append(localProviders, status)
 		}
 	}
 
@@ -696,6 +495,33 @@ func printAvailableProviders() error {
 		fmt.Println()
 	}
 
+	// Print OpenAI-compatible providers
+	if len(openaiProviders) > 0 {
+		fmt.Println("OpenAI-compatible API (OpenAI, Azure OpenAI, LocalAI):")
+		for _, status := range openaiProviders {
+			printProviderStatus(status)
+		}
+		fmt.Println()
+	}
+
+	// Print self-hosted Stable Diffusion providers
+	if len(sdProviders) > 0 {
+		fmt.Println("Self-hosted Stable Diffusion (Automatic1111, ComfyUI):")
+		for _, status := range sdProviders {
+			printProviderStatus(status)
+		}
+		fmt.Println()
+	}
+
+	// Print local providers
+	if len(localProviders) > 0 {
+		fmt.Println("Local (offline, for development and tests):")
+		for _, status := range localProviders {
+			printProviderStatus(status)
+		}
+		fmt.Println()
+	}
+
 	fmt.Println(strings.Repeat("─", 80))
 	fmt.Println("\nUsage:")
 	fmt.Println("  gimage generate \"prompt\" --provider <provider-id>")
@@ -734,6 +560,12 @@ func printProviderStatus(status generate.AuthStatus) {
 		pricing,
 		statusText,
 	)
+	if ratios := p.AspectRatios(); len(ratios) > 0 {
+		fmt.Printf("      --aspect: %s\n", strings.Join(ratios, ", "))
+	}
+	if len(p.Capabilities.Options) > 0 {
+		fmt.Printf("      --param: %s\n", strings.Join(p.OptionNames(), ", "))
+	}
 }
 
 // printAvailableModels displays all available providers in a formatted table
@@ -746,6 +578,8 @@ func printAvailableModels() error {
 	geminiProviders := []generate.AuthStatus{}
 	vertexProviders := []generate.AuthStatus{}
 	bedrockProviders := []generate.AuthStatus{}
+	openaiProviders := []generate.AuthStatus{}
+	sdProviders := []generate.AuthStatus{}
 
 	for _, status := range statuses {
 		switch status.Provider.API {
@@ -755,6 +589,10 @@ func printAvailableModels() error {
 			vertexProviders = append(vertexProviders, status)
 		case "bedrock":
 			bedrockProviders = append(bedrockProviders, status)
+		case "openai":
+			openaiProviders = append(openaiProviders, status)
+		case "sd":
+			sdProviders = append(sdProviders, status)
 		}
 	}
 
@@ -910,6 +748,90 @@ func printAvailableModels() error {
 	}
 	printInfo("└─────────────────────────────────────────────────────────────────────────────────┘\n")
 
+	// Print OpenAI-compatible providers - ALWAYS show, indicate auth status
+	hasOpenAI := false
+	for _, status := range openaiProviders {
+		if status.Configured {
+			hasOpenAI = true
+			break
+		}
+	}
+	printInfo("┌─────────────────────────────────────────────────────────────────────────────────┐")
+	if hasOpenAI {
+		printSuccess("│ ✓ OpenAI-compatible API (AUTHENTICATED - Paid, OpenAI/Azure/LocalAI)           │")
+	} else {
+		printWarning("│ ○ OpenAI API (NOT AUTHENTICATED - Setup: gimage auth setup openai/images)      │")
+	}
+	printInfo("├─────────────────────────────────────────────────────────────────────────────────┤")
+	printInfo("│ Providers:                                                                      │")
+	printInfo("├─────────────────────────────────────────────────────────────────────────────────┤")
+	for _, status := range openaiProviders {
+		p := status.Provider
+		// Format pricing
+		pricingDisplay := "Variable"
+		if p.Pricing.CostPerImage != nil {
+			pricingDisplay = fmt.Sprintf("$%.4f/image", *p.Pricing.CostPerImage)
+		}
+
+		authMark := greenYes()
+		if !status.Configured {
+			authMark = redNo()
+		}
+		// Display provider name (73 chars for name)
+		paddedName := padRight(p.Name, 73)
+		printInfo("│ %s  %s │", authMark, paddedName)
+		// Print details on second line (indented)
+		printInfo("│     Provider ID: %-20s  Pricing: %-35s │", p.ID, pricingDisplay)
+		if viper.GetBool("verbose") {
+			printVerbose("│     %s", p.Description)
+			printVerbose("│     Model ID: %s", p.ModelID)
+		}
+	}
+	printInfo("└─────────────────────────────────────────────────────────────────────────────────┘\n")
+
+	// Print self-hosted Stable Diffusion providers - ALWAYS show, indicate auth status
+	hasSD := false
+	for _, status := range sdProviders {
+		if status.Configured {
+			hasSD = true
+			break
+		}
+	}
+	printInfo("┌─────────────────────────────────────────────────────────────────────────────────┐")
+	if hasSD {
+		printSuccess("│ ✓ Stable Diffusion (CONFIGURED - FREE, self-hosted A1111/ComfyUI)              │")
+	} else {
+		printWarning("│ ○ Stable Diffusion (NOT CONFIGURED - Setup: gimage auth setup sd/a1111)        │")
+	}
+	printInfo("├─────────────────────────────────────────────────────────────────────────────────┤")
+	printInfo("│ Providers:                                                                      │")
+	printInfo("├─────────────────────────────────────────────────────────────────────────────────┤")
+	for _, status := range sdProviders {
+		p := status.Provider
+		// Format pricing
+		pricingDisplay := "Variable"
+		if p.Pricing.FreeTier {
+			pricingDisplay = fmt.Sprintf("FREE (%s)", p.Pricing.FreeTierLimit)
+		} else if p.Pricing.CostPerImage != nil {
+			pricingDisplay = fmt.Sprintf("$%.4f/image", *p.Pricing.CostPerImage)
+		}
+
+		authMark := greenYes()
+		if !status.Configured {
+			authMark = redNo()
+		}
+		// Display provider name (73 chars for name)
+		paddedName := padRight(p.Name, 73)
+		printInfo("│ %s  %s │", authMark, paddedName)
+		// Print details on second line (indented)
+		printInfo("│     Provider ID: %-20s  Pricing: %-35s │", p.ID, pricingDisplay)
+		if viper.GetBool("verbose") {
+			printVerbose("│     %s", p.Description)
+			printVerbose("│     Model ID: %s", p.ModelID)
+		}
+	}
+	printInfo("└─────────────────────────────────────────────────────────────────────────────────┘\n")
+
 	printInfo("╔═══════════════════════════════════════════════════════════════════════════════╗")
 	printInfo("║                                   LEGEND                                      ║")
 	printInfo("╠═══════════════════════════════════════════════════════════════════════════════╣")
@@ -938,6 +860,12 @@ func printAvailableModels() error {
 	} else {
 		printInfo("║    ○ AWS users:   nova-canvas (setup: gimage auth bedrock)                   ║")
 	}
+	if hasOpenAI {
+		printInfo("║    ✓ OpenAI:      openai (OpenAI-compatible endpoint)                        ║")
+	}
+	if hasSD {
+		printInfo("║    ✓ On-prem:     a1111 / comfyui (FREE, self-hosted)                        ║")
+	}
 	printInfo("╠═══════════════════════════════════════════════════════════════════════════════╣")
 	printInfo("║  Examples:                                                                    ║")
 	if hasGemini {
@@ -950,6 +878,9 @@ func printAvailableModels() error {
 		printInfo("║    gimage generate \"landscape\" --api bedrock                                 ║")
 		printInfo("║    gimage generate \"portrait\" --model nova-canvas                            ║")
 	}
+	if hasOpenAI {
+		printInfo("║    gimage generate \"poster\" --model openai                                   ║")
+	}
 	printInfo("╚═══════════════════════════════════════════════════════════════════════════════╝")
 
 	if !hasAnyAuth {
@@ -972,16 +903,21 @@ func init() {
 	generateCmd.Flags().StringP("output", "o", "", "Output file path (default: generated_<timestamp>.png)")
 	generateCmd.Flags().String("provider", "", "Provider to use (e.g., gemini/flash-2.5, vertex/imagen-4)")
 	generateCmd.Flags().String("api", "", "API to use: gemini or vertex (deprecated, use --provider)")
-	generateCmd.Flags().String("api-key", "", "Gemini API key (or use GEMINI_API_KEY env var)")
-	generateCmd.Flags().String("project", "", "Vertex AI project ID (or use GIMAGE_VERTEX_PROJECT env var)")
+	generateCmd.Flags().String("api-key", "", "API key for the selected provider (or use GEMINI_API_KEY / VERTEX_API_KEY env vars)")
+	generateCmd.Flags().String("project", "", "Vertex AI project ID (or use VERTEX_PROJECT env var)")
 	generateCmd.Flags().String("location", "us-central1", "Vertex AI location")
 	generateCmd.Flags().String("model", "", fmt.Sprintf("Model to use (deprecated, use --provider). Default: %s", generate.DefaultModel))
 	generateCmd.Flags().Bool("list-models", false, "List all available models and exit")
 	generateCmd.Flags().Bool("list-providers", false, "List all available providers with pricing and auth status")
 	generateCmd.Flags().String("size", "1024x1024", "Image size (e.g., 1024x1024, 512x512)")
+	generateCmd.Flags().String("aspect", "", "Aspect ratio, e.g. 16:9 (uses the provider's native size for that ratio)")
+	generateCmd.Flags().String("fit", generate.FitCrop, "Bring the native size to the exact --size: crop, resize or none")
 	generateCmd.Flags().String("style", "", "Image style: photorealistic, artistic, anime")
 	generateCmd.Flags().String("negative", "", "Negative prompt to avoid certain features")
 	generateCmd.Flags().Int64("seed", 0, "Random seed for reproducibility (0 for random)")
+	generateCmd.Flags().StringArray("param", nil, "Provider-specific option as key=value (repeatable, e.g. --param cfgScale=8); see --list-providers")
+	generateCmd.Flags().Bool("no-fallback", false, "Disable the fallback_providers failover chain for this request")
+	generateCmd.Flags().IntP("count", "n", 1, fmt.Sprintf("Number of images to generate (1-%d), saved as name_1.png ... name_N.png", generate.MaxImageCount))
 
 	// Bind to viper for config file support
 	viper.BindPFlag("generate.api", generateCmd.Flags().Lookup("api"))
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/apresai/gimage/internal/imaging"
+	"github.com/spf13/cobra"
+)
+
+// addMetadataFlags adds the metadata policy flags shared by the image
+// processing commands. Without them only the ICC color profile is kept.
+func addMetadataFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("keep-metadata", false, "keep EXIF, XMP and the ICC color profile (default: color profile only)")
+	cmd.Flags().Bool("strip-metadata", false, "remove all metadata, including the ICC color profile")
+	cmd.Flags().Bool("strip-gps", false, "keep EXIF and the color profile but remove GPS location data (drops XMP)")
+}
+
+// metadataPolicy returns the policy selected by the metadata flags
+func metadataPolicy(cmd *cobra.Command) (imaging.MetadataPolicy, error) {
+	keep, _ := cmd.Flags().GetBool("keep-metadata")
+	strip, _ := cmd.Flags().GetBool("strip-metadata")
+	stripGPS, _ := cmd.Flags().GetBool("strip-gps")
+
+	switch {
+	case strip && (keep || stripGPS):
+		return "", fmt.Errorf("--strip-metadata cannot be combined with --keep-metadata or --strip-gps")
+	case strip:
+		return imaging.MetadataStrip, nil
+	case stripGPS:
+		return imaging.MetadataStripGPS, nil
+	case keep:
+		return imaging.MetadataKeep, nil
+	default:
+		return imaging.MetadataColor, nil
+	}
+}
+
+// metadataOption sets opts.Metadata to the policy selected by the metadata
+// flags, for the imaging file functions
+func metadataOption(cmd *cobra.Command, opts *imaging.FileOptions) error {
+	policy, err := metadataPolicy(cmd)
+	if err != nil {
+		return err
+	}
+	printVerbose("Metadata: %s", policy)
+	opts.Metadata = policy
+	return nil
+}
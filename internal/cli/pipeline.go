@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apresai/gimage/internal/imaging"
+	"github.com/spf13/cobra"
+)
+
+// pipelineCmd represents the pipeline command
+var pipelineCmd = &cobra.Command{
+	Use:   "pipeline",
+	Short: "Apply several operations to an image in one pass",
+	Long: `Apply a chain of operations to an image in memory. The image is decoded once,
+every step runs on the decoded pixels, and the result is encoded once, so
+chaining steps costs no extra decode/encode cycles or lossy generations.
+
+Steps run in the order given:
+  crop:X,Y,W,H         Crop the WxH region at X,Y
+  crop:WxH[@ANCHOR]    Crop WxH from the center or an anchor (top, bottom, left,
+                       right, top-left, top-right, bottom-left, bottom-right)
+  resize:WxH           Resize to exactly WxH
+  fit:WxH              Resize to fit within WxH, preserving aspect ratio
+  fill:WxH[@ANCHOR]    Cover WxH and crop the overflow at an anchor (default
+                       center), or at the most interesting part with @smart
+  pad:WxH[@BACKGROUND] Fit inside WxH and letterbox on a color name, hex color
+                       or blur (default black)
+  scale:FACTOR         Scale both dimensions by FACTOR
+  sharpen:AMOUNT       Sharpen with an unsharp mask (0.5-1.5 suits a downscale)
+  compress:QUALITY     Encode with quality 1-100 (JPEG, lossy WebP, AVIF)
+  convert:FORMAT       Encode as png, jpg, gif, webp, avif, tiff or bmp
+  frame:N              Keep only frame N (from 0) of an animated GIF or WebP
+
+Without a convert step the output format follows the output file extension.
+--filter sets the resampling filter of the resize, fit, fill, pad and scale steps.
+Animated GIF and WebP inputs keep every frame when written as GIF or WebP.
+
+Examples:
+  gimage pipeline -i photo.jpg --step crop:0,0,1600,900 --step resize:800x450 -o banner.jpg
+  gimage pipeline -i photo.jpg --step crop:1024x1024 --step fit:512x512 --step convert:webp
+  gimage pipeline -i scan.png --step scale:0.5 --step compress:75 -o scan.jpg
+  gimage pipeline -i photo.jpg --step fit:256x256 --step sharpen:0.8 --filter catmullrom
+  gimage pipeline -i photo.jpg --step fill:1200x630@top --step convert:webp`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get flag values
+		inputPath, _ := cmd.Flags().GetString("input")
+		outputPath, _ := cmd.Flags().GetString("output")
+		specs, _ := cmd.Flags().GetStringArray("step")
+
+		// Validate required flags
+		if inputPath == "" {
+			return fmt.Errorf("--input flag is required")
+		}
+		if len(specs) == 0 {
+			return fmt.Errorf("at least one --step is required")
+		}
+		steps, err := imaging.ParseSteps(specs)
+		if err != nil {
+			return err
+		}
+		policy, err := metadataPolicy(cmd)
+		if err != nil {
+			return err
+		}
+		filter, err := filterFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Validate input file exists
+		if _, err := os.Stat(inputPath); os.IsNotExist(err) {
+			return fmt.Errorf("input file does not exist: %s", inputPath)
+		}
+
+		printInfo("Processing %s (%d steps)...", inputPath, len(steps))
+		printVerbose("Input: %s", inputPath)
+		printVerbose("Steps: %s", strings.Join(specs, " -> "))
+
+		p, err := imaging.OpenPipeline(inputPath)
+		if err != nil {
+			return fmt.Errorf("pipeline failed: %w", err)
+		}
+		speed, _ := cmd.Flags().GetInt("speed")
+		if err := p.Metadata(policy).Speed(speed).Filter(filter).Err(); err != nil {
+			return err
+		}
+		for _, step := range steps {
+			if err := p.Apply(step).Err(); err != nil {
+				return fmt.Errorf("pipeline step %s failed: %w", step, err)
+			}
+		}
+
+		// Generate output path if not provided: input_processed.ext, using
+		// the convert step's format when there is one
+		if outputPath == "" {
+			ext := filepath.Ext(inputPath)
+			base := inputPath[:len(inputPath)-len(ext)]
+			for _, step := range steps {
+				if step.Op == imaging.StepConvert {
+					ext = "." + imaging.FormatExtension(step.Format)
+				}
+			}
+			outputPath = fmt.Sprintf("%s_processed%s", base, ext)
+		}
+		printVerbose("Output: %s", outputPath)
+
+		if err := p.Save(outputPath); err != nil {
+			return fmt.Errorf("failed to save %s: %w", outputPath, err)
+		}
+
+		// Report success
+		sourceWidth, sourceHeight := p.SourceSize()
+		width, height := p.Size()
+		printSuccess("Processed successfully!")
+		printInfo("Output: %s", outputPath)
+		printInfo("Dimensions: %dx%d -> %dx%d", sourceWidth, sourceHeight, width, height)
+		if p.Animated() {
+			printInfo("Frames: %d", p.FrameCount())
+		}
+		if size, err := getFileSize(outputPath); err == nil {
+			printInfo("Size: %s", formatFileSize(size))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pipelineCmd)
+
+	// Flags for pipeline command
+	pipelineCmd.Flags().StringP("input", "i", "", "input image file path (required)")
+	pipelineCmd.Flags().StringP("output", "o", "", "output file path (default: input_processed.ext)")
+	pipelineCmd.Flags().StringArray("step", nil, "operation to apply, e.g. resize:800x600 (repeatable, applied in order)")
+	addFilterFlag(pipelineCmd)
+	addSpeedFlag(pipelineCmd)
+	addMetadataFlags(pipelineCmd)
+
+	pipelineCmd.MarkFlagRequired("input")
+	pipelineCmd.MarkFlagRequired("step")
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apresai/gimage/internal/imaging"
+	"github.com/spf13/cobra"
+)
+
+// addFilterFlag adds the resampling filter flag
+func addFilterFlag(cmd *cobra.Command) {
+	cmd.Flags().String("filter", "", fmt.Sprintf("resampling filter: %s (default: %s)",
+		strings.Join(imaging.FilterNames(), ", "), imaging.FilterLanczos))
+}
+
+// addResampleFlags adds the resampling filter and post-resize sharpen flags
+func addResampleFlags(cmd *cobra.Command) {
+	addFilterFlag(cmd)
+	cmd.Flags().Float64("sharpen", 0, fmt.Sprintf("sharpen the result by this amount, 0-%g (about 0.5-1.5 after a large downscale; default: off)",
+		imaging.MaxSharpen))
+}
+
+// filterFlag returns the filter selected by the --filter flag
+func filterFlag(cmd *cobra.Command) (imaging.Filter, error) {
+	name, _ := cmd.Flags().GetString("filter")
+	filter, err := imaging.ParseFilter(name)
+	if err != nil {
+		return "", fmt.Errorf("--filter: %w", err)
+	}
+	return filter, nil
+}
+
+// resampleOptions sets opts.Filter and opts.Sharpen to the --filter and
+// --sharpen flags, for the imaging file functions
+func resampleOptions(cmd *cobra.Command, opts *imaging.FileOptions) error {
+	filter, err := filterFlag(cmd)
+	if err != nil {
+		return err
+	}
+	sharpen, _ := cmd.Flags().GetFloat64("sharpen")
+	if sharpen < 0 || sharpen > imaging.MaxSharpen {
+		return fmt.Errorf("--sharpen must be between 0 and %g", imaging.MaxSharpen)
+	}
+	printVerbose("Filter: %s", filter)
+	if sharpen > 0 {
+		printVerbose("Sharpen: %g", sharpen)
+	}
+	opts.Filter, opts.Sharpen = filter, sharpen
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/apresai/gimage/internal/imaging"
 	"github.com/spf13/cobra"
@@ -16,15 +17,37 @@ var resizeCmd = &cobra.Command{
 	Short: "Resize an image to specific dimensions",
 	Long: `Resize an image to specific dimensions using high-quality Lanczos resampling.
 
+--mode chooses how the aspect ratio is treated:
+  stretch   Resize to exactly WIDTHxHEIGHT (default)
+  fit       Fit within WIDTHxHEIGHT, preserving aspect ratio
+  fill      Cover WIDTHxHEIGHT and crop the overflow at --anchor
+  pad       Fit inside WIDTHxHEIGHT and letterbox on --background
+
+--filter picks another resampling filter: nearest keeps the hard edges of
+pixel art, linear and catmullrom are faster for bulk thumbnails. --sharpen
+restores edge contrast after a large downscale.
+
+Animated GIF and WebP files keep every frame, with their delays and loop
+count; --frame N writes a single frame instead.
+
 Examples:
   gimage resize --input input.jpg --width 800 --height 600
-  gimage resize -i input.png -w 1920 -h 1080 --output resized.png`,
+  gimage resize -i input.png -w 1920 -h 1080 --output resized.png
+  gimage resize -i photo.jpg --width 1024 --height 1024 --mode fit --sharpen 0.8
+  gimage resize -i sprite.png --width 256 --height 256 --filter nearest
+  gimage resize -i photo.jpg --width 1200 --height 630 --mode fill --anchor top
+  gimage resize -i photo.jpg --width 1080 --height 1080 --mode pad --background blur
+  gimage resize -i logo.png --width 512 --height 512 --mode pad --background transparent
+  gimage resize -i banner.gif --width 364 --height 45`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get flag values
 		inputPath, _ := cmd.Flags().GetString("input")
 		width, _ := cmd.Flags().GetInt("width")
 		height, _ := cmd.Flags().GetInt("height")
 		outputPath, _ := cmd.Flags().GetString("output")
+		modeName, _ := cmd.Flags().GetString("mode")
+		anchor, _ := cmd.Flags().GetString("anchor")
+		background, _ := cmd.Flags().GetString("background")
 
 		// Validate required flags
 		if inputPath == "" {
@@ -36,6 +59,15 @@ Examples:
 		if height <= 0 {
 			return fmt.Errorf("--height must be a positive integer")
 		}
+		mode, err := imaging.ParseResizeMode(modeName)
+		if err != nil {
+			return fmt.Errorf("--mode: %w", err)
+		}
+		opts := imaging.ResizeOptions{Mode: mode, Anchor: anchor, Background: background}
+		step := imaging.ResizeStep(width, height, opts)
+		if err := step.Validate(); err != nil {
+			return err
+		}
 
 		// Get output path or generate default
 		if outputPath == "" {
@@ -55,9 +87,25 @@ Examples:
 		printVerbose("Input: %s", inputPath)
 		printVerbose("Output: %s", outputPath)
 		printVerbose("Dimensions: %dx%d", width, height)
+		printVerbose("Mode: %s", mode)
+		switch mode {
+		case imaging.ResizeModeFill:
+			printVerbose("Anchor: %s", step.Anchor)
+		case imaging.ResizeModePad:
+			printVerbose("Background: %s", step.Background)
+		}
 
-		ctx := context.Background()
-		err := imaging.ResizeImage(ctx, inputPath, outputPath, width, height)
+		var fileOpts imaging.FileOptions
+		if err := metadataOption(cmd, &fileOpts); err != nil {
+			return err
+		}
+		if err := frameOption(cmd, &fileOpts, inputPath, outputPath); err != nil {
+			return err
+		}
+		if err := resampleOptions(cmd, &fileOpts); err != nil {
+			return err
+		}
+		err = imaging.ResizeWithOptions(context.Background(), inputPath, outputPath, width, height, opts, fileOpts)
 		if err != nil {
 			return fmt.Errorf("resize failed: %w", err)
 		}
@@ -84,6 +132,12 @@ func init() {
 	resizeCmd.Flags().Int("width", 0, "target width in pixels (required)")
 	resizeCmd.Flags().Int("height", 0, "target height in pixels (required)")
 	resizeCmd.Flags().StringP("output", "o", "", "output file path (default: input_resized_WxH.ext)")
+	resizeCmd.Flags().String("mode", string(imaging.ResizeModeStretch), fmt.Sprintf("resize mode: %s", strings.Join(imaging.ResizeModeNames(), ", ")))
+	resizeCmd.Flags().String("anchor", "center", fmt.Sprintf("part of the image kept by --mode fill: %s, or %s for the most interesting part", strings.Join(imaging.AnchorNames(), ", "), imaging.AnchorSmart))
+	resizeCmd.Flags().String("background", imaging.DefaultBackground, "letterbox background for --mode pad: black, white, gray, transparent, a hex color such as #1e90ff, or blur")
+	addResampleFlags(resizeCmd)
+	addFrameFlag(resizeCmd)
+	addMetadataFlags(resizeCmd)
 
 	resizeCmd.MarkFlagRequired("input")
 	resizeCmd.MarkFlagRequired("width")
@@ -41,6 +41,7 @@ AUTHENTICATION:
 
 AVAILABLE COMMANDS:
   generate    Generate images from text prompts using AI
+  edit        Edit existing images from text instructions using AI
   resize      Resize images to specific dimensions
   scale       Scale images by a factor (e.g., 0.5 for half, 2.0 for double)
   crop        Crop images to specific regions
@@ -145,7 +146,7 @@ TIPS:
   • Use --help on any command for more details (e.g., "gimage generate --help")
   • Default output filenames are auto-generated with timestamps
   • For batch operations, use MCP server with Claude Desktop
-  • All image processing uses high-quality Lanczos resampling
+  • Resizing uses high-quality Lanczos resampling; --filter picks another
   • Config file location: ~/.gimage/config.md
 
 For more information, visit: https://github.com/apresai/gimage`,
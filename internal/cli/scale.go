@@ -16,9 +16,18 @@ var scaleCmd = &cobra.Command{
 	Short: "Scale an image by a factor",
 	Long: `Scale an image by a factor (e.g., 0.5 for half size, 2.0 for double size).
 
+Lanczos resampling is used unless --filter picks another: nearest keeps the
+hard edges of pixel art, linear and catmullrom are faster for bulk
+thumbnails. --sharpen restores edge contrast after a large downscale.
+
+Animated GIF and WebP files are scaled frame by frame; --frame N writes a
+single frame instead.
+
 Examples:
   gimage scale --input input.jpg --factor 0.5
-  gimage scale -i input.png -f 2.0 --output scaled.png`,
+  gimage scale -i input.png -f 2.0 --output scaled.png
+  gimage scale -i sprite.png -f 4 --filter nearest
+  gimage scale -i photo.jpg -f 0.1 --sharpen 0.8`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get flag values
 		inputPath, _ := cmd.Flags().GetString("input")
@@ -50,7 +59,17 @@ Examples:
 		printVerbose("Input: %s", inputPath)
 		printVerbose("Output: %s", outputPath)
 		printVerbose("Scale factor: %.2f", factor)
-		err := imaging.ScaleImage(context.Background(), inputPath, outputPath, factor)
+		var fileOpts imaging.FileOptions
+		if err := metadataOption(cmd, &fileOpts); err != nil {
+			return err
+		}
+		if err := frameOption(cmd, &fileOpts, inputPath, outputPath); err != nil {
+			return err
+		}
+		if err := resampleOptions(cmd, &fileOpts); err != nil {
+			return err
+		}
+		err := imaging.ScaleImage(context.Background(), inputPath, outputPath, factor, fileOpts)
 		if err != nil {
 			return fmt.Errorf("scale failed: %w", err)
 		}
@@ -72,6 +91,9 @@ func init() {
 	scaleCmd.Flags().StringP("input", "i", "", "input image file path (required)")
 	scaleCmd.Flags().Float64P("factor", "f", 0, "scale factor (e.g., 0.5 = half, 2.0 = double) (required)")
 	scaleCmd.Flags().StringP("output", "o", "", "output file path (default: input_scaled_FACTORx.ext)")
+	addResampleFlags(scaleCmd)
+	addFrameFlag(scaleCmd)
+	addMetadataFlags(scaleCmd)
 
 	scaleCmd.MarkFlagRequired("input")
 	scaleCmd.MarkFlagRequired("factor")
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/apresai/gimage/internal/config"
@@ -21,8 +22,15 @@ var serveCmd = &cobra.Command{
 	Long: `Start the gimage MCP (Model Context Protocol) server.
 
 This allows AI assistants like Claude to use gimage for image generation
-and processing operations. The server communicates over stdio using the
-MCP protocol.
+and processing operations. By default the server communicates over stdio
+using the MCP protocol, as a child process of the assistant.
+
+With --transport http it serves the MCP Streamable HTTP transport instead:
+clients POST JSON-RPC messages to --path on --addr and get the responses
+back as JSON or as a server-sent event stream. One shared server can then
+serve several agents, and remote IDEs can connect to it. Set --token (or
+GIMAGE_MCP_TOKEN) to require "Authorization: Bearer <token>"; the server
+refuses to start without one when --addr listens beyond localhost.
 
 USAGE WITH CLAUDE DESKTOP:
 
@@ -54,13 +62,15 @@ The serve command respects the same environment variables as the CLI:
 
 FEATURES:
 
-The MCP server exposes 10 tools to AI assistants:
+The MCP server exposes 12 tools to AI assistants:
   • generate_image    - AI image generation with Gemini/Vertex
+  • edit_image        - AI image editing with Gemini/Nova Canvas
   • resize_image      - Resize to specific dimensions
   • scale_image       - Scale by factor
   • crop_image        - Crop to region
   • compress_image    - Compress to reduce file size
   • convert_image     - Convert between formats
+  • process_pipeline  - Chain operations with one decode/encode
   • batch_resize      - Batch resize operations
   • batch_compress    - Batch compression
   • batch_convert     - Batch format conversion
@@ -77,6 +87,12 @@ EXAMPLES:
   # Use custom config file
   $ gimage serve --config ~/.gimage/custom-config.yaml
 
+  # Shared server over HTTP at http://localhost:8787/mcp
+  $ gimage serve --transport http --addr localhost:8787
+
+  # Reachable from other machines, with a bearer token
+  $ GIMAGE_MCP_TOKEN=s3cret gimage serve --transport http --addr :8787
+
 TROUBLESHOOTING:
 
 If the MCP server isn't working in Claude:
@@ -98,16 +114,35 @@ For more information: https://github.com/apresai/gimage`,
 		// Get verbose flag
 		verbose := viper.GetBool("verbose")
 
+		transport, _ := cmd.Flags().GetString("transport")
+		addr, _ := cmd.Flags().GetString("addr")
+		path, _ := cmd.Flags().GetString("path")
+		token, _ := cmd.Flags().GetString("token")
+		if token == "" {
+			token = os.Getenv("GIMAGE_MCP_TOKEN")
+		}
+		if transport != "stdio" && transport != "http" {
+			return fmt.Errorf("--transport must be stdio or http, got %q", transport)
+		}
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("--path must start with /, got %q", path)
+		}
+		if transport == "http" && token == "" && !mcp.IsLoopbackAddr(addr) {
+			return fmt.Errorf("--addr %s listens beyond localhost; set --token or GIMAGE_MCP_TOKEN to require a bearer token", addr)
+		}
+
 		// Create MCP server
 		server := mcp.NewMCPServer("gimage", version, cfg, verbose)
 
 		// Register all tools
 		tools.RegisterGenerateImageTool(server)
+		tools.RegisterEditImageTool(server)
 		tools.RegisterResizeImageTool(server)
 		tools.RegisterScaleImageTool(server)
 		tools.RegisterCropImageTool(server)
 		tools.RegisterCompressImageTool(server)
 		tools.RegisterConvertImageTool(server)
+		tools.RegisterProcessPipelineTool(server)
 		tools.RegisterBatchResizeTool(server)
 		tools.RegisterBatchCompressTool(server)
 		tools.RegisterBatchConvertTool(server)
@@ -133,8 +168,15 @@ For more information: https://github.com/apresai/gimage`,
 		if verbose {
 			fmt.Fprintln(os.Stderr, "[gimage-mcp] Starting MCP server")
 			fmt.Fprintln(os.Stderr, "[gimage-mcp] Protocol: Model Context Protocol")
-			fmt.Fprintln(os.Stderr, "[gimage-mcp] Transport: stdio")
-			fmt.Fprintln(os.Stderr, "[gimage-mcp] Tools: 10 registered")
+			if transport == "http" {
+				fmt.Fprintf(os.Stderr, "[gimage-mcp] Transport: Streamable HTTP on %s%s\n", addr, path)
+				if token == "" {
+					fmt.Fprintln(os.Stderr, "[gimage-mcp] ⚠️  No --token set: any client that can reach the address can use the server")
+				}
+			} else {
+				fmt.Fprintln(os.Stderr, "[gimage-mcp] Transport: stdio")
+			}
+			fmt.Fprintf(os.Stderr, "[gimage-mcp] Tools: %d registered\n", server.ToolCount())
 			fmt.Fprintln(os.Stderr, "")
 
 			// Show available providers with pricing
@@ -143,6 +185,15 @@ For more information: https://github.com/apresai/gimage`,
 			registry := generate.GetProviderRegistry()
 			statuses := registry.GetAuthStatus()
 
+			// The local mock is always configured but never a real default - leave it out
+			realStatuses := statuses[:0]
+			for _, status := range statuses {
+				if status.Provider.API != "local" {
+					realStatuses = append(realStatuses, status)
+				}
+			}
+			statuses = realStatuses
+
 			// Count configured providers
 			configuredCount := 0
 			for _, status := range statuses {
@@ -163,6 +214,8 @@ For more information: https://github.com/apresai/gimage`,
 				geminiProviders := []generate.AuthStatus{}
 				vertexProviders := []generate.AuthStatus{}
 				bedrockProviders := []generate.AuthStatus{}
+				openaiProviders := []generate.AuthStatus{}
+				sdProviders := []generate.AuthStatus{}
 
 				for _, status := range statuses {
 					if !status.Configured {
@@ -175,6 +228,10 @@ For more information: https://github.com/apresai/gimage`,
 						vertexProviders = append(vertexProviders, status)
 					case "bedrock":
 						bedrockProviders = append(bedrockProviders, status)
+					case "openai":
+						openaiProviders = append(openaiProviders, status)
+					case "sd":
+						sdProviders = append(sdProviders, status)
 					}
 				}
 
@@ -218,6 +275,34 @@ For more information: https://github.com/apresai/gimage`,
 					}
 					fmt.Fprintln(os.Stderr, "")
 				}
+
+				if len(openaiProviders) > 0 {
+					fmt.Fprintf(os.Stderr, "[gimage-mcp] ✓ OpenAI-compatible API - %d provider(s) configured\n", len(openaiProviders))
+					for _, status := range openaiProviders {
+						p := status.Provider
+						pricingInfo := "Variable"
+						if p.Pricing.CostPerImage != nil {
+							pricingInfo = fmt.Sprintf("$%.4f/image", *p.Pricing.CostPerImage)
+						}
+						fmt.Fprintf(os.Stderr, "[gimage-mcp]   • %s - %s\n", p.Name, pricingInfo)
+					}
+					fmt.Fprintln(os.Stderr, "")
+				}
+
+				if len(sdProviders) > 0 {
+					fmt.Fprintf(os.Stderr, "[gimage-mcp] ✓ Self-hosted Stable Diffusion - %d provider(s) configured\n", len(sdProviders))
+					for _, status := range sdProviders {
+						p := status.Provider
+						pricingInfo := "Variable"
+						if p.Pricing.FreeTier {
+							pricingInfo = fmt.Sprintf("FREE (%s)", p.Pricing.FreeTierLimit)
+						} else if p.Pricing.CostPerImage != nil {
+							pricingInfo = fmt.Sprintf("$%.4f/image", *p.Pricing.CostPerImage)
+						}
+						fmt.Fprintf(os.Stderr, "[gimage-mcp]   • %s - %s\n", p.Name, pricingInfo)
+					}
+					fmt.Fprintln(os.Stderr, "")
+				}
 			}
 
 			// Show default provider (first configured, preferring free tier)
@@ -249,7 +334,16 @@ For more information: https://github.com/apresai/gimage`,
 		}
 
 		// Start server
-		if err := server.Start(ctx); err != nil && err != context.Canceled {
+		if transport == "http" {
+			opts := mcp.HTTPOptions{Addr: addr, Path: path}
+			if token != "" {
+				opts.Authorize = mcp.BearerToken(token)
+			}
+			err = server.StartHTTP(ctx, opts)
+		} else {
+			err = server.Start(ctx)
+		}
+		if err != nil && err != context.Canceled {
 			return fmt.Errorf("server error: %w", err)
 		}
 
@@ -259,4 +353,9 @@ For more information: https://github.com/apresai/gimage`,
 
 func init() {
 	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().String("transport", "stdio", "transport: stdio (child process) or http (MCP Streamable HTTP)")
+	serveCmd.Flags().String("addr", "localhost:8787", "address to listen on with --transport http")
+	serveCmd.Flags().String("path", mcp.DefaultHTTPPath, "endpoint path with --transport http")
+	serveCmd.Flags().String("token", "", "bearer token clients must send with --transport http (default: $GIMAGE_MCP_TOKEN)")
 }
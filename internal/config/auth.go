@@ -292,6 +292,36 @@ func HasBedrockCredentials() bool {
 	return false
 }
 
+// HasOpenAICredentials checks if an OpenAI-compatible endpoint is configured
+// Returns true if an API key or a custom base URL (keyless gateway) is set
+func HasOpenAICredentials() bool {
+	if os.Getenv("OPENAI_API_KEY") != "" || os.Getenv("OPENAI_BASE_URL") != "" {
+		return true
+	}
+
+	cfg, err := LoadConfig()
+	if err == nil && (cfg.OpenAIAPIKey != "" || cfg.OpenAIBaseURL != "") {
+		return true
+	}
+
+	return false
+}
+
+// HasStableDiffusionCredentials checks if a self-hosted Stable Diffusion server is configured
+// Returns true if an Automatic1111 or ComfyUI base URL is set
+func HasStableDiffusionCredentials() bool {
+	if os.Getenv("A1111_BASE_URL") != "" || os.Getenv("COMFYUI_BASE_URL") != "" {
+		return true
+	}
+
+	cfg, err := LoadConfig()
+	if err == nil && (cfg.A1111BaseURL != "" || cfg.ComfyUIBaseURL != "") {
+		return true
+	}
+
+	return false
+}
+
 // GetAWSRegion retrieves the AWS region from multiple sources
 // Priority order: flag parameter > AWS_REGION env var > config file > default (us-east-1)
 func GetAWSRegion(flagRegion string) string {
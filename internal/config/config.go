@@ -21,9 +21,17 @@ type Config struct {
 	AWSRegion             string // AWS region (default: us-east-1)
 	AWSProfile            string // AWS profile name
 	AWSBedrockAPIKey      string // For AWS Bedrock REST API (bearer token)
+	OpenAIAPIKey          string // For OpenAI-compatible image APIs
+	OpenAIBaseURL         string // OpenAI-compatible endpoint (default: https://api.openai.com/v1)
+	OpenAIImageModel      string // Model sent to the OpenAI-compatible endpoint
+	A1111BaseURL          string // Stable Diffusion WebUI (Automatic1111) server, e.g. http://gpu-box:7860
+	A1111Auth             string // Optional user:password for A1111 --api-auth
+	ComfyUIBaseURL        string // ComfyUI server, e.g. http://gpu-box:8188
+	ComfyUICheckpoint     string // ComfyUI checkpoint file (default: first installed)
 	DefaultAPI            string
 	DefaultModel          string
 	DefaultSize           string
+	FallbackProviders     []string // Provider failover chain (e.g., gemini/flash-2.5 -> vertex/imagen-4)
 	CacheDir              string
 	LogLevel              string
 }
@@ -82,6 +90,30 @@ func LoadConfig() (*Config, error) {
 	if bedrockKey := os.Getenv("AWS_BEARER_TOKEN_BEDROCK"); bedrockKey != "" {
 		cfg.AWSBedrockAPIKey = bedrockKey
 	}
+	if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" {
+		cfg.OpenAIAPIKey = apiKey
+	}
+	if baseURL := os.Getenv("OPENAI_BASE_URL"); baseURL != "" {
+		cfg.OpenAIBaseURL = baseURL
+	}
+	if model := os.Getenv("OPENAI_IMAGE_MODEL"); model != "" {
+		cfg.OpenAIImageModel = model
+	}
+	if baseURL := os.Getenv("A1111_BASE_URL"); baseURL != "" {
+		cfg.A1111BaseURL = baseURL
+	}
+	if auth := os.Getenv("A1111_API_AUTH"); auth != "" {
+		cfg.A1111Auth = auth
+	}
+	if baseURL := os.Getenv("COMFYUI_BASE_URL"); baseURL != "" {
+		cfg.ComfyUIBaseURL = baseURL
+	}
+	if checkpoint := os.Getenv("COMFYUI_CHECKPOINT"); checkpoint != "" {
+		cfg.ComfyUICheckpoint = checkpoint
+	}
+	if chain := os.Getenv("GIMAGE_FALLBACK_PROVIDERS"); chain != "" {
+		cfg.FallbackProviders = ParseProviderChain(chain)
+	}
 	if logLevel := os.Getenv("GIMAGE_LOG_LEVEL"); logLevel != "" {
 		cfg.LogLevel = logLevel
 	}
@@ -169,12 +201,28 @@ func parseMarkdownConfig(path string, cfg *Config) error {
 			cfg.AWSProfile = value
 		case "aws_bedrock_api_key":
 			cfg.AWSBedrockAPIKey = value
+		case "openai_api_key":
+			cfg.OpenAIAPIKey = value
+		case "openai_base_url":
+			cfg.OpenAIBaseURL = value
+		case "openai_image_model":
+			cfg.OpenAIImageModel = value
+		case "a1111_base_url":
+			cfg.A1111BaseURL = value
+		case "a1111_api_auth":
+			cfg.A1111Auth = value
+		case "comfyui_base_url":
+			cfg.ComfyUIBaseURL = value
+		case "comfyui_checkpoint":
+			cfg.ComfyUICheckpoint = value
 		case "default_api":
 			cfg.DefaultAPI = value
 		case "default_model":
 			cfg.DefaultModel = value
 		case "default_size":
 			cfg.DefaultSize = value
+		case "fallback_providers":
+			cfg.FallbackProviders = ParseProviderChain(value)
 		case "cache_dir":
 			cfg.CacheDir = value
 		case "log_level":
@@ -257,6 +305,27 @@ func SaveConfig(cfg *Config) error {
 	if cfg.AWSBedrockAPIKey != "" {
 		content.WriteString(fmt.Sprintf("**aws_bedrock_api_key**: %s\n", cfg.AWSBedrockAPIKey))
 	}
+	if cfg.OpenAIAPIKey != "" {
+		content.WriteString(fmt.Sprintf("**openai_api_key**: %s\n", cfg.OpenAIAPIKey))
+	}
+	if cfg.OpenAIBaseURL != "" {
+		content.WriteString(fmt.Sprintf("**openai_base_url**: %s\n", cfg.OpenAIBaseURL))
+	}
+	if cfg.OpenAIImageModel != "" {
+		content.WriteString(fmt.Sprintf("**openai_image_model**: %s\n", cfg.OpenAIImageModel))
+	}
+	if cfg.A1111BaseURL != "" {
+		content.WriteString(fmt.Sprintf("**a1111_base_url**: %s\n", cfg.A1111BaseURL))
+	}
+	if cfg.A1111Auth != "" {
+		content.WriteString(fmt.Sprintf("**a1111_api_auth**: %s\n", cfg.A1111Auth))
+	}
+	if cfg.ComfyUIBaseURL != "" {
+		content.WriteString(fmt.Sprintf("**comfyui_base_url**: %s\n", cfg.ComfyUIBaseURL))
+	}
+	if cfg.ComfyUICheckpoint != "" {
+		content.WriteString(fmt.Sprintf("**comfyui_checkpoint**: %s\n", cfg.ComfyUICheckpoint))
+	}
 	if cfg.DefaultAPI != "" {
 		content.WriteString(fmt.Sprintf("**default_api**: %s\n", cfg.DefaultAPI))
 	}
@@ -266,6 +335,9 @@ func SaveConfig(cfg *Config) error {
 	if cfg.DefaultSize != "" {
 		content.WriteString(fmt.Sprintf("**default_size**: %s\n", cfg.DefaultSize))
 	}
+	if len(cfg.FallbackProviders) > 0 {
+		content.WriteString(fmt.Sprintf("**fallback_providers**: %s\n", strings.Join(cfg.FallbackProviders, " -> ")))
+	}
 	if cfg.CacheDir != "" {
 		content.WriteString(fmt.Sprintf("**cache_dir**: %s\n", cfg.CacheDir))
 	}
@@ -281,6 +353,19 @@ func SaveConfig(cfg *Config) error {
 	return nil
 }
 
+// ParseProviderChain parses a provider failover chain such as
+// "gemini/flash-2.5 -> vertex/imagen-4 -> bedrock/nova-canvas".
+// Entries may also be separated by commas.
+func ParseProviderChain(value string) []string {
+	var chain []string
+	for _, part := range strings.FieldsFunc(strings.ReplaceAll(value, "->", ","), func(r rune) bool { return r == ',' }) {
+		if entry := strings.TrimSpace(part); entry != "" {
+			chain = append(chain, entry)
+		}
+	}
+	return chain
+}
+
 // GetConfigPath returns the path to the config file
 // Checks GIMAGE_CONFIG environment variable first, then defaults to ~/.gimage/config.md
 func GetConfigPath() string {
@@ -311,9 +396,11 @@ func ValidateConfig(cfg *Config) error {
 			"gemini":  true,
 			"vertex":  true,
 			"bedrock": true,
+			"openai":  true,
+			"sd":      true,
 		}
 		if !validAPIs[cfg.DefaultAPI] {
-			return fmt.Errorf("default_api must be either 'gemini', 'vertex', or 'bedrock', got: %s", cfg.DefaultAPI)
+			return fmt.Errorf("default_api must be 'gemini', 'vertex', 'bedrock', 'openai', or 'sd', got: %s", cfg.DefaultAPI)
 		}
 	}
 
@@ -0,0 +1,251 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/apresai/gimage/pkg/models"
+	"github.com/sony/gobreaker"
+	"github.com/spf13/viper"
+)
+
+// A1111Client generates images with a Stable Diffusion WebUI (Automatic1111)
+// server through its /sdapi/v1/txt2img API. The server must be started with --api.
+type A1111Client struct {
+	baseURL        string
+	username       string
+	password       string
+	httpClient     *http.Client
+	verbose        bool
+	circuitBreaker *gobreaker.CircuitBreaker
+}
+
+// NewA1111Client creates a client for the WebUI at baseURL (e.g. http://gpu-box:7860).
+// auth is the optional "user:password" the server was started with via --api-auth.
+func NewA1111Client(baseURL, auth string) (*A1111Client, error) {
+	baseURL, err := parseSDBaseURL("Automatic1111", baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var username, password string
+	if auth != "" {
+		var ok bool
+		username, password, ok = strings.Cut(auth, ":")
+		if !ok || username == "" {
+			return nil, fmt.Errorf("invalid Automatic1111 auth: expected user:password")
+		}
+	}
+
+	// Check if verbose mode is enabled via Viper flag or environment variable
+	verbose := viper.GetBool("verbose") || os.Getenv("GIMAGE_VERBOSE") == "true" || os.Getenv("VERBOSE") == "true"
+
+	return &A1111Client{
+		baseURL:  baseURL,
+		username: username,
+		password: password,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Minute, // Large batches on slow GPUs take a while
+		},
+		verbose:        verbose,
+		circuitBreaker: newCircuitBreaker("A1111API"),
+	}, nil
+}
+
+// logVerbose logs debug information if verbose mode is enabled
+func (c *A1111Client) logVerbose(format string, args ...interface{}) {
+	if c.verbose {
+		fmt.Fprintf(os.Stderr, "[A1111] "+format+"\n", args...)
+	}
+}
+
+// a1111Request is the /sdapi/v1/txt2img request body
+type a1111Request struct {
+	Prompt         string  `json:"prompt"`
+	NegativePrompt string  `json:"negative_prompt,omitempty"`
+	Seed           int64   `json:"seed"`
+	Width          int     `json:"width"`
+	Height         int     `json:"height"`
+	Steps          int     `json:"steps"`
+	CfgScale       float64 `json:"cfg_scale"`
+	SamplerName    string  `json:"sampler_name,omitempty"`
+	Scheduler      string  `json:"scheduler,omitempty"`
+	BatchSize      int     `json:"batch_size"`
+	NIter          int     `json:"n_iter"`
+	SendImages     bool    `json:"send_images"`
+	SaveImages     bool    `json:"save_images"`
+}
+
+// a1111Response is the /sdapi/v1/txt2img response body
+type a1111Response struct {
+	Images []string `json:"images"`
+	Info   string   `json:"info"` // JSON-encoded generation info
+}
+
+// a1111Info is the decoded generation info
+type a1111Info struct {
+	Seed        int64   `json:"seed"`
+	AllSeeds    []int64 `json:"all_seeds"`
+	SDModelName string  `json:"sd_model_name"`
+	SamplerName string  `json:"sampler_name"`
+}
+
+// GenerateImage generates a single image
+func (c *A1111Client) GenerateImage(ctx context.Context, prompt string, options models.GenerateOptions) (*models.GeneratedImage, error) {
+	images, err := c.generate(ctx, prompt, options, 1)
+	if err != nil {
+		return nil, err
+	}
+	return images[0], nil
+}
+
+// GenerateImages generates options.Count images using batch_size, split into
+// batches of at most eight images
+func (c *A1111Client) GenerateImages(ctx context.Context, prompt string, options models.GenerateOptions) ([]*models.GeneratedImage, error) {
+	var generated int
+	return generateInBatches(options.Count, sdMaxImagesPerRequest, func(n int) ([]*models.GeneratedImage, error) {
+		batch := options
+		if options.Seed != 0 {
+			batch.Seed = options.Seed + int64(generated) // A1111 increments the seed within a batch
+		}
+		images, err := c.generate(ctx, prompt, batch, n)
+		generated += len(images)
+		return images, err
+	})
+}
+
+// generate runs one txt2img request for count images
+func (c *A1111Client) generate(ctx context.Context, prompt string, options models.GenerateOptions, count int) ([]*models.GeneratedImage, error) {
+	startTime := time.Now()
+
+	settings, err := sdSettingsFromOptions(prompt, options)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &a1111Request{
+		Prompt:         settings.Prompt,
+		NegativePrompt: settings.NegativePrompt,
+		Seed:           settings.Seed,
+		Width:          settings.Width,
+		Height:         settings.Height,
+		Steps:          settings.Steps,
+		CfgScale:       settings.CfgScale,
+		SamplerName:    settings.Sampler,
+		Scheduler:      settings.Scheduler,
+		BatchSize:      count,
+		NIter:          1,
+		SendImages:     true,
+		SaveImages:     false,
+	}
+
+	c.logVerbose("txt2img %dx%d, %d steps, seed %d, batch %d at %s", request.Width, request.Height, request.Steps, request.Seed, count, c.baseURL)
+
+	result, err := c.circuitBreaker.Execute(func() (interface{}, error) {
+		return c.txt2img(ctx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := result.(*a1111Response)
+
+	if len(response.Images) == 0 {
+		return nil, fmt.Errorf("no images in response")
+	}
+
+	// A1111 prepends a grid image to batches when grids are enabled
+	if extra := len(response.Images) - count; extra > 0 {
+		response.Images = response.Images[extra:]
+	}
+
+	// Generation info carries the seeds actually used; fall back to seed+i
+	var info a1111Info
+	if response.Info != "" {
+		json.Unmarshal([]byte(response.Info), &info)
+	}
+	model := "stable-diffusion"
+	if info.SDModelName != "" {
+		model = info.SDModelName
+	}
+
+	images := make([]*models.GeneratedImage, 0, len(response.Images))
+	for i, encoded := range response.Images {
+		// Images may be data URLs on some forks
+		if idx := strings.Index(encoded, ","); idx >= 0 && strings.HasPrefix(encoded, "data:") {
+			encoded = encoded[idx+1:]
+		}
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image data: %w", err)
+		}
+
+		seed := settings.Seed + int64(i)
+		if i < len(info.AllSeeds) {
+			seed = info.AllSeeds[i]
+		}
+
+		width, height := imageDimensions(data, options.Size)
+		images = append(images, &models.GeneratedImage{
+			Data:     data,
+			Format:   extractFormatFromMimeType(http.DetectContentType(data)),
+			Width:    width,
+			Height:   height,
+			Metadata: settings.metadata(model, prompt, seed),
+		})
+	}
+
+	c.logVerbose("Generated %d image(s) in %v", len(images), time.Since(startTime))
+
+	return images, nil
+}
+
+// txt2img sends the request to /sdapi/v1/txt2img
+func (c *A1111Client) txt2img(ctx context.Context, request *a1111Request) (*a1111Response, error) {
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/sdapi/v1/txt2img", bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, sdHTTPError("Automatic1111", resp.StatusCode, body)
+	}
+
+	var response a1111Response
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &response, nil
+}
+
+// Close cleans up resources (no-op for REST client, implements interface)
+func (c *A1111Client) Close() error {
+	return nil
+}
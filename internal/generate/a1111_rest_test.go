@@ -0,0 +1,200 @@
+package generate
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/apresai/gimage/internal/config"
+	"github.com/apresai/gimage/pkg/models"
+)
+
+// newA1111Stub serves /sdapi/v1/txt2img, recording each decoded request
+func newA1111Stub(t *testing.T, requests *[]a1111Request) *httptest.Server {
+	t.Helper()
+	imageData := base64.StdEncoding.EncodeToString(testPNG(t, 64, 48))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sdapi/v1/txt2img" {
+			http.NotFound(w, r)
+			return
+		}
+		if user, pass, ok := r.BasicAuth(); ok && (user != "admin" || pass != "secret") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req a1111Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		*requests = append(*requests, req)
+
+		// Echo the seeds like A1111 does, with a grid image first for batches
+		var seeds []int64
+		images := []string{}
+		if req.BatchSize > 1 {
+			images = append(images, "grid")
+		}
+		for i := 0; i < req.BatchSize; i++ {
+			seeds = append(seeds, req.Seed+int64(i))
+			images = append(images, imageData)
+		}
+		info, _ := json.Marshal(a1111Info{Seed: req.Seed, AllSeeds: seeds, SDModelName: "sd_xl_base_1.0"})
+		json.NewEncoder(w).Encode(a1111Response{Images: images, Info: string(info)})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewA1111Client(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		auth    string
+		wantErr bool
+	}{
+		{name: "valid", baseURL: "http://localhost:7860/"},
+		{name: "with auth", baseURL: "http://localhost:7860", auth: "admin:secret"},
+		{name: "missing URL", wantErr: true},
+		{name: "no scheme", baseURL: "localhost:7860", wantErr: true},
+		{name: "bad auth", baseURL: "http://localhost:7860", auth: "admin", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewA1111Client(tt.baseURL, tt.auth)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewA1111Client() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && strings.HasSuffix(client.baseURL, "/") {
+				t.Errorf("baseURL = %s, want trailing slash trimmed", client.baseURL)
+			}
+		})
+	}
+}
+
+func TestA1111Client_GenerateImage(t *testing.T) {
+	var requests []a1111Request
+	server := newA1111Stub(t, &requests)
+
+	client, err := NewA1111Client(server.URL, "admin:secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image, err := client.GenerateImage(context.Background(), "a lighthouse", models.GenerateOptions{
+		Size:           "640x480",
+		Seed:           42,
+		NegativePrompt: "people",
+		Extra:          map[string]any{SDOptionSampler: "Euler a", SDOptionSteps: 12},
+	})
+	if err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+
+	req := requests[0]
+	if req.Width != 640 || req.Height != 480 || req.Seed != 42 || req.NegativePrompt != "people" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if req.SamplerName != "Euler a" || req.Steps != 12 || req.BatchSize != 1 || req.SaveImages {
+		t.Errorf("unexpected sampling parameters: %+v", req)
+	}
+
+	if image.Width != 64 || image.Height != 48 || image.Format != "png" {
+		t.Errorf("image = %s %dx%d, want png 64x48", image.Format, image.Width, image.Height)
+	}
+	if image.Metadata["model"] != "sd_xl_base_1.0" || image.Metadata["seed"] != "42" || image.Metadata["sampler"] != "Euler a" {
+		t.Errorf("unexpected metadata: %v", image.Metadata)
+	}
+}
+
+func TestA1111Client_GenerateImages(t *testing.T) {
+	var requests []a1111Request
+	server := newA1111Stub(t, &requests)
+
+	client, err := NewA1111Client(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	images, err := client.GenerateImages(context.Background(), "prompt", models.GenerateOptions{Count: 10, Seed: 100})
+	if err != nil {
+		t.Fatalf("GenerateImages() error = %v", err)
+	}
+	if len(images) != 10 {
+		t.Fatalf("got %d images, want 10 (grid images must be dropped)", len(images))
+	}
+	if len(requests) != 2 || requests[0].BatchSize != 8 || requests[1].BatchSize != 2 {
+		t.Errorf("unexpected batches: %+v", requests)
+	}
+
+	// Seeds continue across batches so no candidate repeats
+	seen := map[string]bool{}
+	for _, image := range images {
+		seed := image.Metadata["seed"]
+		if seen[seed] {
+			t.Errorf("duplicate seed %s", seed)
+		}
+		seen[seed] = true
+	}
+}
+
+func TestA1111Client_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":"OutOfMemoryError","detail":"CUDA out of memory"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewA1111Client(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.GenerateImage(context.Background(), "prompt", models.GenerateOptions{})
+	if err == nil || !strings.Contains(err.Error(), "CUDA out of memory") {
+		t.Fatalf("error = %v, want server message", err)
+	}
+	if !IsFailoverError(err) {
+		t.Error("server errors should allow failover")
+	}
+}
+
+func TestSDProviders_Registry(t *testing.T) {
+	clearCredentialEnv(t)
+	registry := NewProviderRegistry()
+
+	for _, id := range []string{"sd/a1111", "sd/comfyui"} {
+		p, err := registry.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Pricing.CostPerImage == nil || *p.Pricing.CostPerImage != 0 {
+			t.Errorf("%s should be zero cost", id)
+		}
+	}
+
+	var requests []a1111Request
+	server := newA1111Stub(t, &requests)
+
+	client, p, err := registry.ResolveClient("a1111", &config.Config{A1111BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("ResolveClient() error = %v", err)
+	}
+	defer client.Close()
+	if p.ID != "sd/a1111" {
+		t.Errorf("provider = %s, want sd/a1111", p.ID)
+	}
+	if _, err := client.GenerateImage(context.Background(), "prompt", models.GenerateOptions{Model: p.ModelID}); err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+
+	if _, _, err := registry.ResolveClient("comfyui", &config.Config{}); err == nil || !strings.Contains(err.Error(), "COMFYUI_BASE_URL") {
+		t.Errorf("error = %v, want missing COMFYUI_BASE_URL", err)
+	}
+}
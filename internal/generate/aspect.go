@@ -0,0 +1,282 @@
+package generate
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/apresai/gimage/internal/imaging"
+	"github.com/apresai/gimage/pkg/models"
+)
+
+// Fit modes for bringing a native-size image to the exact requested size
+const (
+	FitCrop   = "crop"   // Scale to cover the requested size, then center-crop (default)
+	FitResize = "resize" // Stretch to the requested size (distorts when ratios differ)
+	FitNone   = "none"   // Keep the provider's native size
+)
+
+// aspectRatioTolerance is how far a size may be from a ratio and still match it
+const aspectRatioTolerance = 0.02
+
+// SizeSpec is one output size a provider generates natively
+type SizeSpec struct {
+	Width       int
+	Height      int
+	AspectRatio string // e.g. "16:9", as sent to APIs that take a ratio
+}
+
+// String returns the size as WIDTHxHEIGHT
+func (s SizeSpec) String() string {
+	return fmt.Sprintf("%dx%d", s.Width, s.Height)
+}
+
+// ratio returns width / height
+func (s SizeSpec) ratio() float64 {
+	return float64(s.Width) / float64(s.Height)
+}
+
+// ParseAspectRatio parses a ratio such as "16:9" and returns width / height
+func ParseAspectRatio(aspect string) (float64, error) {
+	parts := strings.Split(strings.TrimSpace(aspect), ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid aspect ratio: %s (expected W:H, e.g. 16:9)", aspect)
+	}
+	w, errW := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	h, errH := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if errW != nil || errH != nil || w <= 0 || h <= 0 {
+		return 0, fmt.Errorf("invalid aspect ratio: %s (expected W:H, e.g. 16:9)", aspect)
+	}
+	ratio := w / h
+	if ratio < 0.25 || ratio > 4 {
+		return 0, fmt.Errorf("invalid aspect ratio: %s (must be between 1:4 and 4:1)", aspect)
+	}
+	return ratio, nil
+}
+
+// ValidateFitMode checks a --fit value ("" means FitCrop)
+func ValidateFitMode(mode string) error {
+	switch mode {
+	case "", FitCrop, FitResize, FitNone:
+		return nil
+	default:
+		return fmt.Errorf("invalid fit mode: %s (must be %s, %s or %s)", mode, FitCrop, FitResize, FitNone)
+	}
+}
+
+// RequestedSize returns the exact pixel size asked for by options.Size, or
+// zero when only an aspect ratio (or nothing) was requested. An aspect ratio
+// given with a size must agree with it.
+func RequestedSize(options models.GenerateOptions) (width, height int, err error) {
+	if options.AspectRatio != "" {
+		if _, err := ParseAspectRatio(options.AspectRatio); err != nil {
+			return 0, 0, err
+		}
+	}
+	if options.Size == "" {
+		return 0, 0, nil
+	}
+
+	parts := strings.Split(options.Size, "x")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid size format: %s (expected format: WIDTHxHEIGHT, e.g., 1024x1024)", options.Size)
+	}
+	width, errW := strconv.Atoi(parts[0])
+	height, errH := strconv.Atoi(parts[1])
+	if errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid size: %s (width and height must be positive integers)", options.Size)
+	}
+
+	if options.AspectRatio != "" {
+		ratio, _ := ParseAspectRatio(options.AspectRatio)
+		if math.Abs(float64(width)/float64(height)/ratio-1) > aspectRatioTolerance {
+			return 0, 0, fmt.Errorf("size %s does not match aspect ratio %s", options.Size, options.AspectRatio)
+		}
+	}
+
+	return width, height, nil
+}
+
+// SnapSize returns the native size closest to the requested options: the
+// best-matching aspect ratio first, then the closest pixel count. Providers
+// without a size table get the requested size (or a ~1 megapixel size for a
+// bare aspect ratio) rounded to multiples of 64.
+func (p *Provider) SnapSize(options models.GenerateOptions) (SizeSpec, error) {
+	width, height, err := RequestedSize(options)
+	if err != nil {
+		return SizeSpec{}, err
+	}
+
+	// Target ratio and pixel count; a bare aspect ratio targets ~1 megapixel
+	ratio, area := 1.0, 1024.0*1024.0
+	if width > 0 {
+		ratio, area = float64(width)/float64(height), float64(width*height)
+	} else if options.AspectRatio != "" {
+		ratio, _ = ParseAspectRatio(options.AspectRatio)
+	}
+
+	sizes := p.Capabilities.Sizes
+	if len(sizes) == 0 {
+		if width > 0 {
+			return SizeSpec{Width: width, Height: height, AspectRatio: options.AspectRatio}, nil
+		}
+		w := roundTo(math.Sqrt(area*ratio), 64)
+		h := roundTo(math.Sqrt(area/ratio), 64)
+		return SizeSpec{Width: w, Height: h, AspectRatio: options.AspectRatio}, nil
+	}
+
+	return nearestSize(sizes, ratio, area), nil
+}
+
+// nearestSize picks the size with the closest aspect ratio, then the closest
+// pixel count. Ratios within aspectRatioTolerance count as equal.
+func nearestSize(sizes []SizeSpec, ratio, area float64) SizeSpec {
+	best := sizes[0]
+	bestRatioDiff, bestAreaDiff := math.Inf(1), math.Inf(1)
+	for _, size := range sizes {
+		ratioDiff := math.Abs(math.Log(size.ratio() / ratio))
+		areaDiff := math.Abs(math.Log(float64(size.Width*size.Height) / area))
+
+		switch {
+		case ratioDiff < bestRatioDiff-aspectRatioTolerance:
+		case math.Abs(ratioDiff-bestRatioDiff) <= aspectRatioTolerance && areaDiff < bestAreaDiff:
+		default:
+			continue
+		}
+		best, bestRatioDiff, bestAreaDiff = size, ratioDiff, areaDiff
+	}
+	return best
+}
+
+// AspectRatios returns the distinct aspect ratios in the provider's size
+// table, in table order (nil when any size is accepted)
+func (p *Provider) AspectRatios() []string {
+	var ratios []string
+	seen := map[string]bool{}
+	for _, size := range p.Capabilities.Sizes {
+		if !seen[size.AspectRatio] {
+			seen[size.AspectRatio] = true
+			ratios = append(ratios, size.AspectRatio)
+		}
+	}
+	return ratios
+}
+
+// NativeOptions returns options with Size and AspectRatio replaced by the
+// provider's closest native size, so no API is asked for a size it rejects
+func (p *Provider) NativeOptions(options models.GenerateOptions) (models.GenerateOptions, error) {
+	size, err := p.SnapSize(options)
+	if err != nil {
+		return options, err
+	}
+	options.Size = size.String()
+	options.AspectRatio = size.AspectRatio
+	return options, nil
+}
+
+// FitImages brings images to exactly width x height using the fit mode
+// ("" means FitCrop). Images already at that size are left untouched.
+func FitImages(images []*models.GeneratedImage, width, height int, mode string) error {
+	if err := ValidateFitMode(mode); err != nil {
+		return err
+	}
+	if width <= 0 || height <= 0 || mode == FitNone {
+		return nil
+	}
+
+	for i, image := range images {
+		actualWidth, actualHeight := imageDimensions(image.Data, "")
+		if actualWidth == width && actualHeight == height {
+			continue
+		}
+
+		data, err := imaging.ResizeImageData(image.Data, width, height, mode != FitResize)
+		if err != nil {
+			return fmt.Errorf("failed to fit image %d to %dx%d: %w", i+1, width, height, err)
+		}
+
+		image.Data = data
+		image.Width, image.Height = width, height
+		if image.Metadata == nil {
+			image.Metadata = map[string]string{}
+		}
+		image.Metadata["native_size"] = fmt.Sprintf("%dx%d", actualWidth, actualHeight)
+		image.Metadata["size"] = fmt.Sprintf("%dx%d", width, height)
+	}
+
+	return nil
+}
+
+// roundTo rounds v to the nearest positive multiple of step
+func roundTo(v float64, step int) int {
+	n := int(math.Round(v/float64(step))) * step
+	if n < step {
+		n = step
+	}
+	return n
+}
+
+// sizeTable builds SizeSpecs from "W:H" ratios and their WIDTHxHEIGHT sizes
+func sizeTable(sizes ...string) []SizeSpec {
+	table := make([]SizeSpec, 0, len(sizes)/2)
+	for i := 0; i+1 < len(sizes); i += 2 {
+		width, height := parseDimensions(sizes[i+1])
+		table = append(table, SizeSpec{Width: width, Height: height, AspectRatio: sizes[i]})
+	}
+	return table
+}
+
+// Native output sizes per model family
+var (
+	// geminiImageSizes are the fixed outputs of Gemini 2.5 Flash Image per aspect ratio
+	geminiImageSizes = sizeTable(
+		"1:1", "1024x1024",
+		"2:3", "832x1248",
+		"3:2", "1248x832",
+		"3:4", "864x1184",
+		"4:3", "1184x864",
+		"4:5", "896x1152",
+		"5:4", "1152x896",
+		"9:16", "768x1344",
+		"16:9", "1344x768",
+		"21:9", "1536x672",
+	)
+
+	// imagenSizes are the Imagen 3 outputs for its five aspect ratios
+	imagenSizes = sizeTable(
+		"1:1", "1024x1024",
+		"3:4", "896x1280",
+		"4:3", "1280x896",
+		"9:16", "768x1408",
+		"16:9", "1408x768",
+	)
+
+	// imagen4Sizes add Imagen 4's 2K outputs (sampleImageSize "2K")
+	imagen4Sizes = append(append([]SizeSpec{}, imagenSizes...), sizeTable(
+		"1:1", "2048x2048",
+		"3:4", "1792x2560",
+		"4:3", "2560x1792",
+		"9:16", "1536x2816",
+		"16:9", "2816x1536",
+	)...)
+
+	// novaCanvasSizes are Nova Canvas sizes within 512-2048 in multiples of 64
+	novaCanvasSizes = sizeTable(
+		"1:1", "1024x1024",
+		"1:1", "1408x1408",
+		"4:3", "1024x768",
+		"3:4", "768x1024",
+		"5:4", "1280x1024",
+		"4:5", "1024x1280",
+		"3:2", "1536x1024",
+		"2:3", "1024x1536",
+		"16:9", "1024x576",
+		"16:9", "2048x1152",
+		"9:16", "576x1024",
+		"9:16", "1152x2048",
+		"2:1", "2048x1024",
+		"1:2", "1024x2048",
+		"21:9", "1344x576",
+	)
+)
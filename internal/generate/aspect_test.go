@@ -0,0 +1,123 @@
+package generate
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	"github.com/apresai/gimage/pkg/models"
+)
+
+func TestParseAspectRatio(t *testing.T) {
+	tests := []struct {
+		aspect  string
+		want    float64
+		wantErr bool
+	}{
+		{aspect: "16:9", want: 16.0 / 9.0},
+		{aspect: " 4 : 3 ", want: 4.0 / 3.0},
+		{aspect: "1.91:1", want: 1.91},
+		{aspect: "16x9", wantErr: true},
+		{aspect: "0:1", wantErr: true},
+		{aspect: "5:1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.aspect, func(t *testing.T) {
+			got, err := ParseAspectRatio(tt.aspect)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAspectRatio() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAspectRatio() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestedSize(t *testing.T) {
+	if w, h, err := RequestedSize(models.GenerateOptions{Size: "1920x1080", AspectRatio: "16:9"}); err != nil || w != 1920 || h != 1080 {
+		t.Errorf("RequestedSize() = %dx%d, %v", w, h, err)
+	}
+	if w, h, err := RequestedSize(models.GenerateOptions{AspectRatio: "16:9"}); err != nil || w != 0 || h != 0 {
+		t.Errorf("aspect only: RequestedSize() = %dx%d, %v, want no exact size", w, h, err)
+	}
+	if _, _, err := RequestedSize(models.GenerateOptions{Size: "1024x1024", AspectRatio: "16:9"}); err == nil {
+		t.Error("expected error for a size that contradicts the aspect ratio")
+	}
+	if _, _, err := RequestedSize(models.GenerateOptions{Size: "wide"}); err == nil {
+		t.Error("expected error for a malformed size")
+	}
+}
+
+func TestProvider_SnapSize(t *testing.T) {
+	registry := NewProviderRegistry()
+
+	tests := []struct {
+		name       string
+		provider   string
+		options    models.GenerateOptions
+		wantSize   string
+		wantAspect string
+	}{
+		{name: "gemini hero", provider: "gemini/flash-2.5", options: models.GenerateOptions{Size: "1920x1080"}, wantSize: "1344x768", wantAspect: "16:9"},
+		{name: "gemini portrait aspect", provider: "gemini/flash-2.5", options: models.GenerateOptions{AspectRatio: "9:16"}, wantSize: "768x1344", wantAspect: "9:16"},
+		{name: "imagen 3 hero", provider: "vertex/imagen-3", options: models.GenerateOptions{Size: "1920x1080"}, wantSize: "1408x768", wantAspect: "16:9"},
+		{name: "imagen 4 2K square", provider: "vertex/imagen-4", options: models.GenerateOptions{Size: "2048x2048"}, wantSize: "2048x2048", wantAspect: "1:1"},
+		{name: "imagen 3:2 snaps to 4:3", provider: "vertex/imagen-3", options: models.GenerateOptions{AspectRatio: "3:2"}, wantSize: "1280x896", wantAspect: "4:3"},
+		{name: "nova hero", provider: "bedrock/nova-canvas", options: models.GenerateOptions{Size: "1920x1080"}, wantSize: "2048x1152", wantAspect: "16:9"},
+		{name: "nova default", provider: "bedrock/nova-canvas", options: models.GenerateOptions{}, wantSize: "1024x1024", wantAspect: "1:1"},
+		{name: "any size passes through", provider: "sd/a1111", options: models.GenerateOptions{Size: "1920x1080"}, wantSize: "1920x1080"},
+		{name: "aspect without table", provider: "local/mock", options: models.GenerateOptions{AspectRatio: "16:9"}, wantSize: "1344x768", wantAspect: "16:9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := registry.Get(tt.provider)
+			if err != nil {
+				t.Fatal(err)
+			}
+			options, err := p.NativeOptions(tt.options)
+			if err != nil {
+				t.Fatalf("NativeOptions() error = %v", err)
+			}
+			if options.Size != tt.wantSize || options.AspectRatio != tt.wantAspect {
+				t.Errorf("NativeOptions() = %s (%s), want %s (%s)", options.Size, options.AspectRatio, tt.wantSize, tt.wantAspect)
+			}
+		})
+	}
+}
+
+func TestFitImages(t *testing.T) {
+	images := []*models.GeneratedImage{
+		{Data: testPNG(t, 1408, 768), Format: "png", Width: 1408, Height: 768},
+		{Data: testPNG(t, 1920, 1080), Format: "png", Width: 1920, Height: 1080},
+	}
+	untouched := images[1].Data
+
+	if err := FitImages(images, 1920, 1080, ""); err != nil {
+		t.Fatalf("FitImages() error = %v", err)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(images[0].Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Width != 1920 || cfg.Height != 1080 || images[0].Width != 1920 || images[0].Height != 1080 {
+		t.Errorf("fitted image = %dx%d (reported %dx%d), want 1920x1080", cfg.Width, cfg.Height, images[0].Width, images[0].Height)
+	}
+	if images[0].Metadata["native_size"] != "1408x768" {
+		t.Errorf("native_size = %q", images[0].Metadata["native_size"])
+	}
+	if !bytes.Equal(images[1].Data, untouched) {
+		t.Error("images already at the requested size should not be re-encoded")
+	}
+
+	native := []*models.GeneratedImage{{Data: testPNG(t, 64, 32), Format: "png"}}
+	if err := FitImages(native, 128, 128, FitNone); err != nil || !bytes.Equal(native[0].Data, testPNG(t, 64, 32)) {
+		t.Errorf("FitNone should keep the native image (err = %v)", err)
+	}
+	if err := FitImages(native, 128, 128, "squash"); err == nil {
+		t.Error("expected error for an unknown fit mode")
+	}
+}
@@ -3,13 +3,11 @@ package generate
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/apresai/gimage/pkg/models"
@@ -76,7 +74,25 @@ func NewBedrockRESTClient(apiKey, region string) (*BedrockRESTClient, error) {
 
 // GenerateImage generates an image using AWS Bedrock Nova Canvas via REST API
 func (c *BedrockRESTClient) GenerateImage(ctx context.Context, prompt string, options models.GenerateOptions) (*models.GeneratedImage, error) {
+	images, err := c.generate(ctx, prompt, options, 1)
+	if err != nil {
+		return nil, err
+	}
+	return images[0], nil
+}
+
+// GenerateImages generates options.Count images using numberOfImages, split into
+// calls of at most five images
+func (c *BedrockRESTClient) GenerateImages(ctx context.Context, prompt string, options models.GenerateOptions) ([]*models.GeneratedImage, error) {
+	return generateInBatches(options.Count, novaCanvasMaxImages, func(n int) ([]*models.GeneratedImage, error) {
+		return c.generate(ctx, prompt, options, n)
+	})
+}
+
+// generate runs one TEXT_IMAGE request for count images
+func (c *BedrockRESTClient) generate(ctx context.Context, prompt string, options models.GenerateOptions, count int) ([]*models.GeneratedImage, error) {
 	startTime := time.Now()
+	options.Count = count
 
 	// Build request payload
 	request, err := c.buildRequest(prompt, options)
@@ -84,12 +100,6 @@ func (c *BedrockRESTClient) GenerateImage(ctx context.Context, prompt string, op
 		return nil, fmt.Errorf("failed to build request: %w", err)
 	}
 
-	// Marshal request to JSON
-	requestBody, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
 	if c.verbose {
 		c.logger.Debug().
 			Str("prompt", prompt).
@@ -97,32 +107,103 @@ func (c *BedrockRESTClient) GenerateImage(ctx context.Context, prompt string, op
 			Str("size", options.Size).
 			Str("quality", request.ImageGenerationConfig.Quality).
 			Int("seed", request.ImageGenerationConfig.Seed).
+			Int("count", request.ImageGenerationConfig.NumberOfImages).
 			Msg("Generating image with AWS Bedrock Nova Canvas via REST API")
 	}
 
+	images, err := c.invoke(ctx, request, options.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse dimensions
+	width, height := parseDimensions(options.Size)
+	for _, image := range images {
+		image.Width, image.Height = width, height
+		image.Metadata["prompt"] = prompt
+		image.Metadata["size"] = options.Size
+	}
+
+	if c.verbose {
+		c.logger.Info().
+			Str("model", images[0].Metadata["model"]).
+			Dur("duration", time.Since(startTime)).
+			Int("images", len(images)).
+			Msg("Image generated successfully via REST API")
+	}
+
+	return images, nil
+}
+
+// EditImage edits an existing image using Nova Canvas IMAGE_VARIATION or INPAINTING via REST API
+func (c *BedrockRESTClient) EditImage(ctx context.Context, prompt string, edit models.EditOptions, options models.GenerateOptions) (*models.GeneratedImage, error) {
+	startTime := time.Now()
+
+	request, err := buildNovaCanvasEditRequest(prompt, &edit, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	if c.verbose {
+		c.logger.Debug().
+			Str("prompt", prompt).
+			Str("task_type", request.TaskType).
+			Str("quality", request.ImageGenerationConfig.Quality).
+			Msg("Editing image with AWS Bedrock Nova Canvas via REST API")
+	}
+
+	images, err := c.invoke(ctx, request, options.Model)
+	if err != nil {
+		return nil, err
+	}
+	response := images[0]
+
+	response.Width, response.Height = imageDimensions(response.Data, options.Size)
+	response.Metadata["prompt"] = prompt
+	response.Metadata["mode"] = "edit"
+	response.Metadata["edit_mode"] = edit.Mode
+
+	if c.verbose {
+		c.logger.Info().
+			Str("task_type", request.TaskType).
+			Dur("duration", time.Since(startTime)).
+			Int("image_size_kb", len(response.Data)/1024).
+			Msg("Image edited successfully via REST API")
+	}
+
+	return response, nil
+}
+
+// invoke sends a Nova Canvas request through the circuit breaker and decodes the returned images
+func (c *BedrockRESTClient) invoke(ctx context.Context, request *NovaCanvasRequest, model string) ([]*models.GeneratedImage, error) {
+	// Marshal request to JSON
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
 	// Determine model ID
 	modelID := "amazon.nova-canvas-v1:0"
-	if options.Model != "" {
-		modelID = options.Model
+	if model != "" {
+		modelID = model
 	}
 
 	// Build API endpoint
 	endpoint := fmt.Sprintf("%s/model/%s/invoke", c.baseURL, modelID)
 
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(requestBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	// Execute request via circuit breaker
+	result, err := c.circuitBreaker.Execute(func() (interface{}, error) {
+		// Create HTTP request
+		req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(requestBody))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
 
-	// Set headers - Bearer token authentication
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+		// Set headers - Bearer token authentication
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
 
-	// Execute request via circuit breaker
-	var response *models.GeneratedImage
-	_, err = c.circuitBreaker.Execute(func() (interface{}, error) {
 		// Make HTTP request
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
@@ -141,62 +222,14 @@ func (c *BedrockRESTClient) GenerateImage(ctx context.Context, prompt string, op
 			return nil, c.handleHTTPError(resp.StatusCode, body)
 		}
 
-		// Parse response
-		var novaResponse NovaCanvasResponse
-		if err := json.Unmarshal(body, &novaResponse); err != nil {
-			return nil, fmt.Errorf("failed to parse response: %w", err)
-		}
-
-		// Check for API error
-		if novaResponse.Error != "" {
-			return nil, fmt.Errorf("API error: %s", novaResponse.Error)
-		}
-
-		// Check for images
-		if len(novaResponse.Images) == 0 {
-			return nil, fmt.Errorf("no images generated")
-		}
-
-		// Decode base64 image
-		imageData, err := base64.StdEncoding.DecodeString(novaResponse.Images[0])
-		if err != nil {
-			return nil, fmt.Errorf("failed to decode image: %w", err)
-		}
-
-		// Parse dimensions
-		width, height := parseDimensions(options.Size)
-
-		// Build response
-		response = &models.GeneratedImage{
-			Data:   imageData,
-			Format: "png",
-			Width:  width,
-			Height: height,
-			Metadata: map[string]string{
-				"model":   modelID,
-				"prompt":  prompt,
-				"size":    options.Size,
-				"seed":    fmt.Sprintf("%d", request.ImageGenerationConfig.Seed),
-				"quality": request.ImageGenerationConfig.Quality,
-			},
-		}
-
-		if c.verbose {
-			c.logger.Info().
-				Str("model", modelID).
-				Dur("duration", time.Since(startTime)).
-				Int("image_size_kb", len(imageData)/1024).
-				Msg("Image generated successfully via REST API")
-		}
-
-		return response, nil
+		return decodeNovaCanvasResponse(body, modelID, request)
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	return response, nil
+	return result.([]*models.GeneratedImage), nil
 }
 
 // buildRequest builds the Nova Canvas request from options
@@ -222,31 +255,34 @@ func (c *BedrockRESTClient) buildRequest(prompt string, options models.GenerateO
 		return nil, fmt.Errorf("invalid seed: %d (must be 0-858993459)", options.Seed)
 	}
 
-	// Determine quality from style (Nova Canvas uses standard/premium, not style)
-	// Map common quality/style keywords to Nova Canvas quality levels
-	quality := "standard"
-	if options.Style != "" {
-		lowerStyle := strings.ToLower(options.Style)
-		if lowerStyle == "premium" || lowerStyle == "high" || lowerStyle == "ultra" || lowerStyle == "photorealistic" {
-			quality = "premium"
-		}
+	// Validate image count (Nova Canvas supports 1-5 per request)
+	count := options.Count
+	if count == 0 {
+		count = 1
+	}
+	if count < 1 || count > novaCanvasMaxImages {
+		return nil, fmt.Errorf("invalid count: %d (must be 1-%d per request)", options.Count, novaCanvasMaxImages)
 	}
 
 	// Build request
 	request := &NovaCanvasRequest{
 		TaskType: "TEXT_IMAGE",
-		TextToImageParams: NovaCanvasTextToImageParams{
+		TextToImageParams: &NovaCanvasTextToImageParams{
 			Text: prompt,
 		},
 		ImageGenerationConfig: NovaCanvasImageConfig{
-			NumberOfImages: 1,
-			Quality:        quality,
+			NumberOfImages: count,
+			Quality:        novaCanvasQuality(options.Style),
 			Height:         height,
 			Width:          width,
 			CfgScale:       7.0, // Default CFG scale
 		},
 	}
 
+	if err := applyNovaCanvasOptions(&request.ImageGenerationConfig, options); err != nil {
+		return nil, err
+	}
+
 	// Add negative prompt if provided
 	if options.NegativePrompt != "" {
 		request.TextToImageParams.NegativeText = options.NegativePrompt
@@ -28,10 +28,13 @@ type BedrockSDKClient struct {
 }
 
 // NovaCanvasRequest represents the Nova Canvas API request format
+// Exactly one of the params fields is set, matching TaskType.
 type NovaCanvasRequest struct {
-	TaskType              string                     `json:"taskType"`
-	TextToImageParams     NovaCanvasTextToImageParams `json:"textToImageParams"`
-	ImageGenerationConfig NovaCanvasImageConfig       `json:"imageGenerationConfig"`
+	TaskType              string                          `json:"taskType"`
+	TextToImageParams     *NovaCanvasTextToImageParams    `json:"textToImageParams,omitempty"`
+	ImageVariationParams  *NovaCanvasImageVariationParams `json:"imageVariationParams,omitempty"`
+	InPaintingParams      *NovaCanvasInPaintingParams     `json:"inPaintingParams,omitempty"`
+	ImageGenerationConfig NovaCanvasImageConfig           `json:"imageGenerationConfig"`
 }
 
 type NovaCanvasTextToImageParams struct {
@@ -39,15 +42,35 @@ type NovaCanvasTextToImageParams struct {
 	NegativeText string `json:"negativeText,omitempty"`
 }
 
+// NovaCanvasImageVariationParams generates new images guided by source images (IMAGE_VARIATION)
+type NovaCanvasImageVariationParams struct {
+	Text               string   `json:"text,omitempty"`
+	NegativeText       string   `json:"negativeText,omitempty"`
+	Images             []string `json:"images"`                       // Base64-encoded source images
+	SimilarityStrength float64  `json:"similarityStrength,omitempty"` // 0.2-1.0
+}
+
+// NovaCanvasInPaintingParams modifies a masked region of a source image (INPAINTING)
+type NovaCanvasInPaintingParams struct {
+	Image        string `json:"image"` // Base64-encoded source image
+	Text         string `json:"text,omitempty"`
+	NegativeText string `json:"negativeText,omitempty"`
+	MaskPrompt   string `json:"maskPrompt,omitempty"`
+	MaskImage    string `json:"maskImage,omitempty"` // Base64-encoded mask (black = area to edit)
+}
+
 type NovaCanvasImageConfig struct {
 	NumberOfImages int     `json:"numberOfImages"`
 	Quality        string  `json:"quality"` // "standard" or "premium"
-	Height         int     `json:"height"`
-	Width          int     `json:"width"`
+	Height         int     `json:"height,omitempty"`
+	Width          int     `json:"width,omitempty"`
 	CfgScale       float64 `json:"cfgScale,omitempty"`
 	Seed           int     `json:"seed,omitempty"`
 }
 
+// novaCanvasMaxImages is the most images Nova Canvas returns from one request
+const novaCanvasMaxImages = 5
+
 // NovaCanvasResponse represents the Nova Canvas API response format
 type NovaCanvasResponse struct {
 	Images []string `json:"images"` // Base64-encoded images
@@ -106,7 +129,25 @@ func NewBedrockSDKClient(ctx context.Context, region string) (*BedrockSDKClient,
 
 // GenerateImage generates an image using AWS Bedrock Nova Canvas
 func (c *BedrockSDKClient) GenerateImage(ctx context.Context, prompt string, options models.GenerateOptions) (*models.GeneratedImage, error) {
+	images, err := c.generate(ctx, prompt, options, 1)
+	if err != nil {
+		return nil, err
+	}
+	return images[0], nil
+}
+
+// GenerateImages generates options.Count images using numberOfImages, split into
+// calls of at most five images
+func (c *BedrockSDKClient) GenerateImages(ctx context.Context, prompt string, options models.GenerateOptions) ([]*models.GeneratedImage, error) {
+	return generateInBatches(options.Count, novaCanvasMaxImages, func(n int) ([]*models.GeneratedImage, error) {
+		return c.generate(ctx, prompt, options, n)
+	})
+}
+
+// generate runs one TEXT_IMAGE request for count images
+func (c *BedrockSDKClient) generate(ctx context.Context, prompt string, options models.GenerateOptions, count int) ([]*models.GeneratedImage, error) {
 	startTime := time.Now()
+	options.Count = count
 
 	// Build request payload
 	request, err := c.buildRequest(prompt, options)
@@ -114,12 +155,6 @@ func (c *BedrockSDKClient) GenerateImage(ctx context.Context, prompt string, opt
 		return nil, fmt.Errorf("failed to build request: %w", err)
 	}
 
-	// Marshal request to JSON
-	requestBody, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
 	if c.verbose {
 		c.logger.Debug().
 			Str("prompt", prompt).
@@ -127,13 +162,85 @@ func (c *BedrockSDKClient) GenerateImage(ctx context.Context, prompt string, opt
 			Str("size", options.Size).
 			Str("quality", request.ImageGenerationConfig.Quality).
 			Int("seed", request.ImageGenerationConfig.Seed).
+			Int("count", request.ImageGenerationConfig.NumberOfImages).
 			Msg("Generating image with AWS Bedrock Nova Canvas")
 	}
 
+	images, err := c.invoke(ctx, request, options.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse dimensions
+	width, height := parseDimensions(options.Size)
+	for _, image := range images {
+		image.Width, image.Height = width, height
+		image.Metadata["prompt"] = prompt
+		image.Metadata["size"] = options.Size
+	}
+
+	if c.verbose {
+		c.logger.Info().
+			Str("model", images[0].Metadata["model"]).
+			Dur("duration", time.Since(startTime)).
+			Int("images", len(images)).
+			Msg("Image generated successfully")
+	}
+
+	return images, nil
+}
+
+// EditImage edits an existing image using Nova Canvas IMAGE_VARIATION or INPAINTING
+func (c *BedrockSDKClient) EditImage(ctx context.Context, prompt string, edit models.EditOptions, options models.GenerateOptions) (*models.GeneratedImage, error) {
+	startTime := time.Now()
+
+	request, err := buildNovaCanvasEditRequest(prompt, &edit, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	if c.verbose {
+		c.logger.Debug().
+			Str("prompt", prompt).
+			Str("task_type", request.TaskType).
+			Str("quality", request.ImageGenerationConfig.Quality).
+			Msg("Editing image with AWS Bedrock Nova Canvas")
+	}
+
+	images, err := c.invoke(ctx, request, options.Model)
+	if err != nil {
+		return nil, err
+	}
+	result := images[0]
+
+	result.Width, result.Height = imageDimensions(result.Data, options.Size)
+	result.Metadata["prompt"] = prompt
+	result.Metadata["mode"] = "edit"
+	result.Metadata["edit_mode"] = edit.Mode
+
+	if c.verbose {
+		c.logger.Info().
+			Str("task_type", request.TaskType).
+			Dur("duration", time.Since(startTime)).
+			Int("image_size_kb", len(result.Data)/1024).
+			Msg("Image edited successfully")
+	}
+
+	return result, nil
+}
+
+// invoke sends a Nova Canvas request through the circuit breaker and decodes the returned images
+func (c *BedrockSDKClient) invoke(ctx context.Context, request *NovaCanvasRequest, model string) ([]*models.GeneratedImage, error) {
+	// Marshal request to JSON
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
 	// Determine model ID
 	modelID := "amazon.nova-canvas-v1:0"
-	if options.Model != "" {
-		modelID = options.Model
+	if model != "" {
+		modelID = model
 	}
 
 	// Invoke model via circuit breaker
@@ -149,62 +256,14 @@ func (c *BedrockSDKClient) GenerateImage(ctx context.Context, prompt string, opt
 			return nil, c.handleError(err)
 		}
 
-		// Parse response
-		var novaResponse NovaCanvasResponse
-		if err := json.Unmarshal(output.Body, &novaResponse); err != nil {
-			return nil, fmt.Errorf("failed to parse response: %w", err)
-		}
-
-		// Check for API error
-		if novaResponse.Error != "" {
-			return nil, fmt.Errorf("API error: %s", novaResponse.Error)
-		}
-
-		// Check for images
-		if len(novaResponse.Images) == 0 {
-			return nil, fmt.Errorf("no images generated")
-		}
-
-		// Decode base64 image
-		imageData, err := base64.StdEncoding.DecodeString(novaResponse.Images[0])
-		if err != nil {
-			return nil, fmt.Errorf("failed to decode image: %w", err)
-		}
-
-		// Parse dimensions
-		width, height := parseDimensions(options.Size)
-
-		// Build response
-		generatedImage := &models.GeneratedImage{
-			Data:   imageData,
-			Format: "png",
-			Width:  width,
-			Height: height,
-			Metadata: map[string]string{
-				"model":   modelID,
-				"prompt":  prompt,
-				"size":    options.Size,
-				"seed":    fmt.Sprintf("%d", request.ImageGenerationConfig.Seed),
-				"quality": request.ImageGenerationConfig.Quality,
-			},
-		}
-
-		if c.verbose {
-			c.logger.Info().
-				Str("model", modelID).
-				Dur("duration", time.Since(startTime)).
-				Int("image_size_kb", len(imageData)/1024).
-				Msg("Image generated successfully")
-		}
-
-		return generatedImage, nil
+		return decodeNovaCanvasResponse(output.Body, modelID, request)
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	return result.(*models.GeneratedImage), nil
+	return result.([]*models.GeneratedImage), nil
 }
 
 // buildRequest builds the Nova Canvas request from options
@@ -230,31 +289,34 @@ func (c *BedrockSDKClient) buildRequest(prompt string, options models.GenerateOp
 		return nil, fmt.Errorf("invalid seed: %d (must be 0-858993459)", options.Seed)
 	}
 
-	// Determine quality from style (Nova Canvas uses standard/premium, not style)
-	// Map common quality/style keywords to Nova Canvas quality levels
-	quality := "standard"
-	if options.Style != "" {
-		lowerStyle := strings.ToLower(options.Style)
-		if lowerStyle == "premium" || lowerStyle == "high" || lowerStyle == "ultra" || lowerStyle == "photorealistic" {
-			quality = "premium"
-		}
+	// Validate image count (Nova Canvas supports 1-5 per request)
+	count := options.Count
+	if count == 0 {
+		count = 1
+	}
+	if count < 1 || count > novaCanvasMaxImages {
+		return nil, fmt.Errorf("invalid count: %d (must be 1-%d per request)", options.Count, novaCanvasMaxImages)
 	}
 
 	// Build request
 	request := &NovaCanvasRequest{
 		TaskType: "TEXT_IMAGE",
-		TextToImageParams: NovaCanvasTextToImageParams{
+		TextToImageParams: &NovaCanvasTextToImageParams{
 			Text: prompt,
 		},
 		ImageGenerationConfig: NovaCanvasImageConfig{
-			NumberOfImages: 1,
-			Quality:        quality,
+			NumberOfImages: count,
+			Quality:        novaCanvasQuality(options.Style),
 			Height:         height,
 			Width:          width,
 			CfgScale:       7.0, // Default CFG scale
 		},
 	}
 
+	if err := applyNovaCanvasOptions(&request.ImageGenerationConfig, options); err != nil {
+		return nil, err
+	}
+
 	// Add negative prompt if provided
 	if options.NegativePrompt != "" {
 		request.TextToImageParams.NegativeText = options.NegativePrompt
@@ -268,6 +330,147 @@ func (c *BedrockSDKClient) buildRequest(prompt string, options models.GenerateOp
 	return request, nil
 }
 
+// Nova Canvas keys read from GenerateOptions.Extra
+const (
+	NovaCanvasOptionCfgScale = "cfgScale" // Prompt adherence (1.1-10)
+	NovaCanvasOptionQuality  = "quality"  // standard or premium, overriding the style-based choice
+)
+
+// novaCanvasOptions are the provider options accepted by bedrock/nova-canvas
+var novaCanvasOptions = []ProviderOption{
+	{Name: NovaCanvasOptionCfgScale, Type: OptionNumber, Description: "Prompt adherence (default 7)", Min: 1.1, Max: 10},
+	{Name: NovaCanvasOptionQuality, Type: OptionString, Description: "Rendering quality (default: inferred from style)", Values: []string{"standard", "premium"}},
+}
+
+// applyNovaCanvasOptions overrides the image config defaults with provider options
+func applyNovaCanvasOptions(config *NovaCanvasImageConfig, options models.GenerateOptions) error {
+	if cfgScale, ok, err := extraFloat(options, NovaCanvasOptionCfgScale); err != nil {
+		return err
+	} else if ok {
+		if cfgScale < 1.1 || cfgScale > 10 {
+			return fmt.Errorf("invalid cfgScale: %v (must be 1.1-10)", cfgScale)
+		}
+		config.CfgScale = cfgScale
+	}
+	if quality, ok, err := extraString(options, NovaCanvasOptionQuality); err != nil {
+		return err
+	} else if ok {
+		quality = strings.ToLower(quality)
+		if quality != "standard" && quality != "premium" {
+			return fmt.Errorf("invalid quality: %q (must be standard or premium)", quality)
+		}
+		config.Quality = quality
+	}
+	return nil
+}
+
+// novaCanvasQuality determines quality from style (Nova Canvas uses standard/premium, not style).
+// Common quality/style keywords map to premium; everything else is standard.
+func novaCanvasQuality(style string) string {
+	switch strings.ToLower(style) {
+	case "premium", "high", "ultra", "photorealistic":
+		return "premium"
+	default:
+		return "standard"
+	}
+}
+
+// buildNovaCanvasEditRequest builds an IMAGE_VARIATION or INPAINTING request.
+// Edit options are validated and normalized in place. Output dimensions follow
+// the source image, so no width/height is sent.
+func buildNovaCanvasEditRequest(prompt string, edit *models.EditOptions, options models.GenerateOptions) (*NovaCanvasRequest, error) {
+	if strings.TrimSpace(prompt) == "" {
+		return nil, fmt.Errorf("prompt cannot be empty")
+	}
+
+	if err := ValidateEditOptions(edit); err != nil {
+		return nil, err
+	}
+
+	// Validate seed if provided (Nova Canvas supports 0-858993459)
+	if options.Seed < 0 || options.Seed > 858993459 {
+		return nil, fmt.Errorf("invalid seed: %d (must be 0-858993459)", options.Seed)
+	}
+
+	text := normalizeEditPrompt(prompt)
+	request := &NovaCanvasRequest{
+		ImageGenerationConfig: NovaCanvasImageConfig{
+			NumberOfImages: 1,
+			Quality:        novaCanvasQuality(options.Style),
+			CfgScale:       7.0, // Default CFG scale
+			Seed:           int(options.Seed),
+		},
+	}
+	if err := applyNovaCanvasOptions(&request.ImageGenerationConfig, options); err != nil {
+		return nil, err
+	}
+
+	switch edit.Mode {
+	case EditModeInpainting:
+		request.TaskType = "INPAINTING"
+		request.InPaintingParams = &NovaCanvasInPaintingParams{
+			Image:        base64.StdEncoding.EncodeToString(edit.Image),
+			Text:         text,
+			NegativeText: options.NegativePrompt,
+			MaskPrompt:   edit.MaskPrompt,
+		}
+		if len(edit.Mask) > 0 {
+			request.InPaintingParams.MaskImage = base64.StdEncoding.EncodeToString(edit.Mask)
+		}
+	default:
+		request.TaskType = "IMAGE_VARIATION"
+		request.ImageVariationParams = &NovaCanvasImageVariationParams{
+			Text:               text,
+			NegativeText:       options.NegativePrompt,
+			Images:             []string{base64.StdEncoding.EncodeToString(edit.Image)},
+			SimilarityStrength: edit.Strength,
+		}
+	}
+
+	return request, nil
+}
+
+// decodeNovaCanvasResponse parses a Nova Canvas response body into generated images.
+// Dimensions and prompt metadata are filled in by the caller.
+func decodeNovaCanvasResponse(body []byte, modelID string, request *NovaCanvasRequest) ([]*models.GeneratedImage, error) {
+	// Parse response
+	var novaResponse NovaCanvasResponse
+	if err := json.Unmarshal(body, &novaResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	// Check for API error
+	if novaResponse.Error != "" {
+		return nil, fmt.Errorf("API error: %s", novaResponse.Error)
+	}
+
+	// Check for images
+	if len(novaResponse.Images) == 0 {
+		return nil, fmt.Errorf("no images generated")
+	}
+
+	images := make([]*models.GeneratedImage, 0, len(novaResponse.Images))
+	for _, encoded := range novaResponse.Images {
+		// Decode base64 image
+		imageData, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image: %w", err)
+		}
+
+		images = append(images, &models.GeneratedImage{
+			Data:   imageData,
+			Format: "png",
+			Metadata: map[string]string{
+				"model":   modelID,
+				"seed":    fmt.Sprintf("%d", request.ImageGenerationConfig.Seed),
+				"quality": request.ImageGenerationConfig.Quality,
+			},
+		})
+	}
+
+	return images, nil
+}
+
 // handleError provides user-friendly error messages for AWS errors
 func (c *BedrockSDKClient) handleError(err error) error {
 	if err == nil {
@@ -2,6 +2,7 @@ package generate
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/apresai/gimage/pkg/models"
@@ -303,3 +304,33 @@ func TestBedrockQualityMapping(t *testing.T) {
 		})
 	}
 }
+
+func TestBedrockSDKClient_buildRequestOptions(t *testing.T) {
+	client, err := NewBedrockSDKClient(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	req, err := client.buildRequest("test prompt", models.GenerateOptions{
+		Size:  "1024x1024",
+		Style: "photorealistic",
+		Extra: map[string]any{NovaCanvasOptionCfgScale: "9.5", NovaCanvasOptionQuality: "standard"},
+	})
+	if err != nil {
+		t.Fatalf("buildRequest() error = %v", err)
+	}
+	if req.ImageGenerationConfig.CfgScale != 9.5 {
+		t.Errorf("CfgScale = %v, want 9.5", req.ImageGenerationConfig.CfgScale)
+	}
+	if req.ImageGenerationConfig.Quality != "standard" {
+		t.Errorf("Quality = %v, want the option to override the style", req.ImageGenerationConfig.Quality)
+	}
+
+	_, err = client.buildRequest("test prompt", models.GenerateOptions{
+		Size:  "1024x1024",
+		Extra: map[string]any{NovaCanvasOptionCfgScale: 20.0},
+	})
+	if err == nil || !strings.Contains(err.Error(), "cfgScale") {
+		t.Errorf("buildRequest() error = %v, want cfgScale range error", err)
+	}
+}
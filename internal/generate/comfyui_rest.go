@@ -0,0 +1,372 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/apresai/gimage/pkg/models"
+	"github.com/google/uuid"
+	"github.com/sony/gobreaker"
+	"github.com/spf13/viper"
+)
+
+// ComfyUI defaults
+const (
+	comfyUIDefaultSampler   = "euler"
+	comfyUIDefaultScheduler = "normal"
+
+	// comfyUIPollInterval is how often /history is checked for a queued prompt
+	comfyUIPollInterval = 500 * time.Millisecond
+
+	// comfyUIMaxWait bounds how long a queued prompt may take, including queue time
+	comfyUIMaxWait = 15 * time.Minute
+)
+
+// ComfyUIClient generates images with a ComfyUI server by queueing a standard
+// text-to-image workflow on /prompt, polling /history and downloading the
+// results from /view.
+type ComfyUIClient struct {
+	baseURL        string
+	checkpoint     string
+	clientID       string
+	httpClient     *http.Client
+	pollInterval   time.Duration
+	verbose        bool
+	circuitBreaker *gobreaker.CircuitBreaker
+}
+
+// NewComfyUIClient creates a client for the ComfyUI server at baseURL
+// (e.g. http://gpu-box:8188). checkpoint is the model file to load; when empty
+// the first checkpoint the server reports is used.
+func NewComfyUIClient(baseURL, checkpoint string) (*ComfyUIClient, error) {
+	baseURL, err := parseSDBaseURL("ComfyUI", baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if verbose mode is enabled via Viper flag or environment variable
+	verbose := viper.GetBool("verbose") || os.Getenv("GIMAGE_VERBOSE") == "true" || os.Getenv("VERBOSE") == "true"
+
+	return &ComfyUIClient{
+		baseURL:    baseURL,
+		checkpoint: checkpoint,
+		clientID:   uuid.NewString(),
+		httpClient: &http.Client{
+			Timeout: 2 * time.Minute,
+		},
+		pollInterval:   comfyUIPollInterval,
+		verbose:        verbose,
+		circuitBreaker: newCircuitBreaker("ComfyUIAPI"),
+	}, nil
+}
+
+// logVerbose logs debug information if verbose mode is enabled
+func (c *ComfyUIClient) logVerbose(format string, args ...interface{}) {
+	if c.verbose {
+		fmt.Fprintf(os.Stderr, "[COMFYUI] "+format+"\n", args...)
+	}
+}
+
+// comfyUINode is one node of a workflow in ComfyUI's API format
+type comfyUINode struct {
+	ClassType string         `json:"class_type"`
+	Inputs    map[string]any `json:"inputs"`
+}
+
+// comfyUIPromptResponse is the /prompt response body
+type comfyUIPromptResponse struct {
+	PromptID   string         `json:"prompt_id"`
+	Error      any            `json:"error,omitempty"`
+	NodeErrors map[string]any `json:"node_errors,omitempty"`
+}
+
+// comfyUIHistoryEntry is one prompt's entry in the /history response
+type comfyUIHistoryEntry struct {
+	Outputs map[string]struct {
+		Images []comfyUIImageRef `json:"images"`
+	} `json:"outputs"`
+	Status struct {
+		StatusStr string `json:"status_str"`
+		Completed bool   `json:"completed"`
+		Messages  []any  `json:"messages"`
+	} `json:"status"`
+}
+
+// comfyUIImageRef identifies an output file for /view
+type comfyUIImageRef struct {
+	Filename  string `json:"filename"`
+	Subfolder string `json:"subfolder"`
+	Type      string `json:"type"`
+}
+
+// GenerateImage generates a single image
+func (c *ComfyUIClient) GenerateImage(ctx context.Context, prompt string, options models.GenerateOptions) (*models.GeneratedImage, error) {
+	images, err := c.generate(ctx, prompt, options, 1)
+	if err != nil {
+		return nil, err
+	}
+	return images[0], nil
+}
+
+// GenerateImages generates options.Count images using the latent batch size,
+// split into batches of at most eight images
+func (c *ComfyUIClient) GenerateImages(ctx context.Context, prompt string, options models.GenerateOptions) ([]*models.GeneratedImage, error) {
+	var generated int
+	return generateInBatches(options.Count, sdMaxImagesPerRequest, func(n int) ([]*models.GeneratedImage, error) {
+		batch := options
+		if options.Seed != 0 {
+			batch.Seed = options.Seed + int64(generated) // Vary candidates across batches
+		}
+		images, err := c.generate(ctx, prompt, batch, n)
+		generated += len(images)
+		return images, err
+	})
+}
+
+// generate queues one workflow for count images and waits for the results
+func (c *ComfyUIClient) generate(ctx context.Context, prompt string, options models.GenerateOptions, count int) ([]*models.GeneratedImage, error) {
+	startTime := time.Now()
+
+	settings, err := sdSettingsFromOptions(prompt, options)
+	if err != nil {
+		return nil, err
+	}
+	if settings.Sampler == "" {
+		settings.Sampler = comfyUIDefaultSampler
+	}
+	if settings.Scheduler == "" {
+		settings.Scheduler = comfyUIDefaultScheduler
+	}
+
+	checkpoint, err := c.resolveCheckpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow := comfyUIWorkflow(settings, checkpoint, count)
+
+	c.logVerbose("Queueing %dx%d, %d steps, seed %d, batch %d with %s at %s", settings.Width, settings.Height, settings.Steps, settings.Seed, count, checkpoint, c.baseURL)
+
+	result, err := c.circuitBreaker.Execute(func() (interface{}, error) {
+		return c.run(ctx, workflow)
+	})
+	if err != nil {
+		return nil, err
+	}
+	refs := result.([]comfyUIImageRef)
+
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("no images in ComfyUI output")
+	}
+
+	images := make([]*models.GeneratedImage, 0, len(refs))
+	for i, ref := range refs {
+		data, err := c.download(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		width, height := imageDimensions(data, options.Size)
+		images = append(images, &models.GeneratedImage{
+			Data:     data,
+			Format:   extractFormatFromMimeType(http.DetectContentType(data)),
+			Width:    width,
+			Height:   height,
+			Metadata: settings.metadata(checkpoint, prompt, settings.Seed),
+		})
+		images[i].Metadata["batch_index"] = fmt.Sprintf("%d", i)
+	}
+
+	c.logVerbose("Generated %d image(s) in %v", len(images), time.Since(startTime))
+
+	return images, nil
+}
+
+// comfyUIWorkflow builds the default text-to-image workflow in API format
+func comfyUIWorkflow(s *sdSettings, checkpoint string, count int) map[string]comfyUINode {
+	return map[string]comfyUINode{
+		"3": {ClassType: "KSampler", Inputs: map[string]any{
+			"seed":         s.Seed,
+			"steps":        s.Steps,
+			"cfg":          s.CfgScale,
+			"sampler_name": s.Sampler,
+			"scheduler":    s.Scheduler,
+			"denoise":      1.0,
+			"model":        []any{"4", 0},
+			"positive":     []any{"6", 0},
+			"negative":     []any{"7", 0},
+			"latent_image": []any{"5", 0},
+		}},
+		"4": {ClassType: "CheckpointLoaderSimple", Inputs: map[string]any{
+			"ckpt_name": checkpoint,
+		}},
+		"5": {ClassType: "EmptyLatentImage", Inputs: map[string]any{
+			"width":      s.Width,
+			"height":     s.Height,
+			"batch_size": count,
+		}},
+		"6": {ClassType: "CLIPTextEncode", Inputs: map[string]any{
+			"text": s.Prompt,
+			"clip": []any{"4", 1},
+		}},
+		"7": {ClassType: "CLIPTextEncode", Inputs: map[string]any{
+			"text": s.NegativePrompt,
+			"clip": []any{"4", 1},
+		}},
+		"8": {ClassType: "VAEDecode", Inputs: map[string]any{
+			"samples": []any{"3", 0},
+			"vae":     []any{"4", 2},
+		}},
+		"9": {ClassType: "SaveImage", Inputs: map[string]any{
+			"filename_prefix": "gimage",
+			"images":          []any{"8", 0},
+		}},
+	}
+}
+
+// run queues the workflow and polls its history until the outputs are ready
+func (c *ComfyUIClient) run(ctx context.Context, workflow map[string]comfyUINode) ([]comfyUIImageRef, error) {
+	ctx, cancel := context.WithTimeout(ctx, comfyUIMaxWait)
+	defer cancel()
+
+	var queued comfyUIPromptResponse
+	if err := c.doJSON(ctx, "POST", "/prompt", map[string]any{"prompt": workflow, "client_id": c.clientID}, &queued); err != nil {
+		return nil, err
+	}
+	if queued.PromptID == "" {
+		return nil, fmt.Errorf("ComfyUI did not return a prompt_id")
+	}
+
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		var history map[string]comfyUIHistoryEntry
+		if err := c.doJSON(ctx, "GET", "/history/"+url.PathEscape(queued.PromptID), nil, &history); err != nil {
+			return nil, err
+		}
+
+		if entry, ok := history[queued.PromptID]; ok {
+			if entry.Status.StatusStr == "error" {
+				return nil, fmt.Errorf("ComfyUI workflow failed: %v", entry.Status.Messages)
+			}
+			if entry.Status.Completed || entry.Status.StatusStr == "success" {
+				var refs []comfyUIImageRef
+				for _, output := range entry.Outputs {
+					refs = append(refs, output.Images...)
+				}
+				return refs, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timeout waiting for ComfyUI prompt %s: %w", queued.PromptID, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// resolveCheckpoint returns the configured checkpoint or the first one the server has
+func (c *ComfyUIClient) resolveCheckpoint(ctx context.Context) (string, error) {
+	if c.checkpoint != "" {
+		return c.checkpoint, nil
+	}
+
+	var info map[string]struct {
+		Input struct {
+			Required struct {
+				CkptName []json.RawMessage `json:"ckpt_name"`
+			} `json:"required"`
+		} `json:"input"`
+	}
+	if err := c.doJSON(ctx, "GET", "/object_info/CheckpointLoaderSimple", nil, &info); err != nil {
+		return "", fmt.Errorf("failed to list ComfyUI checkpoints: %w", err)
+	}
+
+	var names []string
+	if fields := info["CheckpointLoaderSimple"].Input.Required.CkptName; len(fields) > 0 {
+		json.Unmarshal(fields[0], &names)
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("ComfyUI has no checkpoints installed; set COMFYUI_CHECKPOINT")
+	}
+
+	c.checkpoint = names[0]
+	return c.checkpoint, nil
+}
+
+// download fetches an output image from /view
+func (c *ComfyUIClient) download(ctx context.Context, ref comfyUIImageRef) ([]byte, error) {
+	query := url.Values{"filename": {ref.Filename}, "subfolder": {ref.Subfolder}, "type": {ref.Type}}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/view?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", ref.Filename, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ref.Filename, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, sdHTTPError("ComfyUI", resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// doJSON sends a JSON request and decodes the JSON response into out
+func (c *ComfyUIClient) doJSON(ctx context.Context, method, path string, payload, out any) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return sdHTTPError("ComfyUI", resp.StatusCode, respBody)
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// Close cleans up resources (no-op for REST client, implements interface)
+func (c *ComfyUIClient) Close() error {
+	return nil
+}
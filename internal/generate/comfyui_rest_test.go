@@ -0,0 +1,183 @@
+package generate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apresai/gimage/pkg/models"
+)
+
+// comfyUIStub emulates the ComfyUI queue: /prompt queues a workflow, /history
+// reports it done after a few polls, and /view serves the output files
+type comfyUIStub struct {
+	mu        sync.Mutex
+	workflows []map[string]comfyUINode
+	polls     int
+	failWith  string
+}
+
+func newComfyUIStub(t *testing.T, stub *comfyUIStub) *httptest.Server {
+	t.Helper()
+	imageData := testPNG(t, 32, 32)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.mu.Lock()
+		defer stub.mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/object_info/CheckpointLoaderSimple":
+			w.Write([]byte(`{"CheckpointLoaderSimple":{"input":{"required":{"ckpt_name":[["v1-5-pruned.safetensors","sdxl.safetensors"],{}]}}}}`))
+
+		case r.URL.Path == "/prompt":
+			var body struct {
+				Prompt   map[string]comfyUINode `json:"prompt"`
+				ClientID string                 `json:"client_id"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.ClientID == "" {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			stub.workflows = append(stub.workflows, body.Prompt)
+			fmt.Fprintf(w, `{"prompt_id":"p%d","number":1,"node_errors":{}}`, len(stub.workflows))
+
+		case strings.HasPrefix(r.URL.Path, "/history/"):
+			id := strings.TrimPrefix(r.URL.Path, "/history/")
+			stub.polls++
+			if stub.polls%2 == 1 {
+				w.Write([]byte(`{}`)) // Still queued
+				return
+			}
+			if stub.failWith != "" {
+				fmt.Fprintf(w, `{%q:{"outputs":{},"status":{"status_str":"error","completed":false,"messages":[[%q,{}]]}}}`, id, stub.failWith)
+				return
+			}
+
+			workflow := stub.workflows[len(stub.workflows)-1]
+			batch := int(workflow["5"].Inputs["batch_size"].(float64))
+			var images []string
+			for i := 0; i < batch; i++ {
+				images = append(images, fmt.Sprintf(`{"filename":"gimage_%05d_.png","subfolder":"","type":"output"}`, i))
+			}
+			fmt.Fprintf(w, `{%q:{"outputs":{"9":{"images":[%s]}},"status":{"status_str":"success","completed":true}}}`, id, strings.Join(images, ","))
+
+		case r.URL.Path == "/view":
+			if r.URL.Query().Get("type") != "output" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(imageData)
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestComfyUIClient(t *testing.T, baseURL, checkpoint string) *ComfyUIClient {
+	t.Helper()
+	client, err := NewComfyUIClient(baseURL, checkpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.pollInterval = time.Millisecond
+	return client
+}
+
+func TestComfyUIClient_GenerateImage(t *testing.T) {
+	stub := &comfyUIStub{}
+	server := newComfyUIStub(t, stub)
+	client := newTestComfyUIClient(t, server.URL, "")
+
+	image, err := client.GenerateImage(context.Background(), "a forest", models.GenerateOptions{
+		Size:           "512x768",
+		Seed:           7,
+		NegativePrompt: "text",
+		Extra:          map[string]any{SDOptionSampler: "dpmpp_2m", SDOptionScheduler: "karras", SDOptionSteps: 25.0},
+	})
+	if err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+
+	workflow := stub.workflows[0]
+	sampler := workflow["3"].Inputs
+	if sampler["seed"].(float64) != 7 || sampler["steps"].(float64) != 25 || sampler["sampler_name"] != "dpmpp_2m" || sampler["scheduler"] != "karras" {
+		t.Errorf("unexpected KSampler inputs: %v", sampler)
+	}
+	if workflow["4"].Inputs["ckpt_name"] != "v1-5-pruned.safetensors" {
+		t.Errorf("checkpoint = %v, want the first installed", workflow["4"].Inputs["ckpt_name"])
+	}
+	latent := workflow["5"].Inputs
+	if latent["width"].(float64) != 512 || latent["height"].(float64) != 768 {
+		t.Errorf("unexpected latent size: %v", latent)
+	}
+	if workflow["7"].Inputs["text"] != "text" {
+		t.Errorf("negative prompt = %v", workflow["7"].Inputs["text"])
+	}
+
+	if image.Width != 32 || image.Format != "png" {
+		t.Errorf("image = %s %dx%d", image.Format, image.Width, image.Height)
+	}
+	if image.Metadata["model"] != "v1-5-pruned.safetensors" || image.Metadata["seed"] != "7" {
+		t.Errorf("unexpected metadata: %v", image.Metadata)
+	}
+}
+
+func TestComfyUIClient_GenerateImages(t *testing.T) {
+	stub := &comfyUIStub{}
+	server := newComfyUIStub(t, stub)
+	client := newTestComfyUIClient(t, server.URL, "sdxl.safetensors")
+
+	images, err := client.GenerateImages(context.Background(), "prompt", models.GenerateOptions{Count: 3})
+	if err != nil {
+		t.Fatalf("GenerateImages() error = %v", err)
+	}
+	if len(images) != 3 {
+		t.Fatalf("got %d images, want 3", len(images))
+	}
+	if len(stub.workflows) != 1 {
+		t.Errorf("got %d queued prompts, want 1 batched prompt", len(stub.workflows))
+	}
+	if stub.workflows[0]["4"].Inputs["ckpt_name"] != "sdxl.safetensors" {
+		t.Errorf("configured checkpoint not used: %v", stub.workflows[0]["4"].Inputs)
+	}
+}
+
+func TestComfyUIClient_WorkflowError(t *testing.T) {
+	stub := &comfyUIStub{failWith: "execution_error"}
+	server := newComfyUIStub(t, stub)
+	client := newTestComfyUIClient(t, server.URL, "sdxl.safetensors")
+
+	_, err := client.GenerateImage(context.Background(), "prompt", models.GenerateOptions{})
+	if err == nil || !strings.Contains(err.Error(), "execution_error") {
+		t.Fatalf("error = %v, want workflow failure", err)
+	}
+}
+
+func TestComfyUIClient_Cancelled(t *testing.T) {
+	// A server that never finishes the prompt
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/prompt" {
+			w.Write([]byte(`{"prompt_id":"stuck"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := newTestComfyUIClient(t, server.URL, "sdxl.safetensors")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.GenerateImage(ctx, "prompt", models.GenerateOptions{}); err == nil {
+		t.Fatal("expected timeout error")
+	}
+}
@@ -0,0 +1,84 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"net/http"
+	"strings"
+
+	"github.com/apresai/gimage/internal/imaging"
+	"github.com/apresai/gimage/pkg/models"
+)
+
+// Edit modes supported by ImageEditor implementations
+const (
+	EditModeVariation  = "variation"  // Regenerate the whole image guided by the source
+	EditModeInpainting = "inpainting" // Change only the masked region of the source
+)
+
+// ValidateEditOptions validates edit options and normalizes them in place.
+// Source images that are not PNG or JPEG are converted to PNG, since that is
+// what every editing provider accepts.
+func ValidateEditOptions(edit *models.EditOptions) error {
+	if edit == nil || len(edit.Image) == 0 {
+		return fmt.Errorf("source image is required for editing")
+	}
+
+	if edit.MimeType == "" {
+		edit.MimeType = http.DetectContentType(edit.Image)
+	}
+
+	if edit.MimeType != "image/png" && edit.MimeType != "image/jpeg" {
+		converted, err := imaging.ConvertImageData(edit.Image, "png")
+		if err != nil {
+			return fmt.Errorf("unsupported source image (%s): %w", edit.MimeType, err)
+		}
+		edit.Image = converted
+		edit.MimeType = "image/png"
+	}
+
+	edit.Mode = strings.ToLower(strings.TrimSpace(edit.Mode))
+	if edit.Mode == "" {
+		edit.Mode = EditModeVariation
+	}
+
+	switch edit.Mode {
+	case EditModeVariation:
+		if len(edit.Mask) > 0 || edit.MaskPrompt != "" {
+			return fmt.Errorf("mask and mask prompt are only valid with %s mode", EditModeInpainting)
+		}
+	case EditModeInpainting:
+		if len(edit.Mask) == 0 && edit.MaskPrompt == "" {
+			return fmt.Errorf("%s mode requires a mask image or mask prompt", EditModeInpainting)
+		}
+		if len(edit.Mask) > 0 && edit.MaskPrompt != "" {
+			return fmt.Errorf("provide either a mask image or a mask prompt, not both")
+		}
+	default:
+		return fmt.Errorf("invalid edit mode: %s (must be '%s' or '%s')", edit.Mode, EditModeVariation, EditModeInpainting)
+	}
+
+	if edit.Strength != 0 && (edit.Strength < 0.2 || edit.Strength > 1.0) {
+		return fmt.Errorf("invalid strength: %.2f (must be 0.2-1.0)", edit.Strength)
+	}
+
+	return nil
+}
+
+// normalizeEditPrompt cleans up an edit instruction. Unlike EnhancePrompt it
+// adds no quality keywords, because edit instructions describe a change rather
+// than a whole scene.
+func normalizeEditPrompt(prompt string) string {
+	return normalizeWhitespace(strings.TrimSpace(prompt))
+}
+
+// imageDimensions reads the dimensions of encoded image data.
+// Falls back to the requested size if the data cannot be decoded.
+func imageDimensions(data []byte, fallbackSize string) (int, int) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 {
+		return parseDimensions(fallbackSize)
+	}
+	return cfg.Width, cfg.Height
+}
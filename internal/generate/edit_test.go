@@ -0,0 +1,231 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/apresai/gimage/pkg/models"
+)
+
+// encodeTestImage returns a small solid-color image encoded with the given encoder
+func encodeTestImage(t *testing.T, format string) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 32, 24))
+	for y := 0; y < 24; y++ {
+		for x := 0; x < 32; x++ {
+			img.Set(x, y, color.RGBA{100, 150, 200, 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	default:
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestValidateEditOptions(t *testing.T) {
+	pngData := encodeTestImage(t, "png")
+
+	tests := []struct {
+		name        string
+		edit        models.EditOptions
+		wantErr     bool
+		errContains string
+		wantMode    string
+	}{
+		{
+			name:     "defaults to variation",
+			edit:     models.EditOptions{Image: pngData},
+			wantMode: EditModeVariation,
+		},
+		{
+			name:     "inpainting with mask prompt",
+			edit:     models.EditOptions{Image: pngData, Mode: "Inpainting", MaskPrompt: "the sky"},
+			wantMode: EditModeInpainting,
+		},
+		{
+			name:     "inpainting with mask image",
+			edit:     models.EditOptions{Image: pngData, Mode: EditModeInpainting, Mask: pngData},
+			wantMode: EditModeInpainting,
+		},
+		{
+			name:        "missing image",
+			edit:        models.EditOptions{},
+			wantErr:     true,
+			errContains: "source image is required",
+		},
+		{
+			name:        "invalid mode",
+			edit:        models.EditOptions{Image: pngData, Mode: "outpainting"},
+			wantErr:     true,
+			errContains: "invalid edit mode",
+		},
+		{
+			name:        "inpainting without mask",
+			edit:        models.EditOptions{Image: pngData, Mode: EditModeInpainting},
+			wantErr:     true,
+			errContains: "requires a mask",
+		},
+		{
+			name:        "inpainting with both masks",
+			edit:        models.EditOptions{Image: pngData, Mode: EditModeInpainting, Mask: pngData, MaskPrompt: "the sky"},
+			wantErr:     true,
+			errContains: "not both",
+		},
+		{
+			name:        "variation with mask",
+			edit:        models.EditOptions{Image: pngData, MaskPrompt: "the sky"},
+			wantErr:     true,
+			errContains: "only valid with inpainting",
+		},
+		{
+			name:        "strength too low",
+			edit:        models.EditOptions{Image: pngData, Strength: 0.1},
+			wantErr:     true,
+			errContains: "invalid strength",
+		},
+		{
+			name:        "undecodable image",
+			edit:        models.EditOptions{Image: []byte("not an image")},
+			wantErr:     true,
+			errContains: "unsupported source image",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateEditOptions(&tt.edit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateEditOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("ValidateEditOptions() error = %v, should contain %q", err, tt.errContains)
+				}
+				return
+			}
+			if tt.edit.Mode != tt.wantMode {
+				t.Errorf("ValidateEditOptions() mode = %v, want %v", tt.edit.Mode, tt.wantMode)
+			}
+			if tt.edit.MimeType != "image/png" {
+				t.Errorf("ValidateEditOptions() mimeType = %v, want image/png", tt.edit.MimeType)
+			}
+		})
+	}
+}
+
+func TestValidateEditOptions_ConvertsToPNG(t *testing.T) {
+	edit := models.EditOptions{Image: encodeTestImage(t, "gif")}
+
+	if err := ValidateEditOptions(&edit); err != nil {
+		t.Fatalf("ValidateEditOptions() error = %v", err)
+	}
+
+	if edit.MimeType != "image/png" {
+		t.Errorf("mimeType = %v, want image/png", edit.MimeType)
+	}
+
+	if _, err := png.Decode(bytes.NewReader(edit.Image)); err != nil {
+		t.Errorf("converted image is not a valid PNG: %v", err)
+	}
+}
+
+func TestBuildNovaCanvasEditRequest(t *testing.T) {
+	pngData := encodeTestImage(t, "png")
+	encoded := base64.StdEncoding.EncodeToString(pngData)
+
+	t.Run("variation", func(t *testing.T) {
+		edit := models.EditOptions{Image: pngData, Strength: 0.7}
+		req, err := buildNovaCanvasEditRequest("watercolor painting", &edit, models.GenerateOptions{
+			NegativePrompt: "text",
+			Seed:           42,
+		})
+		if err != nil {
+			t.Fatalf("buildNovaCanvasEditRequest() error = %v", err)
+		}
+
+		if req.TaskType != "IMAGE_VARIATION" {
+			t.Errorf("taskType = %v, want IMAGE_VARIATION", req.TaskType)
+		}
+		if req.ImageVariationParams == nil || req.TextToImageParams != nil || req.InPaintingParams != nil {
+			t.Fatal("only imageVariationParams should be set")
+		}
+		if len(req.ImageVariationParams.Images) != 1 || req.ImageVariationParams.Images[0] != encoded {
+			t.Error("source image not encoded into images")
+		}
+		if req.ImageVariationParams.SimilarityStrength != 0.7 {
+			t.Errorf("similarityStrength = %v, want 0.7", req.ImageVariationParams.SimilarityStrength)
+		}
+		if req.ImageVariationParams.NegativeText != "text" {
+			t.Errorf("negativeText = %v, want text", req.ImageVariationParams.NegativeText)
+		}
+		if req.ImageGenerationConfig.Seed != 42 {
+			t.Errorf("seed = %v, want 42", req.ImageGenerationConfig.Seed)
+		}
+	})
+
+	t.Run("inpainting", func(t *testing.T) {
+		edit := models.EditOptions{Image: pngData, Mode: EditModeInpainting, MaskPrompt: "the car"}
+		req, err := buildNovaCanvasEditRequest("a red sports car", &edit, models.GenerateOptions{Style: "premium"})
+		if err != nil {
+			t.Fatalf("buildNovaCanvasEditRequest() error = %v", err)
+		}
+
+		if req.TaskType != "INPAINTING" {
+			t.Errorf("taskType = %v, want INPAINTING", req.TaskType)
+		}
+		if req.InPaintingParams == nil {
+			t.Fatal("inPaintingParams should be set")
+		}
+		if req.InPaintingParams.Image != encoded || req.InPaintingParams.MaskPrompt != "the car" {
+			t.Error("inPaintingParams not populated from edit options")
+		}
+		if req.ImageGenerationConfig.Quality != "premium" {
+			t.Errorf("quality = %v, want premium", req.ImageGenerationConfig.Quality)
+		}
+
+		// Output size follows the source image, so width/height must not be sent
+		body, err := json.Marshal(req)
+		if err != nil {
+			t.Fatalf("failed to marshal request: %v", err)
+		}
+		if strings.Contains(string(body), `"width"`) || strings.Contains(string(body), `"textToImageParams"`) {
+			t.Errorf("unexpected fields in request: %s", body)
+		}
+	})
+
+	t.Run("empty prompt", func(t *testing.T) {
+		edit := models.EditOptions{Image: pngData}
+		if _, err := buildNovaCanvasEditRequest("  ", &edit, models.GenerateOptions{}); err == nil {
+			t.Error("expected error for empty prompt")
+		}
+	})
+}
+
+func TestImageDimensions(t *testing.T) {
+	width, height := imageDimensions(encodeTestImage(t, "png"), "1024x1024")
+	if width != 32 || height != 24 {
+		t.Errorf("imageDimensions() = %dx%d, want 32x24", width, height)
+	}
+
+	width, height = imageDimensions([]byte("garbage"), "512x768")
+	if width != 512 || height != 768 {
+		t.Errorf("imageDimensions() fallback = %dx%d, want 512x768", width, height)
+	}
+}
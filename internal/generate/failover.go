@@ -0,0 +1,224 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/apresai/gimage/internal/config"
+	"github.com/apresai/gimage/pkg/models"
+	"github.com/sony/gobreaker"
+	"github.com/spf13/viper"
+)
+
+// FailoverGenerator tries a chain of providers in order, moving to the next
+// provider when one fails with a retryable, quota or open-circuit error.
+// Fallback clients are created on first use; providers without credentials
+// are skipped.
+type FailoverGenerator struct {
+	registry *ProviderRegistry
+	cfg      *config.Config
+	chain    []*Provider
+	clients  map[string]ImageGenerator
+	verbose  bool
+}
+
+// NewFailoverGenerator creates a failover wrapper for the given provider chain.
+// primary, if non-nil, is used as the already-created client for chain[0].
+func (r *ProviderRegistry) NewFailoverGenerator(chain []*Provider, primary ImageGenerator, cfg *config.Config) (*FailoverGenerator, error) {
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("failover chain is empty")
+	}
+
+	f := &FailoverGenerator{
+		registry: r,
+		cfg:      cfg,
+		chain:    chain,
+		clients:  make(map[string]ImageGenerator),
+		verbose:  viper.GetBool("verbose") || os.Getenv("GIMAGE_VERBOSE") == "true" || os.Getenv("VERBOSE") == "true",
+	}
+	if primary != nil {
+		f.clients[chain[0].ID] = primary
+	}
+
+	return f, nil
+}
+
+// FailoverChain returns the providers to try for selected: the selected
+// provider first, then the configured fallbacks in order (duplicates removed)
+func (r *ProviderRegistry) FailoverChain(selected *Provider, fallbacks []string) ([]*Provider, error) {
+	chain := []*Provider{selected}
+	seen := map[string]bool{selected.ID: true}
+
+	for _, entry := range fallbacks {
+		p, err := r.ResolveProvider(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fallback provider: %w", err)
+		}
+		if seen[p.ID] {
+			continue
+		}
+		seen[p.ID] = true
+		chain = append(chain, p)
+	}
+
+	return chain, nil
+}
+
+// Providers returns the chain of providers in the order they are tried
+func (f *FailoverGenerator) Providers() []*Provider {
+	return f.chain
+}
+
+// GenerateImage generates a single image, failing over along the chain
+func (f *FailoverGenerator) GenerateImage(ctx context.Context, prompt string, options models.GenerateOptions) (*models.GeneratedImage, error) {
+	images, err := f.run(ctx, func(client ImageGenerator, opts models.GenerateOptions) ([]*models.GeneratedImage, error) {
+		image, err := client.GenerateImage(ctx, prompt, opts)
+		if err != nil {
+			return nil, err
+		}
+		return []*models.GeneratedImage{image}, nil
+	}, options)
+	if err != nil {
+		return nil, err
+	}
+	return images[0], nil
+}
+
+// GenerateImages generates options.Count images from a single provider,
+// failing over along the chain. Implements MultiImageGenerator so each
+// provider keeps its native batching.
+func (f *FailoverGenerator) GenerateImages(ctx context.Context, prompt string, options models.GenerateOptions) ([]*models.GeneratedImage, error) {
+	return f.run(ctx, func(client ImageGenerator, opts models.GenerateOptions) ([]*models.GeneratedImage, error) {
+		return GenerateImages(ctx, client, prompt, opts)
+	}, options)
+}
+
+// run calls attempt for each provider in the chain until one succeeds
+func (f *FailoverGenerator) run(ctx context.Context, attempt func(client ImageGenerator, opts models.GenerateOptions) ([]*models.GeneratedImage, error), options models.GenerateOptions) ([]*models.GeneratedImage, error) {
+	var failed []string
+	var errs []string
+
+	for i, p := range f.chain {
+		client, err := f.client(p)
+		if err != nil {
+			// Unconfigured fallbacks are skipped, not fatal
+			errs = append(errs, fmt.Sprintf("%s: %v", p.ID, err))
+			continue
+		}
+
+		// Each provider needs its own model ID, and fallbacks only get the
+		// provider options they declare and a size they support
+		opts := options
+		opts.Model = p.ModelID
+		if i > 0 {
+			opts.Extra = p.supportedOptions(options.Extra)
+
+			// Re-snap the previous provider's native size to this one's sizes
+			if opts.Size != "" {
+				opts.AspectRatio = ""
+			}
+			if native, err := p.NativeOptions(opts); err == nil {
+				opts = native
+			}
+		}
+
+		images, err := attempt(client, opts)
+		if err == nil {
+			for _, image := range images {
+				if image.Metadata == nil {
+					image.Metadata = map[string]string{}
+				}
+				image.Metadata["provider"] = p.ID
+				if len(failed) > 0 {
+					image.Metadata["failover_from"] = strings.Join(failed, ",")
+				}
+			}
+			return images, nil
+		}
+
+		failed = append(failed, p.ID)
+		errs = append(errs, fmt.Sprintf("%s: %v", p.ID, err))
+
+		// Caller cancellation and request errors (bad prompt, safety filter) end the chain
+		if ctx.Err() != nil || !IsFailoverError(err) {
+			return nil, err
+		}
+
+		if i < len(f.chain)-1 {
+			f.logVerbose("%s failed: %v - trying next provider", p.ID, err)
+		}
+	}
+
+	return nil, fmt.Errorf("all providers failed:\n  %s", strings.Join(errs, "\n  "))
+}
+
+// logVerbose logs debug information if verbose mode is enabled
+func (f *FailoverGenerator) logVerbose(format string, args ...interface{}) {
+	if f.verbose {
+		fmt.Fprintf(os.Stderr, "[FAILOVER] "+format+"\n", args...)
+	}
+}
+
+// client returns the client for p, creating it on first use
+func (f *FailoverGenerator) client(p *Provider) (ImageGenerator, error) {
+	if client, ok := f.clients[p.ID]; ok {
+		return client, nil
+	}
+
+	client, err := f.registry.NewClient(p, f.cfg)
+	if err != nil {
+		return nil, err
+	}
+	f.clients[p.ID] = client
+	return client, nil
+}
+
+// Close closes every client the chain created
+func (f *FailoverGenerator) Close() error {
+	var firstErr error
+	for _, client := range f.clients {
+		if err := client.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// IsFailoverError reports whether err should move generation to the next
+// provider: transient/retryable errors, quota and throttling errors, and
+// open circuit breakers
+func IsFailoverError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+		return true
+	}
+
+	if isRetryableError(err) {
+		return true
+	}
+
+	errStr := strings.ToLower(err.Error())
+	failoverPatterns := []string{
+		"circuit breaker",
+		"quota",
+		"resource_exhausted",
+		"resource exhausted",
+		"throttl",
+		"server error",
+		"too many requests",
+	}
+
+	for _, pattern := range failoverPatterns {
+		if strings.Contains(errStr, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
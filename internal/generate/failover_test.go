@@ -0,0 +1,325 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/apresai/gimage/internal/config"
+	"github.com/apresai/gimage/pkg/models"
+	"github.com/sony/gobreaker"
+)
+
+// scriptedGenerator fails with err (if set) and records the models, sizes and
+// provider options it was asked for. Multi-image generation calls it from
+// several goroutines, so the records are guarded by mu.
+type scriptedGenerator struct {
+	err error
+
+	mu     sync.Mutex
+	models []string
+	sizes  []string
+	extras []map[string]any
+	closed bool
+}
+
+func (s *scriptedGenerator) GenerateImage(ctx context.Context, prompt string, options models.GenerateOptions) (*models.GeneratedImage, error) {
+	s.mu.Lock()
+	s.models = append(s.models, options.Model)
+	s.sizes = append(s.sizes, options.Size)
+	s.extras = append(s.extras, options.Extra)
+	s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.GeneratedImage{Data: []byte("img"), Format: "png", Metadata: map[string]string{"model": options.Model}}, nil
+}
+
+func (s *scriptedGenerator) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+// recordedModels returns a copy of the models requested so far
+func (s *scriptedGenerator) recordedModels() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.models...)
+}
+
+// recordedSizes returns a copy of the sizes requested so far
+func (s *scriptedGenerator) recordedSizes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.sizes...)
+}
+
+// recordedExtras returns a copy of the provider options passed so far
+func (s *scriptedGenerator) recordedExtras() []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]any(nil), s.extras...)
+}
+
+// isClosed reports whether Close was called
+func (s *scriptedGenerator) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// newTestRegistry returns a registry holding one test provider per client
+func newTestRegistry(clients map[string]*scriptedGenerator) *ProviderRegistry {
+	r := &ProviderRegistry{providers: make(map[string]*Provider)}
+	for id, client := range clients {
+		client := client
+		r.Register(&Provider{
+			ID:      id,
+			Name:    id,
+			API:     "test",
+			ModelID: id + "-model",
+			CreateClient: func(creds map[string]string) (ImageGenerator, error) {
+				return client, nil
+			},
+		})
+	}
+	return r
+}
+
+func newTestFailover(t *testing.T, r *ProviderRegistry, ids ...string) *FailoverGenerator {
+	t.Helper()
+
+	primary, err := r.Get(ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, err := r.FailoverChain(primary, ids[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := r.NewFailoverGenerator(chain, nil, &config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestFailoverGenerator_FailsOverOnQuota(t *testing.T) {
+	primary := &scriptedGenerator{err: fmt.Errorf("API error 429: quota exceeded")}
+	fallback := &scriptedGenerator{}
+	r := newTestRegistry(map[string]*scriptedGenerator{"test/primary": primary, "test/fallback": fallback})
+
+	f := newTestFailover(t, r, "test/primary", "test/fallback")
+	image, err := f.GenerateImage(context.Background(), "prompt", models.GenerateOptions{Model: "ignored"})
+	if err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+
+	if image.Metadata["provider"] != "test/fallback" {
+		t.Errorf("provider = %q, want test/fallback", image.Metadata["provider"])
+	}
+	if image.Metadata["failover_from"] != "test/primary" {
+		t.Errorf("failover_from = %q, want test/primary", image.Metadata["failover_from"])
+	}
+
+	// Each provider is called with its own model ID
+	if len(primary.recordedModels()) == 0 || primary.recordedModels()[0] != "test/primary-model" {
+		t.Errorf("primary models = %v", primary.recordedModels())
+	}
+	if len(fallback.recordedModels()) != 1 || fallback.recordedModels()[0] != "test/fallback-model" {
+		t.Errorf("fallback models = %v", fallback.recordedModels())
+	}
+
+	if err := f.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if !primary.isClosed() || !fallback.isClosed() {
+		t.Error("Close() should close every created client")
+	}
+}
+
+func TestFailoverGenerator_FiltersProviderOptions(t *testing.T) {
+	primary := &scriptedGenerator{err: fmt.Errorf("server error (503): unavailable")}
+	fallback := &scriptedGenerator{}
+	r := newTestRegistry(map[string]*scriptedGenerator{"test/primary": primary, "test/fallback": fallback})
+	r.providers["test/fallback"].Capabilities.Options = []ProviderOption{{Name: "steps", Type: OptionInt}}
+
+	f := newTestFailover(t, r, "test/primary", "test/fallback")
+	extra := map[string]any{"steps": 30, "cfgScale": 8.0}
+	if _, err := f.GenerateImage(context.Background(), "prompt", models.GenerateOptions{Extra: extra}); err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+
+	if len(primary.recordedExtras()[0]) != 2 {
+		t.Errorf("primary options = %v, want all options", primary.recordedExtras()[0])
+	}
+	if got := fallback.recordedExtras()[0]; len(got) != 1 || got["steps"] != 30 {
+		t.Errorf("fallback options = %v, want only steps", got)
+	}
+}
+
+func TestFailoverGenerator_SnapsSizeForFallback(t *testing.T) {
+	primary := &scriptedGenerator{err: fmt.Errorf("server error (503): unavailable")}
+	fallback := &scriptedGenerator{}
+	r := newTestRegistry(map[string]*scriptedGenerator{"test/primary": primary, "test/fallback": fallback})
+	r.providers["test/fallback"].Capabilities.Sizes = novaCanvasSizes
+
+	// The primary's native 16:9 size becomes the fallback's closest 16:9 size
+	f := newTestFailover(t, r, "test/primary", "test/fallback")
+	if _, err := f.GenerateImage(context.Background(), "prompt", models.GenerateOptions{Size: "1408x768", AspectRatio: "16:9"}); err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+	if primary.recordedSizes()[0] != "1408x768" {
+		t.Errorf("primary size = %s, want 1408x768", primary.recordedSizes()[0])
+	}
+	if fallback.recordedSizes()[0] != "1024x576" {
+		t.Errorf("fallback size = %s, want 1024x576", fallback.recordedSizes()[0])
+	}
+}
+
+func TestFailoverGenerator_StopsOnRequestError(t *testing.T) {
+	primary := &scriptedGenerator{err: fmt.Errorf("content blocked by safety filters")}
+	fallback := &scriptedGenerator{}
+	r := newTestRegistry(map[string]*scriptedGenerator{"test/primary": primary, "test/fallback": fallback})
+
+	f := newTestFailover(t, r, "test/primary", "test/fallback")
+	if _, err := f.GenerateImage(context.Background(), "prompt", models.GenerateOptions{}); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(fallback.recordedModels()) != 0 {
+		t.Error("fallback should not be tried for non-retryable errors")
+	}
+}
+
+func TestFailoverGenerator_AllFail(t *testing.T) {
+	r := newTestRegistry(map[string]*scriptedGenerator{
+		"test/a": {err: gobreaker.ErrOpenState},
+		"test/b": {err: fmt.Errorf("server error (503): unavailable")},
+	})
+
+	f := newTestFailover(t, r, "test/a", "test/b")
+	_, err := f.GenerateImage(context.Background(), "prompt", models.GenerateOptions{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "all providers failed") || !strings.Contains(err.Error(), "test/b") {
+		t.Errorf("error should summarize every provider, got: %v", err)
+	}
+}
+
+func TestFailoverGenerator_SkipsUnconfiguredProviders(t *testing.T) {
+	t.Setenv("TEST_FAILOVER_KEY", "")
+
+	fallback := &scriptedGenerator{}
+	r := newTestRegistry(map[string]*scriptedGenerator{
+		"test/primary":  {err: fmt.Errorf("rate limit exceeded (429)")},
+		"test/fallback": fallback,
+	})
+	r.Register(&Provider{
+		ID:              "test/unconfigured",
+		API:             "test",
+		RequiredEnvVars: []EnvVar{{Name: "TEST_FAILOVER_KEY", Required: true}},
+		CreateClient: func(creds map[string]string) (ImageGenerator, error) {
+			t.Fatal("unconfigured provider should not be created")
+			return nil, nil
+		},
+	})
+
+	f := newTestFailover(t, r, "test/primary", "test/unconfigured", "test/fallback")
+	image, err := f.GenerateImage(context.Background(), "prompt", models.GenerateOptions{})
+	if err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+	if image.Metadata["provider"] != "test/fallback" {
+		t.Errorf("provider = %q, want test/fallback", image.Metadata["provider"])
+	}
+}
+
+func TestFailoverGenerator_GenerateImages(t *testing.T) {
+	r := newTestRegistry(map[string]*scriptedGenerator{
+		"test/primary":  {err: fmt.Errorf("connection reset")},
+		"test/fallback": {},
+	})
+
+	f := newTestFailover(t, r, "test/primary", "test/fallback")
+	images, err := GenerateImages(context.Background(), f, "prompt", models.GenerateOptions{Count: 3})
+	if err != nil {
+		t.Fatalf("GenerateImages() error = %v", err)
+	}
+	if len(images) != 3 {
+		t.Fatalf("got %d images, want 3", len(images))
+	}
+	for _, image := range images {
+		if image.Metadata["provider"] != "test/fallback" {
+			t.Errorf("provider = %q, want test/fallback", image.Metadata["provider"])
+		}
+	}
+}
+
+func TestFailoverChain(t *testing.T) {
+	r := newTestRegistry(map[string]*scriptedGenerator{"test/a": {}, "test/b": {}})
+	a, _ := r.Get("test/a")
+
+	chain, err := r.FailoverChain(a, []string{"test/a", "test/b", "test/b"})
+	if err != nil {
+		t.Fatalf("FailoverChain() error = %v", err)
+	}
+	if len(chain) != 2 || chain[0].ID != "test/a" || chain[1].ID != "test/b" {
+		t.Errorf("chain = %v, want [test/a test/b]", chain)
+	}
+
+	if _, err := r.FailoverChain(a, []string{"test/missing"}); err == nil {
+		t.Error("expected error for unknown fallback provider")
+	}
+}
+
+func TestResolveClient_Failover(t *testing.T) {
+	r := newTestRegistry(map[string]*scriptedGenerator{"test/a": {}, "test/b": {}})
+
+	client, p, err := r.ResolveClient("test/a", &config.Config{FallbackProviders: []string{"test/b"}})
+	if err != nil {
+		t.Fatalf("ResolveClient() error = %v", err)
+	}
+	if p.ID != "test/a" {
+		t.Errorf("provider = %s, want test/a", p.ID)
+	}
+	if _, ok := client.(*FailoverGenerator); !ok {
+		t.Errorf("client = %T, want *FailoverGenerator", client)
+	}
+
+	client, _, err = r.ResolveClient("test/a", &config.Config{})
+	if err != nil {
+		t.Fatalf("ResolveClient() error = %v", err)
+	}
+	if _, ok := client.(*FailoverGenerator); ok {
+		t.Error("client should not be wrapped without fallback providers")
+	}
+}
+
+func TestIsFailoverError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{gobreaker.ErrOpenState, true},
+		{fmt.Errorf("API circuit breaker is open (too many failures): %w", gobreaker.ErrOpenState), true},
+		{fmt.Errorf("rate limit exceeded (429)"), true},
+		{fmt.Errorf("API error 429: Resource has been exhausted (e.g. check quota)."), true},
+		{fmt.Errorf("ThrottlingException: Too many requests"), true},
+		{fmt.Errorf("server error (503): the API is temporarily unavailable"), true},
+		{fmt.Errorf("authentication failed (401): invalid API key"), false},
+		{errors.New("content blocked by safety filters"), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsFailoverError(tt.err); got != tt.want {
+			t.Errorf("IsFailoverError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -74,13 +74,68 @@ func (c *GeminiRESTClient) GenerateImage(ctx context.Context, prompt string, opt
 	}
 
 	// Generate image with circuit breaker and retry logic
+	return c.executeWithRetry(ctx, func() (*models.GeneratedImage, error) {
+		return c.generateWithRetry(ctx, modelName, enhancedPrompt, nil, options)
+	})
+}
+
+// EditImage edits an existing image from a text instruction using Gemini REST API.
+// The source image is sent as an inline part alongside the instruction.
+func (c *GeminiRESTClient) EditImage(ctx context.Context, prompt string, edit models.EditOptions, options models.GenerateOptions) (*models.GeneratedImage, error) {
+	if err := ValidatePrompt(prompt); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateEditOptions(&edit); err != nil {
+		return nil, err
+	}
+
+	// Gemini edits conversationally - it cannot take a mask image, but a mask
+	// prompt can be folded into the instruction
+	if len(edit.Mask) > 0 {
+		return nil, fmt.Errorf("Gemini does not support mask images; use a mask prompt or bedrock/nova-canvas")
+	}
+
+	instruction := normalizeEditPrompt(prompt)
+	if edit.MaskPrompt != "" {
+		instruction = fmt.Sprintf("%s. Only change the %s and keep everything else exactly the same", instruction, edit.MaskPrompt)
+	}
+
+	modelName := c.model
+	if options.Model != "" {
+		modelName = options.Model
+	}
+
+	input := &geminiInlineData{
+		MimeType: edit.MimeType,
+		Data:     base64.StdEncoding.EncodeToString(edit.Image),
+	}
+
+	result, err := c.executeWithRetry(ctx, func() (*models.GeneratedImage, error) {
+		return c.generateWithRetry(ctx, modelName, instruction, input, options)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Edits keep the source dimensions rather than the requested size
+	result.Width, result.Height = imageDimensions(result.Data, options.Size)
+	result.Metadata["mode"] = "edit"
+	result.Metadata["edit_mode"] = edit.Mode
+
+	return result, nil
+}
+
+// executeWithRetry runs a generation attempt through the circuit breaker,
+// retrying retryable errors with exponential backoff
+func (c *GeminiRESTClient) executeWithRetry(ctx context.Context, attemptFn func() (*models.GeneratedImage, error)) (*models.GeneratedImage, error) {
 	var lastErr error
 	backoff := retryBackoffInitial
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 		// Execute through circuit breaker
 		result, err := c.circuitBreaker.Execute(func() (interface{}, error) {
-			return c.generateWithRetry(ctx, modelName, enhancedPrompt, options)
+			return attemptFn()
 		})
 
 		if err == nil {
@@ -118,8 +173,9 @@ func (c *GeminiRESTClient) GenerateImage(ctx context.Context, prompt string, opt
 	return nil, fmt.Errorf("failed after %d attempts: %w", maxRetries, lastErr)
 }
 
-// generateWithRetry performs a single generation attempt using REST API
-func (c *GeminiRESTClient) generateWithRetry(ctx context.Context, modelName, prompt string, options models.GenerateOptions) (*models.GeneratedImage, error) {
+// generateWithRetry performs a single generation attempt using REST API.
+// If input is non-nil it is sent as an inline image part before the prompt (image editing).
+func (c *GeminiRESTClient) generateWithRetry(ctx context.Context, modelName, prompt string, input *geminiInlineData, options models.GenerateOptions) (*models.GeneratedImage, error) {
 	// Build the prompt with options
 	fullPrompt := buildPromptWithOptions(prompt, options)
 
@@ -140,14 +196,17 @@ func (c *GeminiRESTClient) generateWithRetry(ctx context.Context, modelName, pro
 	//     "responseModalities": ["IMAGE"]
 	//   }
 	// }
+	parts := []geminiPart{}
+	if input != nil {
+		c.logVerbose("Including input image: %s", input.MimeType)
+		parts = append(parts, geminiPart{InlineData: input})
+	}
+	parts = append(parts, geminiPart{Text: fullPrompt})
+
 	request := geminiGenerateContentRequest{
 		Contents: []geminiContent{
 			{
-				Parts: []geminiPart{
-					{
-						Text: fullPrompt,
-					},
-				},
+				Parts: parts,
 			},
 		},
 		GenerationConfig: &geminiGenerationConfig{
@@ -155,13 +214,23 @@ func (c *GeminiRESTClient) generateWithRetry(ctx context.Context, modelName, pro
 		},
 	}
 
+	// Edits keep the source image's shape; new images use the requested ratio
+	if input == nil && options.AspectRatio != "" {
+		request.GenerationConfig.ImageConfig = &geminiImageConfig{AspectRatio: options.AspectRatio}
+	}
+
 	// Marshal request to JSON
 	requestBody, err := json.Marshal(request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	c.logVerbose("Request body: %s", string(requestBody))
+	// Log request (truncated - edit requests embed the source image)
+	if len(requestBody) > 500 {
+		c.logVerbose("Request body (first 500 chars): %s...", string(requestBody[:500]))
+	} else {
+		c.logVerbose("Request body: %s", string(requestBody))
+	}
 
 	// Build API URL - use generateContent endpoint
 	apiURL := fmt.Sprintf("%s/%s:generateContent?key=%s", geminiAPIEndpoint, modelName, c.apiKey)
@@ -267,6 +336,9 @@ func (c *GeminiRESTClient) generateWithRetry(ctx context.Context, modelName, pro
 
 	c.logVerbose("Successfully generated image: %d bytes, format=%s", len(imageData), format)
 
+	// Report the size the model actually produced
+	width, height = imageDimensions(imageData, options.Size)
+
 	return &models.GeneratedImage{
 		Data:   imageData,
 		Format: format,
@@ -339,10 +411,16 @@ type geminiInlineData struct {
 }
 
 type geminiGenerationConfig struct {
-	ResponseModalities []string `json:"responseModalities,omitempty"`
-	Temperature        *float64 `json:"temperature,omitempty"`
-	TopP               *float64 `json:"topP,omitempty"`
-	TopK               *int     `json:"topK,omitempty"`
+	ResponseModalities []string           `json:"responseModalities,omitempty"`
+	Temperature        *float64           `json:"temperature,omitempty"`
+	TopP               *float64           `json:"topP,omitempty"`
+	TopK               *int               `json:"topK,omitempty"`
+	ImageConfig        *geminiImageConfig `json:"imageConfig,omitempty"`
+}
+
+// geminiImageConfig selects the output aspect ratio (e.g. "16:9")
+type geminiImageConfig struct {
+	AspectRatio string `json:"aspectRatio,omitempty"`
 }
 
 type geminiGenerateContentResponse struct {
@@ -0,0 +1,331 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"regexp"
+	"strings"
+
+	"github.com/apresai/gimage/pkg/models"
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Mock provider constants
+const (
+	// MockErrorEnvVar makes every mock request fail with the given simulated error
+	MockErrorEnvVar = "GIMAGE_MOCK_ERROR"
+
+	MockErrorQuota   = "quota"
+	MockErrorSafety  = "safety"
+	MockErrorTimeout = "timeout"
+
+	// mockMaxDimension caps placeholder size to keep tests fast
+	mockMaxDimension = 4096
+)
+
+// mockErrorTag matches a per-request error directive in the prompt, e.g. "[mock:quota]"
+var mockErrorTag = regexp.MustCompile(`\[mock:([a-z]+)\]`)
+
+// MockClient renders deterministic placeholder images locally, without network
+// access. Output depends only on prompt and options, so identical requests
+// produce identical bytes. Errors can be simulated with GIMAGE_MOCK_ERROR or a
+// "[mock:quota]" / "[mock:safety]" / "[mock:timeout]" tag in the prompt.
+type MockClient struct {
+	capabilities ModelCapabilities
+	failWith     string
+}
+
+// NewMockClient creates a mock client that enforces the given capabilities.
+// failWith, if set, is the simulated error returned by every request.
+func NewMockClient(capabilities ModelCapabilities, failWith string) (*MockClient, error) {
+	failWith = strings.ToLower(strings.TrimSpace(failWith))
+	if err := validateMockError(failWith); err != nil {
+		return nil, err
+	}
+	return &MockClient{capabilities: capabilities, failWith: failWith}, nil
+}
+
+// GenerateImage renders a single placeholder image
+func (c *MockClient) GenerateImage(ctx context.Context, prompt string, options models.GenerateOptions) (*models.GeneratedImage, error) {
+	images, err := c.generate(ctx, prompt, options, 1)
+	if err != nil {
+		return nil, err
+	}
+	return images[0], nil
+}
+
+// GenerateImages renders options.Count placeholders, batched like a real
+// provider according to MaxImagesPerRequest
+func (c *MockClient) GenerateImages(ctx context.Context, prompt string, options models.GenerateOptions) ([]*models.GeneratedImage, error) {
+	var images []*models.GeneratedImage
+	return generateInBatches(options.Count, c.capabilities.MaxImagesPerRequest, func(n int) ([]*models.GeneratedImage, error) {
+		batch := options
+		batch.Seed = options.Seed + int64(len(images)) // Vary candidates across batches
+		result, err := c.generate(ctx, prompt, batch, n)
+		images = append(images, result...)
+		return result, err
+	})
+}
+
+// EditImage renders a placeholder at the source image's dimensions
+func (c *MockClient) EditImage(ctx context.Context, prompt string, edit models.EditOptions, options models.GenerateOptions) (*models.GeneratedImage, error) {
+	if !c.capabilities.SupportsEditing {
+		return nil, fmt.Errorf("mock provider does not support image editing")
+	}
+	if err := ValidateEditOptions(&edit); err != nil {
+		return nil, err
+	}
+
+	width, height := imageDimensions(edit.Image, options.Size)
+	options.Size = fmt.Sprintf("%dx%d", width, height)
+
+	images, err := c.generate(ctx, fmt.Sprintf("%s (%s edit)", prompt, edit.Mode), options, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	images[0].Metadata["mode"] = "edit"
+	images[0].Metadata["edit_mode"] = edit.Mode
+	return images[0], nil
+}
+
+// Close releases resources (none for the mock client)
+func (c *MockClient) Close() error {
+	return nil
+}
+
+// generate validates the request and renders count placeholders
+func (c *MockClient) generate(ctx context.Context, prompt string, options models.GenerateOptions, count int) ([]*models.GeneratedImage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Simulated failures: prompt tag wins over the client-wide setting
+	failWith := c.failWith
+	if m := mockErrorTag.FindStringSubmatch(prompt); m != nil {
+		failWith = m[1]
+		prompt = strings.TrimSpace(mockErrorTag.ReplaceAllString(prompt, ""))
+	}
+	if err := simulatedMockError(failWith); err != nil {
+		return nil, err
+	}
+
+	if err := c.validate(prompt, options, count); err != nil {
+		return nil, err
+	}
+
+	width, height := parseDimensions(options.Size)
+
+	images := make([]*models.GeneratedImage, 0, count)
+	for i := 0; i < count; i++ {
+		hash := mockHash(prompt, options, width, height, i)
+
+		data, err := renderMockImage(prompt, hash, width, height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render mock image: %w", err)
+		}
+
+		images = append(images, &models.GeneratedImage{
+			Data:   data,
+			Format: "png",
+			Width:  width,
+			Height: height,
+			Metadata: map[string]string{
+				"model":  "mock",
+				"prompt": prompt,
+				"size":   fmt.Sprintf("%dx%d", width, height),
+				"seed":   fmt.Sprintf("%d", options.Seed),
+				"hash":   hash,
+				"api":    "local",
+			},
+		})
+	}
+
+	return images, nil
+}
+
+// validate rejects options the configured capabilities don't support
+func (c *MockClient) validate(prompt string, options models.GenerateOptions, count int) error {
+	caps := c.capabilities
+
+	if strings.TrimSpace(prompt) == "" {
+		return fmt.Errorf("prompt cannot be empty")
+	}
+	if caps.MaxPromptLength > 0 && len(prompt) > caps.MaxPromptLength {
+		return fmt.Errorf("prompt too long: %d characters (max %d)", len(prompt), caps.MaxPromptLength)
+	}
+	if options.Style != "" && !caps.SupportsStyles {
+		return fmt.Errorf("mock provider does not support styles")
+	}
+	if options.NegativePrompt != "" && !caps.SupportsNegativePrompt {
+		return fmt.Errorf("mock provider does not support negative prompts")
+	}
+	if options.Seed != 0 && !caps.SupportsSeed {
+		return fmt.Errorf("mock provider does not support seeds")
+	}
+	if caps.MaxImagesPerRequest > 0 && count > caps.MaxImagesPerRequest {
+		return fmt.Errorf("too many images per request: %d (max %d)", count, caps.MaxImagesPerRequest)
+	}
+
+	width, height := parseDimensions(options.Size)
+	if width > mockMaxDimension || height > mockMaxDimension {
+		return fmt.Errorf("invalid size %dx%d: max %dx%d", width, height, mockMaxDimension, mockMaxDimension)
+	}
+
+	return nil
+}
+
+// validateMockError checks a simulated error name
+func validateMockError(name string) error {
+	switch name {
+	case "", MockErrorQuota, MockErrorSafety, MockErrorTimeout:
+		return nil
+	default:
+		return fmt.Errorf("invalid %s value: %s (must be %s, %s or %s)", MockErrorEnvVar, name, MockErrorQuota, MockErrorSafety, MockErrorTimeout)
+	}
+}
+
+// simulatedMockError returns the error a real provider would return for name.
+// Messages match real provider errors so retry and failover logic treat them the same.
+func simulatedMockError(name string) error {
+	switch name {
+	case "":
+		return nil
+	case MockErrorQuota:
+		return fmt.Errorf("API error 429: quota exceeded (simulated by mock provider)")
+	case MockErrorSafety:
+		return fmt.Errorf("content blocked by safety filters (simulated by mock provider)")
+	case MockErrorTimeout:
+		return fmt.Errorf("request timeout (simulated by mock provider): %w", context.DeadlineExceeded)
+	default:
+		return validateMockError(name)
+	}
+}
+
+// mockHash returns a short stable hash of everything that affects the output
+func mockHash(prompt string, options models.GenerateOptions, width, height, index int) string {
+	key := fmt.Sprintf("%s|%dx%d|%d|%s|%s|%d", prompt, width, height, options.Seed, options.Style, options.NegativePrompt, index)
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// renderMockImage draws a diagonal gradient seeded by hash with the prompt and hash as text
+func renderMockImage(prompt, hash string, width, height int) ([]byte, error) {
+	seed, _ := hex.DecodeString(hash)
+	from := color.RGBA{seed[0], seed[1], seed[2], 255}
+	to := color.RGBA{seed[3], seed[4], seed[5], 255}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	span := width + height - 2
+	if span < 1 {
+		span = 1
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			t := float64(x+y) / float64(span)
+			img.SetRGBA(x, y, color.RGBA{
+				R: lerp(from.R, to.R, t),
+				G: lerp(from.G, to.G, t),
+				B: lerp(from.B, to.B, t),
+				A: 255,
+			})
+		}
+	}
+
+	drawMockLabel(img, append(wrapText(prompt, 40, 6), "", "mock "+hash))
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawMockLabel renders lines with the built-in bitmap font on a dark panel,
+// scaled up so the text stays readable on large images
+func drawMockLabel(dst *image.RGBA, lines []string) {
+	face := basicfont.Face7x13
+	lineHeight := face.Metrics().Height.Ceil()
+
+	maxLen := 0
+	for _, line := range lines {
+		if len(line) > maxLen {
+			maxLen = len(line)
+		}
+	}
+	if maxLen == 0 {
+		return
+	}
+
+	const padding = 4
+	label := image.NewRGBA(image.Rect(0, 0, maxLen*face.Advance+2*padding, len(lines)*lineHeight+2*padding))
+	draw.Draw(label, label.Bounds(), &image.Uniform{color.RGBA{0, 0, 0, 160}}, image.Point{}, draw.Src)
+
+	drawer := &font.Drawer{Dst: label, Src: image.White, Face: face}
+	for i, line := range lines {
+		drawer.Dot = fixed.P(padding, padding+(i+1)*lineHeight-face.Descent)
+		drawer.DrawString(line)
+	}
+
+	// Scale the label to at most 80% of the image width
+	scale := float64(dst.Bounds().Dx()) * 0.8 / float64(label.Bounds().Dx())
+	if scale > 4 {
+		scale = 4
+	}
+	scaledW := int(float64(label.Bounds().Dx()) * scale)
+	scaledH := int(float64(label.Bounds().Dy()) * scale)
+	if scaledW < 1 || scaledH < 1 || scaledH > dst.Bounds().Dy() {
+		return
+	}
+	scaled := imaging.Resize(label, scaledW, scaledH, imaging.NearestNeighbor)
+
+	offset := image.Pt((dst.Bounds().Dx()-scaledW)/2, (dst.Bounds().Dy()-scaledH)/2)
+	draw.Draw(dst, scaled.Bounds().Add(offset), scaled, image.Point{}, draw.Over)
+}
+
+// wrapText splits text into at most maxLines lines of up to width characters
+func wrapText(text string, width, maxLines int) []string {
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(text) {
+		if len(word) > width {
+			word = word[:width]
+		}
+		if current == "" {
+			current = word
+		} else if len(current)+1+len(word) <= width {
+			current += " " + word
+		} else {
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+		last := lines[maxLines-1]
+		if len(last) > width-3 {
+			last = last[:width-3]
+		}
+		lines[maxLines-1] = last + "..."
+	}
+	return lines
+}
+
+// lerp interpolates between two color channels
+func lerp(a, b uint8, t float64) uint8 {
+	return uint8(float64(a) + (float64(b)-float64(a))*t)
+}
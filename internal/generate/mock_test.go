@@ -0,0 +1,188 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/apresai/gimage/internal/config"
+	"github.com/apresai/gimage/pkg/models"
+)
+
+var allMockCapabilities = ModelCapabilities{
+	SupportsStyles:         true,
+	SupportsNegativePrompt: true,
+	SupportsSeed:           true,
+	SupportsEditing:        true,
+	MaxImagesPerRequest:    4,
+	MaxPromptLength:        100,
+}
+
+func newTestMockClient(t *testing.T, caps ModelCapabilities, failWith string) *MockClient {
+	t.Helper()
+	client, err := NewMockClient(caps, failWith)
+	if err != nil {
+		t.Fatalf("NewMockClient() error = %v", err)
+	}
+	return client
+}
+
+func TestMockClient_Deterministic(t *testing.T) {
+	client := newTestMockClient(t, allMockCapabilities, "")
+	ctx := context.Background()
+	options := models.GenerateOptions{Size: "320x200", Seed: 42}
+
+	first, err := client.GenerateImage(ctx, "a red fox", options)
+	if err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+	second, err := client.GenerateImage(ctx, "a red fox", options)
+	if err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+	if !bytes.Equal(first.Data, second.Data) {
+		t.Error("identical requests should produce identical images")
+	}
+
+	options.Seed = 43
+	third, err := client.GenerateImage(ctx, "a red fox", options)
+	if err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+	if bytes.Equal(first.Data, third.Data) || first.Metadata["hash"] == third.Metadata["hash"] {
+		t.Error("a different seed should produce a different image")
+	}
+
+	img, err := png.Decode(bytes.NewReader(first.Data))
+	if err != nil {
+		t.Fatalf("mock output is not a valid PNG: %v", err)
+	}
+	if img.Bounds().Dx() != 320 || img.Bounds().Dy() != 200 {
+		t.Errorf("image size = %v, want 320x200", img.Bounds().Size())
+	}
+	if first.Width != 320 || first.Height != 200 {
+		t.Errorf("reported size = %dx%d, want 320x200", first.Width, first.Height)
+	}
+}
+
+func TestMockClient_Capabilities(t *testing.T) {
+	tests := []struct {
+		name    string
+		caps    ModelCapabilities
+		prompt  string
+		options models.GenerateOptions
+		errMsg  string
+	}{
+		{"style unsupported", ModelCapabilities{}, "cat", models.GenerateOptions{Style: "anime"}, "styles"},
+		{"negative unsupported", ModelCapabilities{}, "cat", models.GenerateOptions{NegativePrompt: "dogs"}, "negative prompts"},
+		{"seed unsupported", ModelCapabilities{}, "cat", models.GenerateOptions{Seed: 1}, "seeds"},
+		{"prompt too long", allMockCapabilities, strings.Repeat("a", 101), models.GenerateOptions{}, "prompt too long"},
+		{"size too large", allMockCapabilities, "cat", models.GenerateOptions{Size: "8192x8192"}, "invalid size"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestMockClient(t, tt.caps, "")
+			_, err := client.GenerateImage(context.Background(), tt.prompt, tt.options)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("GenerateImage() error = %v, want error containing %q", err, tt.errMsg)
+			}
+		})
+	}
+
+	client := newTestMockClient(t, ModelCapabilities{}, "")
+	edit := models.EditOptions{Image: encodeTestImage(t, "png")}
+	if _, err := client.EditImage(context.Background(), "cat", edit, models.GenerateOptions{}); err == nil {
+		t.Error("EditImage() should fail when editing is unsupported")
+	}
+}
+
+func TestMockClient_SimulatedErrors(t *testing.T) {
+	ctx := context.Background()
+
+	quota := newTestMockClient(t, allMockCapabilities, MockErrorQuota)
+	_, err := quota.GenerateImage(ctx, "cat", models.GenerateOptions{})
+	if err == nil || !IsFailoverError(err) {
+		t.Errorf("quota error should trigger failover, got %v", err)
+	}
+
+	client := newTestMockClient(t, allMockCapabilities, "")
+
+	_, err = client.GenerateImage(ctx, "cat [mock:safety]", models.GenerateOptions{})
+	if err == nil || !strings.Contains(err.Error(), "safety") || IsFailoverError(err) {
+		t.Errorf("safety error should not trigger failover, got %v", err)
+	}
+
+	_, err = client.GenerateImage(ctx, "[mock:timeout] cat", models.GenerateOptions{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("timeout error should wrap context.DeadlineExceeded, got %v", err)
+	}
+
+	if _, err := NewMockClient(allMockCapabilities, "explode"); err == nil {
+		t.Error("NewMockClient() should reject unknown simulated errors")
+	}
+}
+
+func TestMockClient_GenerateImagesAndEdit(t *testing.T) {
+	client := newTestMockClient(t, allMockCapabilities, "")
+	ctx := context.Background()
+
+	images, err := GenerateImages(ctx, client, "robots", models.GenerateOptions{Size: "64x64", Count: 6})
+	if err != nil {
+		t.Fatalf("GenerateImages() error = %v", err)
+	}
+	if len(images) != 6 {
+		t.Fatalf("got %d images, want 6", len(images))
+	}
+	seen := map[string]bool{}
+	for _, image := range images {
+		seen[image.Metadata["hash"]] = true
+	}
+	if len(seen) != 6 {
+		t.Errorf("candidates should differ, got %d unique hashes", len(seen))
+	}
+
+	edit := models.EditOptions{Image: encodeTestImage(t, "png")}
+	edited, err := client.EditImage(ctx, "make it blue", edit, models.GenerateOptions{})
+	if err != nil {
+		t.Fatalf("EditImage() error = %v", err)
+	}
+	if edited.Width != 32 || edited.Height != 24 {
+		t.Errorf("edited size = %dx%d, want source size 32x24", edited.Width, edited.Height)
+	}
+}
+
+func TestMockProvider_Registry(t *testing.T) {
+	clearCredentialEnv(t)
+	t.Setenv(MockErrorEnvVar, "")
+	registry := NewProviderRegistry()
+
+	client, p, err := registry.ResolveClient("mock", &config.Config{})
+	if err != nil {
+		t.Fatalf("ResolveClient() error = %v", err)
+	}
+	defer client.Close()
+	if p.ID != "local/mock" {
+		t.Errorf("provider = %s, want local/mock", p.ID)
+	}
+	if _, ok := client.(ImageEditor); !ok {
+		t.Error("mock client should implement ImageEditor")
+	}
+
+	// The mock is never picked automatically
+	if _, err := registry.SelectProvider(AutoProvider, &config.Config{}); err == nil {
+		t.Error("auto selection should not fall back to the mock provider")
+	}
+
+	t.Setenv(MockErrorEnvVar, "quota")
+	client, _, err = registry.ResolveClient("local/mock", &config.Config{})
+	if err != nil {
+		t.Fatalf("ResolveClient() error = %v", err)
+	}
+	if _, err := client.GenerateImage(context.Background(), "cat", models.GenerateOptions{}); err == nil {
+		t.Errorf("%s=quota should make requests fail", MockErrorEnvVar)
+	}
+}
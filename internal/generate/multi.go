@@ -0,0 +1,175 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/apresai/gimage/pkg/models"
+)
+
+// MaxImageCount is the largest number of images a single generate request may ask for
+const MaxImageCount = 10
+
+// MultiImageGenerator is implemented by clients that can return several candidates
+// from one API call (Imagen sampleCount, Nova Canvas numberOfImages).
+type MultiImageGenerator interface {
+	GenerateImages(ctx context.Context, prompt string, options models.GenerateOptions) ([]*models.GeneratedImage, error)
+}
+
+// ValidateImageCount checks that count is within 0-MaxImageCount (0 means 1)
+func ValidateImageCount(count int) error {
+	if count < 0 || count > MaxImageCount {
+		return fmt.Errorf("invalid count: %d (must be 1-%d)", count, MaxImageCount)
+	}
+	return nil
+}
+
+// GenerateImages generates options.Count images with the given client.
+// Clients implementing MultiImageGenerator get one batched call; all others
+// are called in parallel, once per image. Any failure fails the whole request.
+func GenerateImages(ctx context.Context, client ImageGenerator, prompt string, options models.GenerateOptions) ([]*models.GeneratedImage, error) {
+	if err := ValidateImageCount(options.Count); err != nil {
+		return nil, err
+	}
+
+	count := options.Count
+	if count == 0 {
+		count = 1
+	}
+
+	var images []*models.GeneratedImage
+
+	if multi, ok := client.(MultiImageGenerator); ok && count > 1 {
+		var err error
+		images, err = multi.GenerateImages(ctx, prompt, options)
+		if err != nil {
+			return nil, err
+		}
+	} else if count == 1 {
+		image, err := client.GenerateImage(ctx, prompt, options)
+		if err != nil {
+			return nil, err
+		}
+		images = []*models.GeneratedImage{image}
+	} else {
+		var err error
+		images, err = generateInParallel(ctx, client, prompt, options, count)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Number images so each metadata sidecar identifies its candidate
+	for i, image := range images {
+		if image.Metadata == nil {
+			image.Metadata = map[string]string{}
+		}
+		image.Metadata["index"] = fmt.Sprintf("%d", i+1)
+		image.Metadata["count"] = fmt.Sprintf("%d", len(images))
+	}
+
+	return images, nil
+}
+
+// generateInParallel calls GenerateImage count times concurrently
+func generateInParallel(ctx context.Context, client ImageGenerator, prompt string, options models.GenerateOptions, count int) ([]*models.GeneratedImage, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	single := options
+	single.Count = 1
+
+	images := make([]*models.GeneratedImage, count)
+	errs := make([]error, count)
+
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			images[i], errs[i] = client.GenerateImage(ctx, prompt, single)
+			if errs[i] != nil {
+				cancel() // Stop the remaining calls early
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("image %d of %d failed: %w", i+1, count, err)
+		}
+	}
+
+	return images, nil
+}
+
+// generateInBatches splits count into API calls of at most maxPerRequest images
+// and concatenates the results. Used by MultiImageGenerator implementations.
+func generateInBatches(count, maxPerRequest int, generateBatch func(n int) ([]*models.GeneratedImage, error)) ([]*models.GeneratedImage, error) {
+	if count <= 0 {
+		count = 1
+	}
+	if maxPerRequest <= 0 {
+		maxPerRequest = 1
+	}
+
+	images := make([]*models.GeneratedImage, 0, count)
+	for remaining := count; remaining > 0; {
+		n := remaining
+		if n > maxPerRequest {
+			n = maxPerRequest
+		}
+
+		batch, err := generateBatch(n)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) == 0 {
+			return nil, fmt.Errorf("no images generated")
+		}
+
+		images = append(images, batch...)
+		remaining -= len(batch)
+	}
+
+	return images, nil
+}
+
+// NumberedOutputPath returns outputPath with _N inserted before the extension
+// (e.g., sunset.png -> sunset_2.png) for multi-image outputs
+func NumberedOutputPath(outputPath string, index int) string {
+	ext := filepath.Ext(outputPath)
+	base := strings.TrimSuffix(outputPath, ext)
+	return fmt.Sprintf("%s_%d%s", base, index, ext)
+}
+
+// SaveImages saves generated images to outputPath. A single image is saved
+// as-is; multiple images are saved as name_1.ext ... name_N.ext, each with its
+// own metadata sidecar. Returns the paths written.
+func SaveImages(images []*models.GeneratedImage, outputPath string) ([]string, error) {
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no images to save")
+	}
+
+	if len(images) == 1 {
+		if err := SaveImage(images[0], outputPath); err != nil {
+			return nil, err
+		}
+		return []string{outputPath}, nil
+	}
+
+	paths := make([]string, 0, len(images))
+	for i, image := range images {
+		path := NumberedOutputPath(outputPath, i+1)
+		if err := SaveImageWithMetadata(image, path); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
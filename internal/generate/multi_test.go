@@ -0,0 +1,164 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/apresai/gimage/pkg/models"
+)
+
+// fakeGenerator returns a tiny PNG per call and counts calls
+type fakeGenerator struct {
+	calls   int32
+	failOn  int32 // 1-based call number that fails (0 = never)
+	imgData []byte
+}
+
+func (f *fakeGenerator) GenerateImage(ctx context.Context, prompt string, options models.GenerateOptions) (*models.GeneratedImage, error) {
+	call := atomic.AddInt32(&f.calls, 1)
+	if f.failOn != 0 && call == f.failOn {
+		return nil, fmt.Errorf("simulated failure")
+	}
+	return &models.GeneratedImage{Data: f.imgData, Format: "png", Metadata: map[string]string{"prompt": prompt}}, nil
+}
+
+func (f *fakeGenerator) Close() error { return nil }
+
+// fakeMultiGenerator records the counts it was asked for
+type fakeMultiGenerator struct {
+	fakeGenerator
+	requested []int
+}
+
+func (f *fakeMultiGenerator) GenerateImages(ctx context.Context, prompt string, options models.GenerateOptions) ([]*models.GeneratedImage, error) {
+	return generateInBatches(options.Count, 4, func(n int) ([]*models.GeneratedImage, error) {
+		f.requested = append(f.requested, n)
+		images := make([]*models.GeneratedImage, n)
+		for i := range images {
+			images[i] = &models.GeneratedImage{Data: f.imgData, Format: "png"}
+		}
+		return images, nil
+	})
+}
+
+func TestGenerateImages(t *testing.T) {
+	pngData := encodeTestImage(t, "png")
+
+	t.Run("single image", func(t *testing.T) {
+		client := &fakeGenerator{imgData: pngData}
+		images, err := GenerateImages(context.Background(), client, "test", models.GenerateOptions{})
+		if err != nil {
+			t.Fatalf("GenerateImages() error = %v", err)
+		}
+		if len(images) != 1 || client.calls != 1 {
+			t.Errorf("got %d images from %d calls, want 1 from 1", len(images), client.calls)
+		}
+	})
+
+	t.Run("parallel fallback", func(t *testing.T) {
+		client := &fakeGenerator{imgData: pngData}
+		images, err := GenerateImages(context.Background(), client, "test", models.GenerateOptions{Count: 3})
+		if err != nil {
+			t.Fatalf("GenerateImages() error = %v", err)
+		}
+		if len(images) != 3 || client.calls != 3 {
+			t.Errorf("got %d images from %d calls, want 3 from 3", len(images), client.calls)
+		}
+		for i, image := range images {
+			if image.Metadata["index"] != fmt.Sprintf("%d", i+1) || image.Metadata["count"] != "3" {
+				t.Errorf("image %d metadata = %v", i, image.Metadata)
+			}
+		}
+	})
+
+	t.Run("parallel failure fails request", func(t *testing.T) {
+		client := &fakeGenerator{imgData: pngData, failOn: 2}
+		if _, err := GenerateImages(context.Background(), client, "test", models.GenerateOptions{Count: 3}); err == nil {
+			t.Error("expected error when one image fails")
+		}
+	})
+
+	t.Run("native batching", func(t *testing.T) {
+		client := &fakeMultiGenerator{fakeGenerator: fakeGenerator{imgData: pngData}}
+		images, err := GenerateImages(context.Background(), client, "test", models.GenerateOptions{Count: 6})
+		if err != nil {
+			t.Fatalf("GenerateImages() error = %v", err)
+		}
+		if len(images) != 6 {
+			t.Errorf("got %d images, want 6", len(images))
+		}
+		if len(client.requested) != 2 || client.requested[0] != 4 || client.requested[1] != 2 {
+			t.Errorf("batches = %v, want [4 2]", client.requested)
+		}
+		if client.calls != 0 {
+			t.Errorf("GenerateImage should not be called for batched clients, got %d calls", client.calls)
+		}
+	})
+
+	t.Run("invalid count", func(t *testing.T) {
+		client := &fakeGenerator{imgData: pngData}
+		if _, err := GenerateImages(context.Background(), client, "test", models.GenerateOptions{Count: MaxImageCount + 1}); err == nil {
+			t.Error("expected error for count above maximum")
+		}
+	})
+}
+
+func TestNumberedOutputPath(t *testing.T) {
+	tests := []struct {
+		path  string
+		index int
+		want  string
+	}{
+		{"sunset.png", 1, "sunset_1.png"},
+		{"out/logo.jpg", 4, "out/logo_4.jpg"},
+		{"noext", 2, "noext_2"},
+	}
+
+	for _, tt := range tests {
+		if got := NumberedOutputPath(tt.path, tt.index); got != tt.want {
+			t.Errorf("NumberedOutputPath(%q, %d) = %q, want %q", tt.path, tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestSaveImages(t *testing.T) {
+	pngData := encodeTestImage(t, "png")
+	tmpDir := t.TempDir()
+
+	images := []*models.GeneratedImage{
+		{Data: pngData, Format: "png", Metadata: map[string]string{"index": "1"}},
+		{Data: pngData, Format: "png", Metadata: map[string]string{"index": "2"}},
+	}
+
+	output := filepath.Join(tmpDir, "candidate.png")
+	paths, err := SaveImages(images, output)
+	if err != nil {
+		t.Fatalf("SaveImages() error = %v", err)
+	}
+
+	if len(paths) != 2 {
+		t.Fatalf("SaveImages() returned %d paths, want 2", len(paths))
+	}
+
+	for i, path := range paths {
+		want := filepath.Join(tmpDir, fmt.Sprintf("candidate_%d.png", i+1))
+		if path != want {
+			t.Errorf("path %d = %q, want %q", i, path, want)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("image not written: %v", err)
+		}
+		if _, err := os.Stat(path + ".json"); err != nil {
+			t.Errorf("metadata sidecar not written: %v", err)
+		}
+	}
+
+	// The unnumbered path is only used for single images
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("unnumbered output should not be written for multiple images")
+	}
+}
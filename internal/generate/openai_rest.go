@@ -0,0 +1,390 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/apresai/gimage/pkg/models"
+	"github.com/sony/gobreaker"
+	"github.com/spf13/viper"
+)
+
+// OpenAI-compatible images API defaults
+const (
+	OpenAIDefaultBaseURL = "https://api.openai.com/v1"
+	OpenAIDefaultModel   = "gpt-image-1"
+
+	// openAIMaxImages is the largest n accepted by /images/generations (dall-e-3 accepts only 1)
+	openAIMaxImages = 10
+
+	// azureOpenAIAPIVersion is sent to Azure OpenAI when the base URL has no api-version
+	azureOpenAIAPIVersion = "2024-02-01"
+)
+
+// OpenAIOptionQuality is the GenerateOptions.Extra key for the quality
+// parameter: low/medium/high/auto for gpt-image models, standard/hd for dall-e-3
+const OpenAIOptionQuality = "quality"
+
+// openAIOptions are the provider options accepted by openai/images. Values
+// are not restricted because they vary by model and gateway.
+var openAIOptions = []ProviderOption{
+	{Name: OpenAIOptionQuality, Type: OptionString, Description: "Rendering quality (gpt-image: low/medium/high/auto, dall-e-3: standard/hd)"},
+}
+
+// OpenAIRESTClient generates images through an OpenAI-compatible
+// /v1/images/generations endpoint: OpenAI, Azure OpenAI, or self-hosted
+// gateways such as LocalAI. The model is fixed when the client is created;
+// options.Model is ignored because it names a gimage provider, not the
+// endpoint's model.
+type OpenAIRESTClient struct {
+	apiKey         string
+	baseURL        string
+	model          string
+	azure          bool
+	httpClient     *http.Client
+	verbose        bool
+	circuitBreaker *gobreaker.CircuitBreaker
+}
+
+// NewOpenAIRESTClient creates a client for an OpenAI-compatible images API.
+// baseURL defaults to https://api.openai.com/v1 and model to gpt-image-1.
+// apiKey may be empty for gateways that don't require authentication.
+func NewOpenAIRESTClient(apiKey, baseURL, model string) (*OpenAIRESTClient, error) {
+	if baseURL == "" {
+		baseURL = OpenAIDefaultBaseURL
+	}
+	if model == "" {
+		model = OpenAIDefaultModel
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid OpenAI base URL: %s (expected e.g. %s)", baseURL, OpenAIDefaultBaseURL)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid OpenAI base URL scheme: %s (must be http or https)", u.Scheme)
+	}
+
+	azure := strings.HasSuffix(u.Hostname(), ".openai.azure.com")
+	if apiKey == "" && (azure || baseURL == OpenAIDefaultBaseURL) {
+		return nil, fmt.Errorf("OPENAI_API_KEY is required for %s", u.Host)
+	}
+
+	// Check if verbose mode is enabled via Viper flag or environment variable
+	verbose := viper.GetBool("verbose") || os.Getenv("GIMAGE_VERBOSE") == "true" || os.Getenv("VERBOSE") == "true"
+
+	return &OpenAIRESTClient{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		model:   model,
+		azure:   azure,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Minute,
+		},
+		verbose:        verbose,
+		circuitBreaker: newCircuitBreaker("OpenAIImagesAPI"),
+	}, nil
+}
+
+// logVerbose logs debug information if verbose mode is enabled
+func (c *OpenAIRESTClient) logVerbose(format string, args ...interface{}) {
+	if c.verbose {
+		fmt.Fprintf(os.Stderr, "[OPENAI-REST] "+format+"\n", args...)
+	}
+}
+
+// GenerateImage generates a single image
+func (c *OpenAIRESTClient) GenerateImage(ctx context.Context, prompt string, options models.GenerateOptions) (*models.GeneratedImage, error) {
+	images, err := c.generate(ctx, prompt, options, 1)
+	if err != nil {
+		return nil, err
+	}
+	return images[0], nil
+}
+
+// GenerateImages generates options.Count images using the n parameter, split
+// into calls of at most ten images (one for dall-e-3)
+func (c *OpenAIRESTClient) GenerateImages(ctx context.Context, prompt string, options models.GenerateOptions) ([]*models.GeneratedImage, error) {
+	return generateInBatches(options.Count, c.maxImagesPerRequest(), func(n int) ([]*models.GeneratedImage, error) {
+		return c.generate(ctx, prompt, options, n)
+	})
+}
+
+// maxImagesPerRequest returns the largest n the configured model accepts
+func (c *OpenAIRESTClient) maxImagesPerRequest() int {
+	if strings.HasPrefix(strings.ToLower(c.model), "dall-e-3") {
+		return 1
+	}
+	return openAIMaxImages
+}
+
+// openAIImageRequest is the /images/generations request body
+type openAIImageRequest struct {
+	Model          string `json:"model"`
+	Prompt         string `json:"prompt"`
+	N              int    `json:"n"`
+	Size           string `json:"size,omitempty"`
+	Style          string `json:"style,omitempty"`
+	Quality        string `json:"quality,omitempty"`
+	ResponseFormat string `json:"response_format,omitempty"`
+}
+
+// openAIImageResponse is the /images/generations response body
+type openAIImageResponse struct {
+	Created int64 `json:"created"`
+	Data    []struct {
+		B64JSON       string `json:"b64_json,omitempty"`
+		URL           string `json:"url,omitempty"`
+		RevisedPrompt string `json:"revised_prompt,omitempty"`
+	} `json:"data"`
+}
+
+// generate runs one /images/generations request for count images
+func (c *OpenAIRESTClient) generate(ctx context.Context, prompt string, options models.GenerateOptions, count int) ([]*models.GeneratedImage, error) {
+	startTime := time.Now()
+
+	request, err := c.buildRequest(prompt, options, count)
+	if err != nil {
+		return nil, err
+	}
+
+	c.logVerbose("Generating %d image(s) with model %s at %s", count, c.model, c.baseURL)
+
+	result, err := c.circuitBreaker.Execute(func() (interface{}, error) {
+		return c.invoke(ctx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := result.(*openAIImageResponse)
+
+	if len(response.Data) == 0 {
+		return nil, fmt.Errorf("no images in response")
+	}
+
+	images := make([]*models.GeneratedImage, 0, len(response.Data))
+	for _, item := range response.Data {
+		data, err := c.imageData(ctx, item.B64JSON, item.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		width, height := imageDimensions(data, request.Size)
+		image := &models.GeneratedImage{
+			Data:   data,
+			Format: extractFormatFromMimeType(http.DetectContentType(data)),
+			Width:  width,
+			Height: height,
+			Metadata: map[string]string{
+				"model":  c.model,
+				"prompt": prompt,
+				"size":   fmt.Sprintf("%dx%d", width, height),
+				"api":    "openai",
+			},
+		}
+		if item.RevisedPrompt != "" {
+			image.Metadata["revised_prompt"] = item.RevisedPrompt
+		}
+		images = append(images, image)
+	}
+
+	c.logVerbose("Generated %d image(s) in %v", len(images), time.Since(startTime))
+
+	return images, nil
+}
+
+// buildRequest builds the request body from options
+func (c *OpenAIRESTClient) buildRequest(prompt string, options models.GenerateOptions, count int) (*openAIImageRequest, error) {
+	if strings.TrimSpace(prompt) == "" {
+		return nil, fmt.Errorf("prompt cannot be empty")
+	}
+	if options.NegativePrompt != "" {
+		return nil, fmt.Errorf("OpenAI images API does not support negative prompts")
+	}
+	if options.Seed != 0 {
+		return nil, fmt.Errorf("OpenAI images API does not support seeds")
+	}
+	if count < 1 || count > c.maxImagesPerRequest() {
+		return nil, fmt.Errorf("invalid count: %d (must be 1-%d per request for %s)", count, c.maxImagesPerRequest(), c.model)
+	}
+
+	request := &openAIImageRequest{
+		Model:  c.model,
+		Prompt: prompt,
+		N:      count,
+		Size:   options.Size,
+	}
+
+	// dall-e-3 has native vivid/natural styles; other styles are folded into the prompt
+	switch strings.ToLower(options.Style) {
+	case "vivid", "natural":
+		request.Style = strings.ToLower(options.Style)
+	default:
+		request.Prompt = buildPromptWithOptions(prompt, options)
+	}
+
+	quality, _, err := extraString(options, OpenAIOptionQuality)
+	if err != nil {
+		return nil, err
+	}
+	request.Quality = strings.ToLower(quality)
+
+	// gpt-image models always return base64 and reject response_format
+	if !strings.HasPrefix(strings.ToLower(c.model), "gpt-image") {
+		request.ResponseFormat = "b64_json"
+	}
+
+	return request, nil
+}
+
+// endpoint returns the /images/generations URL, keeping any query string on the base URL
+func (c *OpenAIRESTClient) endpoint() (string, error) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid OpenAI base URL: %w", err)
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + "/images/generations"
+
+	if c.azure {
+		query := u.Query()
+		if query.Get("api-version") == "" {
+			query.Set("api-version", azureOpenAIAPIVersion)
+			u.RawQuery = query.Encode()
+		}
+	}
+
+	return u.String(), nil
+}
+
+// invoke sends the request and decodes the response
+func (c *OpenAIRESTClient) invoke(ctx context.Context, request *openAIImageRequest) (*openAIImageResponse, error) {
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint, err := c.endpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		// Azure OpenAI authenticates with an api-key header instead of a bearer token
+		if c.azure {
+			req.Header.Set("api-key", c.apiKey)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleHTTPError(resp.StatusCode, body)
+	}
+
+	var response openAIImageResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// imageData returns the image bytes from a base64 payload or, for gateways
+// that ignore response_format, by downloading the returned URL
+func (c *OpenAIRESTClient) imageData(ctx context.Context, b64, imageURL string) ([]byte, error) {
+	if b64 != "" {
+		data, err := base64.StdEncoding.DecodeString(b64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image data: %w", err)
+		}
+		return data, nil
+	}
+
+	if imageURL == "" {
+		return nil, fmt.Errorf("response contains neither b64_json nor url")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", imageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download image: HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// handleHTTPError provides user-friendly error messages for HTTP errors
+func (c *OpenAIRESTClient) handleHTTPError(statusCode int, body []byte) error {
+	// OpenAI-style errors: {"error": {"message": "...", "code": "..."}}
+	var errorResponse struct {
+		Error struct {
+			Message string `json:"message"`
+			Code    string `json:"code"`
+		} `json:"error"`
+	}
+	errorMsg := string(body)
+	if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Error.Message != "" {
+		errorMsg = errorResponse.Error.Message
+		if errorResponse.Error.Code != "" {
+			errorMsg = fmt.Sprintf("%s (%s)", errorMsg, errorResponse.Error.Code)
+		}
+	}
+
+	c.logVerbose("HTTP %d: %s", statusCode, errorMsg)
+
+	switch {
+	case statusCode == http.StatusBadRequest:
+		return fmt.Errorf("invalid request (400): %s\n\nTip: Check the size and count supported by model %s", errorMsg, c.model)
+	case statusCode == http.StatusUnauthorized:
+		return fmt.Errorf("authentication failed (401): %s\n\nTip: Check OPENAI_API_KEY. Run 'gimage auth setup openai/images' to update credentials", errorMsg)
+	case statusCode == http.StatusForbidden:
+		return fmt.Errorf("access denied (403): %s", errorMsg)
+	case statusCode == http.StatusNotFound:
+		return fmt.Errorf("not found (404): %s\n\nTip: Check OPENAI_BASE_URL (%s) and OPENAI_IMAGE_MODEL (%s)", errorMsg, c.baseURL, c.model)
+	case statusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("rate limit exceeded (429): %s", errorMsg)
+	case statusCode >= 500:
+		return fmt.Errorf("server error (%d): %s", statusCode, errorMsg)
+	default:
+		return fmt.Errorf("HTTP error %d: %s", statusCode, errorMsg)
+	}
+}
+
+// Close cleans up resources (no-op for REST client, implements interface)
+func (c *OpenAIRESTClient) Close() error {
+	return nil
+}
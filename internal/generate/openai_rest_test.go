@@ -0,0 +1,356 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/apresai/gimage/internal/config"
+	"github.com/apresai/gimage/pkg/models"
+)
+
+// testPNG returns an encoded PNG of the given size
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newOpenAIStub serves /v1/images/generations, recording each decoded request
+func newOpenAIStub(t *testing.T, requests *[]openAIImageRequest, headers *[]http.Header) *httptest.Server {
+	t.Helper()
+	imageData := base64.StdEncoding.EncodeToString(testPNG(t, 64, 32))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/images/generations" {
+			http.NotFound(w, r)
+			return
+		}
+
+		var req openAIImageRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		*requests = append(*requests, req)
+		if headers != nil {
+			*headers = append(*headers, r.Header.Clone())
+		}
+
+		var response openAIImageResponse
+		response.Data = make([]struct {
+			B64JSON       string `json:"b64_json,omitempty"`
+			URL           string `json:"url,omitempty"`
+			RevisedPrompt string `json:"revised_prompt,omitempty"`
+		}, req.N)
+		for i := range response.Data {
+			response.Data[i].B64JSON = imageData
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewOpenAIRESTClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		apiKey  string
+		baseURL string
+		wantErr bool
+	}{
+		{name: "OpenAI with key", apiKey: "sk-test"},
+		{name: "OpenAI without key", wantErr: true},
+		{name: "gateway without key", baseURL: "http://localhost:8080/v1"},
+		{name: "Azure without key", baseURL: "https://example.openai.azure.com/openai/deployments/dalle", wantErr: true},
+		{name: "invalid URL", apiKey: "sk-test", baseURL: "localhost:8080", wantErr: true},
+		{name: "unsupported scheme", apiKey: "sk-test", baseURL: "ftp://example.com/v1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewOpenAIRESTClient(tt.apiKey, tt.baseURL, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewOpenAIRESTClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && client.model != OpenAIDefaultModel {
+				t.Errorf("model = %s, want %s", client.model, OpenAIDefaultModel)
+			}
+		})
+	}
+}
+
+func TestOpenAIRESTClient_GenerateImage(t *testing.T) {
+	var requests []openAIImageRequest
+	var headers []http.Header
+	server := newOpenAIStub(t, &requests, &headers)
+
+	client, err := NewOpenAIRESTClient("sk-test", server.URL+"/v1/", "dall-e-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// options.Model names the gimage provider and must not reach the endpoint
+	image, err := client.GenerateImage(context.Background(), "a red fox", models.GenerateOptions{
+		Model: OpenAIDefaultModel,
+		Size:  "512x512",
+		Style: "anime",
+		Extra: map[string]any{OpenAIOptionQuality: "HD"},
+	})
+	if err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+	if req.Model != "dall-e-2" || req.N != 1 || req.Size != "512x512" || req.Quality != "hd" || req.ResponseFormat != "b64_json" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if !strings.Contains(req.Prompt, "anime style") {
+		t.Errorf("style should be folded into the prompt, got %q", req.Prompt)
+	}
+	if got := headers[0].Get("Authorization"); got != "Bearer sk-test" {
+		t.Errorf("Authorization = %q", got)
+	}
+
+	if image.Format != "png" || image.Width != 64 || image.Height != 32 {
+		t.Errorf("image = %s %dx%d, want png 64x32", image.Format, image.Width, image.Height)
+	}
+	if image.Metadata["model"] != "dall-e-2" || image.Metadata["api"] != "openai" {
+		t.Errorf("unexpected metadata: %v", image.Metadata)
+	}
+}
+
+func TestOpenAIRESTClient_GenerateImages(t *testing.T) {
+	tests := []struct {
+		model     string
+		wantCalls []int
+	}{
+		{model: "gpt-image-1", wantCalls: []int{10, 2}},
+		{model: "dall-e-3", wantCalls: []int{1, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			var requests []openAIImageRequest
+			server := newOpenAIStub(t, &requests, nil)
+
+			client, err := NewOpenAIRESTClient("sk-test", server.URL+"/v1", tt.model)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := 0
+			for _, n := range tt.wantCalls {
+				want += n
+			}
+			images, err := client.GenerateImages(context.Background(), "prompt", models.GenerateOptions{Count: want})
+			if err != nil {
+				t.Fatalf("GenerateImages() error = %v", err)
+			}
+			if len(images) != want {
+				t.Errorf("got %d images, want %d", len(images), want)
+			}
+
+			var calls []int
+			for _, req := range requests {
+				calls = append(calls, req.N)
+				if strings.HasPrefix(tt.model, "gpt-image") && req.ResponseFormat != "" {
+					t.Errorf("response_format should be omitted for %s", tt.model)
+				}
+			}
+			if len(calls) != len(tt.wantCalls) {
+				t.Errorf("request sizes = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestOpenAIRESTClient_Azure(t *testing.T) {
+	var gotQuery, gotKey, gotAuth string
+	client, err := NewOpenAIRESTClient("azure-key", "https://example.openai.azure.com/openai/deployments/dalle", "dall-e-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endpoint, err := client.endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoint != "https://example.openai.azure.com/openai/deployments/dalle/images/generations?api-version="+azureOpenAIAPIVersion {
+		t.Errorf("endpoint = %s", endpoint)
+	}
+
+	// Route the Azure client at a stub to check its auth header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("api-version")
+		gotKey = r.Header.Get("api-key")
+		gotAuth = r.Header.Get("Authorization")
+		w.Write([]byte(`{"data":[{"b64_json":"` + base64.StdEncoding.EncodeToString(testPNG(t, 8, 8)) + `"}]}`))
+	}))
+	defer server.Close()
+	client.baseURL = server.URL + "/openai/deployments/dalle?api-version=2024-10-21"
+
+	if _, err := client.GenerateImage(context.Background(), "prompt", models.GenerateOptions{Style: "natural"}); err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+	if gotQuery != "2024-10-21" {
+		t.Errorf("api-version = %q, want the one from the base URL", gotQuery)
+	}
+	if gotKey != "azure-key" || gotAuth != "" {
+		t.Errorf("api-key = %q, Authorization = %q", gotKey, gotAuth)
+	}
+}
+
+func TestOpenAIRESTClient_URLResponse(t *testing.T) {
+	imageData := testPNG(t, 16, 16)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/images/generations":
+			w.Write([]byte(`{"data":[{"url":"` + server.URL + `/files/1.png","revised_prompt":"a calm lake"}]}`))
+		case "/files/1.png":
+			w.Write(imageData)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewOpenAIRESTClient("", server.URL+"/v1", "stablediffusion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	image, err := client.GenerateImage(context.Background(), "lake", models.GenerateOptions{})
+	if err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+	if !bytes.Equal(image.Data, imageData) {
+		t.Error("image data should be downloaded from the returned URL")
+	}
+	if image.Metadata["revised_prompt"] != "a calm lake" {
+		t.Errorf("revised_prompt = %q", image.Metadata["revised_prompt"])
+	}
+}
+
+func TestOpenAIRESTClient_Errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		wantContains string
+		wantFailover bool
+	}{
+		{
+			name:         "quota",
+			status:       http.StatusTooManyRequests,
+			body:         `{"error":{"message":"You exceeded your current quota","code":"insufficient_quota"}}`,
+			wantContains: "insufficient_quota",
+			wantFailover: true,
+		},
+		{
+			name:         "server error",
+			status:       http.StatusBadGateway,
+			body:         `bad gateway`,
+			wantContains: "server error (502)",
+			wantFailover: true,
+		},
+		{
+			name:         "safety",
+			status:       http.StatusBadRequest,
+			body:         `{"error":{"message":"Your request was rejected by the safety system","code":"content_policy_violation"}}`,
+			wantContains: "content_policy_violation",
+		},
+		{
+			name:         "auth",
+			status:       http.StatusUnauthorized,
+			body:         `{"error":{"message":"Incorrect API key provided"}}`,
+			wantContains: "authentication failed (401)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, err := NewOpenAIRESTClient("sk-test", server.URL+"/v1", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = client.GenerateImage(context.Background(), "prompt", models.GenerateOptions{})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantContains) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantContains)
+			}
+			if IsFailoverError(err) != tt.wantFailover {
+				t.Errorf("IsFailoverError() = %v, want %v", IsFailoverError(err), tt.wantFailover)
+			}
+		})
+	}
+}
+
+func TestOpenAIRESTClient_UnsupportedOptions(t *testing.T) {
+	client, err := NewOpenAIRESTClient("sk-test", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, options := range []models.GenerateOptions{{NegativePrompt: "blurry"}, {Seed: 42}} {
+		if _, err := client.GenerateImage(context.Background(), "prompt", options); err == nil {
+			t.Errorf("expected error for unsupported options %+v", options)
+		}
+	}
+	if _, err := client.GenerateImage(context.Background(), " ", models.GenerateOptions{}); err == nil {
+		t.Error("expected error for empty prompt")
+	}
+}
+
+func TestOpenAIProvider_Registry(t *testing.T) {
+	clearCredentialEnv(t)
+
+	var requests []openAIImageRequest
+	server := newOpenAIStub(t, &requests, nil)
+
+	registry := NewProviderRegistry()
+	cfg := &config.Config{OpenAIBaseURL: server.URL + "/v1", OpenAIImageModel: "local-sd"}
+
+	// A keyless gateway counts as configured, so auto selects it when nothing else is set up
+	client, p, err := registry.ResolveClient(AutoProvider, cfg)
+	if err != nil {
+		t.Fatalf("ResolveClient() error = %v", err)
+	}
+	defer client.Close()
+	if p.ID != "openai/images" {
+		t.Errorf("provider = %s, want openai/images", p.ID)
+	}
+
+	if _, err := client.GenerateImage(context.Background(), "prompt", models.GenerateOptions{Model: p.ModelID}); err != nil {
+		t.Fatalf("GenerateImage() error = %v", err)
+	}
+	if len(requests) != 1 || requests[0].Model != "local-sd" {
+		t.Errorf("requests = %+v, want model local-sd", requests)
+	}
+
+	// Without a key or base URL the provider is not configured
+	if _, _, err := registry.ResolveClient("openai", &config.Config{}); err == nil {
+		t.Error("expected missing credentials error")
+	}
+}
@@ -0,0 +1,264 @@
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apresai/gimage/pkg/models"
+)
+
+// Provider option value types
+const (
+	OptionString = "string"
+	OptionInt    = "int"
+	OptionNumber = "number"
+	OptionBool   = "bool"
+)
+
+// ProviderOption describes one provider-specific key accepted in GenerateOptions.Extra
+type ProviderOption struct {
+	Name        string   // Key, e.g. "cfgScale"
+	Type        string   // OptionString, OptionInt, OptionNumber or OptionBool
+	Description string   // e.g. "Prompt adherence (1.1-10, default 7)"
+	Values      []string // Allowed values for string options (empty = any)
+	Min         float64  // Range for numeric options (Min == Max == 0 = unbounded)
+	Max         float64
+}
+
+// OptionNames returns the provider's option keys in sorted order
+func (p *Provider) OptionNames() []string {
+	names := make([]string, 0, len(p.Capabilities.Options))
+	for _, option := range p.Capabilities.Options {
+		names = append(names, option.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// option returns the provider option named key
+func (p *Provider) option(key string) (ProviderOption, bool) {
+	for _, option := range p.Capabilities.Options {
+		if option.Name == key {
+			return option, true
+		}
+	}
+	return ProviderOption{}, false
+}
+
+// ValidateOptions checks extra against the provider's declared options and
+// returns a copy with values converted to their declared types (string, int,
+// float64 or bool). Unknown keys are rejected with the list of supported keys.
+func (p *Provider) ValidateOptions(extra map[string]any) (map[string]any, error) {
+	if len(extra) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	validated := make(map[string]any, len(extra))
+	for _, key := range keys {
+		option, ok := p.option(key)
+		if !ok {
+			if len(p.Capabilities.Options) == 0 {
+				return nil, fmt.Errorf("unsupported option %q: %s has no provider options", key, p.ID)
+			}
+			return nil, fmt.Errorf("unsupported option %q for %s (supported: %s)", key, p.ID, strings.Join(p.OptionNames(), ", "))
+		}
+
+		value, err := option.convert(extra[key])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.ID, err)
+		}
+		validated[key] = value
+	}
+
+	return validated, nil
+}
+
+// supportedOptions returns the subset of extra the provider declares, so a
+// failover chain can pass one option set to providers with different options
+func (p *Provider) supportedOptions(extra map[string]any) map[string]any {
+	if len(extra) == 0 {
+		return nil
+	}
+	supported := make(map[string]any, len(extra))
+	for key, value := range extra {
+		if _, ok := p.option(key); ok {
+			supported[key] = value
+		}
+	}
+	return supported
+}
+
+// convert checks value against the option and returns it as the declared type
+func (o ProviderOption) convert(value any) (any, error) {
+	options := models.GenerateOptions{Extra: map[string]any{o.Name: value}}
+
+	switch o.Type {
+	case OptionString:
+		s, _, err := extraString(options, o.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(o.Values) > 0 {
+			for _, allowed := range o.Values {
+				if strings.EqualFold(s, allowed) {
+					return allowed, nil
+				}
+			}
+			return nil, fmt.Errorf("invalid %s: %q (must be one of: %s)", o.Name, s, strings.Join(o.Values, ", "))
+		}
+		return s, nil
+
+	case OptionInt:
+		n, _, err := extraInt(options, o.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := o.checkRange(float64(n)); err != nil {
+			return nil, err
+		}
+		return n, nil
+
+	case OptionNumber:
+		f, _, err := extraFloat(options, o.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := o.checkRange(f); err != nil {
+			return nil, err
+		}
+		return f, nil
+
+	case OptionBool:
+		b, _, err := extraBool(options, o.Name)
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
+
+	default:
+		return nil, fmt.Errorf("unknown option type %s", o.Type)
+	}
+}
+
+// checkRange validates a numeric option value
+func (o ProviderOption) checkRange(f float64) error {
+	if o.Min == 0 && o.Max == 0 {
+		return nil
+	}
+	if f < o.Min || f > o.Max {
+		return fmt.Errorf("invalid %s: %v (must be %g-%g)", o.Name, f, o.Min, o.Max)
+	}
+	return nil
+}
+
+// ParseOptionParams parses repeated key=value CLI parameters into an Extra
+// map. Values stay strings; Provider.ValidateOptions converts them.
+func ParseOptionParams(params []string) (map[string]any, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+	extra := make(map[string]any, len(params))
+	for _, param := range params {
+		key, value, ok := strings.Cut(param, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid parameter %q (expected key=value)", param)
+		}
+		extra[key] = strings.TrimSpace(value)
+	}
+	return extra, nil
+}
+
+// Values in GenerateOptions.Extra arrive as strings from the CLI and as
+// float64 or json.Number from MCP/Lambda JSON, so the accessors below accept
+// all of them.
+
+// extraString returns options.Extra[key] as a string
+func extraString(options models.GenerateOptions, key string) (string, bool, error) {
+	value, ok := options.Extra[key]
+	if !ok || value == nil {
+		return "", false, nil
+	}
+	switch v := value.(type) {
+	case string:
+		return v, true, nil
+	case fmt.Stringer:
+		return v.String(), true, nil
+	default:
+		return "", false, fmt.Errorf("invalid %s: %v (must be a string)", key, value)
+	}
+}
+
+// extraBool returns options.Extra[key] as a boolean
+func extraBool(options models.GenerateOptions, key string) (bool, bool, error) {
+	value, ok := options.Extra[key]
+	if !ok || value == nil {
+		return false, false, nil
+	}
+	switch v := value.(type) {
+	case bool:
+		return v, true, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return false, false, fmt.Errorf("invalid %s: %q (must be true or false)", key, v)
+		}
+		return b, true, nil
+	default:
+		return false, false, fmt.Errorf("invalid %s: %v (must be true or false)", key, value)
+	}
+}
+
+// extraInt returns options.Extra[key] as an integer
+func extraInt(options models.GenerateOptions, key string) (int, bool, error) {
+	f, ok, err := extraFloat(options, key)
+	if err != nil || !ok {
+		return 0, ok, err
+	}
+	if f != math.Trunc(f) {
+		return 0, false, fmt.Errorf("invalid %s: %v (must be an integer)", key, f)
+	}
+	return int(f), true, nil
+}
+
+// extraFloat returns options.Extra[key] as a number
+func extraFloat(options models.GenerateOptions, key string) (float64, bool, error) {
+	value, ok := options.Extra[key]
+	if !ok || value == nil {
+		return 0, false, nil
+	}
+	switch v := value.(type) {
+	case float64:
+		return v, true, nil
+	case float32:
+		return float64(v), true, nil
+	case int:
+		return float64(v), true, nil
+	case int64:
+		return float64(v), true, nil
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid %s: %v (must be a number)", key, value)
+		}
+		return f, true, nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid %s: %q (must be a number)", key, v)
+		}
+		return f, true, nil
+	default:
+		return 0, false, fmt.Errorf("invalid %s: %v (must be a number)", key, value)
+	}
+}
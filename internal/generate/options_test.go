@@ -0,0 +1,82 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProvider_ValidateOptions(t *testing.T) {
+	registry := NewProviderRegistry()
+	nova, err := registry.Get("bedrock/nova-canvas")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	extra, err := nova.ValidateOptions(map[string]any{"cfgScale": "6.5", "quality": "PREMIUM"})
+	if err != nil {
+		t.Fatalf("ValidateOptions() error = %v", err)
+	}
+	if extra["cfgScale"] != 6.5 || extra["quality"] != "premium" {
+		t.Errorf("ValidateOptions() = %v, want converted values", extra)
+	}
+
+	imagen, err := registry.Get("vertex/imagen-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	extra, err = imagen.ValidateOptions(map[string]any{"addWatermark": "false", "personGeneration": "allow_adult"})
+	if err != nil {
+		t.Fatalf("ValidateOptions() error = %v", err)
+	}
+	if extra["addWatermark"] != false {
+		t.Errorf("addWatermark = %v, want bool false", extra["addWatermark"])
+	}
+}
+
+func TestProvider_ValidateOptions_Errors(t *testing.T) {
+	registry := NewProviderRegistry()
+
+	tests := []struct {
+		name        string
+		provider    string
+		extra       map[string]any
+		errContains string
+	}{
+		{name: "unknown key lists supported", provider: "bedrock/nova-canvas", extra: map[string]any{"steps": 20}, errContains: "supported: cfgScale, quality"},
+		{name: "provider without options", provider: "gemini/flash-2.5", extra: map[string]any{"steps": 20}, errContains: "has no provider options"},
+		{name: "out of range", provider: "bedrock/nova-canvas", extra: map[string]any{"cfgScale": 11}, errContains: "1.1-10"},
+		{name: "not in allowed values", provider: "vertex/imagen-3", extra: map[string]any{"safetySetting": "off"}, errContains: "block_none"},
+		{name: "wrong type", provider: "sd/a1111", extra: map[string]any{"steps": "many"}, errContains: "must be a number"},
+		{name: "fractional int", provider: "sd/comfyui", extra: map[string]any{"steps": 2.5}, errContains: "must be an integer"},
+		{name: "bad bool", provider: "vertex/imagen-4", extra: map[string]any{"enhancePrompt": "maybe"}, errContains: "true or false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := registry.Get(tt.provider)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = p.ValidateOptions(tt.extra)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("ValidateOptions() error = %v, want it to contain %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestParseOptionParams(t *testing.T) {
+	extra, err := ParseOptionParams([]string{"steps=30", "sampler=DPM++ 2M Karras", "empty="})
+	if err != nil {
+		t.Fatalf("ParseOptionParams() error = %v", err)
+	}
+	if extra["steps"] != "30" || extra["sampler"] != "DPM++ 2M Karras" || extra["empty"] != "" {
+		t.Errorf("ParseOptionParams() = %v", extra)
+	}
+
+	for _, param := range []string{"steps", "=30"} {
+		if _, err := ParseOptionParams([]string{param}); err == nil {
+			t.Errorf("ParseOptionParams(%q) expected error", param)
+		}
+	}
+}
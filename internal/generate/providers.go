@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/apresai/gimage/internal/config"
@@ -24,6 +25,12 @@ type ImageGenerator interface {
 	Close() error
 }
 
+// ImageEditor is implemented by clients that can edit an existing image from a text instruction.
+// Use Provider.Capabilities.SupportsEditing to check support before type-asserting a client.
+type ImageEditor interface {
+	EditImage(ctx context.Context, prompt string, edit models.EditOptions, options models.GenerateOptions) (*models.GeneratedImage, error)
+}
+
 // Provider represents a specific way to access a model (API + Model + Auth)
 type Provider struct {
 	// Unique identifier: "provider/model" e.g., "gemini/flash-2.5", "vertex/imagen-4"
@@ -31,7 +38,7 @@ type Provider struct {
 
 	// Display information
 	Name        string // e.g., "Gemini 2.5 Flash (via Gemini API)"
-	API         string // "gemini", "vertex", "bedrock"
+	API         string // "gemini", "vertex", "bedrock", "openai", "sd"
 	ModelID     string // Actual model identifier for the API
 	Description string
 
@@ -70,7 +77,26 @@ type ModelCapabilities struct {
 	SupportsStyles         bool
 	SupportsNegativePrompt bool
 	SupportsSeed           bool
+	SupportsEditing        bool // Client implements ImageEditor
+	MaxImagesPerRequest    int  // Candidates returned by one API call (1 = parallel calls for --count)
 	MaxPromptLength        int
+	Options                []ProviderOption // Provider-specific keys accepted in GenerateOptions.Extra
+	Sizes                  []SizeSpec       // Native output sizes; requests snap to the closest (empty = any size)
+}
+
+// AutoProvider selects a provider from the configured credentials
+const AutoProvider = "auto"
+
+// apiPreference orders APIs for auto-selection (free tier first)
+var apiPreference = []string{"gemini", "vertex", "bedrock", "openai", "sd"}
+
+// defaultProviders maps each API to the provider used when only the API is known
+var defaultProviders = map[string]string{
+	"gemini":  "gemini/flash-2.5",
+	"vertex":  "vertex/imagen-4",
+	"bedrock": "bedrock/nova-canvas",
+	"openai":  "openai/images",
+	"sd":      "sd/a1111",
 }
 
 // Helper function for creating float64 pointers
@@ -125,7 +151,10 @@ func (r *ProviderRegistry) registerAllProviders() {
 			SupportsStyles:         true,
 			SupportsNegativePrompt: true,
 			SupportsSeed:           true,
+			SupportsEditing:        true,
+			MaxImagesPerRequest:    1,
 			MaxPromptLength:        480,
+			Sizes:                  geminiImageSizes,
 		},
 		CreateClient: func(creds map[string]string) (ImageGenerator, error) {
 			apiKey := creds["GEMINI_API_KEY"]
@@ -175,7 +204,11 @@ func (r *ProviderRegistry) registerAllProviders() {
 			SupportsStyles:         true,
 			SupportsNegativePrompt: true,
 			SupportsSeed:           true,
+			SupportsEditing:        false,
+			MaxImagesPerRequest:    4,
 			MaxPromptLength:        2000,
+			Options:                imagenOptions,
+			Sizes:                  imagen4Sizes,
 		},
 		CreateClient: func(creds map[string]string) (ImageGenerator, error) {
 			project := creds["VERTEX_PROJECT"]
@@ -233,7 +266,11 @@ func (r *ProviderRegistry) registerAllProviders() {
 			SupportsStyles:         true,
 			SupportsNegativePrompt: true,
 			SupportsSeed:           true,
+			SupportsEditing:        false,
+			MaxImagesPerRequest:    4,
 			MaxPromptLength:        2000,
+			Options:                imagenOptions,
+			Sizes:                  imagenSizes,
 		},
 		CreateClient: func(creds map[string]string) (ImageGenerator, error) {
 			project := creds["VERTEX_PROJECT"]
@@ -291,7 +328,11 @@ func (r *ProviderRegistry) registerAllProviders() {
 			SupportsStyles:         true,
 			SupportsNegativePrompt: true,
 			SupportsSeed:           true,
+			SupportsEditing:        false,
+			MaxImagesPerRequest:    4,
 			MaxPromptLength:        2000,
+			Options:                imagenOptions,
+			Sizes:                  imagenSizes,
 		},
 		CreateClient: func(creds map[string]string) (ImageGenerator, error) {
 			project := creds["VERTEX_PROJECT"]
@@ -349,7 +390,11 @@ func (r *ProviderRegistry) registerAllProviders() {
 			SupportsStyles:         true,
 			SupportsNegativePrompt: true,
 			SupportsSeed:           true,
+			SupportsEditing:        false,
+			MaxImagesPerRequest:    4,
 			MaxPromptLength:        2000,
+			Options:                imagenOptions,
+			Sizes:                  imagenSizes,
 		},
 		CreateClient: func(creds map[string]string) (ImageGenerator, error) {
 			project := creds["VERTEX_PROJECT"]
@@ -414,7 +459,11 @@ func (r *ProviderRegistry) registerAllProviders() {
 			SupportsStyles:         true,
 			SupportsNegativePrompt: true,
 			SupportsSeed:           true,
+			SupportsEditing:        true,
+			MaxImagesPerRequest:    5,
 			MaxPromptLength:        4096,
+			Options:                novaCanvasOptions,
+			Sizes:                  novaCanvasSizes,
 		},
 		CreateClient: func(creds map[string]string) (ImageGenerator, error) {
 			region := creds["AWS_REGION"]
@@ -432,6 +481,167 @@ func (r *ProviderRegistry) registerAllProviders() {
 			return NewBedrockSDKClient(ctx, region)
 		},
 	})
+
+	// OpenAI-compatible /v1/images/generations (OpenAI, Azure OpenAI, LocalAI, ...)
+	r.Register(&Provider{
+		ID:          "openai/images",
+		Name:        "OpenAI Images (OpenAI-compatible API)",
+		API:         "openai",
+		ModelID:     OpenAIDefaultModel,
+		Description: "OpenAI, Azure OpenAI or a self-hosted OpenAI-compatible gateway",
+		RequiredEnvVars: []EnvVar{
+			{
+				Name:        "OPENAI_API_KEY",
+				ConfigKey:   "openai_api_key",
+				Description: "API key (optional for gateways without auth)",
+				Required:    false,
+				Secret:      true,
+			},
+			{
+				Name:        "OPENAI_BASE_URL",
+				ConfigKey:   "openai_base_url",
+				Description: "Endpoint base URL (default: " + OpenAIDefaultBaseURL + ")",
+				Required:    false,
+				Secret:      false,
+			},
+			{
+				Name:        "OPENAI_IMAGE_MODEL",
+				ConfigKey:   "openai_image_model",
+				Description: "Model name (default: " + OpenAIDefaultModel + ")",
+				Required:    false,
+				Secret:      false,
+			},
+		},
+		Pricing: PricingInfo{
+			CostPerImage: float64Ptr(0.04), // gpt-image-1 medium / dall-e-3 standard at 1024x1024
+			FreeTier:     false,
+			Currency:     "USD",
+		},
+		Capabilities: ModelCapabilities{
+			SupportsStyles:         true,
+			SupportsNegativePrompt: false,
+			SupportsSeed:           false,
+			SupportsEditing:        false,
+			MaxImagesPerRequest:    openAIMaxImages,
+			MaxPromptLength:        4000,
+			Options:                openAIOptions,
+		},
+		CreateClient: func(creds map[string]string) (ImageGenerator, error) {
+			return NewOpenAIRESTClient(creds["OPENAI_API_KEY"], creds["OPENAI_BASE_URL"], creds["OPENAI_IMAGE_MODEL"])
+		},
+	})
+
+	// Self-hosted Stable Diffusion: prompts never leave the local network
+	sdCapabilities := ModelCapabilities{
+		SupportsStyles:         true,
+		SupportsNegativePrompt: true,
+		SupportsSeed:           true,
+		SupportsEditing:        false,
+		MaxImagesPerRequest:    sdMaxImagesPerRequest,
+		MaxPromptLength:        4000,
+		Options:                sdOptions,
+	}
+	sdPricing := PricingInfo{
+		CostPerImage:  float64Ptr(0.0),
+		FreeTier:      true,
+		FreeTierLimit: "self-hosted",
+		Currency:      "USD",
+	}
+
+	// Stable Diffusion via Automatic1111 WebUI
+	r.Register(&Provider{
+		ID:          "sd/a1111",
+		Name:        "Stable Diffusion (via Automatic1111 WebUI)",
+		API:         "sd",
+		ModelID:     "a1111",
+		Description: "Self-hosted Stable Diffusion WebUI txt2img API (start with --api)",
+		RequiredEnvVars: []EnvVar{
+			{
+				Name:        "A1111_BASE_URL",
+				ConfigKey:   "a1111_base_url",
+				Description: "WebUI URL (e.g., http://gpu-box:7860)",
+				Required:    true,
+				Secret:      false,
+			},
+			{
+				Name:        "A1111_API_AUTH",
+				ConfigKey:   "a1111_api_auth",
+				Description: "user:password from --api-auth (optional)",
+				Required:    false,
+				Secret:      true,
+			},
+		},
+		Pricing:      sdPricing,
+		Capabilities: sdCapabilities,
+		CreateClient: func(creds map[string]string) (ImageGenerator, error) {
+			return NewA1111Client(creds["A1111_BASE_URL"], creds["A1111_API_AUTH"])
+		},
+	})
+
+	// Stable Diffusion via ComfyUI
+	r.Register(&Provider{
+		ID:          "sd/comfyui",
+		Name:        "Stable Diffusion (via ComfyUI)",
+		API:         "sd",
+		ModelID:     "comfyui",
+		Description: "Self-hosted ComfyUI server running a standard text-to-image workflow",
+		RequiredEnvVars: []EnvVar{
+			{
+				Name:        "COMFYUI_BASE_URL",
+				ConfigKey:   "comfyui_base_url",
+				Description: "ComfyUI URL (e.g., http://gpu-box:8188)",
+				Required:    true,
+				Secret:      false,
+			},
+			{
+				Name:        "COMFYUI_CHECKPOINT",
+				ConfigKey:   "comfyui_checkpoint",
+				Description: "Checkpoint file, e.g. sd_xl_base_1.0.safetensors (optional, default: first installed)",
+				Required:    false,
+				Secret:      false,
+			},
+		},
+		Pricing:      sdPricing,
+		Capabilities: sdCapabilities,
+		CreateClient: func(creds map[string]string) (ImageGenerator, error) {
+			return NewComfyUIClient(creds["COMFYUI_BASE_URL"], creds["COMFYUI_CHECKPOINT"])
+		},
+	})
+
+	// Local mock provider for offline development and tests
+	mockCapabilities := ModelCapabilities{
+		SupportsStyles:         true,
+		SupportsNegativePrompt: true,
+		SupportsSeed:           true,
+		SupportsEditing:        true,
+		MaxImagesPerRequest:    4,
+		MaxPromptLength:        2000,
+	}
+	r.Register(&Provider{
+		ID:          "local/mock",
+		Name:        "Mock (local placeholder)",
+		API:         "local",
+		ModelID:     "mock",
+		Description: "Deterministic placeholder images rendered locally - no network or credentials",
+		RequiredEnvVars: []EnvVar{
+			{
+				Name:        MockErrorEnvVar,
+				Description: "Simulate an error on every request: quota, safety or timeout (optional)",
+				Required:    false,
+				Secret:      false,
+			},
+		},
+		Pricing: PricingInfo{
+			CostPerImage:  float64Ptr(0.0),
+			FreeTier:      true,
+			FreeTierLimit: "unlimited, offline",
+			Currency:      "USD",
+		},
+		Capabilities: mockCapabilities,
+		CreateClient: func(creds map[string]string) (ImageGenerator, error) {
+			return NewMockClient(mockCapabilities, creds[MockErrorEnvVar])
+		},
+	})
 }
 
 // Register adds a provider to the registry
@@ -474,6 +684,12 @@ func (r *ProviderRegistry) CheckAuth(p *Provider) (bool, []string, error) {
 		return false, nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
+	hasAuth, missing := r.checkAuth(p, cfg)
+	return hasAuth, missing, nil
+}
+
+// checkAuth checks a provider's required credentials against cfg
+func (r *ProviderRegistry) checkAuth(p *Provider, cfg *config.Config) (bool, []string) {
 	creds := r.gatherCredentials(p, cfg)
 	missing := []string{}
 
@@ -486,28 +702,28 @@ func (r *ProviderRegistry) CheckAuth(p *Provider) (bool, []string, error) {
 		}
 	}
 
-	// Special check for Bedrock - needs either bearer token OR AWS keys
-	if p.API == "bedrock" && creds["AWS_BEDROCK_API_KEY"] == "" {
+	// Special check for Bedrock - needs a bearer token, AWS keys, or an AWS profile
+	if p.API == "bedrock" && creds["AWS_BEDROCK_API_KEY"] == "" && cfg.AWSProfile == "" {
 		if creds["AWS_ACCESS_KEY_ID"] == "" || creds["AWS_SECRET_ACCESS_KEY"] == "" {
-			missing = append(missing, "AWS_BEDROCK_API_KEY or (AWS_ACCESS_KEY_ID + AWS_SECRET_ACCESS_KEY)")
+			missing = append(missing, "AWS_BEDROCK_API_KEY, AWS_PROFILE or (AWS_ACCESS_KEY_ID + AWS_SECRET_ACCESS_KEY)")
 		}
 	}
 
-	return len(missing) == 0, missing, nil
+	// Special check for OpenAI - needs an API key unless pointed at a custom gateway
+	if p.API == "openai" && creds["OPENAI_API_KEY"] == "" && creds["OPENAI_BASE_URL"] == "" {
+		missing = append(missing, "OPENAI_API_KEY or OPENAI_BASE_URL")
+	}
+
+	return len(missing) == 0, missing
 }
 
-// gatherCredentials collects credentials from env vars and config
+// gatherCredentials collects credentials from config and env vars.
+// LoadConfig already applies env var overrides, so config values win and
+// env vars only fill keys the Config struct doesn't carry.
 func (r *ProviderRegistry) gatherCredentials(p *Provider, cfg *config.Config) map[string]string {
 	creds := make(map[string]string)
 
 	for _, env := range p.RequiredEnvVars {
-		// Check environment variable first
-		if val := os.Getenv(env.Name); val != "" {
-			creds[env.Name] = val
-			continue
-		}
-
-		// Fall back to config file
 		switch env.ConfigKey {
 		case "gemini_api_key":
 			creds[env.Name] = cfg.GeminiAPIKey
@@ -525,13 +741,32 @@ func (r *ProviderRegistry) gatherCredentials(p *Provider, cfg *config.Config) ma
 			creds[env.Name] = cfg.AWSAccessKeyID
 		case "aws_secret_access_key":
 			creds[env.Name] = cfg.AWSSecretAccessKey
+		case "openai_api_key":
+			creds[env.Name] = cfg.OpenAIAPIKey
+		case "openai_base_url":
+			creds[env.Name] = cfg.OpenAIBaseURL
+		case "openai_image_model":
+			creds[env.Name] = cfg.OpenAIImageModel
+		case "a1111_base_url":
+			creds[env.Name] = cfg.A1111BaseURL
+		case "a1111_api_auth":
+			creds[env.Name] = cfg.A1111Auth
+		case "comfyui_base_url":
+			creds[env.Name] = cfg.ComfyUIBaseURL
+		case "comfyui_checkpoint":
+			creds[env.Name] = cfg.ComfyUICheckpoint
+		}
+
+		// Fall back to environment variable
+		if creds[env.Name] == "" {
+			creds[env.Name] = os.Getenv(env.Name)
 		}
 	}
 
 	return creds
 }
 
-// CreateClient creates a client for the provider
+// CreateClient creates a client for the provider using the default config
 func (r *ProviderRegistry) CreateClient(providerID string) (ImageGenerator, error) {
 	p, err := r.Get(providerID)
 	if err != nil {
@@ -543,15 +778,156 @@ func (r *ProviderRegistry) CreateClient(providerID string) (ImageGenerator, erro
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	creds := r.gatherCredentials(p, cfg)
+	return r.NewClient(p, cfg)
+}
 
+// NewClient creates a ready client for the provider using credentials from cfg
+func (r *ProviderRegistry) NewClient(p *Provider, cfg *config.Config) (ImageGenerator, error) {
 	// Check auth before creating client
-	hasAuth, missing, _ := r.CheckAuth(p)
-	if !hasAuth {
-		return nil, fmt.Errorf("missing credentials for %s: %v", p.Name, missing)
+	if hasAuth, missing := r.checkAuth(p, cfg); !hasAuth {
+		return nil, fmt.Errorf("missing credentials for %s: %s\nRun: gimage auth setup %s", p.Name, strings.Join(missing, ", "), p.ID)
 	}
 
-	return p.CreateClient(creds)
+	client, err := p.CreateClient(r.gatherCredentials(p, cfg))
+	if err != nil {
+		return nil, err // Avoid returning a typed nil client
+	}
+	return client, nil
+}
+
+// ResolveClient resolves a provider ID, model alias, model ID or "auto" and
+// returns a ready client along with the selected provider. This is the single
+// entry point the CLI, MCP server and Lambda handler use for generation.
+// When cfg.FallbackProviders is set the client is a FailoverGenerator that
+// starts with the selected provider.
+func (r *ProviderRegistry) ResolveClient(input string, cfg *config.Config) (ImageGenerator, *Provider, error) {
+	p, err := r.SelectProvider(input, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := r.NewClient(p, cfg)
+	if len(cfg.FallbackProviders) == 0 {
+		if err != nil {
+			return nil, p, err
+		}
+		return client, p, nil
+	}
+
+	chain, chainErr := r.FailoverChain(p, cfg.FallbackProviders)
+	if chainErr != nil {
+		if client != nil {
+			client.Close()
+		}
+		return nil, p, chainErr
+	}
+	if len(chain) == 1 {
+		return client, p, err
+	}
+
+	// A primary without credentials is skipped at generation time
+	failover, err := r.NewFailoverGenerator(chain, client, cfg)
+	if err != nil {
+		return nil, p, err
+	}
+	return failover, p, nil
+}
+
+// ResolveEditor resolves input exactly like ResolveClient, but requires a
+// provider that supports editing. The returned client implements ImageEditor.
+// Edits are not failed over, so cfg.FallbackProviders is ignored.
+func (r *ProviderRegistry) ResolveEditor(input string, cfg *config.Config) (ImageGenerator, *Provider, error) {
+	p, err := r.SelectProvider(input, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !p.Capabilities.SupportsEditing {
+		return nil, p, fmt.Errorf("provider %s does not support image editing (supported: %s)", p.ID, strings.Join(r.EditingProviders(), ", "))
+	}
+
+	single := *cfg
+	single.FallbackProviders = nil
+	client, _, err := r.ResolveClient(p.ID, &single)
+	if err != nil {
+		return nil, p, err
+	}
+	if _, ok := client.(ImageEditor); !ok {
+		client.Close()
+		return nil, p, fmt.Errorf("provider %s client does not implement image editing", p.ID)
+	}
+	return client, p, nil
+}
+
+// EditingProviders returns the IDs of the providers that support editing, sorted.
+func (r *ProviderRegistry) EditingProviders() []string {
+	var ids []string
+	for id, p := range r.providers {
+		if p.Capabilities.SupportsEditing {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// SelectProvider resolves input to a provider. An empty input or "auto" picks
+// a provider from the configured credentials: the only configured API, or
+// cfg.DefaultAPI (then gemini) when several are configured.
+func (r *ProviderRegistry) SelectProvider(input string, cfg *config.Config) (*Provider, error) {
+	if input != "" && !strings.EqualFold(input, AutoProvider) {
+		p, err := r.ResolveProvider(input)
+		if err != nil {
+			return nil, fmt.Errorf("%w\nRun 'gimage generate --list-providers' to see available providers", err)
+		}
+		return p, nil
+	}
+
+	// Find the APIs with working credentials
+	var configured []*Provider
+	for _, api := range apiPreference {
+		p, err := r.DefaultProviderForAPI(api)
+		if err != nil {
+			continue
+		}
+		if hasAuth, _ := r.checkAuth(p, cfg); hasAuth {
+			configured = append(configured, p)
+		}
+	}
+
+	if len(configured) == 0 {
+		return nil, fmt.Errorf("no API credentials found. Please set up credentials using:\n" +
+			"  Gemini:  gimage auth gemini\n" +
+			"  Vertex:  gimage auth vertex\n" +
+			"  Bedrock: gimage auth bedrock\n" +
+			"  OpenAI:  gimage auth setup openai/images\n" +
+			"  SD:      gimage auth setup sd/a1111")
+	}
+
+	selected := configured[0]
+	if len(configured) > 1 {
+		for _, p := range configured {
+			if p.API == cfg.DefaultAPI {
+				selected = p
+				break
+			}
+		}
+	}
+
+	// Honor the configured default model when it belongs to the selected API
+	if p, err := r.ResolveProvider(cfg.DefaultModel); err == nil && p.API == selected.API {
+		selected = p
+	}
+
+	return selected, nil
+}
+
+// DefaultProviderForAPI returns the provider used when only the API is known
+func (r *ProviderRegistry) DefaultProviderForAPI(api string) (*Provider, error) {
+	providerID, ok := defaultProviders[api]
+	if !ok {
+		return nil, fmt.Errorf("invalid API: %s (must be 'gemini', 'vertex', 'bedrock', 'openai', or 'sd')", api)
+	}
+	return r.Get(providerID)
 }
 
 // AuthStatus represents the authentication status of a provider
@@ -616,19 +992,31 @@ func (r *ProviderRegistry) ResolveProvider(input string) (*Provider, error) {
 	// Try common aliases
 	input = strings.ToLower(input)
 	aliases := map[string]string{
-		"gemini":       "gemini/flash-2.5",
-		"gemini-flash": "gemini/flash-2.5",
-		"flash":        "gemini/flash-2.5",
-		"imagen":       "vertex/imagen-4",
-		"imagen-4":     "vertex/imagen-4",
-		"nova":         "bedrock/nova-canvas",
-		"nova-canvas":  "bedrock/nova-canvas",
+		"gemini":        "gemini/flash-2.5",
+		"gemini-flash":  "gemini/flash-2.5",
+		"flash":         "gemini/flash-2.5",
+		"imagen":        "vertex/imagen-4",
+		"imagen-4":      "vertex/imagen-4",
+		"nova":          "bedrock/nova-canvas",
+		"nova-canvas":   "bedrock/nova-canvas",
+		"openai":        "openai/images",
+		"a1111":         "sd/a1111",
+		"automatic1111": "sd/a1111",
+		"comfyui":       "sd/comfyui",
+		"mock":          "local/mock",
 	}
 
 	if providerID, ok := aliases[input]; ok {
 		return r.Get(providerID)
 	}
 
+	// Try API model IDs (e.g., "gemini-2.5-flash-image", "amazon.nova-canvas-v1:0")
+	for _, p := range r.providers {
+		if strings.ToLower(p.ModelID) == input {
+			return p, nil
+		}
+	}
+
 	return nil, fmt.Errorf("no provider found for: %s", input)
 }
 
@@ -676,4 +1064,4 @@ func ValidateModelForAPI(modelName, api string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,176 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apresai/gimage/internal/config"
+)
+
+func TestResolveProvider(t *testing.T) {
+	registry := NewProviderRegistry()
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "bedrock/nova-canvas", want: "bedrock/nova-canvas"},
+		{input: "nova-canvas", want: "bedrock/nova-canvas"},
+		{input: "Gemini", want: "gemini/flash-2.5"},
+		{input: "gemini-2.5-flash-image", want: "gemini/flash-2.5"},
+		{input: "amazon.nova-canvas-v1:0", want: "bedrock/nova-canvas"},
+		{input: "imagen-3.0-generate-002", want: "vertex/imagen-3"},
+		{input: "dall-e-3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p, err := registry.ResolveProvider(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveProvider(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && p.ID != tt.want {
+				t.Errorf("ResolveProvider(%q) = %s, want %s", tt.input, p.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectAPIFromModel_Bedrock(t *testing.T) {
+	api, err := DetectAPIFromModel("amazon.nova-canvas-v1:0")
+	if err != nil {
+		t.Fatalf("DetectAPIFromModel() error = %v", err)
+	}
+	if api != "bedrock" {
+		t.Errorf("DetectAPIFromModel() = %s, want bedrock", api)
+	}
+}
+
+// clearCredentialEnv unsets env vars gatherCredentials falls back to
+func clearCredentialEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{
+		"GEMINI_API_KEY", "VERTEX_PROJECT", "VERTEX_LOCATION", "VERTEX_API_KEY",
+		"AWS_REGION", "AWS_BEDROCK_API_KEY", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY",
+		"OPENAI_API_KEY", "OPENAI_BASE_URL", "OPENAI_IMAGE_MODEL",
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestSelectProvider(t *testing.T) {
+	clearCredentialEnv(t)
+	registry := NewProviderRegistry()
+
+	tests := []struct {
+		name    string
+		input   string
+		cfg     config.Config
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "explicit alias ignores credentials",
+			input: "imagen-4",
+			want:  "vertex/imagen-4",
+		},
+		{
+			name:  "auto with only vertex configured",
+			input: AutoProvider,
+			cfg:   config.Config{VertexProject: "my-project", VertexLocation: "us-central1", DefaultAPI: "gemini"},
+			want:  "vertex/imagen-4",
+		},
+		{
+			name:  "empty input uses default api when several are configured",
+			input: "",
+			cfg:   config.Config{GeminiAPIKey: "key", AWSRegion: "us-east-1", AWSProfile: "default", DefaultAPI: "bedrock"},
+			want:  "bedrock/nova-canvas",
+		},
+		{
+			name:  "default model within selected api",
+			input: "auto",
+			cfg:   config.Config{VertexProject: "p", VertexLocation: "us-central1", DefaultAPI: "vertex", DefaultModel: "imagen-3.0-generate-002"},
+			want:  "vertex/imagen-3",
+		},
+		{
+			name:  "default api sd with a1111 configured",
+			input: AutoProvider,
+			cfg:   config.Config{GeminiAPIKey: "key", A1111BaseURL: "http://gpu-box:7860", DefaultAPI: "sd"},
+			want:  "sd/a1111",
+		},
+		{
+			name:    "auto without credentials",
+			input:   AutoProvider,
+			wantErr: true,
+		},
+		{
+			name:    "unknown provider",
+			input:   "dall-e-3",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := registry.SelectProvider(tt.input, &tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SelectProvider(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && p.ID != tt.want {
+				t.Errorf("SelectProvider(%q) = %s, want %s", tt.input, p.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveClient_MissingCredentials(t *testing.T) {
+	clearCredentialEnv(t)
+	registry := NewProviderRegistry()
+
+	_, p, err := registry.ResolveClient("nova-canvas", &config.Config{AWSRegion: "us-east-1"})
+	if err == nil {
+		t.Fatal("expected error for missing credentials")
+	}
+	if p == nil || p.ID != "bedrock/nova-canvas" {
+		t.Errorf("ResolveClient() should still report the selected provider, got %v", p)
+	}
+	if !strings.Contains(err.Error(), "gimage auth setup bedrock/nova-canvas") {
+		t.Errorf("error should include setup hint, got: %v", err)
+	}
+}
+
+func TestResolveEditor(t *testing.T) {
+	clearCredentialEnv(t)
+	registry := NewProviderRegistry()
+
+	// Fallback providers are ignored: edits are not failed over
+	cfg := &config.Config{FallbackProviders: []string{"gemini/flash-2.5"}}
+	client, p, err := registry.ResolveEditor("mock", cfg)
+	if err != nil {
+		t.Fatalf("ResolveEditor() error = %v", err)
+	}
+	defer client.Close()
+	if p.ID != "local/mock" {
+		t.Errorf("provider = %s, want local/mock", p.ID)
+	}
+	if _, ok := client.(ImageEditor); !ok {
+		t.Error("ResolveEditor() client should implement ImageEditor")
+	}
+
+	_, p, err = registry.ResolveEditor("imagen-4", &config.Config{})
+	if err == nil || !strings.Contains(err.Error(), "does not support image editing") {
+		t.Errorf("expected editing error for vertex/imagen-4, got %v", err)
+	}
+	if p == nil || p.ID != "vertex/imagen-4" {
+		t.Errorf("ResolveEditor() should report the selected provider, got %v", p)
+	}
+
+	_, p, err = registry.ResolveEditor("nova-canvas", &config.Config{AWSRegion: "us-east-1"})
+	if err == nil || !strings.Contains(err.Error(), "gimage auth setup bedrock/nova-canvas") {
+		t.Errorf("expected missing credentials error, got %v", err)
+	}
+	if p == nil || p.ID != "bedrock/nova-canvas" {
+		t.Errorf("ResolveEditor() should report the selected provider, got %v", p)
+	}
+}
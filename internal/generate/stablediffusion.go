@@ -0,0 +1,158 @@
+package generate
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"strings"
+
+	"github.com/apresai/gimage/pkg/models"
+)
+
+// Stable Diffusion defaults shared by the Automatic1111 and ComfyUI clients
+const (
+	sdDefaultSteps    = 20
+	sdDefaultCfgScale = 7.0
+	sdMaxSteps        = 150
+	sdMaxDimension    = 4096
+
+	// sdMaxImagesPerRequest caps batch size to keep GPU memory in check
+	sdMaxImagesPerRequest = 8
+)
+
+// Stable Diffusion keys read from GenerateOptions.Extra
+const (
+	SDOptionSampler   = "sampler"   // Sampler name, e.g. "Euler a" (A1111) or "euler" (ComfyUI)
+	SDOptionScheduler = "scheduler" // Scheduler, e.g. "normal" or "karras"
+	SDOptionSteps     = "steps"     // Sampling steps (1-150)
+	SDOptionCfgScale  = "cfg_scale" // Classifier-free guidance scale
+)
+
+// sdOptions are the provider options accepted by the sd/* providers
+var sdOptions = []ProviderOption{
+	{Name: SDOptionSampler, Type: OptionString, Description: "Sampler, e.g. \"Euler a\" (A1111) or \"euler\" (ComfyUI)"},
+	{Name: SDOptionScheduler, Type: OptionString, Description: "Noise schedule, e.g. \"karras\""},
+	{Name: SDOptionSteps, Type: OptionInt, Description: fmt.Sprintf("Sampling steps (default %d)", sdDefaultSteps), Min: 1, Max: sdMaxSteps},
+	{Name: SDOptionCfgScale, Type: OptionNumber, Description: fmt.Sprintf("Classifier-free guidance scale (default %g)", sdDefaultCfgScale), Min: 1, Max: 30},
+}
+
+// sdSettings are the sampling parameters for one Stable Diffusion request
+type sdSettings struct {
+	Prompt         string
+	NegativePrompt string
+	Width          int
+	Height         int
+	Seed           int64
+	Sampler        string
+	Scheduler      string
+	Steps          int
+	CfgScale       float64
+}
+
+// sdSettingsFromOptions maps GenerateOptions onto Stable Diffusion parameters.
+// A zero seed picks a random one so the seed used can always be reported.
+func sdSettingsFromOptions(prompt string, options models.GenerateOptions) (*sdSettings, error) {
+	if strings.TrimSpace(prompt) == "" {
+		return nil, fmt.Errorf("prompt cannot be empty")
+	}
+
+	width, height := parseDimensions(options.Size)
+	if width%8 != 0 || height%8 != 0 || width > sdMaxDimension || height > sdMaxDimension {
+		return nil, fmt.Errorf("invalid size: %dx%d (width and height must be multiples of 8, max %d)", width, height, sdMaxDimension)
+	}
+
+	if options.Seed < 0 {
+		return nil, fmt.Errorf("invalid seed: %d (must be positive)", options.Seed)
+	}
+	seed := options.Seed
+	if seed == 0 {
+		seed = rand.Int63n(1 << 32)
+	}
+
+	settings := &sdSettings{
+		Prompt:         buildPromptWithOptions(prompt, options),
+		NegativePrompt: options.NegativePrompt,
+		Width:          width,
+		Height:         height,
+		Seed:           seed,
+		Steps:          sdDefaultSteps,
+		CfgScale:       sdDefaultCfgScale,
+	}
+
+	var err error
+	if settings.Sampler, _, err = extraString(options, SDOptionSampler); err != nil {
+		return nil, err
+	}
+	if settings.Scheduler, _, err = extraString(options, SDOptionScheduler); err != nil {
+		return nil, err
+	}
+	if steps, ok, err := extraInt(options, SDOptionSteps); err != nil {
+		return nil, err
+	} else if ok {
+		if steps < 1 || steps > sdMaxSteps {
+			return nil, fmt.Errorf("invalid steps: %d (must be 1-%d)", steps, sdMaxSteps)
+		}
+		settings.Steps = steps
+	}
+	if cfgScale, ok, err := extraFloat(options, SDOptionCfgScale); err != nil {
+		return nil, err
+	} else if ok {
+		if cfgScale < 1 || cfgScale > 30 {
+			return nil, fmt.Errorf("invalid cfg_scale: %v (must be 1-30)", cfgScale)
+		}
+		settings.CfgScale = cfgScale
+	}
+
+	return settings, nil
+}
+
+// metadata returns the image metadata shared by both Stable Diffusion clients
+func (s *sdSettings) metadata(model, prompt string, seed int64) map[string]string {
+	metadata := map[string]string{
+		"model":     model,
+		"prompt":    prompt,
+		"size":      fmt.Sprintf("%dx%d", s.Width, s.Height),
+		"seed":      fmt.Sprintf("%d", seed),
+		"steps":     fmt.Sprintf("%d", s.Steps),
+		"cfg_scale": fmt.Sprintf("%g", s.CfgScale),
+		"api":       "sd",
+	}
+	if s.Sampler != "" {
+		metadata["sampler"] = s.Sampler
+	}
+	if s.Scheduler != "" {
+		metadata["scheduler"] = s.Scheduler
+	}
+	return metadata
+}
+
+// parseSDBaseURL validates a local server base URL such as http://gpu-box:7860
+func parseSDBaseURL(name, baseURL string) (string, error) {
+	if baseURL == "" {
+		return "", fmt.Errorf("%s base URL is required", name)
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", fmt.Errorf("invalid %s base URL: %s (expected e.g. http://localhost:7860)", name, baseURL)
+	}
+	return strings.TrimRight(baseURL, "/"), nil
+}
+
+// sdHTTPError provides user-friendly messages for local Stable Diffusion server errors
+func sdHTTPError(server string, statusCode int, body []byte) error {
+	message := strings.TrimSpace(string(body))
+	if len(message) > 500 {
+		message = message[:500] + "..."
+	}
+
+	switch {
+	case statusCode == 401 || statusCode == 403:
+		return fmt.Errorf("authentication failed (%d): %s\n\nTip: Check the %s credentials", statusCode, message, server)
+	case statusCode == 404:
+		return fmt.Errorf("not found (404): %s\n\nTip: Check the %s base URL (A1111 needs to be started with --api)", message, server)
+	case statusCode >= 500:
+		return fmt.Errorf("server error (%d): %s", statusCode, message)
+	default:
+		return fmt.Errorf("%s error %d: %s", server, statusCode, message)
+	}
+}
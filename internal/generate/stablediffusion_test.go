@@ -0,0 +1,75 @@
+package generate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/apresai/gimage/pkg/models"
+)
+
+func TestSDSettingsFromOptions(t *testing.T) {
+	settings, err := sdSettingsFromOptions("a castle", models.GenerateOptions{
+		Size:           "768x512",
+		Seed:           1234,
+		NegativePrompt: "blurry",
+		Style:          "anime",
+		Extra: map[string]any{
+			SDOptionSampler:  "DPM++ 2M",
+			SDOptionSteps:    "30",               // CLI strings
+			SDOptionCfgScale: json.Number("5.5"), // JSON numbers
+		},
+	})
+	if err != nil {
+		t.Fatalf("sdSettingsFromOptions() error = %v", err)
+	}
+
+	if settings.Width != 768 || settings.Height != 512 {
+		t.Errorf("size = %dx%d, want 768x512", settings.Width, settings.Height)
+	}
+	if settings.Seed != 1234 || settings.NegativePrompt != "blurry" {
+		t.Errorf("seed = %d, negative = %q", settings.Seed, settings.NegativePrompt)
+	}
+	if settings.Sampler != "DPM++ 2M" || settings.Steps != 30 || settings.CfgScale != 5.5 {
+		t.Errorf("sampler = %q, steps = %d, cfg_scale = %v", settings.Sampler, settings.Steps, settings.CfgScale)
+	}
+	if settings.Prompt != "a castle, anime style" {
+		t.Errorf("prompt = %q", settings.Prompt)
+	}
+}
+
+func TestSDSettingsFromOptions_Defaults(t *testing.T) {
+	settings, err := sdSettingsFromOptions("a castle", models.GenerateOptions{})
+	if err != nil {
+		t.Fatalf("sdSettingsFromOptions() error = %v", err)
+	}
+	if settings.Steps != sdDefaultSteps || settings.CfgScale != sdDefaultCfgScale {
+		t.Errorf("steps = %d, cfg_scale = %v, want defaults", settings.Steps, settings.CfgScale)
+	}
+	if settings.Seed <= 0 {
+		t.Errorf("seed = %d, want a random positive seed", settings.Seed)
+	}
+}
+
+func TestSDSettingsFromOptions_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompt  string
+		options models.GenerateOptions
+	}{
+		{name: "empty prompt", prompt: " "},
+		{name: "size not multiple of 8", prompt: "p", options: models.GenerateOptions{Size: "1000x1001"}},
+		{name: "negative seed", prompt: "p", options: models.GenerateOptions{Seed: -1}},
+		{name: "steps out of range", prompt: "p", options: models.GenerateOptions{Extra: map[string]any{SDOptionSteps: 500}}},
+		{name: "fractional steps", prompt: "p", options: models.GenerateOptions{Extra: map[string]any{SDOptionSteps: 20.5}}},
+		{name: "non-numeric cfg", prompt: "p", options: models.GenerateOptions{Extra: map[string]any{SDOptionCfgScale: "high"}}},
+		{name: "non-string sampler", prompt: "p", options: models.GenerateOptions{Extra: map[string]any{SDOptionSampler: 3}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := sdSettingsFromOptions(tt.prompt, tt.options); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/apresai/gimage/pkg/models"
@@ -72,8 +73,28 @@ func (c *VertexRESTClient) logVerbose(format string, args ...interface{}) {
 	}
 }
 
+// vertexMaxSampleCount is the most images Imagen returns from one predict call
+const vertexMaxSampleCount = 4
+
 // GenerateImage generates an image using Vertex AI REST API
 func (c *VertexRESTClient) GenerateImage(ctx context.Context, prompt string, options models.GenerateOptions) (*models.GeneratedImage, error) {
+	images, err := c.generate(ctx, prompt, options, 1)
+	if err != nil {
+		return nil, err
+	}
+	return images[0], nil
+}
+
+// GenerateImages generates options.Count images using sampleCount, split into
+// calls of at most four images
+func (c *VertexRESTClient) GenerateImages(ctx context.Context, prompt string, options models.GenerateOptions) ([]*models.GeneratedImage, error) {
+	return generateInBatches(options.Count, vertexMaxSampleCount, func(n int) ([]*models.GeneratedImage, error) {
+		return c.generate(ctx, prompt, options, n)
+	})
+}
+
+// generate runs one predict call for sampleCount images with circuit breaker and retry logic
+func (c *VertexRESTClient) generate(ctx context.Context, prompt string, options models.GenerateOptions, sampleCount int) ([]*models.GeneratedImage, error) {
 	// Validate prompt
 	if err := ValidatePrompt(prompt); err != nil {
 		return nil, err
@@ -82,6 +103,11 @@ func (c *VertexRESTClient) GenerateImage(ctx context.Context, prompt string, opt
 	// Enhance prompt for better results
 	enhancedPrompt := EnhancePrompt(prompt)
 
+	// Validate provider options before any request is sent
+	if _, err := imagenParameters(options); err != nil {
+		return nil, err
+	}
+
 	// Use custom model if provided
 	modelName := c.model
 	if options.Model != "" {
@@ -95,11 +121,11 @@ func (c *VertexRESTClient) GenerateImage(ctx context.Context, prompt string, opt
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 		// Execute through circuit breaker
 		result, err := c.circuitBreaker.Execute(func() (interface{}, error) {
-			return c.generateWithRetry(ctx, modelName, enhancedPrompt, options)
+			return c.generateWithRetry(ctx, modelName, enhancedPrompt, sampleCount, options)
 		})
 
 		if err == nil {
-			return result.(*models.GeneratedImage), nil
+			return result.([]*models.GeneratedImage), nil
 		}
 
 		lastErr = err
@@ -133,8 +159,40 @@ func (c *VertexRESTClient) GenerateImage(ctx context.Context, prompt string, opt
 	return nil, fmt.Errorf("failed after %d attempts: %w", maxRetries, lastErr)
 }
 
+// Imagen keys read from GenerateOptions.Extra, sent as-is in "parameters"
+const (
+	ImagenOptionPersonGeneration = "personGeneration" // dont_allow, allow_adult or allow_all
+	ImagenOptionSafetySetting    = "safetySetting"    // Safety filter threshold
+	ImagenOptionAddWatermark     = "addWatermark"     // SynthID watermark (must be false to use a seed)
+	ImagenOptionEnhancePrompt    = "enhancePrompt"    // LLM prompt rewriting
+)
+
+// imagenOptions are the provider options accepted by the vertex/imagen-* providers
+var imagenOptions = []ProviderOption{
+	{Name: ImagenOptionPersonGeneration, Type: OptionString, Description: "Whether people may be generated", Values: []string{"dont_allow", "allow_adult", "allow_all"}},
+	{Name: ImagenOptionSafetySetting, Type: OptionString, Description: "Safety filter threshold", Values: []string{"block_low_and_above", "block_medium_and_above", "block_only_high", "block_none"}},
+	{Name: ImagenOptionAddWatermark, Type: OptionBool, Description: "Add an invisible SynthID watermark (disable to use a seed)"},
+	{Name: ImagenOptionEnhancePrompt, Type: OptionBool, Description: "Let Imagen rewrite the prompt for better results"},
+}
+
+// imagenParameters converts Imagen provider options to request parameters
+func imagenParameters(options models.GenerateOptions) (map[string]interface{}, error) {
+	params := make(map[string]interface{})
+	for _, option := range imagenOptions {
+		if _, ok := options.Extra[option.Name]; !ok {
+			continue
+		}
+		value, err := option.convert(options.Extra[option.Name])
+		if err != nil {
+			return nil, err
+		}
+		params[option.Name] = value
+	}
+	return params, nil
+}
+
 // generateWithRetry performs a single generation attempt using REST API
-func (c *VertexRESTClient) generateWithRetry(ctx context.Context, modelName, prompt string, options models.GenerateOptions) (*models.GeneratedImage, error) {
+func (c *VertexRESTClient) generateWithRetry(ctx context.Context, modelName, prompt string, sampleCount int, options models.GenerateOptions) ([]*models.GeneratedImage, error) {
 	// Build the prompt with options
 	fullPrompt := buildPromptWithOptions(prompt, options)
 
@@ -142,34 +200,12 @@ func (c *VertexRESTClient) generateWithRetry(ctx context.Context, modelName, pro
 	c.logVerbose("Project: %s, Location: %s", c.projectID, c.location)
 	c.logVerbose("Full prompt: %s", fullPrompt)
 
-	// Determine aspect ratio from size
-	aspectRatio := "1:1" // Default
-	if options.Size != "" {
-		width, height := parseDimensions(options.Size)
-		c.logVerbose("Requested dimensions: %dx%d", width, height)
-
-		// Calculate aspect ratio
-		if width == height {
-			aspectRatio = "1:1"
-		} else if width > height {
-			ratio := float64(width) / float64(height)
-			if ratio >= 1.7 && ratio <= 1.9 {
-				aspectRatio = "16:9"
-			} else if ratio >= 1.4 && ratio <= 1.6 {
-				aspectRatio = "3:2"
-			} else {
-				aspectRatio = "4:3"
-			}
-		} else {
-			ratio := float64(height) / float64(width)
-			if ratio >= 1.7 && ratio <= 1.9 {
-				aspectRatio = "9:16"
-			} else if ratio >= 1.4 && ratio <= 1.6 {
-				aspectRatio = "2:3"
-			} else {
-				aspectRatio = "3:4"
-			}
-		}
+	// Use the requested aspect ratio, or the Imagen ratio closest to the size
+	width, height := parseDimensions(options.Size)
+	c.logVerbose("Requested dimensions: %dx%d", width, height)
+	aspectRatio := options.AspectRatio
+	if aspectRatio == "" {
+		aspectRatio = nearestSize(imagenSizes, float64(width)/float64(height), float64(width*height)).AspectRatio
 	}
 
 	c.logVerbose("Using aspect ratio: %s", aspectRatio)
@@ -184,11 +220,16 @@ func (c *VertexRESTClient) generateWithRetry(ctx context.Context, modelName, pro
 			},
 		},
 		"parameters": map[string]interface{}{
-			"sampleCount":  1,
+			"sampleCount":  sampleCount,
 			"aspectRatio":  aspectRatio,
 		},
 	}
 
+	// Imagen 4 renders 2K output on request
+	if strings.HasPrefix(modelName, "imagen-4") && (width > 1408 || height > 1408) {
+		requestBody["parameters"].(map[string]interface{})["sampleImageSize"] = "2K"
+	}
+
 	// Add negative prompt if provided
 	if options.NegativePrompt != "" {
 		requestBody["parameters"].(map[string]interface{})["negativePrompt"] = options.NegativePrompt
@@ -199,6 +240,15 @@ func (c *VertexRESTClient) generateWithRetry(ctx context.Context, modelName, pro
 		requestBody["parameters"].(map[string]interface{})["seed"] = options.Seed
 	}
 
+	// Add provider options (personGeneration, safetySetting, ...)
+	extraParams, err := imagenParameters(options)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range extraParams {
+		requestBody["parameters"].(map[string]interface{})[key] = value
+	}
+
 	// Marshal request to JSON
 	requestJSON, err := json.Marshal(requestBody)
 	if err != nil {
@@ -273,58 +323,59 @@ func (c *VertexRESTClient) generateWithRetry(ctx context.Context, modelName, pro
 		return nil, fmt.Errorf("no image generated from prompt")
 	}
 
-	prediction := response.Predictions[0]
-
-	// Check for base64 encoded image
-	var imageData []byte
-	var mimeType string
+	images := make([]*models.GeneratedImage, 0, len(response.Predictions))
+	for i, prediction := range response.Predictions {
+		// Check for base64 encoded image
+		if prediction.BytesBase64Encoded == "" {
+			c.logVerbose("No image data found in prediction %d", i)
+			continue
+		}
 
-	if prediction.BytesBase64Encoded != "" {
 		// Decode base64 image data
-		imageData, err = base64.StdEncoding.DecodeString(prediction.BytesBase64Encoded)
+		imageData, err := base64.StdEncoding.DecodeString(prediction.BytesBase64Encoded)
 		if err != nil {
 			c.logVerbose("Failed to decode base64: %v", err)
 			return nil, fmt.Errorf("failed to decode base64 image data: %w", err)
 		}
-		mimeType = prediction.MimeType
-		c.logVerbose("Successfully decoded image: %d bytes, mime=%s", len(imageData), mimeType)
-	} else {
-		c.logVerbose("No image data found in prediction")
-		return nil, fmt.Errorf("no image data found in response")
-	}
+		c.logVerbose("Successfully decoded image %d: %d bytes, mime=%s", i, len(imageData), prediction.MimeType)
 
-	// Determine format from MIME type
-	format := "png"
-	if mimeType != "" {
-		switch mimeType {
+		// Determine format from MIME type
+		format := "png"
+		switch prediction.MimeType {
 		case "image/jpeg":
 			format = "jpg"
-		case "image/png":
-			format = "png"
 		case "image/webp":
 			format = "webp"
 		}
+
+		// Report the size the model actually produced
+		imageWidth, imageHeight := imageDimensions(imageData, options.Size)
+
+		images = append(images, &models.GeneratedImage{
+			Data:   imageData,
+			Format: format,
+			Width:  imageWidth,
+			Height: imageHeight,
+			Metadata: map[string]string{
+				"model":    modelName,
+				"prompt":   prompt,
+				"style":    options.Style,
+				"api":      "imagen-genai",
+				"project":  c.projectID,
+				"location": c.location,
+			},
+		})
 	}
 
-	// Parse dimensions from options
-	width, height := parseDimensions(options.Size)
+	// Filtered predictions are dropped by the API, so fewer images than requested is possible
+	if len(images) == 0 {
+		c.logVerbose("No image data found in response")
+		return nil, fmt.Errorf("no image data found in response")
+	}
 
-	c.logVerbose("Successfully generated image: %d bytes, format=%s", len(imageData), format)
-
-	return &models.GeneratedImage{
-		Data:   imageData,
-		Format: format,
-		Width:  width,
-		Height: height,
-		Metadata: map[string]string{
-			"model":    modelName,
-			"prompt":   prompt,
-			"style":    options.Style,
-			"api":      "imagen-genai",
-			"project":  c.projectID,
-			"location": c.location,
-		},
-	}, nil
+	c.logVerbose("Successfully generated %d of %d images", len(images), sampleCount)
+
+	return images, nil
 }
 
 // handleHTTPError handles HTTP error responses from the API
@@ -87,6 +87,11 @@ func (c *VertexSDKClient) GenerateImage(ctx context.Context, prompt string, opti
 		return nil, err
 	}
 
+	// The SDK's GenerateContent call has no Imagen parameters
+	if len(options.Extra) > 0 {
+		return nil, fmt.Errorf("provider options require the Vertex REST API (set VERTEX_API_KEY)")
+	}
+
 	// Enhance prompt for better results
 	enhancedPrompt := EnhancePrompt(prompt)
 
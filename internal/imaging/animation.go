@@ -0,0 +1,608 @@
+// Package imaging provides image processing operations using pure Go.
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/webp"
+)
+
+// disposal says what happens to a frame's area of the canvas once the
+// frame has been shown
+type disposal int
+
+const (
+	disposeNone       disposal = iota // leave the frame on the canvas
+	disposeBackground                 // clear the frame's area to transparent
+	disposePrevious                   // restore the canvas from before the frame (GIF only)
+)
+
+// animationFrame is one frame of an animation. Frames are coalesced: image
+// is the whole canvas as shown while the frame is displayed, so operations
+// can treat every frame as a still image of the same size.
+type animationFrame struct {
+	image    *image.NRGBA
+	delay    time.Duration
+	disposal disposal      // source disposal, kept on encode
+	palette  color.Palette // source GIF palette, reused when writing GIF
+}
+
+// animation is a decoded animated GIF or WebP
+type animation struct {
+	format    string // "gif" or "webp"
+	frames    []animationFrame
+	loopCount int // times the animation plays, 0 = forever
+}
+
+// SupportsAnimation reports whether format can hold an animation (GIF and
+// WebP). Animated sources written in other formats keep one frame.
+func SupportsAnimation(format string) bool {
+	switch normalizeFormat(format) {
+	case "gif", "webp":
+		return true
+	default:
+		return false
+	}
+}
+
+// CountFrames returns the number of frames in an animated GIF or WebP, or 1
+// for any other image. It reads only the container, not the pixels.
+func CountFrames(data []byte) int {
+	switch {
+	case bytes.HasPrefix(data, []byte("GIF8")):
+		return max(countGIFFrames(data), 1)
+	case isAnimatedWebP(data):
+		n := 0
+		forEachWebPChunk(data[12:], func(fourCC string, _ []byte) {
+			if fourCC == "ANMF" {
+				n++
+			}
+		})
+		return max(n, 1)
+	default:
+		return 1
+	}
+}
+
+// countGIFFrames counts the image descriptors in a GIF stream
+func countGIFFrames(data []byte) int {
+	if len(data) < 13 {
+		return 0
+	}
+	i := 13
+	if data[10]&0x80 != 0 {
+		i += 3 << (data[10]&0x07 + 1) // global color table
+	}
+
+	// skipSubBlocks returns the index after a chain of data sub-blocks
+	skipSubBlocks := func(i int) int {
+		for i < len(data) {
+			size := int(data[i])
+			i++
+			if size == 0 {
+				break
+			}
+			i += size
+		}
+		return i
+	}
+
+	n := 0
+	for i < len(data) {
+		switch data[i] {
+		case 0x2c: // image descriptor
+			n++
+			if i+10 >= len(data) {
+				return n
+			}
+			flags := data[i+9]
+			i += 10
+			if flags&0x80 != 0 {
+				i += 3 << (flags&0x07 + 1) // local color table
+			}
+			i = skipSubBlocks(i + 1) // LZW minimum code size, then image data
+		case 0x21: // extension
+			i = skipSubBlocks(i + 2)
+		default: // trailer
+			return n
+		}
+	}
+	return n
+}
+
+// decodeAnimation decodes every frame of an animated GIF or WebP. It
+// returns nil for other formats and for single-frame images.
+func decodeAnimation(data []byte) (*animation, error) {
+	var anim *animation
+	var err error
+	switch {
+	case bytes.HasPrefix(data, []byte("GIF8")):
+		anim, err = decodeGIFAnimation(data)
+	case isAnimatedWebP(data):
+		anim, err = decodeWebPAnimation(data)
+	}
+	if err != nil || anim == nil || len(anim.frames) < 2 {
+		return nil, err
+	}
+	return anim, nil
+}
+
+// decodeGIFAnimation decodes a GIF, coalescing its frames
+func decodeGIFAnimation(data []byte) (*animation, error) {
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// image/gif counts repeats after the first play, -1 meaning none
+	anim := &animation{format: "gif"}
+	if g.LoopCount > 0 {
+		anim.loopCount = g.LoopCount + 1
+	} else if g.LoopCount < 0 {
+		anim.loopCount = 1
+	}
+
+	canvas := image.NewNRGBA(image.Rect(0, 0, g.Config.Width, g.Config.Height))
+	for i, frame := range g.Image {
+		rect := frame.Bounds().Intersect(canvas.Bounds())
+		var saved *image.NRGBA
+		var dispose byte
+		if i < len(g.Disposal) {
+			dispose = g.Disposal[i]
+		}
+		if dispose == gif.DisposalPrevious {
+			saved = imaging.Clone(canvas)
+		}
+
+		draw.Draw(canvas, rect, frame, rect.Min, draw.Over)
+		f := animationFrame{
+			image:   imaging.Clone(canvas),
+			delay:   time.Duration(g.Delay[i]) * 10 * time.Millisecond,
+			palette: frame.Palette,
+		}
+
+		switch dispose {
+		case gif.DisposalBackground:
+			f.disposal = disposeBackground
+			draw.Draw(canvas, rect, image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			f.disposal = disposePrevious
+			canvas = saved
+		}
+		anim.frames = append(anim.frames, f)
+	}
+	return anim, nil
+}
+
+// isAnimatedWebP reports whether data is a WebP with the animation flag set
+func isAnimatedWebP(data []byte) bool {
+	return len(data) >= 30 && string(data[:4]) == "RIFF" && string(data[8:16]) == "WEBPVP8X" && data[20]&0x02 != 0
+}
+
+// forEachWebPChunk calls fn for each RIFF chunk in data, stopping at the
+// first truncated chunk
+func forEachWebPChunk(data []byte, fn func(fourCC string, payload []byte)) {
+	for i := 0; i+8 <= len(data); {
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		if size < 0 || i+8+size > len(data) {
+			return
+		}
+		fn(string(data[i:i+4]), data[i+8:i+8+size])
+		i += 8 + size + size&1
+	}
+}
+
+// decodeWebPAnimation decodes an animated WebP, coalescing its frames
+func decodeWebPAnimation(data []byte) (*animation, error) {
+	anim := &animation{format: "webp"}
+	var canvas *image.NRGBA
+	var err error
+	forEachWebPChunk(data[12:], func(fourCC string, payload []byte) {
+		if err != nil {
+			return
+		}
+		switch fourCC {
+		case "VP8X":
+			if len(payload) < 10 {
+				err = fmt.Errorf("invalid animated WebP: short VP8X chunk")
+				return
+			}
+			width := 1 + int(uint24(payload[4:]))
+			height := 1 + int(uint24(payload[7:]))
+			canvas = image.NewNRGBA(image.Rect(0, 0, width, height))
+		case "ANIM":
+			if len(payload) >= 6 {
+				anim.loopCount = int(binary.LittleEndian.Uint16(payload[4:]))
+			}
+		case "ANMF":
+			if canvas == nil || len(payload) < 16 {
+				err = fmt.Errorf("invalid animated WebP: malformed frame")
+				return
+			}
+			x, y := 2*int(uint24(payload[0:])), 2*int(uint24(payload[3:]))
+			rect := image.Rect(x, y, x+1+int(uint24(payload[6:])), y+1+int(uint24(payload[9:])))
+			if !rect.In(canvas.Bounds()) {
+				err = fmt.Errorf("invalid animated WebP: frame %v outside canvas %v", rect, canvas.Bounds())
+				return
+			}
+			var frame image.Image
+			if frame, err = decodeWebPFrame(payload[16:], rect.Dx(), rect.Dy()); err != nil {
+				return
+			}
+
+			flags := payload[15]
+			op := draw.Over
+			if flags&0x02 != 0 {
+				op = draw.Src // no blending
+			}
+			draw.Draw(canvas, rect, frame, frame.Bounds().Min, op)
+			f := animationFrame{
+				image: imaging.Clone(canvas),
+				delay: time.Duration(uint24(payload[12:])) * time.Millisecond,
+			}
+			if flags&0x01 != 0 {
+				f.disposal = disposeBackground
+				draw.Draw(canvas, rect, image.Transparent, image.Point{}, draw.Src)
+			}
+			anim.frames = append(anim.frames, f)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(anim.frames) == 0 {
+		return nil, fmt.Errorf("invalid animated WebP: no frames")
+	}
+	return anim, nil
+}
+
+// decodeWebPFrame decodes the image chunks of an ANMF frame by wrapping
+// them as a still WebP
+func decodeWebPFrame(chunks []byte, width, height int) (image.Image, error) {
+	var alph, bitstream []byte
+	var lossless bool
+	forEachWebPChunk(chunks, func(fourCC string, payload []byte) {
+		switch fourCC {
+		case "ALPH":
+			alph = payload
+		case "VP8 ":
+			bitstream = payload
+		case "VP8L":
+			bitstream, lossless = payload, true
+		}
+	})
+	if bitstream == nil {
+		return nil, fmt.Errorf("invalid animated WebP: frame has no image data")
+	}
+
+	var body bytes.Buffer
+	switch {
+	case lossless:
+		writeRIFFChunk(&body, "VP8L", bitstream)
+	case alph != nil:
+		writeRIFFChunk(&body, "VP8X", webpVP8X(0x10, width, height))
+		writeRIFFChunk(&body, "ALPH", alph)
+		writeRIFFChunk(&body, "VP8 ", bitstream)
+	default:
+		writeRIFFChunk(&body, "VP8 ", bitstream)
+	}
+	img, err := webp.Decode(bytes.NewReader(webpFile(body.Bytes())))
+	if err != nil {
+		return nil, err
+	}
+	return webpToNRGBA(img), nil
+}
+
+// mapFrames returns a copy of the animation with f applied to every frame
+func (a *animation) mapFrames(f func(image.Image) *image.NRGBA) *animation {
+	out := &animation{format: a.format, loopCount: a.loopCount, frames: make([]animationFrame, len(a.frames))}
+	for i, frame := range a.frames {
+		frame.image = f(frame.image)
+		out.frames[i] = frame
+	}
+	return out
+}
+
+// encodeGIFAnimation writes an animation as GIF. Frames after the first
+// shrink to the area that changed when the previous frame stays on the
+// canvas, and pixels less than half opaque become transparent.
+func encodeGIFAnimation(w io.Writer, anim *animation) error {
+	bounds := anim.frames[0].image.Bounds()
+	paletted := make([]*image.Paletted, len(anim.frames))
+	for i, frame := range anim.frames {
+		paletted[i] = palettedFrame(frame.image, frame.palette)
+	}
+
+	out := &gif.GIF{
+		Config: image.Config{Width: bounds.Dx(), Height: bounds.Dy()},
+	}
+	switch {
+	case anim.loopCount == 1:
+		out.LoopCount = -1
+	case anim.loopCount > 1:
+		out.LoopCount = anim.loopCount - 1
+	}
+
+	var previous *image.Paletted // frame left on the canvas, nil once cleared
+	for i, frame := range anim.frames {
+		// Frames are whole canvases, so restoring the previous canvas
+		// and clearing render the same; clearing is also needed when
+		// the next frame turns pixels of this one transparent
+		dispose := byte(gif.DisposalNone)
+		if frame.disposal != disposeNone || (i+1 < len(paletted) && uncovers(paletted[i], paletted[i+1])) {
+			dispose = gif.DisposalBackground
+		}
+
+		img := paletted[i]
+		if previous != nil {
+			img = img.SubImage(changedBounds(previous, img)).(*image.Paletted)
+		}
+		out.Image = append(out.Image, img)
+		out.Delay = append(out.Delay, int(frame.delay/(10*time.Millisecond)))
+		out.Disposal = append(out.Disposal, dispose)
+
+		previous = nil
+		if dispose == gif.DisposalNone {
+			previous = paletted[i]
+		}
+	}
+	return gif.EncodeAll(w, out)
+}
+
+// palettedFrame converts a frame to at most 256 colors for GIF, using the
+// opaque colors of palette when given or a palette built from the frame
+// otherwise. Pixels less than half opaque map to a transparent entry.
+func palettedFrame(img *image.NRGBA, palette color.Palette) *image.Paletted {
+	var opaque []color.NRGBA
+	for _, c := range palette {
+		if n := color.NRGBAModel.Convert(c).(color.NRGBA); n.A == 0xff {
+			opaque = append(opaque, n)
+		}
+	}
+
+	transparent := false
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] < 0x80 {
+			transparent = true
+			break
+		}
+	}
+	maxColors := 256
+	if transparent {
+		maxColors = 255
+	}
+	if len(opaque) == 0 {
+		opaque = medianCut(img, maxColors)
+	}
+	opaque = opaque[:min(len(opaque), maxColors)]
+
+	pal := make(color.Palette, 0, len(opaque)+1)
+	for _, c := range opaque {
+		pal = append(pal, c)
+	}
+	transparentIndex := uint8(len(pal))
+	if transparent || len(pal) == 0 {
+		pal = append(pal, color.NRGBA{})
+	}
+
+	bounds := img.Bounds()
+	out := image.NewPaletted(bounds, pal)
+	nearest := make(map[[3]uint8]uint8)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			i := img.PixOffset(x, y)
+			px := img.Pix[i : i+4 : i+4]
+			if px[3] < 0x80 {
+				out.SetColorIndex(x, y, transparentIndex)
+				continue
+			}
+			key := [3]uint8{px[0], px[1], px[2]}
+			index, ok := nearest[key]
+			if !ok {
+				index = nearestColor(opaque, key)
+				nearest[key] = index
+			}
+			out.SetColorIndex(x, y, index)
+		}
+	}
+	return out
+}
+
+// nearestColor returns the index of the palette color closest to rgb
+func nearestColor(palette []color.NRGBA, rgb [3]uint8) uint8 {
+	best, bestDist := 0, -1
+	for i, c := range palette {
+		dr := int(c.R) - int(rgb[0])
+		dg := int(c.G) - int(rgb[1])
+		db := int(c.B) - int(rgb[2])
+		if dist := dr*dr + dg*dg + db*db; bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return uint8(best)
+}
+
+// medianCut builds a palette of at most n colors for the mostly opaque
+// pixels of img by repeatedly splitting the most populated box of colors
+// along its widest channel
+func medianCut(img *image.NRGBA, n int) []color.NRGBA {
+	type entry struct {
+		rgb   [3]uint8
+		count int
+	}
+	counts := make(map[[3]uint8]int)
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		if img.Pix[i+3] >= 0x80 {
+			// 5 bits per channel keep the histogram small
+			counts[[3]uint8{img.Pix[i] &^ 7, img.Pix[i+1] &^ 7, img.Pix[i+2] &^ 7}]++
+		}
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	entries := make([]entry, 0, len(counts))
+	for rgb, count := range counts {
+		entries = append(entries, entry{rgb, count})
+	}
+
+	boxes := [][]entry{entries}
+	for len(boxes) < n {
+		// Split the box holding the most pixels that has more than one color
+		split, splitCount := -1, 0
+		for i, box := range boxes {
+			total := 0
+			for _, e := range box {
+				total += e.count
+			}
+			if len(box) > 1 && total > splitCount {
+				split, splitCount = i, total
+			}
+		}
+		if split < 0 {
+			break
+		}
+
+		box := boxes[split]
+		channel, widest := 0, -1
+		for c := 0; c < 3; c++ {
+			lo, hi := 255, 0
+			for _, e := range box {
+				lo, hi = min(lo, int(e.rgb[c])), max(hi, int(e.rgb[c]))
+			}
+			if hi-lo > widest {
+				channel, widest = c, hi-lo
+			}
+		}
+		sort.Slice(box, func(i, j int) bool { return box[i].rgb[channel] < box[j].rgb[channel] })
+
+		// Cut where half the pixels fall on each side
+		half, seen, cut := splitCount/2, 0, 1
+		for i, e := range box[:len(box)-1] {
+			seen += e.count
+			if seen >= half {
+				cut = i + 1
+				break
+			}
+		}
+		boxes[split] = box[:cut]
+		boxes = append(boxes, box[cut:])
+	}
+
+	palette := make([]color.NRGBA, len(boxes))
+	for i, box := range boxes {
+		var r, g, b, total int
+		for _, e := range box {
+			r += (int(e.rgb[0]) + 4) * e.count
+			g += (int(e.rgb[1]) + 4) * e.count
+			b += (int(e.rgb[2]) + 4) * e.count
+			total += e.count
+		}
+		palette[i] = color.NRGBA{uint8(r / total), uint8(g / total), uint8(b / total), 0xff}
+	}
+	return palette
+}
+
+// uncovers reports whether next has transparent pixels where cur is opaque,
+// which drawing next over cur cannot show
+func uncovers(cur, next *image.Paletted) bool {
+	for i, index := range next.Pix {
+		if paletteAlpha(next.Palette, index) == 0 && paletteAlpha(cur.Palette, cur.Pix[i]) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// paletteAlpha returns the alpha of a palette entry
+func paletteAlpha(p color.Palette, index uint8) uint32 {
+	_, _, _, a := p[index].RGBA()
+	return a
+}
+
+// changedBounds returns the smallest rectangle holding every pixel that
+// differs between two images of the same size, or a single pixel when they
+// are identical
+func changedBounds(prev, cur image.Image) image.Rectangle {
+	bounds := cur.Bounds()
+	changed := image.Rectangle{}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r1, g1, b1, a1 := prev.At(x, y).RGBA()
+			r2, g2, b2, a2 := cur.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				changed = changed.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if changed.Empty() {
+		return image.Rect(bounds.Min.X, bounds.Min.Y, bounds.Min.X+1, bounds.Min.Y+1)
+	}
+	return changed
+}
+
+// encodeWebPAnimation writes an animation as WebP, lossy at quality 1-100
+// or lossless at 0. Frames replace their area of the canvas rather than
+// blending, and after the first shrink to the area that changed when the
+// previous frame stays on the canvas.
+func encodeWebPAnimation(w io.Writer, anim *animation, quality int) error {
+	bounds := anim.frames[0].image.Bounds()
+	var frames bytes.Buffer
+	hasAlpha := false
+	var previous *image.NRGBA // frame left on the canvas, nil once cleared
+	for _, frame := range anim.frames {
+		rect := bounds
+		if previous != nil {
+			// Frame offsets are stored halved, so they must be even
+			rect = changedBounds(previous, frame.image)
+			rect.Min.X &^= 1
+			rect.Min.Y &^= 1
+		}
+		chunks, alpha, err := encodeWebPFrame(frame.image.SubImage(rect), quality)
+		if err != nil {
+			return err
+		}
+		hasAlpha = hasAlpha || alpha
+
+		flags := byte(0x02) // do not blend
+		if frame.disposal != disposeNone {
+			flags |= 0x01 // dispose to background
+		}
+		header := make([]byte, 16)
+		putUint24(header[0:], uint32(rect.Min.X/2))
+		putUint24(header[3:], uint32(rect.Min.Y/2))
+		putUint24(header[6:], uint32(rect.Dx()-1))
+		putUint24(header[9:], uint32(rect.Dy()-1))
+		putUint24(header[12:], uint32(min(frame.delay.Milliseconds(), 1<<24-1)))
+		header[15] = flags
+		writeRIFFChunk(&frames, "ANMF", append(header, chunks...))
+
+		previous = nil
+		if frame.disposal == disposeNone {
+			previous = frame.image
+		}
+	}
+
+	var body bytes.Buffer
+	flags := byte(0x02) // animation
+	if hasAlpha {
+		flags |= 0x10
+	}
+	writeRIFFChunk(&body, "VP8X", webpVP8X(flags, bounds.Dx(), bounds.Dy()))
+	animChunk := make([]byte, 6) // transparent background
+	binary.LittleEndian.PutUint16(animChunk[4:], uint16(min(anim.loopCount, 1<<16-1)))
+	writeRIFFChunk(&body, "ANIM", animChunk)
+	body.Write(frames.Bytes())
+
+	_, err := w.Write(webpFile(body.Bytes()))
+	return err
+}
@@ -0,0 +1,279 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	animRed   = color.RGBA{255, 0, 0, 255}
+	animGreen = color.RGBA{0, 255, 0, 255}
+	animBlue  = color.RGBA{0, 0, 255, 255}
+	animWhite = color.RGBA{255, 255, 255, 255}
+)
+
+// animatedGIF returns a 40x30 three-frame GIF that plays three times:
+//   - frame 0 (100ms): white, with a red square at the top left
+//   - frame 1 (200ms): a green square in the middle, then cleared
+//   - frame 2 (300ms): a blue square at the top, then restored away
+func animatedGIF(t *testing.T) []byte {
+	t.Helper()
+	palette := color.Palette{color.RGBA{}, animRed, animGreen, animBlue, animWhite}
+	fill := func(rect image.Rectangle, index uint8) *image.Paletted {
+		img := image.NewPaletted(rect, palette)
+		for i := range img.Pix {
+			img.Pix[i] = index
+		}
+		return img
+	}
+
+	first := fill(image.Rect(0, 0, 40, 30), 4)
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			first.SetColorIndex(x, y, 1)
+		}
+	}
+	g := &gif.GIF{
+		Image: []*image.Paletted{
+			first,
+			fill(image.Rect(10, 10, 20, 20), 2),
+			fill(image.Rect(20, 0, 30, 10), 3),
+		},
+		Delay:     []int{10, 20, 30},
+		Disposal:  []byte{gif.DisposalNone, gif.DisposalBackground, gif.DisposalPrevious},
+		LoopCount: 2,
+		Config:    image.Config{Width: 40, Height: 30, ColorModel: palette},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, g))
+	return buf.Bytes()
+}
+
+// assertColor checks that the pixel at x, y is close to want
+func assertColor(t *testing.T, img image.Image, x, y int, want color.Color) {
+	t.Helper()
+	got := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+	w := color.NRGBAModel.Convert(want).(color.NRGBA)
+	if w.A == 0 {
+		assert.Less(t, got.A, uint8(0x80), "pixel (%d,%d) = %v, want transparent", x, y, got)
+		return
+	}
+	near := func(a, b uint8) bool { return int(a)-int(b) < 40 && int(b)-int(a) < 40 }
+	assert.True(t, near(got.R, w.R) && near(got.G, w.G) && near(got.B, w.B) && near(got.A, w.A),
+		"pixel (%d,%d) = %v, want %v", x, y, got, w)
+}
+
+func TestDecodeAnimation_GIFCoalescesFrames(t *testing.T) {
+	anim, err := decodeAnimation(animatedGIF(t))
+	require.NoError(t, err)
+	require.NotNil(t, anim)
+	require.Len(t, anim.frames, 3)
+	assert.Equal(t, 3, anim.loopCount)
+
+	assert.Equal(t, 100*time.Millisecond, anim.frames[0].delay)
+	assert.Equal(t, 300*time.Millisecond, anim.frames[2].delay)
+	assert.Equal(t, disposeBackground, anim.frames[1].disposal)
+	assert.Equal(t, disposePrevious, anim.frames[2].disposal)
+
+	assertColor(t, anim.frames[1].image, 15, 15, animGreen)
+	assertColor(t, anim.frames[1].image, 5, 5, animRed)
+	// Frame 1 was cleared, so frame 2 shows a hole in the middle
+	assertColor(t, anim.frames[2].image, 15, 15, color.Transparent)
+	assertColor(t, anim.frames[2].image, 25, 5, animBlue)
+	assertColor(t, anim.frames[2].image, 35, 25, animWhite)
+}
+
+func TestDecodeAnimation_Still(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, texturedImage(8, 8)))
+	anim, err := decodeAnimation(buf.Bytes())
+	require.NoError(t, err)
+	assert.Nil(t, anim)
+}
+
+func TestPipeline_AnimatedGIFResize(t *testing.T) {
+	p, err := DecodePipeline(animatedGIF(t))
+	require.NoError(t, err)
+	assert.Equal(t, 3, p.FrameCount())
+
+	data, err := p.Resize(20, 15).Bytes()
+	require.NoError(t, err)
+
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Len(t, g.Image, 3)
+	assert.Equal(t, []int{10, 20, 30}, g.Delay)
+	assert.Equal(t, 2, g.LoopCount)
+	assert.Equal(t, 20, g.Config.Width)
+	assert.Equal(t, 15, g.Config.Height)
+
+	anim, err := decodeAnimation(data)
+	require.NoError(t, err)
+	require.Len(t, anim.frames, 3)
+	assertColor(t, anim.frames[0].image, 2, 2, animRed)
+	assertColor(t, anim.frames[1].image, 7, 7, animGreen)
+	assertColor(t, anim.frames[2].image, 7, 7, color.Transparent)
+	assertColor(t, anim.frames[2].image, 12, 2, animBlue)
+	assertColor(t, anim.frames[2].image, 17, 12, animWhite)
+}
+
+func TestPipeline_AnimatedGIFCrop(t *testing.T) {
+	p, err := DecodePipeline(animatedGIF(t))
+	require.NoError(t, err)
+
+	data, err := p.Crop(10, 0, 20, 20).Bytes()
+	require.NoError(t, err)
+
+	anim, err := decodeAnimation(data)
+	require.NoError(t, err)
+	require.Len(t, anim.frames, 3)
+	assert.Equal(t, image.Rect(0, 0, 20, 20), anim.frames[0].image.Bounds())
+	assertColor(t, anim.frames[1].image, 5, 15, animGreen)
+	assertColor(t, anim.frames[2].image, 5, 15, color.Transparent)
+	assertColor(t, anim.frames[2].image, 15, 5, animBlue)
+}
+
+func TestPipeline_AnimatedGIFToWebP(t *testing.T) {
+	for _, quality := range []int{0, 80} {
+		p, err := DecodePipeline(animatedGIF(t))
+		require.NoError(t, err)
+		p.Convert("webp")
+		if quality > 0 {
+			p.Compress(quality)
+		}
+		data, err := p.Bytes()
+		require.NoError(t, err)
+		require.True(t, isAnimatedWebP(data), "quality %d", quality)
+		assert.Equal(t, 3, CountFrames(data))
+
+		anim, err := decodeAnimation(data)
+		require.NoError(t, err)
+		require.Len(t, anim.frames, 3)
+		assert.Equal(t, "webp", anim.format)
+		assert.Equal(t, 3, anim.loopCount)
+		assert.Equal(t, 200*time.Millisecond, anim.frames[1].delay)
+		assertColor(t, anim.frames[0].image, 5, 5, animRed)
+		assertColor(t, anim.frames[1].image, 15, 15, animGreen)
+		assertColor(t, anim.frames[2].image, 15, 15, color.Transparent)
+		assertColor(t, anim.frames[2].image, 25, 5, animBlue)
+		assertColor(t, anim.frames[2].image, 35, 25, animWhite)
+
+		// And back to GIF
+		back, err := DecodePipeline(data)
+		require.NoError(t, err)
+		gifData, err := back.Convert("gif").Bytes()
+		require.NoError(t, err)
+		g, err := gif.DecodeAll(bytes.NewReader(gifData))
+		require.NoError(t, err)
+		assert.Equal(t, []int{10, 20, 30}, g.Delay)
+	}
+}
+
+func TestPipeline_Frame(t *testing.T) {
+	p, err := DecodePipeline(animatedGIF(t))
+	require.NoError(t, err)
+
+	data, err := p.Frame(2).Convert("png").Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.FrameCount())
+	assert.Equal(t, []string{"frame:2", "convert:png"}, p.Steps())
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assertColor(t, img, 25, 5, animBlue)
+	assertColor(t, img, 15, 15, color.Transparent)
+
+	// Without Frame, still formats get the first frame
+	p, err = DecodePipeline(animatedGIF(t))
+	require.NoError(t, err)
+	assert.True(t, p.Animated())
+	data, err = p.Convert("png").Bytes()
+	require.NoError(t, err)
+	assert.False(t, p.Animated())
+	img, err = png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assertColor(t, img, 25, 5, animWhite)
+
+	p, err = DecodePipeline(animatedGIF(t))
+	require.NoError(t, err)
+	err = p.Frame(3).Err()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "out of range")
+
+	step, err := ParseStep("frame:1")
+	require.NoError(t, err)
+	assert.Equal(t, Step{Op: StepFrame, Frame: 1}, step)
+	_, err = ParseStep("frame:-1")
+	assert.Error(t, err)
+}
+
+func TestCountFrames(t *testing.T) {
+	assert.Equal(t, 3, CountFrames(animatedGIF(t)))
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, texturedImage(8, 8)))
+	assert.Equal(t, 1, CountFrames(buf.Bytes()))
+
+	buf.Reset()
+	require.NoError(t, gif.Encode(&buf, texturedImage(8, 8), nil))
+	assert.Equal(t, 1, CountFrames(buf.Bytes()))
+
+	assert.True(t, SupportsAnimation("GIF"))
+	assert.True(t, SupportsAnimation("webp"))
+	assert.False(t, SupportsAnimation("png"))
+}
+
+func TestResizeImage_AnimatedWithFrameOption(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, "banner.gif")
+	require.NoError(t, os.WriteFile(inputPath, animatedGIF(t), 0644))
+
+	// Animated output keeps every frame
+	outputPath := filepath.Join(tmpDir, "small.gif")
+	require.NoError(t, ResizeImage(context.Background(), inputPath, outputPath, 20, 15, FileOptions{}))
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, 3, CountFrames(data))
+
+	// Frame picks one
+	frame := 1
+	require.NoError(t, ResizeImage(context.Background(), inputPath, outputPath, 20, 15, FileOptions{Frame: &frame}))
+	data, err = os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, 1, CountFrames(data))
+
+	frame = 9
+	assert.Error(t, ResizeImage(context.Background(), inputPath, outputPath, 20, 15, FileOptions{Frame: &frame}))
+}
+
+func TestEncodeToSize_AnimatedKeepsFrames(t *testing.T) {
+	p, err := DecodePipeline(animatedGIF(t))
+	require.NoError(t, err)
+
+	full, err := p.Convert("webp").Compress(90).Bytes()
+	require.NoError(t, err)
+
+	p, err = DecodePipeline(animatedGIF(t))
+	require.NoError(t, err)
+	data, result, err := p.Convert("webp").EncodeToSize(SizeLimit{
+		MaxBytes:    int64(len(full)) - 1,
+		MinQuality:  90,
+		AllowResize: true,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Resized)
+	assert.Equal(t, 3, CountFrames(data))
+	assert.Equal(t, 3, p.FrameCount())
+}
@@ -0,0 +1,125 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeAVIF(t *testing.T) {
+	img := texturedImage(64, 48)
+
+	var low, high bytes.Buffer
+	require.NoError(t, encodeImageQuality(&low, img, "avif", 30))
+	require.NoError(t, encodeImageQuality(&high, img, "avif", 90))
+	assert.Less(t, low.Len(), high.Len())
+
+	decoded, format, err := image.Decode(bytes.NewReader(high.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, "avif", format)
+	assert.Equal(t, img.Bounds().Size(), decoded.Bounds().Size())
+
+	result, err := Compare(img, decoded)
+	require.NoError(t, err)
+	assert.Greater(t, result.SSIM, 0.9)
+}
+
+func TestEncodeAVIF_KeepsAlpha(t *testing.T) {
+	img := texturedImage(64, 48)
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 32; x++ {
+			img.Pix[img.PixOffset(x, y)+3] = 0
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, encodeImageQuality(&buf, img, "avif", 80))
+
+	decoded, _, err := image.Decode(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	_, _, _, a := decoded.At(10, 20).RGBA()
+	assert.Less(t, a, uint32(0x1000), "transparent half")
+	_, _, _, a = decoded.At(50, 20).RGBA()
+	assert.Greater(t, a, uint32(0xf000), "opaque half")
+}
+
+func TestPipeline_Speed(t *testing.T) {
+	p := NewPipeline(texturedImage(32, 32), "png").Convert("avif").Speed(10)
+	require.NoError(t, p.Err())
+	data, err := p.Bytes()
+	require.NoError(t, err)
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "avif", format)
+
+	err = NewPipeline(texturedImage(8, 8), "png").Speed(11).Err()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "speed must be between 1 and 10")
+}
+
+func TestDecodeHEIC(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "photo.heic"))
+	require.NoError(t, err)
+
+	p, err := DecodePipeline(data)
+	require.NoError(t, err)
+	assert.Equal(t, "heic", p.SourceFormat())
+	width, height := p.Size()
+	assert.Equal(t, 512, width)
+	assert.Equal(t, 512, height)
+
+	// HEIC converts to the common formats but cannot be written
+	converted, err := ConvertImageData(data, "jpg")
+	require.NoError(t, err)
+	_, format, err := image.DecodeConfig(bytes.NewReader(converted))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+
+	err = p.Convert("heic").Err()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported output format")
+}
+
+func TestExtractFormatFromPath_HEIFAndAVIF(t *testing.T) {
+	assert.Equal(t, "heic", ExtractFormatFromPath("IMG_0042.HEIC"))
+	assert.Equal(t, "heic", ExtractFormatFromPath("photo.heif"))
+	assert.Equal(t, "avif", ExtractFormatFromPath("photo.avif"))
+	assert.Equal(t, "avif", FormatExtension("AVIF"))
+}
+
+func TestSaveImageWithFormat_AVIF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.avif")
+	require.NoError(t, SaveImageWithFormat(texturedImage(40, 30), path, "avif"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "avif", format)
+	assert.Equal(t, 40, config.Width)
+}
+
+func TestCompressImage_AVIFSpeedOption(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, "in.png")
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, texturedImage(48, 48)))
+	require.NoError(t, os.WriteFile(inputPath, buf.Bytes(), 0644))
+
+	outputPath := filepath.Join(tmpDir, "out.avif")
+	require.NoError(t, CompressImage(context.Background(), inputPath, outputPath, 50, FileOptions{Speed: 10}))
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "avif", format)
+
+	assert.Error(t, CompressImage(context.Background(), inputPath, outputPath, 50, FileOptions{Speed: 42}))
+}
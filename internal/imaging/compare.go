@@ -0,0 +1,212 @@
+// Package imaging provides image processing operations using pure Go.
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"os"
+)
+
+// Comparison holds similarity metrics between two images of the same size
+type Comparison struct {
+	PSNR float64 // peak signal-to-noise ratio in dB over RGB (+Inf when identical)
+	SSIM float64 // mean structural similarity of the luma, 0-1 (1 = identical)
+}
+
+// ssimWindow is the Gaussian window of the reference SSIM implementation
+// (Wang et al. 2004): 11 taps, sigma 1.5.
+var ssimWindow = func() []float64 {
+	const radius, sigma = 5, 1.5
+	w := make([]float64, 2*radius+1)
+	var sum float64
+	for i := range w {
+		d := float64(i - radius)
+		w[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		sum += w[i]
+	}
+	for i := range w {
+		w[i] /= sum
+	}
+	return w
+}()
+
+// Compare computes PSNR and SSIM between two images of the same size
+func Compare(a, b image.Image) (Comparison, error) {
+	psnr, err := PSNR(a, b)
+	if err != nil {
+		return Comparison{}, err
+	}
+	ssim, err := SSIM(a, b)
+	if err != nil {
+		return Comparison{}, err
+	}
+	return Comparison{PSNR: psnr, SSIM: ssim}, nil
+}
+
+// CompareFiles decodes two image files and compares them
+func CompareFiles(pathA, pathB string) (Comparison, error) {
+	a, err := openImage(pathA)
+	if err != nil {
+		return Comparison{}, err
+	}
+	b, err := openImage(pathB)
+	if err != nil {
+		return Comparison{}, err
+	}
+	return Compare(a, b)
+}
+
+func openImage(path string) (image.Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image %s: %w", path, err)
+	}
+	img, _, err := decodeImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// PSNR returns the peak signal-to-noise ratio between two images in dB,
+// computed over the 8-bit R, G and B channels. Identical images return
+// +Inf. Typical lossy results range from 30 dB (visible loss) to 45 dB
+// (hard to tell apart).
+func PSNR(a, b image.Image) (float64, error) {
+	if err := sameSize(a, b); err != nil {
+		return 0, err
+	}
+	ab, bb := a.Bounds(), b.Bounds()
+	var sum float64
+	for y := 0; y < ab.Dy(); y++ {
+		for x := 0; x < ab.Dx(); x++ {
+			r1, g1, b1, _ := a.At(ab.Min.X+x, ab.Min.Y+y).RGBA()
+			r2, g2, b2, _ := b.At(bb.Min.X+x, bb.Min.Y+y).RGBA()
+			for _, d := range [3]float64{
+				float64(r1>>8) - float64(r2>>8),
+				float64(g1>>8) - float64(g2>>8),
+				float64(b1>>8) - float64(b2>>8),
+			} {
+				sum += d * d
+			}
+		}
+	}
+	mse := sum / float64(3*ab.Dx()*ab.Dy())
+	if mse == 0 {
+		return math.Inf(1), nil
+	}
+	return 10 * math.Log10(255*255/mse), nil
+}
+
+// SSIM returns the mean structural similarity index between two images,
+// from 0 (unrelated) to 1 (identical). It follows the reference method:
+// the luma of each image is averaged down so the smaller side is about 256
+// pixels, then compared in Gaussian-weighted 11x11 windows. Values above
+// about 0.98 are usually visually lossless.
+func SSIM(a, b image.Image) (float64, error) {
+	if err := sameSize(a, b); err != nil {
+		return 0, err
+	}
+	factor := ssimScale(a)
+	x, w, h := lumaPlane(a, factor)
+	y, _, _ := lumaPlane(b, factor)
+	return ssimIndex(x, y, w, h), nil
+}
+
+// ssimScale returns the downsampling factor SSIM applies to img
+func ssimScale(img image.Image) int {
+	size := img.Bounds().Size()
+	return max(1, int(math.Round(float64(min(size.X, size.Y))/256)))
+}
+
+// ssimIndex returns the mean SSIM of two luma planes of size w x h
+func ssimIndex(x, y []float64, w, h int) float64 {
+	xy := make([]float64, len(x))
+	xx := make([]float64, len(x))
+	yy := make([]float64, len(x))
+	for i := range x {
+		xy[i] = x[i] * y[i]
+		xx[i] = x[i] * x[i]
+		yy[i] = y[i] * y[i]
+	}
+	muX, muY := gaussianBlur(x, w, h), gaussianBlur(y, w, h)
+	sigmaXX, sigmaYY, sigmaXY := gaussianBlur(xx, w, h), gaussianBlur(yy, w, h), gaussianBlur(xy, w, h)
+
+	const (
+		c1 = (0.01 * 255) * (0.01 * 255)
+		c2 = (0.03 * 255) * (0.03 * 255)
+	)
+	var sum float64
+	for i := range x {
+		mx, my := muX[i], muY[i]
+		vx := sigmaXX[i] - mx*mx
+		vy := sigmaYY[i] - my*my
+		cov := sigmaXY[i] - mx*my
+		sum += ((2*mx*my + c1) * (2*cov + c2)) / ((mx*mx + my*my + c1) * (vx + vy + c2))
+	}
+	return sum / float64(len(x))
+}
+
+func sameSize(a, b image.Image) error {
+	as, bs := a.Bounds().Size(), b.Bounds().Size()
+	if as != bs {
+		return fmt.Errorf("images differ in size: %dx%d vs %dx%d", as.X, as.Y, bs.X, bs.Y)
+	}
+	if as.X == 0 || as.Y == 0 {
+		return fmt.Errorf("images are empty")
+	}
+	return nil
+}
+
+// lumaPlane returns the BT.601 luma of img averaged over factor x factor
+// blocks, with its dimensions
+func lumaPlane(img image.Image, factor int) ([]float64, int, int) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx()/factor, bounds.Dy()/factor
+	w, h = max(w, 1), max(h, 1)
+	plane := make([]float64, w*h)
+	scale := 1 / float64(factor*factor)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var sum float64
+			for dy := 0; dy < factor; dy++ {
+				for dx := 0; dx < factor; dx++ {
+					r, g, b, _ := img.At(bounds.Min.X+x*factor+dx, bounds.Min.Y+y*factor+dy).RGBA()
+					sum += 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
+				}
+			}
+			plane[y*w+x] = sum * scale
+		}
+	}
+	return plane, w, h
+}
+
+// gaussianBlur filters a plane with ssimWindow in both directions,
+// clamping at the edges
+func gaussianBlur(src []float64, w, h int) []float64 {
+	radius := len(ssimWindow) / 2
+	tmp := make([]float64, len(src))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var sum float64
+			for k, weight := range ssimWindow {
+				sx := min(max(x+k-radius, 0), w-1)
+				sum += weight * src[y*w+sx]
+			}
+			tmp[y*w+x] = sum
+		}
+	}
+	out := make([]float64, len(src))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var sum float64
+			for k, weight := range ssimWindow {
+				sy := min(max(y+k-radius, 0), h-1)
+				sum += weight * tmp[sy*w+x]
+			}
+			out[y*w+x] = sum
+		}
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noisyCopy returns img with every channel offset by a deterministic
+// pattern of amplitude amount
+func noisyCopy(img *image.NRGBA, amount int) *image.NRGBA {
+	out := image.NewNRGBA(img.Bounds())
+	copy(out.Pix, img.Pix)
+	for i := range out.Pix {
+		if i%4 == 3 {
+			continue
+		}
+		v := int(out.Pix[i]) + (i*7919%(2*amount+1) - amount)
+		out.Pix[i] = uint8(min(max(v, 0), 255))
+	}
+	return out
+}
+
+func TestCompare_Identical(t *testing.T) {
+	img := texturedImage(120, 80)
+	result, err := Compare(img, img)
+	require.NoError(t, err)
+	assert.True(t, math.IsInf(result.PSNR, 1))
+	assert.InDelta(t, 1.0, result.SSIM, 1e-9)
+}
+
+func TestCompare_Degraded(t *testing.T) {
+	img := texturedImage(120, 80)
+
+	slight, err := Compare(img, noisyCopy(img, 4))
+	require.NoError(t, err)
+	heavy, err := Compare(img, noisyCopy(img, 40))
+	require.NoError(t, err)
+
+	assert.Less(t, slight.SSIM, 1.0)
+	assert.Less(t, heavy.SSIM, slight.SSIM)
+	assert.Greater(t, slight.PSNR, 35.0)
+	assert.Less(t, heavy.PSNR, slight.PSNR)
+}
+
+func TestCompare_SizeMismatch(t *testing.T) {
+	_, err := Compare(texturedImage(10, 10), texturedImage(10, 12))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "differ in size")
+}
+
+func TestCompareFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	img := texturedImage(64, 64)
+	pngPath := filepath.Join(tmpDir, "a.png")
+	jpegPath := filepath.Join(tmpDir, "a.jpg")
+	require.NoError(t, NewPipeline(img, "png").Save(pngPath))
+	require.NoError(t, NewPipeline(img, "png").Compress(50).Save(jpegPath))
+
+	result, err := CompareFiles(pngPath, jpegPath)
+	require.NoError(t, err)
+	assert.Less(t, result.SSIM, 1.0)
+	assert.Greater(t, result.SSIM, 0.5)
+
+	_, err = CompareFiles(pngPath, filepath.Join(tmpDir, "missing.png"))
+	assert.Error(t, err)
+}
+
+func TestDecodeImage_LossyWebPColors(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+	want := color.NRGBA{R: 40, G: 90, B: 200, A: 255}
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = want.R, want.G, want.B, want.A
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, encodeImageQuality(&buf, img, "webp", 90))
+	decoded, format, err := decodeImage(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "webp", format)
+
+	got := color.NRGBAModel.Convert(decoded.At(16, 16)).(color.NRGBA)
+	assert.InDelta(t, want.R, got.R, 3)
+	assert.InDelta(t, want.G, got.G, 3)
+	assert.InDelta(t, want.B, got.B, 3)
+}
@@ -1,7 +1,6 @@
 package imaging
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"image"
@@ -14,8 +13,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/HugoSmits86/nativewebp"
-	"github.com/apresai/gimage/internal/progress"
 	"github.com/disintegration/imaging"
 	"golang.org/x/image/bmp"
 	"golang.org/x/image/tiff"
@@ -24,41 +21,49 @@ import (
 // ConvertImageData converts image data from one format to another.
 //
 // Parameters:
-//   - data: input image data (any supported format)
-//   - targetFormat: desired output format (png, jpg, jpeg, webp, gif, tiff, bmp)
+//   - data: input image data (any supported format, including HEIC/HEIF and AVIF)
+//   - targetFormat: desired output format (png, jpg, jpeg, webp, avif, gif, tiff, bmp)
 //
 // Returns converted image data and error if conversion fails.
 func ConvertImageData(data []byte, targetFormat string) ([]byte, error) {
-	if len(data) == 0 {
-		return nil, fmt.Errorf("input data is empty")
-	}
-
-	// Normalize target format
-	targetFormat = strings.ToLower(targetFormat)
-	targetFormat = strings.TrimPrefix(targetFormat, ".")
+	return ConvertImageDataWithQuality(data, targetFormat, 0)
+}
 
-	// Decode the input image
-	img, srcFormat, err := image.Decode(bytes.NewReader(data))
+// ConvertImageDataWithQuality converts image data to another format, encoding
+// it with the given quality.
+//
+// Parameters:
+//   - data: input image data (any supported format, including HEIC/HEIF and AVIF)
+//   - targetFormat: desired output format (png, jpg, jpeg, webp, avif, gif, tiff, bmp)
+//   - quality: 1-100 for JPEG, lossy WebP and AVIF, or 0 for the format
+//     default (JPEG quality 90, lossless WebP, AVIF quality 60)
+//
+// With quality 0, data already in the target format is returned unchanged;
+// otherwise it is re-encoded at the requested quality, upright and with its
+// ICC color profile.
+func ConvertImageDataWithQuality(data []byte, targetFormat string, quality int) ([]byte, error) {
+	// Decode the input image, turning it upright
+	p, err := DecodePipeline(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode image: %w", err)
+		return nil, err
 	}
 
-	// Normalize source format for comparison
-	normalizedSrcFormat := normalizeFormat(srcFormat)
-	normalizedTargetFormat := normalizeFormat(targetFormat)
-
-	// If formats match, return original data
-	if normalizedSrcFormat == normalizedTargetFormat {
+	// If formats match and no quality was requested, return original data
+	targetFormat = normalizeFormat(targetFormat)
+	if p.SourceFormat() == targetFormat && quality == 0 {
 		return data, nil
 	}
+	if quality < 0 || quality > 100 {
+		return nil, fmt.Errorf("quality must be between 1 and 100, got %d", quality)
+	}
 
-	// Convert format
-	var buf bytes.Buffer
-	if err := encodeImage(&buf, img, targetFormat); err != nil {
+	// Convert format, keeping the color profile
+	converted, err := p.encode(p.img, targetFormat, quality)
+	if err != nil {
 		return nil, fmt.Errorf("failed to encode image as %s: %w", targetFormat, err)
 	}
 
-	return buf.Bytes(), nil
+	return converted, nil
 }
 
 // ConvertImageFile converts an image file from one format to another.
@@ -67,51 +72,14 @@ func ConvertImageData(data []byte, targetFormat string) ([]byte, error) {
 //   - ctx: context for cancellation support
 //   - inputPath: path to input image
 //   - outputPath: path to save converted image
+//   - opts: file settings (see FileOptions)
 //
 // The output format is determined by the file extension of outputPath.
 // Progress reporting can be provided via context using progress.WithReporter.
-func ConvertImageFile(ctx context.Context, inputPath, outputPath string) error {
-	reporter := progress.FromContext(ctx)
+func ConvertImageFile(ctx context.Context, inputPath, outputPath string, opts FileOptions) error {
 	targetFormat := ExtractFormatFromPath(outputPath)
-	reporter.Start(ctx, fmt.Sprintf("Converting image to %s", targetFormat))
-
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
-	}
-
-	// Load the input image
-	reporter.Update(1, 3, "Loading input image")
-	img, err := imaging.Open(inputPath)
-	if err != nil {
-		err = fmt.Errorf("failed to open image %s: %w", inputPath, err)
-		reporter.Error(err)
-		return err
-	}
-
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
-	}
-
-	// Save with the target format
-	reporter.Update(2, 3, fmt.Sprintf("Converting to %s format", targetFormat))
-	if err := SaveImageWithFormat(img, outputPath, targetFormat); err != nil {
-		reporter.Error(err)
-		return err
-	}
-
-	reporter.Update(3, 3, "Conversion complete")
-	reporter.Complete(outputPath)
-	return nil
+	return processFile(ctx, inputPath, outputPath, opts, fmt.Sprintf("Converting image to %s", targetFormat), "converted",
+		Step{Op: StepConvert, Format: targetFormat})
 }
 
 // SaveImageWithFormat saves an image to a file with explicit format specification.
@@ -124,8 +92,8 @@ func SaveImageWithFormat(img image.Image, path string, format string) error {
 		img = removeTransparency(img)
 	}
 
-	// Handle WebP encoding with nativewebp
-	if format == "webp" {
+	// WebP (lossless) and AVIF are encoded here; imaging.Save knows neither
+	if format == "webp" || format == "avif" {
 		absPath, err := filepath.Abs(path)
 		if err != nil {
 			return fmt.Errorf("failed to get absolute path: %w", err)
@@ -142,10 +110,7 @@ func SaveImageWithFormat(img image.Image, path string, format string) error {
 		}
 		defer outFile.Close()
 
-		// nativewebp provides lossless VP8L encoding
-		return nativewebp.Encode(outFile, img, &nativewebp.Options{
-			UseExtendedFormat: false, // Basic VP8L format, no metadata
-		})
+		return encodeImage(outFile, img, format)
 	}
 
 	// Use imaging package's Save which handles format detection
@@ -173,6 +138,14 @@ func ExtractFormatFromPath(path string) string {
 
 // encodeImage encodes an image to a specific format
 func encodeImage(w io.Writer, img image.Image, format string) error {
+	return encodeImageQuality(w, img, format, 0)
+}
+
+// encodeImageQuality encodes an image to a specific format. quality (1-100)
+// applies to JPEG, where 0 selects the default of 90, to WebP, where 0
+// selects lossless encoding and any other value lossy, and to AVIF, where 0
+// selects DefaultAVIFQuality. Lossless formats ignore it.
+func encodeImageQuality(w io.Writer, img image.Image, format string, quality int) error {
 	format = strings.ToLower(format)
 
 	// Handle transparency for formats that don't support it
@@ -185,17 +158,20 @@ func encodeImage(w io.Writer, img image.Image, format string) error {
 		return png.Encode(w, img)
 
 	case "jpg", "jpeg":
-		// Use 90% quality for JPEG
-		return jpeg.Encode(w, img, &jpeg.Options{Quality: 90})
+		// Use 90% quality for JPEG unless asked otherwise
+		if quality <= 0 {
+			quality = 90
+		}
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
 
 	case "gif":
 		return gif.Encode(w, img, &gif.Options{NumColors: 256})
 
 	case "webp":
-		// Use nativewebp for pure Go lossless WebP encoding (VP8L)
-		return nativewebp.Encode(w, img, &nativewebp.Options{
-			UseExtendedFormat: false, // Basic VP8L format
-		})
+		return encodeWebP(w, img, quality)
+
+	case "avif":
+		return encodeAVIF(w, img, quality, 0)
 
 	case "tiff", "tif":
 		return tiff.Encode(w, img, &tiff.Options{Compression: tiff.Deflate})
@@ -219,6 +195,8 @@ func normalizeFormat(format string) string {
 		return "jpeg"
 	case "tif":
 		return "tiff"
+	case "heic", "heif":
+		return "heic"
 	default:
 		return format
 	}
@@ -228,7 +206,7 @@ func normalizeFormat(format string) string {
 func supportsTransparency(format string) bool {
 	format = normalizeFormat(format)
 	switch format {
-	case "png", "gif", "webp":
+	case "png", "gif", "webp", "avif":
 		return true
 	default:
 		return false
@@ -4,9 +4,7 @@ package imaging
 import (
 	"context"
 	"fmt"
-	"image"
 
-	"github.com/apresai/gimage/internal/progress"
 	"github.com/disintegration/imaging"
 )
 
@@ -18,6 +16,7 @@ import (
 //   - outputPath: path to save cropped image
 //   - x, y: top-left corner coordinates of the crop region
 //   - width, height: dimensions of crop region
+//   - opts: file settings (see FileOptions)
 //
 // Progress reporting can be provided via context using progress.WithReporter.
 //
@@ -27,115 +26,9 @@ import (
 //   - crop region is outside image bounds
 //   - dimensions are not positive
 //   - output cannot be written
-func CropImage(ctx context.Context, inputPath, outputPath string, x, y, width, height int) error {
-	reporter := progress.FromContext(ctx)
-	reporter.Start(ctx, fmt.Sprintf("Cropping image region %dx%d at (%d,%d)", width, height, x, y))
-
-	// Validate dimensions
-	if width <= 0 {
-		err := fmt.Errorf("width must be positive, got %d", width)
-		reporter.Error(err)
-		return err
-	}
-	if height <= 0 {
-		err := fmt.Errorf("height must be positive, got %d", height)
-		reporter.Error(err)
-		return err
-	}
-
-	// Validate coordinates
-	if x < 0 {
-		err := fmt.Errorf("x coordinate must be non-negative, got %d", x)
-		reporter.Error(err)
-		return err
-	}
-	if y < 0 {
-		err := fmt.Errorf("y coordinate must be non-negative, got %d", y)
-		reporter.Error(err)
-		return err
-	}
-
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
-	}
-
-	// Load the input image
-	reporter.Update(1, 4, "Loading input image")
-	img, err := imaging.Open(inputPath)
-	if err != nil {
-		err = fmt.Errorf("failed to open image %s: %w", inputPath, err)
-		reporter.Error(err)
-		return err
-	}
-
-	// Get image dimensions
-	bounds := img.Bounds()
-	imgWidth := bounds.Dx()
-	imgHeight := bounds.Dy()
-
-	// Validate crop region is within image bounds
-	reporter.Update(2, 4, "Validating crop region")
-	if x >= imgWidth {
-		err := fmt.Errorf("x coordinate %d is outside image width %d", x, imgWidth)
-		reporter.Error(err)
-		return err
-	}
-	if y >= imgHeight {
-		err := fmt.Errorf("y coordinate %d is outside image height %d", y, imgHeight)
-		reporter.Error(err)
-		return err
-	}
-	if x+width > imgWidth {
-		err := fmt.Errorf("crop region (x=%d + width=%d = %d) exceeds image width %d", x, width, x+width, imgWidth)
-		reporter.Error(err)
-		return err
-	}
-	if y+height > imgHeight {
-		err := fmt.Errorf("crop region (y=%d + height=%d = %d) exceeds image height %d", y, height, y+height, imgHeight)
-		reporter.Error(err)
-		return err
-	}
-
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
-	}
-
-	// Create crop rectangle
-	cropRect := image.Rect(x, y, x+width, y+height)
-
-	// Perform the crop
-	reporter.Update(3, 4, "Cropping image")
-	cropped := imaging.Crop(img, cropRect)
-
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
-	}
-
-	// Save the cropped image
-	reporter.Update(4, 4, "Saving cropped image")
-	if err := imaging.Save(cropped, outputPath); err != nil {
-		err = fmt.Errorf("failed to save cropped image to %s: %w", outputPath, err)
-		reporter.Error(err)
-		return err
-	}
-
-	reporter.Complete(outputPath)
-	return nil
+func CropImage(ctx context.Context, inputPath, outputPath string, x, y, width, height int, opts FileOptions) error {
+	return processFile(ctx, inputPath, outputPath, opts, fmt.Sprintf("Cropping image region %dx%d at (%d,%d)", width, height, x, y), "cropped",
+		Step{Op: StepCrop, X: x, Y: y, Width: width, Height: height})
 }
 
 // CropCenter crops a region from the center of the image.
@@ -145,94 +38,15 @@ func CropImage(ctx context.Context, inputPath, outputPath string, x, y, width, h
 //   - inputPath: path to input image
 //   - outputPath: path to save cropped image
 //   - width, height: dimensions of crop region
+//   - opts: file settings (see FileOptions)
 //
 // The crop region will be centered on the image. If the requested dimensions
 // are larger than the image, an error is returned.
 //
 // Progress reporting can be provided via context using progress.WithReporter.
-func CropCenter(ctx context.Context, inputPath, outputPath string, width, height int) error {
-	reporter := progress.FromContext(ctx)
-	reporter.Start(ctx, fmt.Sprintf("Cropping %dx%d from center", width, height))
-
-	// Validate dimensions
-	if width <= 0 {
-		err := fmt.Errorf("width must be positive, got %d", width)
-		reporter.Error(err)
-		return err
-	}
-	if height <= 0 {
-		err := fmt.Errorf("height must be positive, got %d", height)
-		reporter.Error(err)
-		return err
-	}
-
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
-	}
-
-	// Load the input image
-	reporter.Update(1, 3, "Loading input image")
-	img, err := imaging.Open(inputPath)
-	if err != nil {
-		err = fmt.Errorf("failed to open image %s: %w", inputPath, err)
-		reporter.Error(err)
-		return err
-	}
-
-	// Get image dimensions
-	bounds := img.Bounds()
-	imgWidth := bounds.Dx()
-	imgHeight := bounds.Dy()
-
-	// Validate crop region fits within image
-	if width > imgWidth {
-		err := fmt.Errorf("crop width %d exceeds image width %d", width, imgWidth)
-		reporter.Error(err)
-		return err
-	}
-	if height > imgHeight {
-		err := fmt.Errorf("crop height %d exceeds image height %d", height, imgHeight)
-		reporter.Error(err)
-		return err
-	}
-
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
-	}
-
-	// Use imaging.CropCenter which handles center calculation
-	reporter.Update(2, 3, "Cropping from center")
-	cropped := imaging.CropCenter(img, width, height)
-
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
-	}
-
-	// Save the cropped image
-	reporter.Update(3, 3, "Saving cropped image")
-	if err := imaging.Save(cropped, outputPath); err != nil {
-		err = fmt.Errorf("failed to save cropped image to %s: %w", outputPath, err)
-		reporter.Error(err)
-		return err
-	}
-
-	reporter.Complete(outputPath)
-	return nil
+func CropCenter(ctx context.Context, inputPath, outputPath string, width, height int, opts FileOptions) error {
+	return processFile(ctx, inputPath, outputPath, opts, fmt.Sprintf("Cropping %dx%d from center", width, height), "cropped",
+		Step{Op: StepCrop, Width: width, Height: height, Anchor: "center"})
 }
 
 // CropAnchor crops a region with a specific anchor point.
@@ -243,6 +57,7 @@ func CropCenter(ctx context.Context, inputPath, outputPath string, width, height
 //   - outputPath: path to save cropped image
 //   - width, height: dimensions of crop region
 //   - anchor: anchor point for cropping (Center, Top, Bottom, Left, Right, TopLeft, TopRight, BottomLeft, BottomRight)
+//   - opts: file settings (see FileOptions)
 //
 // The anchor determines which part of the image to keep when cropping.
 // For example, using TopLeft anchor will crop from the top-left corner.
@@ -259,87 +74,29 @@ func CropCenter(ctx context.Context, inputPath, outputPath string, width, height
 //   - imaging.BottomRight: crop from bottom-right corner
 //
 // Progress reporting can be provided via context using progress.WithReporter.
-func CropAnchor(ctx context.Context, inputPath, outputPath string, width, height int, anchor imaging.Anchor) error {
-	reporter := progress.FromContext(ctx)
-	reporter.Start(ctx, fmt.Sprintf("Cropping %dx%d with anchor", width, height))
-
-	// Validate dimensions
-	if width <= 0 {
-		err := fmt.Errorf("width must be positive, got %d", width)
-		reporter.Error(err)
-		return err
-	}
-	if height <= 0 {
-		err := fmt.Errorf("height must be positive, got %d", height)
-		reporter.Error(err)
-		return err
-	}
-
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
-	}
-
-	// Load the input image
-	reporter.Update(1, 3, "Loading input image")
-	img, err := imaging.Open(inputPath)
-	if err != nil {
-		err = fmt.Errorf("failed to open image %s: %w", inputPath, err)
-		reporter.Error(err)
-		return err
-	}
-
-	// Get image dimensions
-	bounds := img.Bounds()
-	imgWidth := bounds.Dx()
-	imgHeight := bounds.Dy()
-
-	// Validate crop region fits within image
-	if width > imgWidth {
-		err := fmt.Errorf("crop width %d exceeds image width %d", width, imgWidth)
-		reporter.Error(err)
-		return err
-	}
-	if height > imgHeight {
-		err := fmt.Errorf("crop height %d exceeds image height %d", height, imgHeight)
-		reporter.Error(err)
-		return err
-	}
-
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
-	}
-
-	// Use imaging.CropAnchor which handles anchor positioning
-	reporter.Update(2, 3, "Cropping with anchor")
-	cropped := imaging.CropAnchor(img, width, height, anchor)
-
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
-	}
-
-	// Save the cropped image
-	reporter.Update(3, 3, "Saving cropped image")
-	if err := imaging.Save(cropped, outputPath); err != nil {
-		err = fmt.Errorf("failed to save cropped image to %s: %w", outputPath, err)
-		reporter.Error(err)
-		return err
-	}
+func CropAnchor(ctx context.Context, inputPath, outputPath string, width, height int, anchor imaging.Anchor, opts FileOptions) error {
+	return processFile(ctx, inputPath, outputPath, opts, fmt.Sprintf("Cropping %dx%d with anchor", width, height), "cropped",
+		Step{Op: StepCrop, Width: width, Height: height, Anchor: anchorName(anchor)})
+}
 
-	reporter.Complete(outputPath)
-	return nil
+// SmartCrop crops the most interesting part of an image to width x height.
+//
+// Parameters:
+//   - ctx: context for cancellation support
+//   - inputPath: path to input image
+//   - outputPath: path to save cropped image
+//   - width, height: dimensions of the result
+//   - opts: file settings (see FileOptions)
+//
+// Unlike CropCenter and CropAnchor, the window is chosen from the image
+// content: of all windows with the aspect ratio of width x height, as large
+// as the image allows, it keeps the one with the most detail, skin tones and
+// color, then scales it to exactly width x height. Faces and products
+// therefore survive thumbnails that a center crop would cut. The resampling
+// filter can be set with opts.Filter.
+//
+// Progress reporting can be provided via context using progress.WithReporter.
+func SmartCrop(ctx context.Context, inputPath, outputPath string, width, height int, opts FileOptions) error {
+	return processFile(ctx, inputPath, outputPath, opts, fmt.Sprintf("Smart cropping to %dx%d", width, height), "cropped",
+		Step{Op: StepFill, Width: width, Height: height, Anchor: AnchorSmart})
 }
@@ -0,0 +1,704 @@
+// Package imaging provides image processing operations using pure Go.
+package imaging
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+// MetadataPolicy decides which metadata of the source image is written to
+// the output. Only JPEG, PNG and WebP outputs can carry metadata; the other
+// formats always drop it.
+type MetadataPolicy string
+
+const (
+	// MetadataColor keeps only the ICC color profile, so colors render as
+	// in the source without carrying camera or location data. It is the
+	// default.
+	MetadataColor MetadataPolicy = "color"
+
+	// MetadataKeep keeps EXIF, XMP and the ICC color profile
+	MetadataKeep MetadataPolicy = "keep"
+
+	// MetadataStripGPS keeps EXIF with its GPS block removed and the ICC
+	// color profile. XMP is dropped because it can repeat the location.
+	MetadataStripGPS MetadataPolicy = "strip-gps"
+
+	// MetadataStrip drops all metadata, including the ICC color profile
+	MetadataStrip MetadataPolicy = "strip"
+)
+
+// metadataPolicies lists the policies in the order they are documented
+var metadataPolicies = []MetadataPolicy{MetadataColor, MetadataKeep, MetadataStripGPS, MetadataStrip}
+
+// MetadataPolicyNames returns the policy names in the order they are documented
+func MetadataPolicyNames() []string {
+	names := make([]string, len(metadataPolicies))
+	for i, policy := range metadataPolicies {
+		names[i] = string(policy)
+	}
+	return names
+}
+
+// ParseMetadataPolicy parses a policy name; "" selects MetadataColor
+func ParseMetadataPolicy(s string) (MetadataPolicy, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return MetadataColor, nil
+	}
+	for _, policy := range metadataPolicies {
+		if string(policy) == s {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("unknown metadata policy %q (supported: %s)", s, strings.Join(MetadataPolicyNames(), ", "))
+}
+
+// Metadata is the metadata carried by an encoded image
+type Metadata struct {
+	Orientation int    // EXIF orientation, 1-8 (0 when absent)
+	EXIF        []byte // TIFF-structured EXIF data, without the "Exif\0\0" prefix
+	ICC         []byte // ICC color profile
+	XMP         []byte // XMP packet
+}
+
+// HasGPS reports whether the EXIF data contains a GPS block
+func (m Metadata) HasGPS() bool {
+	order, ifd, ok := exifHeader(m.EXIF)
+	if !ok {
+		return false
+	}
+	for _, offset := range exifIFDs(m.EXIF, order, ifd) {
+		if _, found := exifEntry(m.EXIF, order, offset, exifTagGPS); found {
+			return true
+		}
+	}
+	return false
+}
+
+func (m Metadata) empty() bool {
+	return len(m.EXIF) == 0 && len(m.ICC) == 0 && len(m.XMP) == 0
+}
+
+// forPolicy returns the metadata to write under policy. Kept EXIF data has
+// its orientation reset, since decoding already turned the pixels upright.
+func (m Metadata) forPolicy(policy MetadataPolicy) Metadata {
+	switch policy {
+	case MetadataKeep:
+		return Metadata{EXIF: resetOrientation(m.EXIF), ICC: m.ICC, XMP: m.XMP}
+	case MetadataStripGPS:
+		// When the EXIF data is too malformed to edit safely, drop it all
+		exif, ok := stripGPS(m.EXIF)
+		if !ok {
+			exif = nil
+		}
+		return Metadata{EXIF: resetOrientation(exif), ICC: m.ICC}
+	case MetadataStrip:
+		return Metadata{}
+	default:
+		return Metadata{ICC: m.ICC}
+	}
+}
+
+var (
+	jpegEXIFPrefix = []byte("Exif\x00\x00")
+	jpegXMPPrefix  = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	jpegICCPrefix  = []byte("ICC_PROFILE\x00")
+	pngSignature   = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// pngXMPKeyword is the iTXt keyword of an XMP packet in PNG
+const pngXMPKeyword = "XML:com.adobe.xmp"
+
+// maxInflatedMetadata caps decompressed PNG metadata chunks
+const maxInflatedMetadata = 16 << 20
+
+// ReadMetadata extracts the EXIF, ICC and XMP metadata of an encoded JPEG,
+// PNG or WebP image. Other formats, and segments too malformed to read,
+// yield no metadata.
+func ReadMetadata(data []byte) Metadata {
+	var m Metadata
+	switch {
+	case len(data) > 2 && data[0] == 0xff && data[1] == 0xd8:
+		m = readJPEGMetadata(data)
+	case bytes.HasPrefix(data, pngSignature):
+		m = readPNGMetadata(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		m = readWebPMetadata(data)
+	}
+	m.Orientation = exifOrientation(m.EXIF)
+	return m
+}
+
+func readJPEGMetadata(data []byte) Metadata {
+	var m Metadata
+	iccChunks := map[byte][]byte{}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			break
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xff: // fill byte
+			i++
+			continue
+		case marker == 0x01 || marker == 0xd8 || (marker >= 0xd0 && marker <= 0xd7): // no payload
+			i += 2
+			continue
+		case marker == 0xda || marker == 0xd9: // start of scan, end of image
+			i = len(data)
+			continue
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			break
+		}
+		payload := data[i+4 : i+2+length]
+		switch {
+		case marker == 0xe1 && bytes.HasPrefix(payload, jpegEXIFPrefix) && m.EXIF == nil:
+			m.EXIF = bytes.Clone(payload[len(jpegEXIFPrefix):])
+		case marker == 0xe1 && bytes.HasPrefix(payload, jpegXMPPrefix) && m.XMP == nil:
+			m.XMP = bytes.Clone(payload[len(jpegXMPPrefix):])
+		case marker == 0xe2 && bytes.HasPrefix(payload, jpegICCPrefix) && len(payload) > len(jpegICCPrefix)+2:
+			// ICC profiles are split over APP2 segments numbered from 1
+			iccChunks[payload[len(jpegICCPrefix)]] = payload[len(jpegICCPrefix)+2:]
+		}
+		i += 2 + length
+	}
+
+	if len(iccChunks) > 0 {
+		seqs := make([]int, 0, len(iccChunks))
+		for seq := range iccChunks {
+			seqs = append(seqs, int(seq))
+		}
+		sort.Ints(seqs)
+		for _, seq := range seqs {
+			m.ICC = append(m.ICC, iccChunks[byte(seq)]...)
+		}
+	}
+	return m
+}
+
+func readPNGMetadata(data []byte) Metadata {
+	var m Metadata
+	for i := len(pngSignature); i+12 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		chunkType := string(data[i+4 : i+8])
+		if length < 0 || i+12+length > len(data) {
+			break
+		}
+		payload := data[i+8 : i+8+length]
+		switch chunkType {
+		case "eXIf":
+			m.EXIF = bytes.Clone(payload)
+		case "iCCP":
+			// profile name, NUL, compression method (0 = zlib), profile
+			if _, rest, ok := bytes.Cut(payload, []byte{0}); ok && len(rest) > 1 && rest[0] == 0 {
+				m.ICC = inflate(rest[1:])
+			}
+		case "iTXt":
+			m.XMP = append(m.XMP, readPNGXMP(payload)...)
+		case "IEND":
+			return m
+		}
+		i += 12 + length
+	}
+	return m
+}
+
+// readPNGXMP returns the text of an iTXt chunk carrying XMP
+func readPNGXMP(payload []byte) []byte {
+	// keyword, NUL, compression flag, method, language, NUL, translated keyword, NUL, text
+	keyword, rest, ok := bytes.Cut(payload, []byte{0})
+	if !ok || string(keyword) != pngXMPKeyword || len(rest) < 2 {
+		return nil
+	}
+	compressed := rest[0] == 1
+	if _, rest, ok = bytes.Cut(rest[2:], []byte{0}); !ok {
+		return nil
+	}
+	if _, rest, ok = bytes.Cut(rest, []byte{0}); !ok {
+		return nil
+	}
+	if compressed {
+		return inflate(rest)
+	}
+	return bytes.Clone(rest)
+}
+
+func readWebPMetadata(data []byte) Metadata {
+	var m Metadata
+	for i := 12; i+8 <= len(data); {
+		fourCC := string(data[i : i+4])
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		if size < 0 || i+8+size > len(data) {
+			break
+		}
+		payload := data[i+8 : i+8+size]
+		switch fourCC {
+		case "ICCP":
+			m.ICC = bytes.Clone(payload)
+		case "EXIF":
+			// Some writers keep the JPEG-style prefix
+			m.EXIF = bytes.Clone(bytes.TrimPrefix(payload, jpegEXIFPrefix))
+		case "XMP ":
+			m.XMP = bytes.Clone(payload)
+		}
+		i += 8 + size + size&1
+	}
+	return m
+}
+
+// inflate decompresses zlib data, returning nil if it is malformed
+func inflate(data []byte) []byte {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+	out, err := io.ReadAll(io.LimitReader(r, maxInflatedMetadata))
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+// writeMetadata embeds m in an encoded image. Formats other than JPEG, PNG
+// and WebP are returned unchanged.
+func writeMetadata(data []byte, format string, m Metadata) ([]byte, error) {
+	if m.empty() {
+		return data, nil
+	}
+	switch normalizeFormat(format) {
+	case "jpeg":
+		return writeJPEGMetadata(data, m)
+	case "png":
+		return writePNGMetadata(data, m)
+	case "webp":
+		return writeWebPMetadata(data, m)
+	default:
+		return data, nil
+	}
+}
+
+// writeJPEGMetadata inserts APP1 (EXIF, XMP) and APP2 (ICC) segments after
+// the start-of-image marker. Data too large for a segment is left out.
+func writeJPEGMetadata(data []byte, m Metadata) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil, fmt.Errorf("unexpected JPEG layout")
+	}
+	const maxSegment = 0xffff - 2
+
+	var buf bytes.Buffer
+	buf.Grow(len(data) + len(m.EXIF) + len(m.ICC) + len(m.XMP) + 64)
+	buf.Write(data[:2])
+	writeSegment := func(marker byte, parts ...[]byte) {
+		length := 2
+		for _, part := range parts {
+			length += len(part)
+		}
+		if length-2 > maxSegment {
+			return
+		}
+		buf.Write([]byte{0xff, marker, byte(length >> 8), byte(length)})
+		for _, part := range parts {
+			buf.Write(part)
+		}
+	}
+
+	if len(m.EXIF) > 0 {
+		writeSegment(0xe1, jpegEXIFPrefix, m.EXIF)
+	}
+	if len(m.XMP) > 0 {
+		writeSegment(0xe1, jpegXMPPrefix, m.XMP)
+	}
+	if len(m.ICC) > 0 {
+		chunkSize := maxSegment - len(jpegICCPrefix) - 2
+		count := (len(m.ICC) + chunkSize - 1) / chunkSize
+		if count <= 255 {
+			for i := 0; i < count; i++ {
+				chunk := m.ICC[i*chunkSize : min((i+1)*chunkSize, len(m.ICC))]
+				writeSegment(0xe2, jpegICCPrefix, []byte{byte(i + 1), byte(count)}, chunk)
+			}
+		}
+	}
+	buf.Write(data[2:])
+	return buf.Bytes(), nil
+}
+
+// writePNGMetadata inserts iCCP, eXIf and iTXt chunks after IHDR
+func writePNGMetadata(data []byte, m Metadata) ([]byte, error) {
+	// signature, then IHDR: length, type, 13 bytes of data, CRC
+	const ihdrEnd = 8 + 12 + 13
+	if len(data) < ihdrEnd || !bytes.HasPrefix(data, pngSignature) || string(data[12:16]) != "IHDR" {
+		return nil, fmt.Errorf("unexpected PNG layout")
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(data) + len(m.EXIF) + len(m.ICC) + len(m.XMP) + 128)
+	buf.Write(data[:ihdrEnd])
+	if len(m.ICC) > 0 {
+		var profile bytes.Buffer
+		profile.WriteString("icc\x00\x00") // name, NUL, zlib
+		zw := zlib.NewWriter(&profile)
+		if _, err := zw.Write(m.ICC); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		writePNGChunk(&buf, "iCCP", profile.Bytes())
+	}
+	if len(m.EXIF) > 0 {
+		writePNGChunk(&buf, "eXIf", m.EXIF)
+	}
+	if len(m.XMP) > 0 {
+		// keyword, NUL, uncompressed, method, empty language and translation
+		header := pngXMPKeyword + "\x00\x00\x00\x00\x00"
+		writePNGChunk(&buf, "iTXt", append([]byte(header), m.XMP...))
+	}
+	buf.Write(data[ihdrEnd:])
+	return buf.Bytes(), nil
+}
+
+func writePNGChunk(buf *bytes.Buffer, chunkType string, payload []byte) {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(payload)))
+	copy(header[4:], chunkType)
+	buf.Write(header[:])
+	buf.Write(payload)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(payload)
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	buf.Write(sum[:])
+}
+
+// WebP VP8X feature flags
+const (
+	webpFlagICC  = 0x20
+	webpFlagEXIF = 0x08
+	webpFlagXMP  = 0x04
+)
+
+// writeWebPMetadata rewrites a WebP file in the extended format with ICCP,
+// EXIF and XMP chunks, keeping its image chunks in order
+func writeWebPMetadata(data []byte, m Metadata) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, fmt.Errorf("unexpected WebP layout")
+	}
+
+	var flags byte
+	var width, height int
+	var frames bytes.Buffer
+	for i := 12; i+8 <= len(data); {
+		fourCC := string(data[i : i+4])
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		if size < 0 || i+8+size > len(data) {
+			return nil, fmt.Errorf("unexpected WebP layout")
+		}
+		payload := data[i+8 : i+8+size]
+		switch fourCC {
+		case "VP8X":
+			if size < 10 {
+				return nil, fmt.Errorf("unexpected WebP layout")
+			}
+			flags = payload[0]
+			width = 1 + (int(payload[4]) | int(payload[5])<<8 | int(payload[6])<<16)
+			height = 1 + (int(payload[7]) | int(payload[8])<<8 | int(payload[9])<<16)
+		case "ICCP", "EXIF", "XMP ":
+			// replaced below
+		default:
+			if width == 0 {
+				width, height = webpFrameSize(fourCC, payload)
+				if fourCC == "VP8L" && len(payload) >= 5 && payload[4]&0x10 != 0 {
+					flags |= 0x10 // alpha is used
+				}
+			}
+			writeRIFFChunk(&frames, fourCC, payload)
+		}
+		i += 8 + size + size&1
+	}
+	if width == 0 || height == 0 {
+		return nil, fmt.Errorf("unexpected WebP layout")
+	}
+
+	flags &^= webpFlagICC | webpFlagEXIF | webpFlagXMP
+	if len(m.ICC) > 0 {
+		flags |= webpFlagICC
+	}
+	if len(m.EXIF) > 0 {
+		flags |= webpFlagEXIF
+	}
+	if len(m.XMP) > 0 {
+		flags |= webpFlagXMP
+	}
+
+	var body bytes.Buffer
+	vp8x := make([]byte, 10)
+	vp8x[0] = flags
+	putUint24(vp8x[4:], uint32(width-1))
+	putUint24(vp8x[7:], uint32(height-1))
+	writeRIFFChunk(&body, "VP8X", vp8x)
+	if len(m.ICC) > 0 {
+		writeRIFFChunk(&body, "ICCP", m.ICC)
+	}
+	body.Write(frames.Bytes())
+	if len(m.EXIF) > 0 {
+		writeRIFFChunk(&body, "EXIF", m.EXIF)
+	}
+	if len(m.XMP) > 0 {
+		writeRIFFChunk(&body, "XMP ", m.XMP)
+	}
+
+	out := make([]byte, 12, 12+body.Len())
+	copy(out, "RIFF")
+	binary.LittleEndian.PutUint32(out[4:], uint32(4+body.Len()))
+	copy(out[8:], "WEBP")
+	return append(out, body.Bytes()...), nil
+}
+
+// webpFrameSize reads the dimensions from a VP8 or VP8L frame header
+func webpFrameSize(fourCC string, payload []byte) (int, int) {
+	switch fourCC {
+	case "VP8 ":
+		// frame tag (3), start code (3), then 14-bit width and height
+		if len(payload) >= 10 {
+			return int(binary.LittleEndian.Uint16(payload[6:]) & 0x3fff),
+				int(binary.LittleEndian.Uint16(payload[8:]) & 0x3fff)
+		}
+	case "VP8L":
+		// signature (1), then 14-bit width-1 and height-1
+		if len(payload) >= 5 {
+			bits := binary.LittleEndian.Uint32(payload[1:])
+			return int(bits&0x3fff) + 1, int(bits>>14&0x3fff) + 1
+		}
+	}
+	return 0, 0
+}
+
+// EXIF tags this package reads or edits
+const (
+	exifTagOrientation = 0x0112
+	exifTagGPS         = 0x8825
+)
+
+// exifTypeSizes maps TIFF field types to their size in bytes
+var exifTypeSizes = [...]int{0, 1, 1, 2, 4, 8, 1, 1, 2, 4, 8, 4, 8}
+
+// exifHeader returns the byte order and first IFD offset of EXIF data
+func exifHeader(exif []byte) (binary.ByteOrder, int, bool) {
+	if len(exif) < 8 {
+		return nil, 0, false
+	}
+	var order binary.ByteOrder
+	switch string(exif[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, 0, false
+	}
+	if order.Uint16(exif[2:]) != 42 {
+		return nil, 0, false
+	}
+	return order, int(order.Uint32(exif[4:])), true
+}
+
+// exifIFDs returns the offsets of the IFD chain starting at ifd (IFD0 and
+// the thumbnail's IFD1), stopping at the first malformed link
+func exifIFDs(exif []byte, order binary.ByteOrder, ifd int) []int {
+	var offsets []int
+	for ifd > 0 && ifd+2 <= len(exif) && len(offsets) < 4 {
+		offsets = append(offsets, ifd)
+		next := ifd + 2 + 12*int(order.Uint16(exif[ifd:]))
+		if next+4 > len(exif) {
+			break
+		}
+		ifd = int(order.Uint32(exif[next:]))
+	}
+	return offsets
+}
+
+// exifEntry returns the offset of tag's 12-byte entry in the IFD at ifd
+func exifEntry(exif []byte, order binary.ByteOrder, ifd int, tag uint16) (int, bool) {
+	if ifd <= 0 || ifd+2 > len(exif) {
+		return 0, false
+	}
+	count := int(order.Uint16(exif[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + 12*i
+		if entry+12 > len(exif) {
+			return 0, false
+		}
+		if order.Uint16(exif[entry:]) == tag {
+			return entry, true
+		}
+	}
+	return 0, false
+}
+
+// exifOrientation returns the orientation tag of EXIF data (0 when absent)
+func exifOrientation(exif []byte) int {
+	order, ifd, ok := exifHeader(exif)
+	if !ok {
+		return 0
+	}
+	entry, ok := exifEntry(exif, order, ifd, exifTagOrientation)
+	if !ok || order.Uint16(exif[entry+2:]) != 3 { // SHORT
+		return 0
+	}
+	orientation := int(order.Uint16(exif[entry+8:]))
+	if orientation < 1 || orientation > 8 {
+		return 0
+	}
+	return orientation
+}
+
+// resetOrientation returns a copy of EXIF data with its orientation set
+// to 1 (upright)
+func resetOrientation(exif []byte) []byte {
+	order, ifd, ok := exifHeader(exif)
+	if !ok {
+		return exif
+	}
+	entry, ok := exifEntry(exif, order, ifd, exifTagOrientation)
+	if !ok || order.Uint16(exif[entry+2:]) != 3 {
+		return exif
+	}
+	out := bytes.Clone(exif)
+	order.PutUint16(out[entry+8:], 1)
+	return out
+}
+
+// stripGPS returns a copy of EXIF data without its GPS block: the GPS
+// pointer is removed from the IFD that holds it and the GPS IFD and every
+// value it references are zeroed, so no location bytes remain. It reports
+// false when the data is too malformed to do so safely.
+func stripGPS(exif []byte) ([]byte, bool) {
+	if len(exif) == 0 {
+		return nil, true
+	}
+	order, first, ok := exifHeader(exif)
+	if !ok {
+		return nil, false
+	}
+	out := bytes.Clone(exif)
+	for _, ifd := range exifIFDs(out, order, first) {
+		entry, found := exifEntry(out, order, ifd, exifTagGPS)
+		if !found {
+			continue
+		}
+		if !zeroIFD(out, order, int(order.Uint32(out[entry+8:]))) {
+			return nil, false
+		}
+
+		// Close the gap left by the pointer entry, moving the later
+		// entries and the next-IFD link up
+		count := int(order.Uint16(out[ifd:]))
+		end := ifd + 2 + 12*count + 4
+		if end > len(out) {
+			return nil, false
+		}
+		copy(out[entry:], out[entry+12:end])
+		clear(out[end-12 : end])
+		order.PutUint16(out[ifd:], uint16(count-1))
+	}
+	return out, true
+}
+
+// zeroIFD zeroes an IFD and the out-of-line values of its entries
+func zeroIFD(exif []byte, order binary.ByteOrder, ifd int) bool {
+	if ifd <= 0 || ifd+2 > len(exif) {
+		return false
+	}
+	count := int(order.Uint16(exif[ifd:]))
+	end := ifd + 2 + 12*count
+	if end > len(exif) {
+		return false
+	}
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + 12*i
+		fieldType := int(order.Uint16(exif[entry+2:]))
+		if fieldType <= 0 || fieldType >= len(exifTypeSizes) {
+			return false
+		}
+		size := exifTypeSizes[fieldType] * int(order.Uint32(exif[entry+4:]))
+		if size > 4 {
+			offset := int(order.Uint32(exif[entry+8:]))
+			if offset < 0 || size < 0 || offset+size > len(exif) {
+				return false
+			}
+			clear(exif[offset : offset+size])
+		}
+	}
+	clear(exif[ifd:min(end+4, len(exif))])
+	return true
+}
+
+// decodeImage decodes image data like image.Decode, turning it upright
+// according to its EXIF orientation and correcting the colors of lossy
+// WebP images (see webpToNRGBA).
+func decodeImage(data []byte) (image.Image, string, error) {
+	img, format, _, err := decodeImageMetadata(data)
+	return img, format, err
+}
+
+// decodeImageMetadata is decodeImage that also returns the source metadata
+func decodeImageMetadata(data []byte) (image.Image, string, Metadata, error) {
+	var img image.Image
+	var format string
+	if isAnimatedWebP(data) {
+		// x/image/webp cannot read animations; use the first frame
+		anim, err := decodeWebPAnimation(data)
+		if err != nil {
+			return nil, "", Metadata{}, err
+		}
+		img, format = anim.frames[0].image, "webp"
+	} else {
+		var err error
+		if img, format, err = image.Decode(bytes.NewReader(data)); err != nil {
+			return nil, "", Metadata{}, err
+		}
+		if format == "webp" {
+			img = webpToNRGBA(img)
+		}
+	}
+	meta := ReadMetadata(data)
+	return orient(img, meta.Orientation), format, meta, nil
+}
+
+// orient turns an image stored with the given EXIF orientation upright
+func orient(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return imaging.FlipH(img)
+	case 3:
+		return imaging.Rotate180(img)
+	case 4:
+		return imaging.FlipV(img)
+	case 5:
+		return imaging.Transpose(img)
+	case 6:
+		return imaging.Rotate270(img)
+	case 7:
+		return imaging.Transverse(img)
+	case 8:
+		return imaging.Rotate90(img)
+	default:
+		return img
+	}
+}
@@ -0,0 +1,261 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gpsMarker fills the GPS latitude rationals of testEXIF so tests can check
+// that no location bytes survive
+var gpsMarker = []byte{0x2a, 0x2b, 0x2c, 0x2d}
+
+// testEXIF builds little-endian EXIF data with Make, Orientation and a GPS
+// block holding a latitude
+func testEXIF(orientation int) []byte {
+	order := binary.LittleEndian
+	buf := make([]byte, 110)
+	copy(buf, "II")
+	order.PutUint16(buf[2:], 42)
+	order.PutUint32(buf[4:], 8)
+
+	entry := func(at int, tag, fieldType uint16, count, value uint32) {
+		order.PutUint16(buf[at:], tag)
+		order.PutUint16(buf[at+2:], fieldType)
+		order.PutUint32(buf[at+4:], count)
+		order.PutUint32(buf[at+8:], value)
+	}
+
+	// IFD0 at 8: three entries, then no next IFD
+	order.PutUint16(buf[8:], 3)
+	entry(10, 0x010f, 2, 6, 50) // Make, stored at 50
+	entry(22, exifTagOrientation, 3, 1, uint32(orientation))
+	entry(34, exifTagGPS, 4, 1, 56) // GPS IFD at 56
+	copy(buf[50:], "Canon\x00")
+
+	// GPS IFD at 56: latitude ref and latitude, values at 86
+	order.PutUint16(buf[56:], 2)
+	entry(58, 0x0001, 2, 2, uint32('N'))
+	entry(70, 0x0002, 5, 3, 86)
+	for i := 86; i < 110; i += 4 {
+		copy(buf[i:], gpsMarker)
+	}
+	return buf
+}
+
+// testMetadata returns metadata with every kind of data set. The ICC
+// profile is large enough to need several JPEG segments.
+func testMetadata(orientation int) Metadata {
+	icc := make([]byte, 70000)
+	for i := range icc {
+		icc[i] = byte(i * 7)
+	}
+	return Metadata{
+		EXIF: testEXIF(orientation),
+		ICC:  icc,
+		XMP:  []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF/></x:xmpmeta>`),
+	}
+}
+
+// markedImage returns a wide image whose top-left pixel is red
+func markedImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = 0, 0, 255, 255
+	}
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	return img
+}
+
+// encodeWithMetadata encodes img in format and embeds m
+func encodeWithMetadata(t *testing.T, img image.Image, format string, m Metadata) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, encodeImageQuality(&buf, img, format, 80))
+	data, err := writeMetadata(buf.Bytes(), format, m)
+	require.NoError(t, err)
+	return data
+}
+
+func TestReadMetadata_RoundTrip(t *testing.T) {
+	want := testMetadata(6)
+	for _, format := range []string{"jpeg", "png", "webp"} {
+		t.Run(format, func(t *testing.T) {
+			data := encodeWithMetadata(t, texturedImage(64, 48), format, want)
+
+			got := ReadMetadata(data)
+			assert.Equal(t, 6, got.Orientation)
+			assert.Equal(t, want.EXIF, got.EXIF)
+			assert.Equal(t, want.ICC, got.ICC)
+			assert.Equal(t, want.XMP, got.XMP)
+			assert.True(t, got.HasGPS())
+
+			// The file still decodes
+			decoded, _, err := image.Decode(bytes.NewReader(data))
+			require.NoError(t, err)
+			assert.Equal(t, 64, decoded.Bounds().Dx())
+		})
+	}
+}
+
+func TestReadMetadata_None(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, texturedImage(8, 8)))
+	assert.Equal(t, Metadata{}, ReadMetadata(buf.Bytes()))
+	assert.Equal(t, Metadata{}, ReadMetadata([]byte("not an image")))
+}
+
+func TestDecodePipeline_Orientation(t *testing.T) {
+	tests := []struct {
+		orientation   int
+		width, height int
+		redX, redY    int // where the top-left pixel ends up
+	}{
+		{1, 40, 20, 0, 0},
+		{2, 40, 20, 39, 0},
+		{3, 40, 20, 39, 19},
+		{4, 40, 20, 0, 19},
+		{5, 20, 40, 0, 0},
+		{6, 20, 40, 19, 0},
+		{7, 20, 40, 19, 39},
+		{8, 20, 40, 0, 39},
+	}
+	for _, tt := range tests {
+		data := encodeWithMetadata(t, markedImage(), "png", Metadata{EXIF: testEXIF(tt.orientation)})
+		p, err := DecodePipeline(data)
+		require.NoError(t, err)
+
+		width, height := p.Size()
+		assert.Equal(t, tt.width, width, "orientation %d", tt.orientation)
+		assert.Equal(t, tt.height, height, "orientation %d", tt.orientation)
+		r, _, b, _ := p.Image().At(tt.redX, tt.redY).RGBA()
+		assert.Equal(t, uint32(0xffff), r, "orientation %d", tt.orientation)
+		assert.Equal(t, uint32(0), b, "orientation %d", tt.orientation)
+	}
+}
+
+func TestPipeline_MetadataPolicies(t *testing.T) {
+	for _, format := range []string{"jpeg", "png", "webp"} {
+		source := encodeWithMetadata(t, markedImage(), format, testMetadata(6))
+
+		t.Run(format+"/default keeps color profile", func(t *testing.T) {
+			p, err := DecodePipeline(source)
+			require.NoError(t, err)
+			data, err := p.Resize(10, 20).Bytes()
+			require.NoError(t, err)
+
+			got := ReadMetadata(data)
+			assert.Equal(t, testMetadata(6).ICC, got.ICC)
+			assert.Nil(t, got.EXIF)
+			assert.Nil(t, got.XMP)
+		})
+
+		t.Run(format+"/keep", func(t *testing.T) {
+			p, err := DecodePipeline(source)
+			require.NoError(t, err)
+			data, err := p.Metadata(MetadataKeep).Bytes()
+			require.NoError(t, err)
+
+			got := ReadMetadata(data)
+			assert.Equal(t, 1, got.Orientation, "pixels are already upright")
+			assert.True(t, got.HasGPS())
+			assert.Equal(t, testMetadata(6).ICC, got.ICC)
+			assert.Equal(t, testMetadata(6).XMP, got.XMP)
+
+			// Decoding the output must not rotate it again
+			again, err := DecodePipeline(data)
+			require.NoError(t, err)
+			width, height := again.Size()
+			assert.Equal(t, 20, width)
+			assert.Equal(t, 40, height)
+		})
+
+		t.Run(format+"/strip-gps", func(t *testing.T) {
+			p, err := DecodePipeline(source)
+			require.NoError(t, err)
+			data, err := p.Metadata(MetadataStripGPS).Bytes()
+			require.NoError(t, err)
+
+			got := ReadMetadata(data)
+			require.NotNil(t, got.EXIF)
+			assert.False(t, got.HasGPS())
+			assert.Contains(t, string(got.EXIF), "Canon")
+			assert.NotContains(t, string(got.EXIF), string(gpsMarker))
+			assert.Equal(t, 1, got.Orientation)
+			assert.Equal(t, testMetadata(6).ICC, got.ICC)
+			assert.Nil(t, got.XMP)
+		})
+
+		t.Run(format+"/strip", func(t *testing.T) {
+			p, err := DecodePipeline(source)
+			require.NoError(t, err)
+			data, err := p.Metadata(MetadataStrip).Bytes()
+			require.NoError(t, err)
+			assert.Equal(t, Metadata{}, ReadMetadata(data))
+		})
+	}
+}
+
+func TestPipeline_MetadataUnknownPolicy(t *testing.T) {
+	err := NewPipeline(texturedImage(8, 8), "png").Metadata("everything").Err()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown metadata policy")
+}
+
+func TestParseMetadataPolicy(t *testing.T) {
+	policy, err := ParseMetadataPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, MetadataColor, policy)
+
+	policy, err = ParseMetadataPolicy(" Strip-GPS ")
+	require.NoError(t, err)
+	assert.Equal(t, MetadataStripGPS, policy)
+
+	_, err = ParseMetadataPolicy("none")
+	assert.Error(t, err)
+}
+
+func TestStripGPS_MalformedDropsEXIF(t *testing.T) {
+	exif := testEXIF(1)
+	// Point the GPS IFD past the end of the data
+	binary.LittleEndian.PutUint32(exif[34+8:], 5000)
+
+	_, ok := stripGPS(exif)
+	assert.False(t, ok)
+	assert.Nil(t, Metadata{EXIF: exif}.forPolicy(MetadataStripGPS).EXIF)
+}
+
+func TestFileOptions_Metadata(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, "in.jpg")
+	require.NoError(t, os.WriteFile(inputPath, encodeWithMetadata(t, markedImage(), "jpeg", testMetadata(1)), 0644))
+
+	// The zero value keeps only the color profile
+	colorPath := filepath.Join(tmpDir, "color.jpg")
+	require.NoError(t, ResizeImage(context.Background(), inputPath, colorPath, 20, 10, FileOptions{}))
+	colorOnly, err := os.ReadFile(colorPath)
+	require.NoError(t, err)
+	assert.False(t, ReadMetadata(colorOnly).HasGPS())
+	assert.NotEmpty(t, ReadMetadata(colorOnly).ICC)
+
+	keepPath := filepath.Join(tmpDir, "keep.jpg")
+	require.NoError(t, ResizeImage(context.Background(), inputPath, keepPath, 20, 10, FileOptions{Metadata: MetadataKeep}))
+	keep, err := os.ReadFile(keepPath)
+	require.NoError(t, err)
+	assert.True(t, ReadMetadata(keep).HasGPS())
+
+	stripPath := filepath.Join(tmpDir, "strip.webp")
+	require.NoError(t, ConvertImageFile(context.Background(), inputPath, stripPath, FileOptions{Metadata: MetadataStrip}))
+	strip, err := os.ReadFile(stripPath)
+	require.NoError(t, err)
+	assert.Equal(t, Metadata{}, ReadMetadata(strip))
+}
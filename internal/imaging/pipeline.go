@@ -0,0 +1,957 @@
+// Package imaging provides image processing operations using pure Go.
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apresai/gimage/internal/progress"
+	"github.com/disintegration/imaging"
+)
+
+// Pipeline step operations
+const (
+	StepCrop     = "crop"     // crop:X,Y,W,H (region) or crop:WxH[@ANCHOR] (anchored, default center)
+	StepResize   = "resize"   // resize:WxH (exact size)
+	StepFit      = "fit"      // fit:WxH (fit within, preserving aspect ratio)
+	StepFill     = "fill"     // fill:WxH[@ANCHOR] (cover, then crop at the anchor or "smart", default center)
+	StepPad      = "pad"      // pad:WxH[@BACKGROUND] (contain, letterboxed on a color or blur)
+	StepScale    = "scale"    // scale:FACTOR
+	StepCompress = "compress" // compress:QUALITY (1-100, applied at encode)
+	StepConvert  = "convert"  // convert:FORMAT (applied at encode)
+	StepFrame    = "frame"    // frame:N (keep frame N of an animation, counting from 0)
+	StepSharpen  = "sharpen"  // sharpen:AMOUNT (unsharp mask, e.g. after a downscale)
+)
+
+// stepOps lists the step operations in the order they are documented
+var stepOps = []string{StepCrop, StepResize, StepFit, StepFill, StepPad, StepScale, StepSharpen, StepCompress, StepConvert, StepFrame}
+
+// anchors maps crop anchor names to imaging anchors
+var anchors = map[string]imaging.Anchor{
+	"center":       imaging.Center,
+	"top":          imaging.Top,
+	"bottom":       imaging.Bottom,
+	"left":         imaging.Left,
+	"right":        imaging.Right,
+	"top-left":     imaging.TopLeft,
+	"top-right":    imaging.TopRight,
+	"bottom-left":  imaging.BottomLeft,
+	"bottom-right": imaging.BottomRight,
+}
+
+// outputFormats are the formats encodeImage can write
+var outputFormats = []string{"png", "jpeg", "gif", "webp", "avif", "tiff", "bmp"}
+
+// Step is one operation in a Pipeline.
+//
+// A crop step with an Anchor keeps a Width x Height region positioned by the
+// anchor; without one it keeps the region at X, Y. A fill step keeps the
+// region at its Anchor too.
+type Step struct {
+	Op         string
+	X, Y       int
+	Width      int
+	Height     int
+	Anchor     string  // crop and fill anchor name, e.g. "center" or "top-left"
+	Background string  // pad background: a color such as "#ffffff", or "blur"
+	Factor     float64 // scale factor
+	Quality    int     // compress quality
+	Format     string  // convert target format
+	Frame      int     // animation frame index
+	Amount     float64 // sharpen amount
+}
+
+// ParseStep parses a step such as "crop:0,0,800,600", "crop:800x600@top",
+// "resize:1024x768", "fit:512x512", "fill:1200x630@top", "pad:1080x1080@blur",
+// "scale:0.5", "sharpen:0.8", "compress:80", "convert:webp" or "frame:0"
+func ParseStep(s string) (Step, error) {
+	op, arg, ok := strings.Cut(strings.TrimSpace(s), ":")
+	op = strings.ToLower(strings.TrimSpace(op))
+	arg = strings.TrimSpace(arg)
+	if !ok || arg == "" {
+		return Step{}, fmt.Errorf("invalid step %q (expected op:args, e.g. resize:800x600)", s)
+	}
+
+	step := Step{Op: op}
+	var err error
+	switch op {
+	case StepCrop:
+		if size, anchor, anchored := strings.Cut(arg, "@"); anchored || !strings.Contains(arg, ",") {
+			step.Anchor = "center"
+			if anchored {
+				step.Anchor = strings.ToLower(strings.TrimSpace(anchor))
+			}
+			step.Width, step.Height, err = parseStepSize(size)
+		} else {
+			var values []int
+			values, err = parseStepInts(arg, 4)
+			if err == nil {
+				step.X, step.Y, step.Width, step.Height = values[0], values[1], values[2], values[3]
+			}
+		}
+	case StepResize, StepFit:
+		step.Width, step.Height, err = parseStepSize(arg)
+	case StepFill, StepPad:
+		size, option, _ := strings.Cut(arg, "@")
+		if op == StepFill {
+			step = ResizeStep(0, 0, ResizeOptions{Mode: ResizeModeFill, Anchor: option})
+		} else {
+			step = ResizeStep(0, 0, ResizeOptions{Mode: ResizeModePad, Background: option})
+		}
+		step.Width, step.Height, err = parseStepSize(size)
+	case StepScale:
+		step.Factor, err = strconv.ParseFloat(arg, 64)
+	case StepSharpen:
+		step.Amount, err = strconv.ParseFloat(arg, 64)
+	case StepCompress:
+		step.Quality, err = strconv.Atoi(arg)
+	case StepConvert:
+		step.Format = normalizeFormat(arg)
+	case StepFrame:
+		step.Frame, err = strconv.Atoi(arg)
+	default:
+		return Step{}, fmt.Errorf("unknown step %q (supported: %s)", op, strings.Join(stepOps, ", "))
+	}
+	if err != nil {
+		return Step{}, fmt.Errorf("invalid step %q: %w", s, err)
+	}
+
+	if err := step.Validate(); err != nil {
+		return Step{}, fmt.Errorf("invalid step %q: %w", s, err)
+	}
+	return step, nil
+}
+
+// ParseSteps parses each step with ParseStep
+func ParseSteps(specs []string) ([]Step, error) {
+	steps := make([]Step, 0, len(specs))
+	for _, spec := range specs {
+		step, err := ParseStep(spec)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// parseStepSize parses WIDTHxHEIGHT
+func parseStepSize(s string) (int, int, error) {
+	w, h, ok := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("expected WIDTHxHEIGHT, got %q", s)
+	}
+	width, errW := strconv.Atoi(strings.TrimSpace(w))
+	height, errH := strconv.Atoi(strings.TrimSpace(h))
+	if errW != nil || errH != nil {
+		return 0, 0, fmt.Errorf("expected WIDTHxHEIGHT, got %q", s)
+	}
+	return width, height, nil
+}
+
+// parseStepInts parses exactly n comma-separated integers
+func parseStepInts(s string, n int) ([]int, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d comma-separated integers, got %q", n, s)
+	}
+	values := make([]int, n)
+	for i, part := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("expected %d comma-separated integers, got %q", n, s)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// Validate checks the step's arguments without looking at an image
+func (s Step) Validate() error {
+	switch s.Op {
+	case StepCrop, StepResize, StepFit, StepFill, StepPad:
+		if s.Width <= 0 {
+			return fmt.Errorf("width must be positive, got %d", s.Width)
+		}
+		if s.Height <= 0 {
+			return fmt.Errorf("height must be positive, got %d", s.Height)
+		}
+		switch s.Op {
+		case StepResize, StepFit:
+			return nil
+		case StepFill:
+			if s.Anchor == AnchorSmart {
+				return nil
+			}
+			return validateAnchor(s.Op, s.Anchor)
+		case StepPad:
+			_, _, err := parseBackground(s.Background)
+			return err
+		}
+		if s.Anchor != "" {
+			return validateAnchor(s.Op, s.Anchor)
+		}
+		if s.X < 0 {
+			return fmt.Errorf("x coordinate must be non-negative, got %d", s.X)
+		}
+		if s.Y < 0 {
+			return fmt.Errorf("y coordinate must be non-negative, got %d", s.Y)
+		}
+	case StepScale:
+		if s.Factor <= 0 {
+			return fmt.Errorf("factor must be positive, got %f", s.Factor)
+		}
+	case StepSharpen:
+		if s.Amount <= 0 {
+			return fmt.Errorf("sharpen amount must be positive, got %g", s.Amount)
+		}
+		return validateSharpen(s.Amount)
+	case StepCompress:
+		if s.Quality < 1 || s.Quality > 100 {
+			return fmt.Errorf("quality must be between 1 and 100, got %d", s.Quality)
+		}
+	case StepConvert:
+		if !isOutputFormat(s.Format) {
+			return fmt.Errorf("unsupported output format: %s (supported: %s)", s.Format, strings.Join(outputFormats, ", "))
+		}
+	case StepFrame:
+		if s.Frame < 0 {
+			return fmt.Errorf("frame must be non-negative, got %d", s.Frame)
+		}
+	default:
+		return fmt.Errorf("unknown step %q (supported: %s)", s.Op, strings.Join(stepOps, ", "))
+	}
+	return nil
+}
+
+// String returns the step in ParseStep syntax
+func (s Step) String() string {
+	switch s.Op {
+	case StepCrop:
+		if s.Anchor != "" {
+			return fmt.Sprintf("%s:%dx%d@%s", s.Op, s.Width, s.Height, s.Anchor)
+		}
+		return fmt.Sprintf("%s:%d,%d,%d,%d", s.Op, s.X, s.Y, s.Width, s.Height)
+	case StepResize, StepFit:
+		return fmt.Sprintf("%s:%dx%d", s.Op, s.Width, s.Height)
+	case StepFill:
+		return fmt.Sprintf("%s:%dx%d@%s", s.Op, s.Width, s.Height, s.Anchor)
+	case StepPad:
+		return fmt.Sprintf("%s:%dx%d@%s", s.Op, s.Width, s.Height, s.Background)
+	case StepScale:
+		return fmt.Sprintf("%s:%g", s.Op, s.Factor)
+	case StepSharpen:
+		return fmt.Sprintf("%s:%g", s.Op, s.Amount)
+	case StepCompress:
+		return fmt.Sprintf("%s:%d", s.Op, s.Quality)
+	case StepConvert:
+		return fmt.Sprintf("%s:%s", s.Op, s.Format)
+	case StepFrame:
+		return fmt.Sprintf("%s:%d", s.Op, s.Frame)
+	default:
+		return s.Op
+	}
+}
+
+// validateAnchor checks the anchor name of a crop or fill step
+func validateAnchor(op, anchor string) error {
+	if _, ok := anchors[anchor]; !ok {
+		names := AnchorNames()
+		if op == StepFill {
+			names = append(names, AnchorSmart)
+		}
+		return fmt.Errorf("unknown %s anchor %q (supported: %s)", op, anchor, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// AnchorNames returns the crop and fill anchor names in sorted order
+func AnchorNames() []string {
+	names := make([]string, 0, len(anchors))
+	for name := range anchors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// anchorName returns the name of an imaging anchor
+func anchorName(anchor imaging.Anchor) string {
+	for name, a := range anchors {
+		if a == anchor {
+			return name
+		}
+	}
+	return "center"
+}
+
+// isOutputFormat reports whether encodeImage can write format
+func isOutputFormat(format string) bool {
+	format = normalizeFormat(format)
+	for _, f := range outputFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatExtension returns the usual file extension for a format, without the dot
+func FormatExtension(format string) string {
+	switch format = normalizeFormat(format); format {
+	case "jpeg":
+		return "jpg"
+	default:
+		return format
+	}
+}
+
+// Pipeline holds a decoded image and applies operations to it in memory, so
+// a chain such as crop -> resize -> compress decodes and encodes only once.
+//
+// Operations are chainable. The first failing operation records its error,
+// later operations are skipped, and Err, Encode, Bytes and Save return it.
+//
+// An animated GIF or WebP keeps all its frames: crop, resize and scale apply
+// to each one, and GIF or WebP output writes the whole animation with its
+// frame delays and loop count. Other output formats get the first frame, or
+// the one chosen with Frame.
+type Pipeline struct {
+	img          image.Image
+	anim         *animation // all frames of an animated source, nil for stills
+	sourceFormat string     // format the image was decoded from
+	format       string     // output format set by Convert ("" = source or path format)
+	quality      int        // encode quality set by Compress (0 = encoder default)
+	speed        int        // AVIF encoder speed set by Speed (0 = encoder default)
+	filter       Filter     // resampling filter set by Filter ("" = FilterLanczos)
+	sourceWidth  int        // dimensions before any operation
+	sourceHeight int
+	meta         Metadata       // metadata read from the source
+	metadata     MetadataPolicy // which source metadata the encode keeps ("" = MetadataColor)
+	steps        []string       // applied steps in ParseStep syntax
+	err          error
+}
+
+// NewPipeline starts a pipeline from a decoded image and its source format
+func NewPipeline(img image.Image, sourceFormat string) *Pipeline {
+	bounds := img.Bounds()
+	return &Pipeline{
+		img:          img,
+		sourceFormat: normalizeFormat(sourceFormat),
+		sourceWidth:  bounds.Dx(),
+		sourceHeight: bounds.Dy(),
+	}
+}
+
+// DecodePipeline starts a pipeline from encoded image data
+func DecodePipeline(data []byte) (*Pipeline, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("input data is empty")
+	}
+
+	anim, err := decodeAnimation(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if anim != nil {
+		meta := ReadMetadata(data)
+		anim = anim.mapFrames(func(img image.Image) *image.NRGBA {
+			return imaging.Clone(orient(img, meta.Orientation))
+		})
+		p := NewPipeline(anim.frames[0].image, anim.format)
+		p.anim = anim
+		p.meta = meta
+		return p, nil
+	}
+
+	img, format, meta, err := decodeImageMetadata(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	p := NewPipeline(img, format)
+	p.meta = meta
+	return p, nil
+}
+
+// OpenPipeline starts a pipeline from an image file
+func OpenPipeline(path string) (*Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image %s: %w", path, err)
+	}
+	p, err := DecodePipeline(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Err returns the first error recorded by an operation
+func (p *Pipeline) Err() error {
+	return p.err
+}
+
+// Image returns the current image
+func (p *Pipeline) Image() image.Image {
+	return p.img
+}
+
+// Size returns the current image dimensions
+func (p *Pipeline) Size() (width, height int) {
+	bounds := p.img.Bounds()
+	return bounds.Dx(), bounds.Dy()
+}
+
+// SourceSize returns the image dimensions before any operation
+func (p *Pipeline) SourceSize() (width, height int) {
+	return p.sourceWidth, p.sourceHeight
+}
+
+// FrameCount returns the number of animation frames the pipeline holds, 1
+// for still images and after Frame
+func (p *Pipeline) FrameCount() int {
+	if p.anim == nil {
+		return 1
+	}
+	return len(p.anim.frames)
+}
+
+// Animated reports whether the output will be animated: the pipeline holds
+// several frames and the output format is GIF or WebP
+func (p *Pipeline) Animated() bool {
+	return p.anim != nil && SupportsAnimation(p.Format())
+}
+
+// SourceFormat returns the format the image was decoded from
+func (p *Pipeline) SourceFormat() string {
+	return p.sourceFormat
+}
+
+// Format returns the output format: the Convert target if set, otherwise
+// the source format (png when unknown)
+func (p *Pipeline) Format() string {
+	if p.format != "" {
+		return p.format
+	}
+	if isOutputFormat(p.sourceFormat) {
+		return p.sourceFormat
+	}
+	return "png"
+}
+
+// Quality returns the encode quality set by Compress (0 = encoder default)
+func (p *Pipeline) Quality() int {
+	return p.quality
+}
+
+// SourceMetadata returns the metadata read from the source image. Decoding
+// has already applied its orientation.
+func (p *Pipeline) SourceMetadata() Metadata {
+	return p.meta
+}
+
+// Steps returns the applied steps in ParseStep syntax
+func (p *Pipeline) Steps() []string {
+	return append([]string(nil), p.steps...)
+}
+
+// Apply runs a single step
+func (p *Pipeline) Apply(step Step) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+	if err := step.Validate(); err != nil {
+		p.err = err
+		return p
+	}
+
+	switch step.Op {
+	case StepCrop:
+		if step.Anchor != "" {
+			return p.CropAnchor(step.Width, step.Height, anchors[step.Anchor])
+		}
+		return p.Crop(step.X, step.Y, step.Width, step.Height)
+	case StepResize:
+		return p.Resize(step.Width, step.Height)
+	case StepFit:
+		return p.ResizeFit(step.Width, step.Height)
+	case StepFill:
+		if step.Anchor == AnchorSmart {
+			return p.SmartCrop(step.Width, step.Height)
+		}
+		return p.ResizeFill(step.Width, step.Height, anchors[step.Anchor])
+	case StepPad:
+		return p.ResizePad(step.Width, step.Height, step.Background)
+	case StepScale:
+		return p.Scale(step.Factor)
+	case StepSharpen:
+		return p.Sharpen(step.Amount)
+	case StepCompress:
+		return p.Compress(step.Quality)
+	case StepConvert:
+		return p.Convert(step.Format)
+	case StepFrame:
+		return p.Frame(step.Frame)
+	}
+	return p
+}
+
+// Crop keeps the width x height region whose top-left corner is at x, y
+func (p *Pipeline) Crop(x, y, width, height int) *Pipeline {
+	step := Step{Op: StepCrop, X: x, Y: y, Width: width, Height: height}
+	if p.check(step) {
+		return p
+	}
+
+	imgWidth, imgHeight := p.Size()
+	switch {
+	case x >= imgWidth:
+		p.err = fmt.Errorf("x coordinate %d is outside image width %d", x, imgWidth)
+	case y >= imgHeight:
+		p.err = fmt.Errorf("y coordinate %d is outside image height %d", y, imgHeight)
+	case x+width > imgWidth:
+		p.err = fmt.Errorf("crop region (x=%d + width=%d = %d) exceeds image width %d", x, width, x+width, imgWidth)
+	case y+height > imgHeight:
+		p.err = fmt.Errorf("crop region (y=%d + height=%d = %d) exceeds image height %d", y, height, y+height, imgHeight)
+	default:
+		p.transform(func(img image.Image) *image.NRGBA {
+			rect := image.Rect(x, y, x+width, y+height).Add(img.Bounds().Min)
+			return imaging.Crop(img, rect)
+		})
+		p.steps = append(p.steps, step.String())
+	}
+	return p
+}
+
+// CropCenter keeps a width x height region from the center of the image
+func (p *Pipeline) CropCenter(width, height int) *Pipeline {
+	return p.CropAnchor(width, height, imaging.Center)
+}
+
+// CropAnchor keeps a width x height region positioned by anchor
+func (p *Pipeline) CropAnchor(width, height int, anchor imaging.Anchor) *Pipeline {
+	step := Step{Op: StepCrop, Width: width, Height: height, Anchor: anchorName(anchor)}
+	if p.check(step) {
+		return p
+	}
+
+	imgWidth, imgHeight := p.Size()
+	switch {
+	case width > imgWidth:
+		p.err = fmt.Errorf("crop width %d exceeds image width %d", width, imgWidth)
+	case height > imgHeight:
+		p.err = fmt.Errorf("crop height %d exceeds image height %d", height, imgHeight)
+	default:
+		p.transform(func(img image.Image) *image.NRGBA {
+			return imaging.CropAnchor(img, width, height, anchor)
+		})
+		p.steps = append(p.steps, step.String())
+	}
+	return p
+}
+
+// Resize resizes to exactly width x height with the pipeline's resampling
+// filter (Lanczos unless set with Filter)
+func (p *Pipeline) Resize(width, height int) *Pipeline {
+	step := Step{Op: StepResize, Width: width, Height: height}
+	if p.check(step) {
+		return p
+	}
+	p.transform(func(img image.Image) *image.NRGBA {
+		return imaging.Resize(img, width, height, p.filter.resampleFilter())
+	})
+	p.steps = append(p.steps, step.String())
+	return p
+}
+
+// ResizeFit resizes to fit within width x height, preserving aspect ratio
+func (p *Pipeline) ResizeFit(width, height int) *Pipeline {
+	step := Step{Op: StepFit, Width: width, Height: height}
+	if p.check(step) {
+		return p
+	}
+	p.transform(func(img image.Image) *image.NRGBA {
+		return imaging.Fit(img, width, height, p.filter.resampleFilter())
+	})
+	p.steps = append(p.steps, step.String())
+	return p
+}
+
+// ResizeFill scales the image to cover width x height, preserving aspect
+// ratio, and crops the overflow at anchor, so the result is exactly width x
+// height with nothing stretched
+func (p *Pipeline) ResizeFill(width, height int, anchor imaging.Anchor) *Pipeline {
+	step := Step{Op: StepFill, Width: width, Height: height, Anchor: anchorName(anchor)}
+	if p.check(step) {
+		return p
+	}
+	p.transform(func(img image.Image) *image.NRGBA {
+		return fillImage(img, width, height, anchor, p.filter.resampleFilter())
+	})
+	p.steps = append(p.steps, step.String())
+	return p
+}
+
+// SmartCrop crops the most interesting window of the image with the aspect
+// ratio of width x height, judged by its detail, skin tones and color, and
+// scales it to exactly width x height. Animated images are cropped to the
+// window chosen for their first frame, so the crop does not jump around.
+func (p *Pipeline) SmartCrop(width, height int) *Pipeline {
+	step := Step{Op: StepFill, Width: width, Height: height, Anchor: AnchorSmart}
+	if p.check(step) {
+		return p
+	}
+	window := SmartCropRegion(p.img, width, height)
+	p.transform(func(img image.Image) *image.NRGBA {
+		cropped := imaging.Crop(img, window.Add(img.Bounds().Min))
+		return imaging.Resize(cropped, width, height, p.filter.resampleFilter())
+	})
+	p.steps = append(p.steps, step.String())
+	return p
+}
+
+// ResizePad scales the image to fit inside width x height, preserving
+// aspect ratio, and centers it on background, so the result is exactly
+// width x height with nothing cropped. background is a color name (black,
+// white, gray, transparent), a hex color such as "#1e90ff", or "blur" for
+// a blurred copy of the image; "" means DefaultBackground.
+func (p *Pipeline) ResizePad(width, height int, background string) *Pipeline {
+	step := Step{Op: StepPad, Width: width, Height: height, Background: normalizeBackground(background)}
+	if p.check(step) {
+		return p
+	}
+	fill, blur, _ := parseBackground(step.Background)
+	p.transform(func(img image.Image) *image.NRGBA {
+		return padImage(img, width, height, fill, blur, p.filter.resampleFilter())
+	})
+	p.steps = append(p.steps, step.String())
+	return p
+}
+
+// Scale multiplies both dimensions by factor (at least 1 pixel each)
+func (p *Pipeline) Scale(factor float64) *Pipeline {
+	step := Step{Op: StepScale, Factor: factor}
+	if p.check(step) {
+		return p
+	}
+
+	imgWidth, imgHeight := p.Size()
+	newWidth := max(int(float64(imgWidth)*factor), 1)
+	newHeight := max(int(float64(imgHeight)*factor), 1)
+	p.transform(func(img image.Image) *image.NRGBA {
+		return imaging.Resize(img, newWidth, newHeight, p.filter.resampleFilter())
+	})
+	p.steps = append(p.steps, step.String())
+	return p
+}
+
+// Sharpen applies an unsharp mask whose blur has a sigma of amount pixels
+// (up to MaxSharpen). Heavy downscales look soft; 0.5-1.5 after the resize
+// restores edge contrast.
+func (p *Pipeline) Sharpen(amount float64) *Pipeline {
+	step := Step{Op: StepSharpen, Amount: amount}
+	if p.check(step) {
+		return p
+	}
+	p.transform(func(img image.Image) *image.NRGBA {
+		return imaging.Sharpen(img, amount)
+	})
+	p.steps = append(p.steps, step.String())
+	return p
+}
+
+// Compress sets the quality used by the final encode (1-100). It affects
+// JPEG, AVIF and WebP, which switches from lossless to lossy encoding; PNG
+// and the other lossless formats ignore it.
+func (p *Pipeline) Compress(quality int) *Pipeline {
+	step := Step{Op: StepCompress, Quality: quality}
+	if p.check(step) {
+		return p
+	}
+	p.quality = quality
+	p.steps = append(p.steps, step.String())
+	return p
+}
+
+// Convert sets the format used by the final encode
+func (p *Pipeline) Convert(format string) *Pipeline {
+	step := Step{Op: StepConvert, Format: normalizeFormat(format)}
+	if p.check(step) {
+		return p
+	}
+	p.format = step.Format
+	p.steps = append(p.steps, step.String())
+	return p
+}
+
+// Frame keeps only frame index (counting from 0) of an animation, making
+// it a still image. A still image has just frame 0.
+func (p *Pipeline) Frame(index int) *Pipeline {
+	step := Step{Op: StepFrame, Frame: index}
+	if p.check(step) {
+		return p
+	}
+	if frames := p.FrameCount(); index >= frames {
+		p.err = fmt.Errorf("frame %d is out of range: the image has %d frame(s)", index, frames)
+		return p
+	}
+	if p.anim != nil {
+		p.img = p.anim.frames[index].image
+		p.anim = nil
+	}
+	p.steps = append(p.steps, step.String())
+	return p
+}
+
+// Metadata sets which source metadata the final encode writes (see
+// MetadataPolicy). Without it only the ICC color profile is kept.
+func (p *Pipeline) Metadata(policy MetadataPolicy) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+	policy, err := ParseMetadataPolicy(string(policy))
+	if err != nil {
+		p.err = err
+		return p
+	}
+	p.metadata = policy
+	return p
+}
+
+// Speed sets the AVIF encoder speed (1-10, 0 for the default). Slower
+// speeds produce smaller files at the same quality; other formats ignore
+// it.
+func (p *Pipeline) Speed(speed int) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+	if err := validateAVIFSpeed(speed); err != nil {
+		p.err = err
+		return p
+	}
+	p.speed = speed
+	return p
+}
+
+// Filter sets the resampling filter used by later Resize, ResizeFit and
+// Scale operations. Without it they use FilterLanczos.
+func (p *Pipeline) Filter(filter Filter) *Pipeline {
+	if p.err != nil {
+		return p
+	}
+	filter, err := ParseFilter(string(filter))
+	if err != nil {
+		p.err = err
+		return p
+	}
+	p.filter = filter
+	return p
+}
+
+// FileOptions are the settings of the file functions (ResizeImage,
+// CompressImage, ProcessFile, ...) besides their operation. The zero value
+// keeps the defaults.
+type FileOptions struct {
+	// Metadata is the source metadata to write (default MetadataColor)
+	Metadata MetadataPolicy
+
+	// Speed is the AVIF encoder speed, 1-MaxAVIFSpeed (0 for the encoder
+	// default); other formats ignore it
+	Speed int
+
+	// Frame, when set, keeps only that frame (counting from 0) of an
+	// animated input; nil keeps every frame
+	Frame *int
+
+	// Filter is the resampling filter of resize, scale and smart crop
+	// operations (default FilterLanczos)
+	Filter Filter
+
+	// Sharpen sharpens the result of ResizeImage, ResizeFit,
+	// ResizeWithOptions and ScaleImage by this amount (see
+	// Pipeline.Sharpen); 0 leaves it as it is
+	Sharpen float64
+}
+
+// withOptions applies opts. An out of range opts.Sharpen fails here, so the
+// file functions that do not resize reject it too.
+func (p *Pipeline) withOptions(opts FileOptions) *Pipeline {
+	p.Metadata(opts.Metadata).Speed(opts.Speed).Filter(opts.Filter)
+	if opts.Frame != nil {
+		p.Frame(*opts.Frame)
+	}
+	if err := validateSharpen(opts.Sharpen); err != nil && p.err == nil {
+		p.err = err
+	}
+	return p
+}
+
+// transform replaces the image, and every frame of an animation, with f's
+// result
+func (p *Pipeline) transform(f func(image.Image) *image.NRGBA) {
+	if p.anim == nil {
+		p.img = f(p.img)
+		return
+	}
+	p.anim = p.anim.mapFrames(f)
+	p.img = p.anim.frames[0].image
+}
+
+// check reports whether step must be skipped, recording its validation error
+func (p *Pipeline) check(step Step) bool {
+	if p.err != nil {
+		return true
+	}
+	if err := step.Validate(); err != nil {
+		p.err = err
+		return true
+	}
+	return false
+}
+
+// Encode writes the image in the pipeline's output format
+func (p *Pipeline) Encode(w io.Writer) error {
+	data, err := p.Bytes()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Bytes returns the image encoded in the pipeline's output format
+func (p *Pipeline) Bytes() ([]byte, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.encode(p.img, p.Format(), p.quality)
+}
+
+// Save encodes the image to path. Without a Convert step the format comes
+// from the path's extension; with one, the extension (if any) must match.
+// Afterwards Format and Animated describe the written file.
+func (p *Pipeline) Save(path string) error {
+	if p.err != nil {
+		return p.err
+	}
+
+	format := p.format
+	if format == "" {
+		format = ExtractFormatFromPath(path)
+		p.format = format
+	} else if ext := filepath.Ext(path); ext != "" && normalizeFormat(ext) != format {
+		return fmt.Errorf("output path %s does not match target format %s", path, format)
+	}
+
+	data, err := p.encode(p.img, format, p.quality)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// encode encodes img and embeds the source metadata the policy keeps. An
+// animated pipeline writes all its frames instead when format is GIF or
+// WebP; img is then its first frame.
+func (p *Pipeline) encode(img image.Image, format string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format = normalizeFormat(format); {
+	case p.anim != nil && format == "gif":
+		err = encodeGIFAnimation(&buf, p.anim)
+	case p.anim != nil && format == "webp":
+		err = encodeWebPAnimation(&buf, p.anim, quality)
+	case format == "avif":
+		err = encodeAVIF(&buf, img, quality, p.speed)
+	default:
+		err = encodeImageQuality(&buf, img, format, quality)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return writeMetadata(buf.Bytes(), format, p.meta.forPolicy(p.metadata))
+}
+
+// ProcessFile applies steps to the image at inputPath and saves the result
+// to outputPath with opts, decoding and encoding only once.
+//
+// Progress reporting can be provided via context using progress.WithReporter.
+func ProcessFile(ctx context.Context, inputPath, outputPath string, opts FileOptions, steps ...Step) error {
+	return processFile(ctx, inputPath, outputPath, opts, fmt.Sprintf("Processing image (%d steps)", len(steps)), "processed", steps...)
+}
+
+// processFile is the shared body of ProcessFile and the single-operation
+// file functions. Steps are validated before the input is read.
+func processFile(ctx context.Context, inputPath, outputPath string, opts FileOptions, operation, noun string, steps ...Step) error {
+	reporter := progress.FromContext(ctx)
+	reporter.Start(ctx, operation)
+
+	for _, step := range steps {
+		if err := step.Validate(); err != nil {
+			reporter.Error(err)
+			return err
+		}
+	}
+
+	total := int64(len(steps) + 2)
+	cancelled := func() error {
+		select {
+		case <-ctx.Done():
+			err := fmt.Errorf("operation cancelled: %w", ctx.Err())
+			reporter.Error(err)
+			return err
+		default:
+			return nil
+		}
+	}
+
+	if err := cancelled(); err != nil {
+		return err
+	}
+
+	// Load the input image
+	reporter.Update(1, total, "Loading input image")
+	p, err := OpenPipeline(inputPath)
+	if err != nil {
+		reporter.Error(err)
+		return err
+	}
+	if err := p.withOptions(opts).Err(); err != nil {
+		reporter.Error(err)
+		return err
+	}
+
+	for i, step := range steps {
+		if err := cancelled(); err != nil {
+			return err
+		}
+		reporter.Update(int64(i+2), total, fmt.Sprintf("Applying %s", step))
+		if err := p.Apply(step).Err(); err != nil {
+			reporter.Error(err)
+			return err
+		}
+	}
+
+	if err := cancelled(); err != nil {
+		return err
+	}
+
+	// Save with a single encode
+	reporter.Update(total, total, fmt.Sprintf("Saving %s image", noun))
+	if err := p.Save(outputPath); err != nil {
+		err = fmt.Errorf("failed to save %s image to %s: %w", noun, outputPath, err)
+		reporter.Error(err)
+		return err
+	}
+
+	reporter.Complete(outputPath)
+	return nil
+}
@@ -0,0 +1,216 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStep(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Step
+	}{
+		{spec: "crop:10,20,300,200", want: Step{Op: StepCrop, X: 10, Y: 20, Width: 300, Height: 200}},
+		{spec: "crop:300x200", want: Step{Op: StepCrop, Width: 300, Height: 200, Anchor: "center"}},
+		{spec: "crop:300x200@Top-Left", want: Step{Op: StepCrop, Width: 300, Height: 200, Anchor: "top-left"}},
+		{spec: "resize:800x600", want: Step{Op: StepResize, Width: 800, Height: 600}},
+		{spec: " fit: 512x512 ", want: Step{Op: StepFit, Width: 512, Height: 512}},
+		{spec: "fill:1200x630", want: Step{Op: StepFill, Width: 1200, Height: 630, Anchor: "center"}},
+		{spec: "fill:1200x630@Top", want: Step{Op: StepFill, Width: 1200, Height: 630, Anchor: "top"}},
+		{spec: "fill:400x400@smart", want: Step{Op: StepFill, Width: 400, Height: 400, Anchor: AnchorSmart}},
+		{spec: "pad:1080x1080", want: Step{Op: StepPad, Width: 1080, Height: 1080, Background: "black"}},
+		{spec: "pad:1080x1080@blur", want: Step{Op: StepPad, Width: 1080, Height: 1080, Background: "blur"}},
+		{spec: "pad:64x64@#1E90FF", want: Step{Op: StepPad, Width: 64, Height: 64, Background: "#1e90ff"}},
+		{spec: "scale:0.5", want: Step{Op: StepScale, Factor: 0.5}},
+		{spec: "sharpen:0.8", want: Step{Op: StepSharpen, Amount: 0.8}},
+		{spec: "compress:80", want: Step{Op: StepCompress, Quality: 80}},
+		{spec: "convert:JPG", want: Step{Op: StepConvert, Format: "jpeg"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			step, err := ParseStep(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, step)
+
+			// String round-trips through ParseStep
+			again, err := ParseStep(step.String())
+			require.NoError(t, err)
+			assert.Equal(t, step, again)
+		})
+	}
+}
+
+func TestParseStep_Invalid(t *testing.T) {
+	tests := []struct {
+		spec   string
+		errMsg string
+	}{
+		{spec: "resize", errMsg: "expected op:args"},
+		{spec: "blur:3", errMsg: "unknown step"},
+		{spec: "resize:800", errMsg: "expected WIDTHxHEIGHT"},
+		{spec: "resize:0x600", errMsg: "width must be positive"},
+		{spec: "crop:1,2,3", errMsg: "expected 4 comma-separated integers"},
+		{spec: "crop:-1,0,10,10", errMsg: "x coordinate must be non-negative"},
+		{spec: "crop:10x10@middle", errMsg: "unknown crop anchor"},
+		{spec: "crop:10x10@smart", errMsg: "unknown crop anchor"},
+		{spec: "fill:10x10@middle", errMsg: "unknown fill anchor"},
+		{spec: "fill:0x10", errMsg: "width must be positive"},
+		{spec: "pad:10x10@purple", errMsg: "invalid background"},
+		{spec: "pad:10x10@#12345", errMsg: "invalid background"},
+		{spec: "scale:-2", errMsg: "factor must be positive"},
+		{spec: "sharpen:0", errMsg: "sharpen amount must be positive"},
+		{spec: "sharpen:11", errMsg: "sharpen amount must be between 0 and 10"},
+		{spec: "compress:101", errMsg: "quality must be between 1 and 100"},
+		{spec: "convert:svg", errMsg: "unsupported output format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := ParseStep(tt.spec)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestPipeline_Chain(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 800, 600))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+
+	p := NewPipeline(img, "png").
+		Crop(0, 0, 400, 300).
+		Resize(200, 150).
+		Scale(0.5).
+		Compress(70).
+		Convert("jpg")
+	require.NoError(t, p.Err())
+
+	width, height := p.Size()
+	assert.Equal(t, 100, width)
+	assert.Equal(t, 75, height)
+	sourceWidth, sourceHeight := p.SourceSize()
+	assert.Equal(t, 800, sourceWidth)
+	assert.Equal(t, 600, sourceHeight)
+	assert.Equal(t, "png", p.SourceFormat())
+	assert.Equal(t, "jpeg", p.Format())
+	assert.Equal(t, 70, p.Quality())
+	assert.Equal(t, []string{"crop:0,0,400,300", "resize:200x150", "scale:0.5", "compress:70", "convert:jpeg"}, p.Steps())
+
+	data, err := p.Bytes()
+	require.NoError(t, err)
+	decoded, format, err := image.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, 100, decoded.Bounds().Dx())
+	assert.Equal(t, 75, decoded.Bounds().Dy())
+}
+
+func TestPipeline_FirstErrorSticks(t *testing.T) {
+	p := NewPipeline(image.NewRGBA(image.Rect(0, 0, 100, 100)), "png").
+		CropCenter(200, 50).
+		Resize(10, 10)
+
+	require.Error(t, p.Err())
+	assert.Contains(t, p.Err().Error(), "crop width 200 exceeds image width 100")
+	assert.Empty(t, p.Steps(), "operations after a failure must be skipped")
+
+	_, err := p.Bytes()
+	assert.Equal(t, p.Err(), err)
+}
+
+func TestPipeline_CompressQuality(t *testing.T) {
+	// A noisy image so JPEG quality changes the encoded size
+	img := image.NewRGBA(image.Rect(0, 0, 256, 256))
+	for y := 0; y < 256; y++ {
+		for x := 0; x < 256; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * y), G: uint8(x ^ y), B: uint8(x + y), A: 255})
+		}
+	}
+
+	high, err := NewPipeline(img, "jpeg").Compress(95).Bytes()
+	require.NoError(t, err)
+	low, err := NewPipeline(img, "jpeg").Compress(20).Bytes()
+	require.NoError(t, err)
+	assert.Less(t, len(low), len(high))
+}
+
+func TestDecodePipeline(t *testing.T) {
+	_, err := DecodePipeline(nil)
+	assert.Error(t, err)
+
+	_, err = DecodePipeline([]byte("not an image"))
+	assert.Error(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 30)), nil))
+
+	p, err := DecodePipeline(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", p.SourceFormat())
+	assert.Equal(t, "jpeg", p.Format(), "output format defaults to the source format")
+}
+
+func TestPipeline_Save(t *testing.T) {
+	tmpDir := t.TempDir()
+	img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+
+	// Format from the extension
+	pngPath := filepath.Join(tmpDir, "out.png")
+	require.NoError(t, NewPipeline(img, "jpeg").Save(pngPath))
+	f, err := os.Open(pngPath)
+	require.NoError(t, err)
+	_, err = png.Decode(f)
+	f.Close()
+	require.NoError(t, err)
+
+	// A convert step must agree with the extension
+	err = NewPipeline(img, "png").Convert("jpeg").Save(filepath.Join(tmpDir, "out.webp"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match target format jpeg")
+
+	require.NoError(t, NewPipeline(img, "png").Convert("webp").Save(filepath.Join(tmpDir, "out.webp")))
+}
+
+func TestProcessFile(t *testing.T) {
+	inputPath := setupTestImage(t, 800, 600)
+	outputPath := filepath.Join(t.TempDir(), "out.jpg")
+
+	steps, err := ParseSteps([]string{"crop:100,100,600,400", "resize:300x200", "compress:80"})
+	require.NoError(t, err)
+	require.NoError(t, ProcessFile(context.Background(), inputPath, outputPath, FileOptions{}, steps...))
+
+	out, err := imaging.Open(outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, 300, out.Bounds().Dx())
+	assert.Equal(t, 200, out.Bounds().Dy())
+}
+
+func TestProcessFile_Errors(t *testing.T) {
+	inputPath := setupTestImage(t, 100, 100)
+	outputPath := filepath.Join(t.TempDir(), "out.png")
+
+	err := ProcessFile(context.Background(), inputPath, outputPath, FileOptions{}, Step{Op: StepCrop, X: 90, Y: 0, Width: 20, Height: 20})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds image width 100")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = ProcessFile(ctx, inputPath, outputPath, FileOptions{}, Step{Op: StepScale, Factor: 2})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "operation cancelled")
+
+	_, statErr := os.Stat(outputPath)
+	assert.True(t, os.IsNotExist(statErr), "no output is written on failure")
+}
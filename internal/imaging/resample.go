@@ -0,0 +1,273 @@
+// Package imaging provides image processing operations using pure Go.
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+// Filter is a resampling filter used when resizing. Sharper filters keep
+// more detail but cost more time per pixel.
+type Filter string
+
+const (
+	// FilterNearest copies the nearest source pixel. It keeps the hard edges
+	// of pixel art and is the fastest.
+	FilterNearest Filter = "nearest"
+
+	// FilterBox averages the source pixels under each output pixel
+	FilterBox Filter = "box"
+
+	// FilterLinear is bilinear interpolation: fast, slightly soft
+	FilterLinear Filter = "linear"
+
+	// FilterMitchell is the Mitchell-Netravali cubic, a balance between
+	// blurring and ringing
+	FilterMitchell Filter = "mitchell"
+
+	// FilterCatmullRom is a sharp cubic, close to Lanczos at a lower cost
+	FilterCatmullRom Filter = "catmullrom"
+
+	// FilterLanczos is the sharpest and slowest filter. It is the default.
+	FilterLanczos Filter = "lanczos"
+)
+
+// filters lists the filters from fastest to sharpest, the order they are
+// documented in
+var filters = []Filter{FilterNearest, FilterBox, FilterLinear, FilterMitchell, FilterCatmullRom, FilterLanczos}
+
+// filterAliases maps other common names to filters
+var filterAliases = map[string]Filter{
+	"nearest-neighbor": FilterNearest,
+	"bilinear":         FilterLinear,
+	"bicubic":          FilterCatmullRom,
+	"catmull-rom":      FilterCatmullRom,
+}
+
+// MaxSharpen is the largest sharpen amount. Amounts are the sigma of the
+// unsharp mask's blur in pixels; 0.5-1.5 suits most downscales.
+const MaxSharpen = 10.0
+
+// FilterNames returns the filter names from fastest to sharpest
+func FilterNames() []string {
+	names := make([]string, len(filters))
+	for i, filter := range filters {
+		names[i] = string(filter)
+	}
+	return names
+}
+
+// ParseFilter parses a filter name; "" selects FilterLanczos
+func ParseFilter(s string) (Filter, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return FilterLanczos, nil
+	}
+	if filter, ok := filterAliases[s]; ok {
+		return filter, nil
+	}
+	for _, filter := range filters {
+		if string(filter) == s {
+			return filter, nil
+		}
+	}
+	return "", fmt.Errorf("unknown resampling filter %q (supported: %s)", s, strings.Join(FilterNames(), ", "))
+}
+
+// resampleFilter returns the imaging filter for f ("" = FilterLanczos)
+func (f Filter) resampleFilter() imaging.ResampleFilter {
+	switch f {
+	case FilterNearest:
+		return imaging.NearestNeighbor
+	case FilterBox:
+		return imaging.Box
+	case FilterLinear:
+		return imaging.Linear
+	case FilterMitchell:
+		return imaging.MitchellNetravali
+	case FilterCatmullRom:
+		return imaging.CatmullRom
+	default:
+		return imaging.Lanczos
+	}
+}
+
+// validateSharpen checks a sharpen amount (0 = none)
+func validateSharpen(amount float64) error {
+	if amount < 0 || amount > MaxSharpen {
+		return fmt.Errorf("sharpen amount must be between 0 and %g, got %g", MaxSharpen, amount)
+	}
+	return nil
+}
+
+// ResizeMode decides how a resize treats the source aspect ratio
+type ResizeMode string
+
+const (
+	// ResizeModeStretch resizes to exactly the requested size, stretching
+	// the image when the aspect ratios differ. It is the default.
+	ResizeModeStretch ResizeMode = "stretch"
+
+	// ResizeModeFit resizes to fit within the requested size, preserving
+	// the aspect ratio; one side may come out smaller
+	ResizeModeFit ResizeMode = "fit"
+
+	// ResizeModeFill scales the image to cover the requested size and crops
+	// the overflow, keeping the part chosen by an anchor
+	ResizeModeFill ResizeMode = "fill"
+
+	// ResizeModePad scales the image to fit inside the requested size and
+	// letterboxes it on a background color or a blurred copy of itself
+	ResizeModePad ResizeMode = "pad"
+)
+
+// resizeModes lists the modes in the order they are documented
+var resizeModes = []ResizeMode{ResizeModeStretch, ResizeModeFit, ResizeModeFill, ResizeModePad}
+
+// ResizeModeNames returns the mode names in the order they are documented
+func ResizeModeNames() []string {
+	names := make([]string, len(resizeModes))
+	for i, mode := range resizeModes {
+		names[i] = string(mode)
+	}
+	return names
+}
+
+// ParseResizeMode parses a mode name; "" selects ResizeModeStretch
+func ParseResizeMode(s string) (ResizeMode, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return ResizeModeStretch, nil
+	}
+	for _, mode := range resizeModes {
+		if string(mode) == s {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unknown resize mode %q (supported: %s)", s, strings.Join(ResizeModeNames(), ", "))
+}
+
+// ResizeOptions are the settings of a resize besides its size
+type ResizeOptions struct {
+	Mode       ResizeMode // "" = ResizeModeStretch
+	Anchor     string     // part of the image ResizeModeFill keeps ("" = center)
+	Background string     // ResizeModePad background: a color or "blur" ("" = DefaultBackground)
+}
+
+// ResizeStep returns the pipeline step that resizes to width x height with
+// opts
+func ResizeStep(width, height int, opts ResizeOptions) Step {
+	step := Step{Op: StepResize, Width: width, Height: height}
+	switch opts.Mode {
+	case ResizeModeFit:
+		step.Op = StepFit
+	case ResizeModeFill:
+		step.Op = StepFill
+		step.Anchor = strings.ToLower(strings.TrimSpace(opts.Anchor))
+		if step.Anchor == "" {
+			step.Anchor = "center"
+		}
+	case ResizeModePad:
+		step.Op = StepPad
+		step.Background = normalizeBackground(opts.Background)
+	}
+	return step
+}
+
+// DefaultBackground is the letterbox color of ResizeModePad when none is
+// given
+const DefaultBackground = "black"
+
+// BackgroundBlur selects a blurred, enlarged copy of the image as the
+// ResizeModePad background
+const BackgroundBlur = "blur"
+
+// namedBackgrounds are the background colors accepted by name
+var namedBackgrounds = map[string]color.NRGBA{
+	"black":       {0, 0, 0, 255},
+	"white":       {255, 255, 255, 255},
+	"gray":        {128, 128, 128, 255},
+	"transparent": {},
+}
+
+// normalizeBackground lowercases a background and fills in the default
+func normalizeBackground(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return DefaultBackground
+	}
+	return s
+}
+
+// parseBackground parses a pad background: "blur", a color name (black,
+// white, gray or transparent) or a hex color #RGB, #RRGGBB or #RRGGBBAA
+// (the # is optional). It reports blur as true.
+func parseBackground(s string) (color.NRGBA, bool, error) {
+	s = normalizeBackground(s)
+	if s == BackgroundBlur {
+		return color.NRGBA{}, true, nil
+	}
+	if c, ok := namedBackgrounds[s]; ok {
+		return c, false, nil
+	}
+
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 8 || err != nil {
+		return color.NRGBA{}, false, fmt.Errorf("invalid background %q (expected blur, black, white, gray, transparent or a hex color such as #1e90ff)", s)
+	}
+	return color.NRGBA{R: uint8(value >> 24), G: uint8(value >> 16), B: uint8(value >> 8), A: uint8(value)}, false, nil
+}
+
+// fillImage scales img to cover width x height and crops the overflow at
+// anchor
+func fillImage(img image.Image, width, height int, anchor imaging.Anchor, filter imaging.ResampleFilter) *image.NRGBA {
+	bounds := img.Bounds()
+	scale := math.Max(float64(width)/float64(bounds.Dx()), float64(height)/float64(bounds.Dy()))
+	coverWidth := max(int(math.Ceil(float64(bounds.Dx())*scale)), width)
+	coverHeight := max(int(math.Ceil(float64(bounds.Dy())*scale)), height)
+	return imaging.CropAnchor(imaging.Resize(img, coverWidth, coverHeight, filter), width, height, anchor)
+}
+
+// padImage scales img to fit inside width x height, enlarging it if
+// needed, and centers it on background (or a blurred copy of img when blur
+// is set)
+func padImage(img image.Image, width, height int, background color.NRGBA, blur bool, filter imaging.ResampleFilter) *image.NRGBA {
+	bounds := img.Bounds()
+	scale := math.Min(float64(width)/float64(bounds.Dx()), float64(height)/float64(bounds.Dy()))
+	fitWidth := min(max(int(math.Round(float64(bounds.Dx())*scale)), 1), width)
+	fitHeight := min(max(int(math.Round(float64(bounds.Dy())*scale)), 1), height)
+	foreground := imaging.Resize(img, fitWidth, fitHeight, filter)
+
+	var canvas *image.NRGBA
+	if blur {
+		// Blurring at an eighth of the size and scaling back up is much
+		// cheaper than a wide blur at full size, and looks the same
+		small := imaging.Fill(img, max(width/8, 1), max(height/8, 1), imaging.Center, imaging.Linear)
+		canvas = imaging.Resize(imaging.Blur(small, 3), width, height, imaging.Linear)
+	} else {
+		canvas = imaging.New(width, height, background)
+	}
+	return imaging.OverlayCenter(canvas, foreground, 1)
+}
+
+// resizeSteps returns step followed by the sharpen step opts asks for, if
+// any
+func resizeSteps(opts FileOptions, step Step) []Step {
+	if opts.Sharpen > 0 {
+		return []Step{step, {Op: StepSharpen, Amount: opts.Sharpen}}
+	}
+	return []Step{step}
+}
@@ -0,0 +1,258 @@
+package imaging
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		want Filter
+	}{
+		{name: "", want: FilterLanczos},
+		{name: "Nearest", want: FilterNearest},
+		{name: "nearest-neighbor", want: FilterNearest},
+		{name: "bilinear", want: FilterLinear},
+		{name: "catmull-rom", want: FilterCatmullRom},
+		{name: " mitchell ", want: FilterMitchell},
+	}
+	for _, tt := range tests {
+		filter, err := ParseFilter(tt.name)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, filter, tt.name)
+	}
+
+	_, err := ParseFilter("sinc")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown resampling filter")
+	assert.Equal(t, []string{"nearest", "box", "linear", "mitchell", "catmullrom", "lanczos"}, FilterNames())
+}
+
+func TestParseResizeMode(t *testing.T) {
+	mode, err := ParseResizeMode("")
+	require.NoError(t, err)
+	assert.Equal(t, ResizeModeStretch, mode)
+
+	mode, err = ParseResizeMode("FIT")
+	require.NoError(t, err)
+	assert.Equal(t, ResizeModeFit, mode)
+
+	_, err = ParseResizeMode("squash")
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"stretch", "fit", "fill", "pad"}, ResizeModeNames())
+
+	assert.Equal(t, Step{Op: StepFit, Width: 4, Height: 3}, ResizeStep(4, 3, ResizeOptions{Mode: ResizeModeFit}))
+	assert.Equal(t, Step{Op: StepResize, Width: 4, Height: 3}, ResizeStep(4, 3, ResizeOptions{}))
+	assert.Equal(t, Step{Op: StepFill, Width: 4, Height: 3, Anchor: "center"}, ResizeStep(4, 3, ResizeOptions{Mode: ResizeModeFill}))
+	assert.Equal(t, Step{Op: StepFill, Width: 4, Height: 3, Anchor: "top"}, ResizeStep(4, 3, ResizeOptions{Mode: ResizeModeFill, Anchor: "Top"}))
+	assert.Equal(t, Step{Op: StepPad, Width: 4, Height: 3, Background: "black"}, ResizeStep(4, 3, ResizeOptions{Mode: ResizeModePad}))
+}
+
+func TestParseBackground(t *testing.T) {
+	tests := []struct {
+		background string
+		want       color.NRGBA
+		blur       bool
+	}{
+		{background: "", want: color.NRGBA{0, 0, 0, 255}},
+		{background: "White", want: color.NRGBA{255, 255, 255, 255}},
+		{background: "transparent", want: color.NRGBA{}},
+		{background: "blur", blur: true},
+		{background: "#1e90ff", want: color.NRGBA{0x1e, 0x90, 0xff, 255}},
+		{background: "f0a", want: color.NRGBA{0xff, 0x00, 0xaa, 255}},
+		{background: "#00000080", want: color.NRGBA{0, 0, 0, 0x80}},
+	}
+	for _, tt := range tests {
+		c, blur, err := parseBackground(tt.background)
+		require.NoError(t, err, tt.background)
+		assert.Equal(t, tt.want, c, tt.background)
+		assert.Equal(t, tt.blur, blur, tt.background)
+	}
+
+	for _, bad := range []string{"purple", "#12", "#12345", "#gggggg"} {
+		_, _, err := parseBackground(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+// halves returns a width x height image whose top half is red and bottom
+// half blue
+func halves(width, height int) *image.NRGBA {
+	img := imaging.New(width, height, color.NRGBA{255, 0, 0, 255})
+	for y := height / 2; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{0, 0, 255, 255})
+		}
+	}
+	return img
+}
+
+func TestPipeline_ResizeFill(t *testing.T) {
+	// A tall 100x200 image filled to 100x50 keeps a band of the chosen part
+	p := NewPipeline(halves(100, 200), "png").ResizeFill(100, 50, imaging.Top)
+	require.NoError(t, p.Err())
+	w, h := p.Size()
+	assert.Equal(t, 100, w)
+	assert.Equal(t, 50, h)
+	assert.Equal(t, []string{"fill:100x50@top"}, p.Steps())
+	assert.Equal(t, color.NRGBA{255, 0, 0, 255}, p.Image().(*image.NRGBA).NRGBAAt(50, 25))
+
+	p = NewPipeline(halves(100, 200), "png").Apply(Step{Op: StepFill, Width: 100, Height: 50, Anchor: "bottom"})
+	require.NoError(t, p.Err())
+	assert.Equal(t, color.NRGBA{0, 0, 255, 255}, p.Image().(*image.NRGBA).NRGBAAt(50, 25))
+
+	// Filling enlarges a small image to cover the target
+	p = NewPipeline(halves(10, 10), "png").ResizeFill(40, 30, imaging.Center)
+	require.NoError(t, p.Err())
+	w, h = p.Size()
+	assert.Equal(t, 40, w)
+	assert.Equal(t, 30, h)
+}
+
+func TestPipeline_ResizePad(t *testing.T) {
+	// A wide 200x100 image padded to 100x100 is letterboxed top and bottom
+	p := NewPipeline(halves(200, 100), "png").ResizePad(100, 100, "white")
+	require.NoError(t, p.Err())
+	w, h := p.Size()
+	assert.Equal(t, 100, w)
+	assert.Equal(t, 100, h)
+	assert.Equal(t, []string{"pad:100x100@white"}, p.Steps())
+	out := p.Image().(*image.NRGBA)
+	assert.Equal(t, color.NRGBA{255, 255, 255, 255}, out.NRGBAAt(50, 5))
+	assert.Equal(t, color.NRGBA{255, 255, 255, 255}, out.NRGBAAt(50, 95))
+	assert.Equal(t, color.NRGBA{255, 0, 0, 255}, out.NRGBAAt(50, 30))
+
+	// Padding enlarges a small image to touch the edges
+	p = NewPipeline(halves(10, 20), "png").ResizePad(100, 100, "#00ff00")
+	require.NoError(t, p.Err())
+	out = p.Image().(*image.NRGBA)
+	assert.Equal(t, color.NRGBA{0, 255, 0, 255}, out.NRGBAAt(5, 50))
+	assert.Equal(t, color.NRGBA{255, 0, 0, 255}, out.NRGBAAt(50, 1))
+
+	// A transparent background leaves the bars clear
+	out = NewPipeline(halves(200, 100), "png").ResizePad(100, 100, "transparent").Image().(*image.NRGBA)
+	assert.Equal(t, uint8(0), out.NRGBAAt(50, 5).A)
+
+	// A blurred background takes its colors from the image
+	out = NewPipeline(halves(200, 100), "png").ResizePad(100, 100, "blur").Image().(*image.NRGBA)
+	bar := out.NRGBAAt(50, 2)
+	assert.Equal(t, uint8(255), bar.A)
+	assert.Greater(t, int(bar.R)+int(bar.B), 128)
+
+	assert.Error(t, NewPipeline(halves(20, 10), "png").ResizePad(10, 10, "purple").Err())
+}
+
+func TestResizeWithOptions(t *testing.T) {
+	inputPath := filepath.Join(t.TempDir(), "halves.png")
+	require.NoError(t, imaging.Save(halves(60, 40), inputPath))
+	outputPath := filepath.Join(t.TempDir(), "card.png")
+
+	for _, opts := range []ResizeOptions{
+		{Mode: ResizeModeFill, Anchor: "top"},
+		{Mode: ResizeModePad, Background: "blur"},
+	} {
+		require.NoError(t, ResizeWithOptions(context.Background(), inputPath, outputPath, 120, 63, opts, FileOptions{}), opts.Mode)
+		out, err := imaging.Open(outputPath)
+		require.NoError(t, err)
+		assert.Equal(t, image.Pt(120, 63), out.Bounds().Size(), opts.Mode)
+	}
+
+	err := ResizeWithOptions(context.Background(), inputPath, outputPath, 120, 63, ResizeOptions{Mode: ResizeModeFill, Anchor: "middle"}, FileOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown fill anchor")
+}
+
+// checkerboard returns a size x size image of cell x cell black and white
+// squares
+func checkerboard(size, cell int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			c := color.NRGBA{A: 255}
+			if (x/cell+y/cell)%2 == 0 {
+				c = color.NRGBA{255, 255, 255, 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestPipeline_FilterNearestKeepsHardEdges(t *testing.T) {
+	// Upscaling 4x4 cells by 4 with nearest gives pure black and white
+	p := NewPipeline(checkerboard(16, 4), "png").Filter(FilterNearest).Scale(4)
+	require.NoError(t, p.Err())
+	out := p.Image().(*image.NRGBA)
+	for _, v := range out.Pix {
+		assert.True(t, v == 0 || v == 255, "nearest produced an in-between value %d", v)
+	}
+
+	// Lanczos blends across the cell edges
+	blended := false
+	out = NewPipeline(checkerboard(16, 4), "png").Scale(4).Image().(*image.NRGBA)
+	for _, v := range out.Pix {
+		if v != 0 && v != 255 {
+			blended = true
+			break
+		}
+	}
+	assert.True(t, blended)
+
+	assert.Error(t, NewPipeline(checkerboard(16, 4), "png").Filter("sinc").Err())
+}
+
+func TestPipeline_Sharpen(t *testing.T) {
+	img := imaging.Blur(checkerboard(32, 8), 1.5)
+	p := NewPipeline(img, "png").Sharpen(1)
+	require.NoError(t, p.Err())
+	assert.Equal(t, []string{"sharpen:1"}, p.Steps())
+
+	// Sharpening pushes edge pixels back toward black and white
+	contrast := func(img image.Image) int {
+		total := 0
+		nrgba := imaging.Clone(img)
+		for i := 0; i < len(nrgba.Pix); i += 4 {
+			v := int(nrgba.Pix[i])
+			total += max(v, 255-v)
+		}
+		return total
+	}
+	assert.Greater(t, contrast(p.Image()), contrast(img))
+
+	assert.Error(t, NewPipeline(img, "png").Sharpen(-1).Err())
+	assert.Error(t, NewPipeline(img, "png").Sharpen(MaxSharpen+1).Err())
+}
+
+func TestResizeImage_FilterAndSharpenOptions(t *testing.T) {
+	inputPath := filepath.Join(t.TempDir(), "board.png")
+	require.NoError(t, imaging.Save(checkerboard(16, 4), inputPath))
+	outputPath := filepath.Join(t.TempDir(), "big.png")
+
+	require.NoError(t, ScaleImage(context.Background(), inputPath, outputPath, 2, FileOptions{Filter: FilterNearest}))
+	out, err := imaging.Open(outputPath)
+	require.NoError(t, err)
+	for _, v := range imaging.Clone(out).Pix {
+		assert.True(t, v == 0 || v == 255, "nearest produced an in-between value %d", v)
+	}
+
+	require.NoError(t, ResizeFit(context.Background(), inputPath, outputPath, 8, 8, FileOptions{Sharpen: 1}))
+
+	for _, amount := range []float64{-1, MaxSharpen + 1} {
+		err = ResizeImage(context.Background(), inputPath, outputPath, 8, 8, FileOptions{Sharpen: amount})
+		require.Error(t, err, "sharpen %g", amount)
+		assert.Contains(t, err.Error(), "sharpen amount")
+	}
+
+	err = CompressImage(context.Background(), inputPath, outputPath, 80, FileOptions{Sharpen: -1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sharpen amount")
+}
@@ -2,14 +2,16 @@
 package imaging
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"image"
 
-	"github.com/apresai/gimage/internal/progress"
 	"github.com/disintegration/imaging"
 )
 
-// ResizeImage resizes an image to specific dimensions using high-quality Lanczos resampling.
+// ResizeImage resizes an image to specific dimensions using high-quality Lanczos resampling,
+// or opts.Filter. opts.Sharpen sharpens the result.
 //
 // Parameters:
 //   - ctx: context for cancellation support
@@ -17,6 +19,7 @@ import (
 //   - outputPath: path to save resized image
 //   - width: target width in pixels
 //   - height: target height in pixels
+//   - opts: file settings (see FileOptions)
 //
 // The image will be resized to exactly the specified dimensions. If you want to preserve
 // aspect ratio, use ResizeFit or calculate one dimension based on the other.
@@ -28,71 +31,9 @@ import (
 //   - input file does not exist or cannot be read
 //   - dimensions are not positive
 //   - output cannot be written
-func ResizeImage(ctx context.Context, inputPath, outputPath string, width, height int) error {
-	reporter := progress.FromContext(ctx)
-	reporter.Start(ctx, fmt.Sprintf("Resizing image to %dx%d", width, height))
-	// Validate dimensions
-	if width <= 0 {
-		err := fmt.Errorf("width must be positive, got %d", width)
-		reporter.Error(err)
-		return err
-	}
-	if height <= 0 {
-		err := fmt.Errorf("height must be positive, got %d", height)
-		reporter.Error(err)
-		return err
-	}
-
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
-	}
-
-	// Load the input image
-	reporter.Update(1, 3, "Loading input image")
-	img, err := imaging.Open(inputPath)
-	if err != nil {
-		err = fmt.Errorf("failed to open image %s: %w", inputPath, err)
-		reporter.Error(err)
-		return err
-	}
-
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
-	}
-
-	// Resize using Lanczos resampling (high quality)
-	reporter.Update(2, 3, "Resizing image")
-	resized := imaging.Resize(img, width, height, imaging.Lanczos)
-
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
-	}
-
-	// Save the resized image
-	reporter.Update(3, 3, "Saving resized image")
-	if err := imaging.Save(resized, outputPath); err != nil {
-		err = fmt.Errorf("failed to save resized image to %s: %w", outputPath, err)
-		reporter.Error(err)
-		return err
-	}
-
-	reporter.Complete(outputPath)
-	return nil
+func ResizeImage(ctx context.Context, inputPath, outputPath string, width, height int, opts FileOptions) error {
+	return processFile(ctx, inputPath, outputPath, opts, fmt.Sprintf("Resizing image to %dx%d", width, height), "resized",
+		resizeSteps(opts, Step{Op: StepResize, Width: width, Height: height})...)
 }
 
 // ResizeFit resizes an image to fit within specified dimensions while preserving aspect ratio.
@@ -103,75 +44,64 @@ func ResizeImage(ctx context.Context, inputPath, outputPath string, width, heigh
 //   - outputPath: path to save resized image
 //   - width: maximum width in pixels
 //   - height: maximum height in pixels
+//   - opts: file settings (see FileOptions)
 //
 // The image will be resized to fit within the specified dimensions while maintaining
 // its original aspect ratio. The resulting image may be smaller than the specified
-// dimensions in one or both directions.
+// dimensions in one or both directions. opts.Filter and opts.Sharpen apply
+// as for ResizeImage.
 //
 // Progress reporting can be provided via context using progress.WithReporter.
-func ResizeFit(ctx context.Context, inputPath, outputPath string, width, height int) error {
-	reporter := progress.FromContext(ctx)
-	reporter.Start(ctx, fmt.Sprintf("Resizing image to fit %dx%d", width, height))
-	// Validate dimensions
-	if width <= 0 {
-		err := fmt.Errorf("width must be positive, got %d", width)
-		reporter.Error(err)
-		return err
-	}
-	if height <= 0 {
-		err := fmt.Errorf("height must be positive, got %d", height)
-		reporter.Error(err)
-		return err
-	}
+func ResizeFit(ctx context.Context, inputPath, outputPath string, width, height int, opts FileOptions) error {
+	return processFile(ctx, inputPath, outputPath, opts, fmt.Sprintf("Resizing image to fit %dx%d", width, height), "resized",
+		resizeSteps(opts, Step{Op: StepFit, Width: width, Height: height})...)
+}
 
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
-	}
+// ResizeWithOptions resizes an image to width x height in resize.Mode:
+//   - ResizeModeStretch: exactly width x height (see ResizeImage)
+//   - ResizeModeFit: within width x height, preserving aspect ratio (see ResizeFit)
+//   - ResizeModeFill: exactly width x height, scaled to cover and cropped at
+//     resize.Anchor (center when empty)
+//   - ResizeModePad: exactly width x height, scaled to fit and letterboxed on
+//     resize.Background, a color or "blur" (DefaultBackground when empty)
+//
+// opts.Filter and opts.Sharpen apply as for ResizeImage.
+//
+// Progress reporting can be provided via context using progress.WithReporter.
+func ResizeWithOptions(ctx context.Context, inputPath, outputPath string, width, height int, resize ResizeOptions, opts FileOptions) error {
+	step := ResizeStep(width, height, resize)
+	return processFile(ctx, inputPath, outputPath, opts, fmt.Sprintf("Resizing image to %dx%d (%s)", width, height, step.Op), "resized",
+		resizeSteps(opts, step)...)
+}
 
-	// Load the input image
-	reporter.Update(1, 3, "Loading input image")
-	img, err := imaging.Open(inputPath)
-	if err != nil {
-		err = fmt.Errorf("failed to open image %s: %w", inputPath, err)
-		reporter.Error(err)
-		return err
+// ResizeImageData resizes encoded image data to exactly width x height and
+// re-encodes it in its original format.
+//
+// With crop set, the image is scaled to cover the target and center-cropped,
+// preserving its aspect ratio; otherwise it is stretched to the target.
+func ResizeImageData(data []byte, width, height int, crop bool) ([]byte, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("width and height must be positive, got %dx%d", width, height)
 	}
-
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
+	if len(data) == 0 {
+		return nil, fmt.Errorf("input data is empty")
 	}
 
-	// Resize to fit within bounds, preserving aspect ratio
-	reporter.Update(2, 3, "Resizing image to fit")
-	resized := imaging.Fit(img, width, height, imaging.Lanczos)
-
-	// Check for cancellation
-	select {
-	case <-ctx.Done():
-		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
-		reporter.Error(err)
-		return err
-	default:
+	img, format, err := decodeImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
 	}
 
-	// Save the resized image
-	reporter.Update(3, 3, "Saving resized image")
-	if err := imaging.Save(resized, outputPath); err != nil {
-		err = fmt.Errorf("failed to save resized image to %s: %w", outputPath, err)
-		reporter.Error(err)
-		return err
+	var resized image.Image
+	if crop {
+		resized = imaging.Fill(img, width, height, imaging.Center, imaging.Lanczos)
+	} else {
+		resized = imaging.Resize(img, width, height, imaging.Lanczos)
 	}
 
-	reporter.Complete(outputPath)
-	return nil
+	var buf bytes.Buffer
+	if err := encodeImage(&buf, resized, format); err != nil {
+		return nil, fmt.Errorf("failed to encode image as %s: %w", format, err)
+	}
+	return buf.Bytes(), nil
 }
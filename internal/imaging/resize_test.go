@@ -1,8 +1,10 @@
 package imaging
 
 import (
+	"bytes"
 	"context"
 	"image"
+	"image/jpeg"
 	"os"
 	"path/filepath"
 	"testing"
@@ -121,7 +123,7 @@ func TestResizeImage(t *testing.T) {
 			require.NoError(t, err, "failed to create test image")
 
 			// Test resize
-			err = ResizeImage(context.Background(), inputPath, outputPath, tt.newWidth, tt.newHeight)
+			err = ResizeImage(context.Background(), inputPath, outputPath, tt.newWidth, tt.newHeight, FileOptions{})
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -156,7 +158,7 @@ func TestResizeImage_InvalidInputFile(t *testing.T) {
 	nonexistentPath := filepath.Join(tmpDir, "nonexistent.png")
 	outputPath := filepath.Join(tmpDir, "output.png")
 
-	err := ResizeImage(context.Background(), nonexistentPath, outputPath, 100, 100)
+	err := ResizeImage(context.Background(), nonexistentPath, outputPath, 100, 100, FileOptions{})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to open image")
 }
@@ -274,7 +276,7 @@ func TestResizeFit(t *testing.T) {
 			require.NoError(t, err, "failed to create test image")
 
 			// Test resize fit
-			err = ResizeFit(context.Background(), inputPath, outputPath, tt.maxWidth, tt.maxHeight)
+			err = ResizeFit(context.Background(), inputPath, outputPath, tt.maxWidth, tt.maxHeight, FileOptions{})
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -313,7 +315,7 @@ func TestResizeFit_InvalidInputFile(t *testing.T) {
 	nonexistentPath := filepath.Join(tmpDir, "nonexistent.png")
 	outputPath := filepath.Join(tmpDir, "output.png")
 
-	err := ResizeFit(context.Background(), nonexistentPath, outputPath, 100, 100)
+	err := ResizeFit(context.Background(), nonexistentPath, outputPath, 100, 100, FileOptions{})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to open image")
 }
@@ -328,7 +330,7 @@ func TestResizeFit_RealFixture(t *testing.T) {
 	tmpDir := t.TempDir()
 	outputPath := filepath.Join(tmpDir, "resized_fit.png")
 
-	err := ResizeFit(context.Background(), fixturePath, outputPath, 400, 400)
+	err := ResizeFit(context.Background(), fixturePath, outputPath, 400, 400, FileOptions{})
 	assert.NoError(t, err)
 
 	// Verify output exists
@@ -343,3 +345,24 @@ func TestResizeFit_RealFixture(t *testing.T) {
 	assert.LessOrEqual(t, bounds.Dx(), 400)
 	assert.LessOrEqual(t, bounds.Dy(), 400)
 }
+
+func TestResizeImageData(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1408, 768)), nil))
+
+	for _, crop := range []bool{true, false} {
+		data, err := ResizeImageData(buf.Bytes(), 1920, 1080, crop)
+		require.NoError(t, err)
+
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, 1920, cfg.Width)
+		assert.Equal(t, 1080, cfg.Height)
+		assert.Equal(t, "jpeg", format, "output keeps the source format")
+	}
+
+	_, err := ResizeImageData(buf.Bytes(), 0, 100, true)
+	assert.Error(t, err)
+	_, err = ResizeImageData([]byte("not an image"), 100, 100, true)
+	assert.Error(t, err)
+}
@@ -0,0 +1,174 @@
+// Package imaging provides image processing operations using pure Go.
+package imaging
+
+import (
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// AnchorSmart is the fill anchor that keeps the most interesting part of the
+// image, chosen from its content rather than its geometry
+const AnchorSmart = "smart"
+
+// smartCropSize is the longest side the saliency map is computed at. Finer
+// maps cost time without moving the window by more than a few pixels.
+const smartCropSize = 256
+
+// Saliency weights. Detail (edges and texture) is what separates a subject
+// from a plain background; skin tones pull the window toward faces, and
+// saturation toward colorful products on neutral backdrops.
+const (
+	detailWeight     = 1.0
+	skinWeight       = 0.4
+	saturationWeight = 0.15
+)
+
+// smartCropSlack is how much less saliency than the best window a window
+// may hold and still be chosen for centering its subject better
+const smartCropSlack = 0.02
+
+// SmartCropRegion returns the window of img that Pipeline.SmartCrop keeps:
+// the most interesting one with the aspect ratio width:height, as large as
+// img allows. The window is relative to the top-left corner of img.
+func SmartCropRegion(img image.Image, width, height int) image.Rectangle {
+	bounds := img.Bounds()
+	imgWidth, imgHeight := bounds.Dx(), bounds.Dy()
+
+	if width <= 0 || height <= 0 {
+		return image.Rect(0, 0, imgWidth, imgHeight)
+	}
+
+	// The largest window with the target aspect ratio spans the full image
+	// in one direction, so the search only ever slides along the other
+	cropWidth, cropHeight := imgWidth, imgHeight
+	if imgWidth*height > imgHeight*width {
+		cropWidth = min(max(int(math.Round(float64(imgHeight)*float64(width)/float64(height))), 1), imgWidth)
+	} else {
+		cropHeight = min(max(int(math.Round(float64(imgWidth)*float64(height)/float64(width))), 1), imgHeight)
+	}
+	if cropWidth == imgWidth && cropHeight == imgHeight {
+		return image.Rect(0, 0, imgWidth, imgHeight)
+	}
+
+	scale := math.Min(1, float64(smartCropSize)/float64(max(imgWidth, imgHeight)))
+	small := imaging.Resize(img, max(int(math.Round(float64(imgWidth)*scale)), 1),
+		max(int(math.Round(float64(imgHeight)*scale)), 1), imaging.Box)
+	smallWidth, smallHeight := small.Bounds().Dx(), small.Bounds().Dy()
+	scores := saliency(small)
+	sum := summedArea(scores, smallWidth, func(x, y int, s float64) float64 { return s })
+	sumX := summedArea(scores, smallWidth, func(x, y int, s float64) float64 { return s * (float64(x) + 0.5) })
+	sumY := summedArea(scores, smallWidth, func(x, y int, s float64) float64 { return s * (float64(y) + 0.5) })
+	windowWidth := min(max(int(math.Round(float64(cropWidth)*scale)), 1), smallWidth)
+	windowHeight := min(max(int(math.Round(float64(cropHeight)*scale)), 1), smallHeight)
+
+	most := 0.0
+	for y := 0; y+windowHeight <= smallHeight; y++ {
+		for x := 0; x+windowWidth <= smallWidth; x++ {
+			most = math.Max(most, sum.window(x, y, windowWidth, windowHeight))
+		}
+	}
+
+	// Of the windows holding nearly the most saliency, keep the one that
+	// centers it best, so a subject is not left against an edge. A slight
+	// pull toward the middle of the image settles ties, such as on a plain
+	// image.
+	bestX, bestY, bestCost := 0, 0, math.MaxFloat64
+	for y := 0; y+windowHeight <= smallHeight; y++ {
+		for x := 0; x+windowWidth <= smallWidth; x++ {
+			score := sum.window(x, y, windowWidth, windowHeight)
+			if score < most*(1-smartCropSlack) {
+				continue
+			}
+			centerX, centerY := float64(smallWidth)/2, float64(smallHeight)/2
+			if score > 1e-9 {
+				centerX = sumX.window(x, y, windowWidth, windowHeight) / score
+				centerY = sumY.window(x, y, windowWidth, windowHeight) / score
+			}
+			distance := math.Hypot(float64(x)+float64(windowWidth)/2-centerX, float64(y)+float64(windowHeight)/2-centerY)
+			offset := math.Hypot(float64(x)-float64(smallWidth-windowWidth)/2, float64(y)-float64(smallHeight-windowHeight)/2)
+			if cost := distance + 0.01*offset; cost < bestCost {
+				bestX, bestY, bestCost = x, y, cost
+			}
+		}
+	}
+
+	x := min(max(int(math.Round(float64(bestX)/scale)), 0), imgWidth-cropWidth)
+	y := min(max(int(math.Round(float64(bestY)/scale)), 0), imgHeight-cropHeight)
+	return image.Rect(x, y, x+cropWidth, y+cropHeight)
+}
+
+// saliency scores every pixel of img by how much it is likely to matter to
+// a viewer. Transparent pixels score nothing.
+func saliency(img *image.NRGBA) []float64 {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	luma := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := img.NRGBAAt(x, y)
+			luma[y*width+x] = (0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)) / 255
+		}
+	}
+	at := func(x, y int) float64 {
+		return luma[min(max(y, 0), height-1)*width+min(max(x, 0), width-1)]
+	}
+
+	scores := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := img.NRGBAAt(x, y)
+			// The Laplacian responds to edges and texture, not to gradients
+			detail := math.Min(math.Abs(4*at(x, y)-at(x-1, y)-at(x+1, y)-at(x, y-1)-at(x, y+1)), 1)
+			high := max(c.R, c.G, c.B)
+			low := min(c.R, c.G, c.B)
+			saturation := 0.0
+			if high > 0 {
+				saturation = float64(high-low) / float64(high)
+			}
+			skin := 0.0
+			if isSkinTone(c.R, c.G, c.B) {
+				skin = 1
+			}
+			score := detailWeight*detail + skinWeight*skin + saturationWeight*saturation
+			scores[y*width+x] = score * float64(c.A) / 255
+		}
+	}
+	return scores
+}
+
+// isSkinTone reports whether r, g, b falls in the RGB skin-tone range of
+// Kovac et al., which covers light to dark skin under daylight
+func isSkinTone(r, g, b uint8) bool {
+	return r > 95 && g > 40 && b > 20 &&
+		r > g && r > b && int(r)-int(g) > 15 &&
+		int(max(r, g, b))-int(min(r, g, b)) > 15
+}
+
+// summedAreaTable holds the sums of a map over every rectangle anchored at
+// its origin, so any window sums in constant time
+type summedAreaTable struct {
+	width  int // row stride of values, one more than the map width
+	values []float64
+}
+
+// summedArea builds the summed-area table of value applied to scores, laid
+// out width values per row
+func summedArea(scores []float64, width int, value func(x, y int, score float64) float64) summedAreaTable {
+	height := len(scores) / width
+	t := summedAreaTable{width: width + 1, values: make([]float64, (width+1)*(height+1))}
+	for y := 0; y < height; y++ {
+		row := 0.0
+		for x := 0; x < width; x++ {
+			row += value(x, y, scores[y*width+x])
+			t.values[(y+1)*t.width+x+1] = t.values[y*t.width+x+1] + row
+		}
+	}
+	return t
+}
+
+// window returns the sum of the width x height window at x, y
+func (t summedAreaTable) window(x, y, width, height int) float64 {
+	return t.values[(y+height)*t.width+x+width] - t.values[y*t.width+x+width] -
+		t.values[(y+height)*t.width+x] + t.values[y*t.width+x]
+}
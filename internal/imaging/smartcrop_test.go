@@ -0,0 +1,103 @@
+package imaging
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// plainWithDetail returns a width x height gray image with a 40x40
+// checkerboard patch at x, y
+func plainWithDetail(width, height, x, y int) *image.NRGBA {
+	img := imaging.New(width, height, color.NRGBA{128, 128, 128, 255})
+	return imaging.Paste(img, checkerboard(40, 4), image.Pt(x, y))
+}
+
+func TestSmartCropRect(t *testing.T) {
+	// The window slides to the detailed patch on the right
+	rect := SmartCropRegion(plainWithDetail(400, 200, 320, 80), 100, 100)
+	assert.Equal(t, image.Rect(200, 0, 400, 200), rect)
+
+	// ...and on a tall image to the patch at the top
+	rect = SmartCropRegion(plainWithDetail(200, 600, 80, 20), 200, 100)
+	assert.Equal(t, 200, rect.Dx())
+	assert.Equal(t, 100, rect.Dy())
+	assert.LessOrEqual(t, rect.Min.Y, 20)
+	assert.GreaterOrEqual(t, rect.Max.Y, 60)
+
+	// A plain image gives the center window
+	rect = SmartCropRegion(imaging.New(400, 200, color.NRGBA{10, 20, 30, 255}), 100, 100)
+	assert.Equal(t, image.Rect(100, 0, 300, 200), rect)
+
+	// The same aspect ratio keeps the whole image
+	rect = SmartCropRegion(plainWithDetail(400, 200, 0, 0), 800, 400)
+	assert.Equal(t, image.Rect(0, 0, 400, 200), rect)
+}
+
+func TestSmartCropRect_SkinAndTransparency(t *testing.T) {
+	// A skin-toned face on a plain background beats the empty side
+	img := imaging.New(600, 200, color.NRGBA{40, 60, 90, 255})
+	img = imaging.Paste(img, imaging.New(60, 80, color.NRGBA{224, 172, 140, 255}), image.Pt(40, 60))
+	rect := SmartCropRegion(img, 200, 200)
+	assert.Equal(t, 0, rect.Min.X)
+
+	// Detail hidden in transparent pixels does not count
+	img = plainWithDetail(400, 200, 20, 80)
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 100; x++ {
+			img.Pix[img.PixOffset(x, y)+3] = 0
+		}
+	}
+	img = imaging.Paste(img, checkerboard(20, 2), image.Pt(360, 90))
+	rect = SmartCropRegion(img, 100, 100)
+	assert.Equal(t, 200, rect.Min.X)
+}
+
+func TestPipeline_SmartCrop(t *testing.T) {
+	p := NewPipeline(plainWithDetail(400, 200, 320, 80), "png").SmartCrop(100, 100)
+	require.NoError(t, p.Err())
+	w, h := p.Size()
+	assert.Equal(t, 100, w)
+	assert.Equal(t, 100, h)
+	assert.Equal(t, []string{"fill:100x100@smart"}, p.Steps())
+
+	// The step form runs the same crop
+	step, err := ParseStep("fill:100x100@Smart")
+	require.NoError(t, err)
+	q := NewPipeline(plainWithDetail(400, 200, 320, 80), "png").Apply(step)
+	require.NoError(t, q.Err())
+	assert.Equal(t, p.Image(), q.Image())
+
+	assert.Error(t, NewPipeline(plainWithDetail(400, 200, 0, 0), "png").SmartCrop(0, 100).Err())
+}
+
+func TestSmartCrop(t *testing.T) {
+	inputPath := filepath.Join(t.TempDir(), "wide.png")
+	require.NoError(t, imaging.Save(plainWithDetail(400, 200, 320, 80), inputPath))
+	outputPath := filepath.Join(t.TempDir(), "thumb.png")
+
+	require.NoError(t, SmartCrop(context.Background(), inputPath, outputPath, 64, 64, FileOptions{}))
+	out, err := imaging.Open(outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(64, 64), out.Bounds().Size())
+
+	// The thumbnail shows the patch, which a center crop would miss
+	dark := 0
+	nrgba := imaging.Clone(out)
+	for i := 0; i < len(nrgba.Pix); i += 4 {
+		if nrgba.Pix[i] < 64 {
+			dark++
+		}
+	}
+	assert.Greater(t, dark, 0)
+
+	err = SmartCrop(context.Background(), inputPath, outputPath, 0, 64, FileOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "width must be positive")
+}
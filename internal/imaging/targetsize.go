@@ -0,0 +1,332 @@
+// Package imaging provides image processing operations using pure Go.
+package imaging
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/apresai/gimage/internal/progress"
+	"github.com/disintegration/imaging"
+)
+
+const (
+	// DefaultMinQuality is the lowest quality EncodeToSize tries unless told
+	// otherwise; below it lossy artifacts are rarely acceptable.
+	DefaultMinQuality = 10
+
+	// minFitDimension stops EncodeToSize from shrinking an image further
+	minFitDimension = 16
+)
+
+// SizeLimit is a byte budget for EncodeToSize and CompressImageToSize.
+type SizeLimit struct {
+	MaxBytes    int64 // largest acceptable encoded size
+	MinQuality  int   // lowest quality to try (0 = DefaultMinQuality)
+	MaxQuality  int   // highest quality to try (0 = 100)
+	AllowResize bool  // step dimensions down when MinQuality still doesn't fit
+}
+
+// SizeResult reports the encode EncodeToSize settled on.
+type SizeResult struct {
+	Quality  int   // chosen quality (0 for formats without a quality setting)
+	Bytes    int64 // encoded size
+	Width    int   // final dimensions
+	Height   int
+	Resized  bool // whether the dimensions were stepped down
+	Attempts int  // number of encodes tried
+}
+
+// Validate checks the limit's fields
+func (l SizeLimit) Validate() error {
+	if l.MaxBytes <= 0 {
+		return fmt.Errorf("max size must be positive, got %d", l.MaxBytes)
+	}
+	if l.MinQuality < 0 || l.MinQuality > 100 {
+		return fmt.Errorf("min quality must be between 1 and 100, got %d", l.MinQuality)
+	}
+	if l.MaxQuality < 0 || l.MaxQuality > 100 {
+		return fmt.Errorf("quality must be between 1 and 100, got %d", l.MaxQuality)
+	}
+	minQuality, maxQuality := l.qualityRange()
+	if minQuality > maxQuality {
+		return fmt.Errorf("min quality %d exceeds quality %d", minQuality, maxQuality)
+	}
+	return nil
+}
+
+func (l SizeLimit) qualityRange() (int, int) {
+	return qualityBounds(l.MinQuality, l.MaxQuality)
+}
+
+// qualityBounds fills in the defaults of a quality search range
+func qualityBounds(minQuality, maxQuality int) (int, int) {
+	if minQuality == 0 {
+		minQuality = DefaultMinQuality
+	}
+	if maxQuality == 0 {
+		maxQuality = 100
+	}
+	return minQuality, maxQuality
+}
+
+// hasQualitySetting reports whether quality changes the encoded size of a
+// format
+func hasQualitySetting(format string) bool {
+	switch normalizeFormat(format) {
+	case "jpeg", "webp", "avif":
+		return true
+	default:
+		return false
+	}
+}
+
+// EncodeToSize encodes the image in the pipeline's output format at the
+// highest quality whose output fits limit.MaxBytes, binary-searching
+// between the limit's quality bounds. When even the lowest quality is too
+// large and limit.AllowResize is set, the image is scaled down in steps
+// and searched again. Formats without a quality setting (PNG, GIF, ...)
+// can only fit by resizing.
+//
+// On success the pipeline holds the chosen image and quality, so Size,
+// Quality and Steps describe the returned data.
+func (p *Pipeline) EncodeToSize(limit SizeLimit) ([]byte, SizeResult, error) {
+	if p.err != nil {
+		return nil, SizeResult{}, p.err
+	}
+	if err := limit.Validate(); err != nil {
+		return nil, SizeResult{}, err
+	}
+
+	format := p.Format()
+	width, height := p.Size()
+	candidate := p
+	result := SizeResult{Width: width, Height: height}
+
+	for {
+		data, quality, smallest, err := candidate.fitQuality(candidate.img, format, limit, &result.Attempts)
+		if err != nil {
+			return nil, result, err
+		}
+		if data != nil {
+			if result.Resized {
+				p.img, p.anim = candidate.img, candidate.anim
+				p.steps = append(p.steps, Step{Op: StepResize, Width: result.Width, Height: result.Height}.String())
+			}
+			if quality > 0 {
+				p.quality = quality
+				p.steps = append(p.steps, Step{Op: StepCompress, Quality: quality}.String())
+			}
+			result.Quality = quality
+			result.Bytes = int64(len(data))
+			return data, result, nil
+		}
+
+		if !limit.AllowResize {
+			if !hasQualitySetting(format) {
+				return nil, result, fmt.Errorf("%s output is %d bytes, over the %d byte limit, and %s has no quality setting (allow resizing or use jpeg or webp)",
+					format, smallest, limit.MaxBytes, format)
+			}
+			minQuality, _ := limit.qualityRange()
+			return nil, result, fmt.Errorf("output is %d bytes at the minimum quality %d, over the %d byte limit (allow resizing or lower the minimum quality)",
+				smallest, minQuality, limit.MaxBytes)
+		}
+
+		// Shrink the area in proportion to the overshoot, by at least 10%
+		// per round so the search always makes progress
+		factor := math.Min(math.Sqrt(float64(limit.MaxBytes)/float64(smallest))*0.95, 0.9)
+		newWidth := int(float64(result.Width) * factor)
+		newHeight := int(float64(result.Height) * factor)
+		if newWidth < minFitDimension || newHeight < minFitDimension {
+			return nil, result, fmt.Errorf("cannot fit within %d bytes: output is still %d bytes at %dx%d",
+				limit.MaxBytes, smallest, result.Width, result.Height)
+		}
+		candidate = p.resized(newWidth, newHeight)
+		result.Width, result.Height = newWidth, newHeight
+		result.Resized = true
+	}
+}
+
+// resized returns a copy of p resized to width x height, leaving p as it is
+func (p *Pipeline) resized(width, height int) *Pipeline {
+	q := *p
+	q.transform(func(img image.Image) *image.NRGBA {
+		return imaging.Resize(img, width, height, p.filter.resampleFilter())
+	})
+	return &q
+}
+
+// fitQuality finds the highest quality in the limit's range whose encoding
+// of img fits. It returns the encoded data and quality on success, or nil
+// and the smallest size it produced when nothing fits.
+func (p *Pipeline) fitQuality(img image.Image, format string, limit SizeLimit, attempts *int) ([]byte, int, int64, error) {
+	encode := func(quality int) ([]byte, error) {
+		*attempts++
+		return p.encode(img, format, quality)
+	}
+	fits := func(data []byte) bool {
+		return int64(len(data)) <= limit.MaxBytes
+	}
+
+	if !hasQualitySetting(format) {
+		data, err := encode(0)
+		if err != nil || fits(data) {
+			return data, 0, 0, err
+		}
+		return nil, 0, int64(len(data)), nil
+	}
+
+	lo, hi := limit.qualityRange()
+	best, err := encode(hi)
+	if err != nil || fits(best) {
+		return best, hi, 0, err
+	}
+	if lo == hi {
+		return nil, 0, int64(len(best)), nil
+	}
+	best, err = encode(lo)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !fits(best) {
+		return nil, 0, int64(len(best)), nil
+	}
+
+	// lo always fits and hi never does
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		data, err := encode(mid)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if fits(data) {
+			lo, best = mid, data
+		} else {
+			hi = mid
+		}
+	}
+	return best, lo, 0, nil
+}
+
+// CompressImageToSize compresses an image to fit within a byte budget,
+// choosing the highest quality that fits (see Pipeline.EncodeToSize).
+//
+// Parameters:
+//   - ctx: context for cancellation support
+//   - inputPath: path to input image
+//   - outputPath: path to save compressed image; its extension selects the format
+//   - limit: the byte budget and quality bounds
+//   - opts: file settings (see FileOptions)
+//
+// Progress reporting can be provided via context using progress.WithReporter.
+//
+// Returns the chosen quality and final size, or an error if the image
+// cannot fit the budget.
+func CompressImageToSize(ctx context.Context, inputPath, outputPath string, limit SizeLimit, opts FileOptions) (SizeResult, error) {
+	var result SizeResult
+	err := compressFileWith(ctx, inputPath, outputPath, opts,
+		fmt.Sprintf("Compressing image to at most %d bytes", limit.MaxBytes),
+		"Searching for the highest quality that fits",
+		limit.Validate,
+		func(p *Pipeline) ([]byte, error) {
+			data, r, err := p.EncodeToSize(limit)
+			result = r
+			return data, err
+		})
+	return result, err
+}
+
+// compressFileWith loads inputPath, converts it to the format of outputPath,
+// encodes it with search and writes the result, reporting progress along
+// the way. validate runs before anything is read.
+func compressFileWith(ctx context.Context, inputPath, outputPath string, opts FileOptions, operation, searching string,
+	validate func() error, search func(*Pipeline) ([]byte, error)) error {
+	reporter := progress.FromContext(ctx)
+	reporter.Start(ctx, operation)
+
+	if err := validate(); err != nil {
+		reporter.Error(err)
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		err := fmt.Errorf("operation cancelled: %w", ctx.Err())
+		reporter.Error(err)
+		return err
+	default:
+	}
+
+	reporter.Update(1, 3, "Loading input image")
+	p, err := OpenPipeline(inputPath)
+	if err != nil {
+		reporter.Error(err)
+		return err
+	}
+	p.withOptions(opts)
+
+	reporter.Update(2, 3, searching)
+	data, err := search(p.Convert(ExtractFormatFromPath(outputPath)))
+	if err != nil {
+		reporter.Error(err)
+		return err
+	}
+
+	reporter.Update(3, 3, "Saving compressed image")
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		err = fmt.Errorf("failed to save compressed image to %s: %w", outputPath, err)
+		reporter.Error(err)
+		return err
+	}
+
+	reporter.Complete(outputPath)
+	return nil
+}
+
+// byteUnits maps size suffixes to multipliers. KB and MB are decimal, as
+// upload limits usually are; KiB and MiB are binary.
+var byteUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"kb":  1000,
+	"kib": 1 << 10,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"mib": 1 << 20,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"gib": 1 << 30,
+}
+
+// ParseByteSize parses a size such as "200KB", "1.5MB", "512KiB" or
+// "204800" into bytes. KB and MB are powers of 1000, KiB and MiB powers of
+// 1024.
+func ParseByteSize(s string) (int64, error) {
+	trimmed := strings.ToLower(strings.TrimSpace(s))
+	i := strings.IndexFunc(trimmed, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(trimmed)
+	}
+	number, unit := trimmed[:i], strings.TrimSpace(trimmed[i:])
+
+	multiplier, ok := byteUnits[unit]
+	if !ok || number == "" {
+		return 0, fmt.Errorf("invalid size %q (expected e.g. 200KB, 1.5MB or 204800)", s)
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q (expected e.g. 200KB, 1.5MB or 204800)", s)
+	}
+	size := int64(value * float64(multiplier))
+	if size <= 0 {
+		return 0, fmt.Errorf("size must be positive, got %q", s)
+	}
+	return size, nil
+}
@@ -0,0 +1,130 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"204800", 204800},
+		{"200KB", 200000},
+		{"200 kb", 200000},
+		{"200k", 200000},
+		{"512KiB", 512 * 1024},
+		{"1.5MB", 1500000},
+		{"2MiB", 2 << 20},
+		{"900B", 900},
+	}
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	for _, bad := range []string{"", "KB", "12XB", "-5KB", "0", "1.2.3MB"} {
+		_, err := ParseByteSize(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSizeLimit_Validate(t *testing.T) {
+	assert.NoError(t, SizeLimit{MaxBytes: 1000}.Validate())
+	assert.Error(t, SizeLimit{}.Validate())
+	assert.Error(t, SizeLimit{MaxBytes: 1000, MinQuality: 101}.Validate())
+	assert.Error(t, SizeLimit{MaxBytes: 1000, MinQuality: 80, MaxQuality: 60}.Validate())
+}
+
+func TestEncodeToSize_Quality(t *testing.T) {
+	img := texturedImage(256, 256)
+	for _, format := range []string{"jpeg", "webp"} {
+		t.Run(format, func(t *testing.T) {
+			full, err := NewPipeline(img, format).Compress(100).Bytes()
+			require.NoError(t, err)
+			limit := int64(len(full) / 3)
+
+			p := NewPipeline(img, format)
+			data, result, err := p.EncodeToSize(SizeLimit{MaxBytes: limit})
+			require.NoError(t, err)
+			assert.LessOrEqual(t, int64(len(data)), limit)
+			assert.Equal(t, int64(len(data)), result.Bytes)
+			assert.False(t, result.Resized)
+			assert.Equal(t, 256, result.Width)
+			assert.Greater(t, result.Quality, DefaultMinQuality-1)
+			assert.Less(t, result.Quality, 100)
+			assert.Equal(t, result.Quality, p.Quality())
+
+			// One step of quality more would not have fit
+			above, err := NewPipeline(img, format).Compress(result.Quality + 1).Bytes()
+			require.NoError(t, err)
+			assert.Greater(t, int64(len(above)), limit)
+		})
+	}
+}
+
+func TestEncodeToSize_Resize(t *testing.T) {
+	img := texturedImage(400, 300)
+
+	// Far below what any quality can reach at full size
+	_, _, err := NewPipeline(img, "jpeg").EncodeToSize(SizeLimit{MaxBytes: 3000})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "allow resizing")
+
+	p := NewPipeline(img, "jpeg")
+	data, result, err := p.EncodeToSize(SizeLimit{MaxBytes: 3000, AllowResize: true})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(data), 3000)
+	assert.True(t, result.Resized)
+	assert.Less(t, result.Width, 400)
+	assert.InDelta(t, 4.0/3.0, float64(result.Width)/float64(result.Height), 0.05, "aspect ratio is kept")
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, result.Width, decoded.Bounds().Dx())
+	width, height := p.Size()
+	assert.Equal(t, result.Width, width)
+	assert.Equal(t, result.Height, height)
+}
+
+func TestEncodeToSize_LosslessFormat(t *testing.T) {
+	img := texturedImage(200, 200)
+
+	_, _, err := NewPipeline(img, "png").EncodeToSize(SizeLimit{MaxBytes: 2000})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has no quality setting")
+
+	data, result, err := NewPipeline(img, "png").EncodeToSize(SizeLimit{MaxBytes: 20000, AllowResize: true})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(data), 20000)
+	assert.Equal(t, 0, result.Quality)
+	assert.True(t, result.Resized)
+}
+
+func TestCompressImageToSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, "in.png")
+	require.NoError(t, NewPipeline(texturedImage(320, 240), "png").Save(inputPath))
+
+	outputPath := filepath.Join(tmpDir, "out.webp")
+	result, err := CompressImageToSize(context.Background(), inputPath, outputPath, SizeLimit{MaxBytes: 15000, MaxQuality: 90}, FileOptions{})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, result.Quality, 90)
+
+	info, err := os.Stat(outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, result.Bytes, info.Size())
+	assert.LessOrEqual(t, info.Size(), int64(15000))
+
+	_, err = CompressImageToSize(context.Background(), inputPath, outputPath, SizeLimit{}, FileOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,157 @@
+// Package imaging provides image processing operations using pure Go.
+package imaging
+
+import (
+	"context"
+	"fmt"
+)
+
+// SSIMTarget is a perceptual similarity floor for EncodeToSSIM and
+// CompressImageToSSIM.
+type SSIMTarget struct {
+	MinSSIM    float64 // lowest acceptable SSIM against the original, 0-1
+	MinQuality int     // lowest quality to try (0 = DefaultMinQuality)
+	MaxQuality int     // highest quality to try (0 = 100)
+}
+
+// SSIMResult reports the encode EncodeToSSIM settled on.
+type SSIMResult struct {
+	Quality  int     // chosen quality
+	SSIM     float64 // SSIM of the decoded output against the original
+	Bytes    int64   // encoded size
+	Attempts int     // number of encodes tried
+}
+
+// Validate checks the target's fields
+func (t SSIMTarget) Validate() error {
+	if t.MinSSIM <= 0 || t.MinSSIM > 1 {
+		return fmt.Errorf("target SSIM must be greater than 0 and at most 1, got %g", t.MinSSIM)
+	}
+	if t.MinQuality < 0 || t.MinQuality > 100 {
+		return fmt.Errorf("min quality must be between 1 and 100, got %d", t.MinQuality)
+	}
+	if t.MaxQuality < 0 || t.MaxQuality > 100 {
+		return fmt.Errorf("quality must be between 1 and 100, got %d", t.MaxQuality)
+	}
+	minQuality, maxQuality := qualityBounds(t.MinQuality, t.MaxQuality)
+	if minQuality > maxQuality {
+		return fmt.Errorf("min quality %d exceeds quality %d", minQuality, maxQuality)
+	}
+	return nil
+}
+
+// EncodeToSSIM encodes the image in the pipeline's output format (JPEG or
+// WebP) at the lowest quality whose decoded result still reaches
+// target.MinSSIM against the pipeline's current image, binary-searching
+// between the target's quality bounds. SSIM rises with quality, so the
+// result is the smallest file that meets the threshold.
+//
+// On success the pipeline holds the chosen quality, so Quality and Steps
+// describe the returned data.
+func (p *Pipeline) EncodeToSSIM(target SSIMTarget) ([]byte, SSIMResult, error) {
+	if p.err != nil {
+		return nil, SSIMResult{}, p.err
+	}
+	if err := target.Validate(); err != nil {
+		return nil, SSIMResult{}, err
+	}
+	format := p.Format()
+	if !hasQualitySetting(format) {
+		return nil, SSIMResult{}, fmt.Errorf("%s has no quality setting (use jpeg or webp)", format)
+	}
+
+	// Compare against what the encoder actually sees: formats without
+	// alpha are flattened onto white
+	reference := p.img
+	if !supportsTransparency(format) && hasTransparency(reference) {
+		reference = removeTransparency(reference)
+	}
+	factor := ssimScale(reference)
+	refPlane, w, h := lumaPlane(reference, factor)
+
+	var result SSIMResult
+	try := func(quality int) ([]byte, float64, error) {
+		result.Attempts++
+		data, err := p.encode(p.img, format, quality)
+		if err != nil {
+			return nil, 0, err
+		}
+		decoded, _, err := decodeImage(data)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to decode quality %d output: %w", quality, err)
+		}
+		plane, _, _ := lumaPlane(decoded, factor)
+		return data, ssimIndex(refPlane, plane, w, h), nil
+	}
+
+	lo, hi := qualityBounds(target.MinQuality, target.MaxQuality)
+	best, bestSSIM, err := try(hi)
+	if err != nil {
+		return nil, result, err
+	}
+	if bestSSIM < target.MinSSIM {
+		return nil, result, fmt.Errorf("quality %d only reaches SSIM %.4f, below the %.4f target (raise the quality or lower the target)",
+			hi, bestSSIM, target.MinSSIM)
+	}
+	quality := hi
+
+	if lo < hi {
+		data, ssim, err := try(lo)
+		if err != nil {
+			return nil, result, err
+		}
+		if ssim >= target.MinSSIM {
+			best, bestSSIM, quality = data, ssim, lo
+		} else {
+			// lo never meets the target and hi always does
+			for hi-lo > 1 {
+				mid := (lo + hi) / 2
+				data, ssim, err := try(mid)
+				if err != nil {
+					return nil, result, err
+				}
+				if ssim >= target.MinSSIM {
+					hi, best, bestSSIM = mid, data, ssim
+				} else {
+					lo = mid
+				}
+			}
+			quality = hi
+		}
+	}
+
+	p.quality = quality
+	p.steps = append(p.steps, Step{Op: StepCompress, Quality: quality}.String())
+	result.Quality = quality
+	result.SSIM = bestSSIM
+	result.Bytes = int64(len(best))
+	return best, result, nil
+}
+
+// CompressImageToSSIM compresses an image to the lowest quality that keeps
+// it perceptually close to the original (see Pipeline.EncodeToSSIM).
+//
+// Parameters:
+//   - ctx: context for cancellation support
+//   - inputPath: path to input image
+//   - outputPath: path to save compressed image; its extension selects the format
+//   - target: the SSIM floor and quality bounds
+//   - opts: file settings (see FileOptions)
+//
+// Progress reporting can be provided via context using progress.WithReporter.
+//
+// Returns the chosen quality and the SSIM it reaches, or an error if even
+// the highest allowed quality misses the target.
+func CompressImageToSSIM(ctx context.Context, inputPath, outputPath string, target SSIMTarget, opts FileOptions) (SSIMResult, error) {
+	var result SSIMResult
+	err := compressFileWith(ctx, inputPath, outputPath, opts,
+		fmt.Sprintf("Compressing image to SSIM %.4f", target.MinSSIM),
+		"Searching for the lowest quality that meets the target",
+		target.Validate,
+		func(p *Pipeline) ([]byte, error) {
+			data, r, err := p.EncodeToSSIM(target)
+			result = r
+			return data, err
+		})
+	return result, err
+}
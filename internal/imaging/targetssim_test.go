@@ -0,0 +1,82 @@
+package imaging
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSIMTarget_Validate(t *testing.T) {
+	assert.NoError(t, SSIMTarget{MinSSIM: 0.98}.Validate())
+	assert.Error(t, SSIMTarget{}.Validate())
+	assert.Error(t, SSIMTarget{MinSSIM: 1.5}.Validate())
+	assert.Error(t, SSIMTarget{MinSSIM: 0.9, MinQuality: 80, MaxQuality: 60}.Validate())
+}
+
+func TestEncodeToSSIM(t *testing.T) {
+	img := texturedImage(256, 256)
+	for _, format := range []string{"jpeg", "webp"} {
+		t.Run(format, func(t *testing.T) {
+			p := NewPipeline(img, format)
+			data, result, err := p.EncodeToSSIM(SSIMTarget{MinSSIM: 0.95})
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(data)), result.Bytes)
+			assert.GreaterOrEqual(t, result.SSIM, 0.95)
+			assert.Equal(t, result.Quality, p.Quality())
+
+			decoded, _, err := decodeImage(data)
+			require.NoError(t, err)
+			ssim, err := SSIM(img, decoded)
+			require.NoError(t, err)
+			assert.InDelta(t, result.SSIM, ssim, 1e-9)
+
+			// One step of quality less would have missed the target
+			if result.Quality > DefaultMinQuality {
+				below, err := NewPipeline(img, format).Compress(result.Quality - 1).Bytes()
+				require.NoError(t, err)
+				decoded, _, err := decodeImage(below)
+				require.NoError(t, err)
+				ssim, err := SSIM(img, decoded)
+				require.NoError(t, err)
+				assert.Less(t, ssim, 0.95)
+			}
+		})
+	}
+}
+
+func TestEncodeToSSIM_Errors(t *testing.T) {
+	img := texturedImage(128, 128)
+
+	_, _, err := NewPipeline(img, "png").EncodeToSSIM(SSIMTarget{MinSSIM: 0.9})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has no quality setting")
+
+	_, _, err = NewPipeline(img, "jpeg").EncodeToSSIM(SSIMTarget{MinSSIM: 0.999, MaxQuality: 20})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only reaches SSIM")
+}
+
+func TestCompressImageToSSIM(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, "in.png")
+	require.NoError(t, NewPipeline(texturedImage(320, 240), "png").Save(inputPath))
+
+	outputPath := filepath.Join(tmpDir, "out.jpg")
+	result, err := CompressImageToSSIM(context.Background(), inputPath, outputPath, SSIMTarget{MinSSIM: 0.9}, FileOptions{})
+	require.NoError(t, err)
+
+	info, err := os.Stat(outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, result.Bytes, info.Size())
+
+	comparison, err := CompareFiles(inputPath, outputPath)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, comparison.SSIM, 0.9)
+
+	_, err = CompressImageToSSIM(context.Background(), inputPath, outputPath, SSIMTarget{}, FileOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,551 @@
+// Package vp8 implements a lossy VP8 key-frame encoder, the bitstream
+// carried by the "VP8 " chunk of a lossy WebP file.
+//
+// The encoder uses 16x16 luma and 8x8 chroma intra prediction, picks the
+// prediction mode with the lowest distortion per macroblock, and adapts the
+// token probabilities to the image before writing them. It has no
+// dependencies beyond the standard library.
+package vp8
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"math"
+)
+
+// MaxDimension is the largest width or height a VP8 frame can describe.
+const MaxDimension = 16383
+
+// Intra prediction modes shared by the 16x16 luma and 8x8 chroma planes.
+const (
+	predDC = iota
+	predTM
+	predVE
+	predHE
+	nPred
+)
+
+// macroblock holds the chosen modes and quantized levels of one
+// macroblock, in raster coefficient order.
+type macroblock struct {
+	yMode, uvMode int
+	skip          bool
+	y2            [16]int16
+	y             [16][16]int16
+	uv            [8][16]int16 // U blocks 0-3, then V blocks 4-7
+}
+
+type quantizer struct {
+	dc, ac int32
+}
+
+type encoder struct {
+	mbw, mbh int
+
+	// Source planes, padded to whole macroblocks
+	srcY, srcU, srcV []uint8
+	// Reconstructed planes, as the decoder will see them before filtering
+	recY, recU, recV []uint8
+	yStride          int
+	uvStride         int
+
+	q          int
+	y1, y2, uv quantizer
+
+	mbs      []macroblock
+	probs    [nPlane][nBand][nContext][nProb]uint8
+	stats    *[nPlane][nBand][nContext][nProb][2]uint32
+	tokens   *boolEncoder
+	skipProb uint8
+}
+
+// Encode writes img to w as a VP8 key frame. Quality ranges from 1
+// (smallest file) to 100 (best quality). Alpha is ignored; callers that
+// need transparency store it separately (the WebP "ALPH" chunk).
+func Encode(w io.Writer, img image.Image, quality int) error {
+	if quality < 1 || quality > 100 {
+		return fmt.Errorf("quality must be between 1 and 100, got %d", quality)
+	}
+	b := img.Bounds()
+	if b.Empty() {
+		return errors.New("vp8: image is empty")
+	}
+	if b.Dx() > MaxDimension || b.Dy() > MaxDimension {
+		return fmt.Errorf("vp8: image %dx%d exceeds the maximum dimension of %d", b.Dx(), b.Dy(), MaxDimension)
+	}
+
+	e := newEncoder(img, QualityToQuantizer(quality))
+	e.analyze()
+	_, err := w.Write(e.frame(b.Dx(), b.Dy()))
+	return err
+}
+
+// QualityToQuantizer maps a 1-100 quality to a VP8 quantizer index
+// (0 = finest, 127 = coarsest), following the curve libwebp uses so that
+// a given quality produces comparable files.
+func QualityToQuantizer(quality int) int {
+	c := float64(quality) / 100
+	if c < 0.75 {
+		c *= 2.0 / 3.0
+	} else {
+		c = 2*c - 1
+	}
+	q := int(127 * (1 - math.Cbrt(c)))
+	if q < 0 {
+		q = 0
+	}
+	if q > 127 {
+		q = 127
+	}
+	return q
+}
+
+func newEncoder(img image.Image, q int) *encoder {
+	b := img.Bounds()
+	e := &encoder{
+		mbw: (b.Dx() + 15) / 16,
+		mbh: (b.Dy() + 15) / 16,
+		q:   q,
+	}
+	e.yStride = 16 * e.mbw
+	e.uvStride = 8 * e.mbw
+	ySize := e.yStride * 16 * e.mbh
+	uvSize := e.uvStride * 8 * e.mbh
+	e.srcY, e.srcU, e.srcV = make([]uint8, ySize), make([]uint8, uvSize), make([]uint8, uvSize)
+	e.recY, e.recU, e.recV = make([]uint8, ySize), make([]uint8, uvSize), make([]uint8, uvSize)
+	e.mbs = make([]macroblock, e.mbw*e.mbh)
+
+	e.y1 = quantizer{int32(dcTable[q]), int32(acTable[q])}
+	e.y2 = quantizer{2 * int32(dcTable[q]), int32(acTable[q]) * 155 / 100}
+	if e.y2.ac < 8 {
+		e.y2.ac = 8
+	}
+	uvq := q
+	if uvq > 117 {
+		uvq = 117
+	}
+	e.uv = quantizer{int32(dcTable[uvq]), int32(acTable[q])}
+
+	e.convert(img)
+	return e
+}
+
+// convert fills the source planes with the BT.601 (studio range) Y'CbCr
+// representation of img, replicating the right and bottom edges into the
+// macroblock padding.
+func (e *encoder) convert(img image.Image) {
+	b := img.Bounds()
+	src, ok := img.(*image.NRGBA)
+	if !ok || src.Rect.Min != (image.Point{}) {
+		src = image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(src, src.Rect, img, b.Min, draw.Src)
+	}
+	w, h := b.Dx(), b.Dy()
+	pix := func(x, y int) (int32, int32, int32) {
+		if x >= w {
+			x = w - 1
+		}
+		if y >= h {
+			y = h - 1
+		}
+		i := y*src.Stride + 4*x
+		return int32(src.Pix[i]), int32(src.Pix[i+1]), int32(src.Pix[i+2])
+	}
+
+	for y := 0; y < 16*e.mbh; y++ {
+		for x := 0; x < e.yStride; x++ {
+			r, g, b := pix(x, y)
+			e.srcY[y*e.yStride+x] = uint8((16839*r + 33059*g + 6420*b + 16<<16 + 1<<15) >> 16)
+		}
+	}
+	for y := 0; y < 8*e.mbh; y++ {
+		for x := 0; x < e.uvStride; x++ {
+			var r, g, b int32
+			for _, d := range [4][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+				pr, pg, pb := pix(2*x+d[0], 2*y+d[1])
+				r, g, b = r+pr, g+pg, b+pb
+			}
+			e.srcU[y*e.uvStride+x] = clipUV(-9719*r - 19081*g + 28800*b)
+			e.srcV[y*e.uvStride+x] = clipUV(28800*r - 24116*g - 4684*b)
+		}
+	}
+}
+
+// clipUV scales a chroma sum over four pixels back to 8 bits.
+func clipUV(v int32) uint8 {
+	v = (v + 1<<17 + 128<<18) >> 18
+	return clip8(v)
+}
+
+func clip8(v int32) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
+
+// analyze chooses modes and quantizes every macroblock, reconstructing
+// each one so later predictions match the decoder's.
+func (e *encoder) analyze() {
+	for mby := 0; mby < e.mbh; mby++ {
+		for mbx := 0; mbx < e.mbw; mbx++ {
+			e.encodeMacroblock(mbx, mby, &e.mbs[mby*e.mbw+mbx])
+		}
+	}
+}
+
+func (e *encoder) encodeMacroblock(mbx, mby int, mb *macroblock) {
+	var pred [nPred][256]uint8
+	var srcBlock [256]uint8
+	yOff := 16*mby*e.yStride + 16*mbx
+	for y := 0; y < 16; y++ {
+		copy(srcBlock[16*y:16*y+16], e.srcY[yOff+y*e.yStride:])
+	}
+	e.predict(pred[:], e.recY, e.yStride, 16, mbx, mby)
+	mb.yMode = bestMode(pred[:], srcBlock[:], 16)
+
+	// Luma: sixteen 4x4 DCTs whose DCs go through the Y2 Walsh-Hadamard
+	// transform
+	var coeffs [16][16]int32
+	var dcs [16]int32
+	for n := 0; n < 16; n++ {
+		var res [16]int32
+		bx, by := 4*(n%4), 4*(n/4)
+		for i := 0; i < 16; i++ {
+			p := (by+i/4)*16 + bx + i%4
+			res[i] = int32(srcBlock[p]) - int32(pred[mb.yMode][p])
+		}
+		fdct(&res, &coeffs[n])
+		dcs[n] = coeffs[n][0]
+	}
+	var y2 [16]int32
+	fwht(&dcs, &y2)
+	nonzero := quantize(&y2, &mb.y2, e.y2, 0)
+	var y2deq [16]int32
+	for i := range y2deq {
+		q := e.y2.ac
+		if i == 0 {
+			q = e.y2.dc
+		}
+		y2deq[i] = int32(mb.y2[i]) * q
+	}
+	var dcOut [16]int32
+	iwht(&y2deq, &dcOut)
+
+	recOff := yOff
+	for n := 0; n < 16; n++ {
+		if quantize(&coeffs[n], &mb.y[n], e.y1, 1) {
+			nonzero = true
+		}
+		var deq [16]int32
+		deq[0] = dcOut[n]
+		for i := 1; i < 16; i++ {
+			deq[i] = int32(mb.y[n][i]) * e.y1.ac
+		}
+		bx, by := 4*(n%4), 4*(n/4)
+		off := recOff + by*e.yStride + bx
+		for y := 0; y < 4; y++ {
+			copy(e.recY[off+y*e.yStride:off+y*e.yStride+4], pred[mb.yMode][(by+y)*16+bx:])
+		}
+		idctAdd(&deq, e.recY[off:], e.yStride)
+	}
+
+	// Chroma: U and V share one 8x8 prediction mode
+	var predU, predV [nPred][256]uint8
+	var srcU, srcV [64]uint8
+	uvOff := 8*mby*e.uvStride + 8*mbx
+	for y := 0; y < 8; y++ {
+		copy(srcU[8*y:8*y+8], e.srcU[uvOff+y*e.uvStride:])
+		copy(srcV[8*y:8*y+8], e.srcV[uvOff+y*e.uvStride:])
+	}
+	e.predict(predU[:], e.recU, e.uvStride, 8, mbx, mby)
+	e.predict(predV[:], e.recV, e.uvStride, 8, mbx, mby)
+	best, bestCost := 0, int64(math.MaxInt64)
+	for m := 0; m < nPred; m++ {
+		cost := sse(predU[m][:64], srcU[:]) + sse(predV[m][:64], srcV[:])
+		if cost < bestCost {
+			best, bestCost = m, cost
+		}
+	}
+	mb.uvMode = best
+
+	for c, plane := range []struct {
+		src  []uint8
+		pred []uint8
+		rec  []uint8
+	}{
+		{srcU[:], predU[best][:], e.recU},
+		{srcV[:], predV[best][:], e.recV},
+	} {
+		for n := 0; n < 4; n++ {
+			var res, coeff [16]int32
+			bx, by := 4*(n%2), 4*(n/2)
+			for i := 0; i < 16; i++ {
+				p := (by+i/4)*8 + bx + i%4
+				res[i] = int32(plane.src[p]) - int32(plane.pred[p])
+			}
+			fdct(&res, &coeff)
+			levels := &mb.uv[4*c+n]
+			if quantize(&coeff, levels, e.uv, 0) {
+				nonzero = true
+			}
+			var deq [16]int32
+			deq[0] = int32(levels[0]) * e.uv.dc
+			for i := 1; i < 16; i++ {
+				deq[i] = int32(levels[i]) * e.uv.ac
+			}
+			off := uvOff + by*e.uvStride + bx
+			for y := 0; y < 4; y++ {
+				copy(plane.rec[off+y*e.uvStride:off+y*e.uvStride+4], plane.pred[(by+y)*8+bx:])
+			}
+			idctAdd(&deq, plane.rec[off:], e.uvStride)
+		}
+	}
+
+	mb.skip = !nonzero
+}
+
+// predict computes the four intra predictions of the size x size block at
+// macroblock (mbx, mby) from the reconstructed plane, using the decoder's
+// edge conventions: 127 above the first row and 129 left of the first
+// column.
+func (e *encoder) predict(out [][256]uint8, rec []uint8, stride, size, mbx, mby int) {
+	x0, y0 := size*mbx, size*mby
+	var above, left [16]int32
+	var corner int32
+	for i := 0; i < size; i++ {
+		above[i], left[i] = 127, 129
+		if mby > 0 {
+			above[i] = int32(rec[(y0-1)*stride+x0+i])
+		}
+		if mbx > 0 {
+			left[i] = int32(rec[(y0+i)*stride+x0-1])
+		}
+	}
+	switch {
+	case mby == 0:
+		corner = 127
+	case mbx == 0:
+		corner = 129
+	default:
+		corner = int32(rec[(y0-1)*stride+x0-1])
+	}
+
+	shift := 3
+	if size == 16 {
+		shift = 4
+	}
+	var dc int32
+	switch {
+	case mbx == 0 && mby == 0:
+		dc = 128
+	case mby == 0:
+		for i := 0; i < size; i++ {
+			dc += left[i]
+		}
+		dc = (dc + 1<<(shift-1)) >> shift
+	case mbx == 0:
+		for i := 0; i < size; i++ {
+			dc += above[i]
+		}
+		dc = (dc + 1<<(shift-1)) >> shift
+	default:
+		for i := 0; i < size; i++ {
+			dc += above[i] + left[i]
+		}
+		dc = (dc + 1<<shift) >> (shift + 1)
+	}
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			i := y*size + x
+			out[predDC][i] = uint8(dc)
+			out[predTM][i] = clip8(left[y] + above[x] - corner)
+			out[predVE][i] = uint8(above[x])
+			out[predHE][i] = uint8(left[y])
+		}
+	}
+}
+
+// bestMode returns the prediction with the lowest squared error against
+// src.
+func bestMode(pred [][256]uint8, src []uint8, size int) int {
+	best, bestCost := 0, int64(math.MaxInt64)
+	for m := range pred {
+		cost := sse(pred[m][:size*size], src)
+		if cost < bestCost {
+			best, bestCost = m, cost
+		}
+	}
+	return best
+}
+
+func sse(a, b []uint8) int64 {
+	var sum int64
+	for i := range a {
+		d := int64(a[i]) - int64(b[i])
+		sum += d * d
+	}
+	return sum
+}
+
+// maxLevel is the largest magnitude a DCT_CAT6 token can carry.
+const maxLevel = 2048
+
+// quantize divides coeff by the step sizes into levels, starting at first,
+// and reports whether any level is nonzero. DC values round to nearest;
+// AC values round towards zero slightly more, which saves bits on the
+// many small high-frequency coefficients.
+func quantize(coeff *[16]int32, levels *[16]int16, q quantizer, first int) bool {
+	nonzero := false
+	for i := first; i < 16; i++ {
+		step, bias := q.ac, q.ac*3/8
+		if i == 0 {
+			step, bias = q.dc, q.dc/2
+		}
+		v := coeff[i]
+		neg := v < 0
+		if neg {
+			v = -v
+		}
+		l := (v + bias) / step
+		if l > maxLevel {
+			l = maxLevel
+		}
+		if neg {
+			l = -l
+		}
+		levels[i] = int16(l)
+		if l != 0 {
+			nonzero = true
+		}
+	}
+	return nonzero
+}
+
+// fdct is the forward 4x4 DCT from libvpx (vp8_short_fdct4x4_c), the exact
+// counterpart of the decoder's inverse transform.
+func fdct(in, out *[16]int32) {
+	var tmp [16]int32
+	for i := 0; i < 4; i++ {
+		ip := in[4*i:]
+		a1 := (ip[0] + ip[3]) * 8
+		b1 := (ip[1] + ip[2]) * 8
+		c1 := (ip[1] - ip[2]) * 8
+		d1 := (ip[0] - ip[3]) * 8
+		tmp[4*i+0] = a1 + b1
+		tmp[4*i+2] = a1 - b1
+		tmp[4*i+1] = (c1*2217 + d1*5352 + 14500) >> 12
+		tmp[4*i+3] = (d1*2217 - c1*5352 + 7500) >> 12
+	}
+	for i := 0; i < 4; i++ {
+		a1 := tmp[i] + tmp[12+i]
+		b1 := tmp[4+i] + tmp[8+i]
+		c1 := tmp[4+i] - tmp[8+i]
+		d1 := tmp[i] - tmp[12+i]
+		out[i] = (a1 + b1 + 7) >> 4
+		out[8+i] = (a1 - b1 + 7) >> 4
+		out[4+i] = (c1*2217 + d1*5352 + 12000) >> 16
+		if d1 != 0 {
+			out[4+i]++
+		}
+		out[12+i] = (d1*2217 - c1*5352 + 51000) >> 16
+	}
+}
+
+// fwht is the forward Walsh-Hadamard transform of the sixteen luma DCs
+// (libvpx vp8_short_walsh4x4_c).
+func fwht(in, out *[16]int32) {
+	var tmp [16]int32
+	for i := 0; i < 4; i++ {
+		ip := in[4*i:]
+		a1 := (ip[0] + ip[2]) * 4
+		d1 := (ip[1] + ip[3]) * 4
+		c1 := (ip[1] - ip[3]) * 4
+		b1 := (ip[0] - ip[2]) * 4
+		tmp[4*i+0] = a1 + d1
+		if a1 != 0 {
+			tmp[4*i+0]++
+		}
+		tmp[4*i+1] = b1 + c1
+		tmp[4*i+2] = b1 - c1
+		tmp[4*i+3] = a1 - d1
+	}
+	for i := 0; i < 4; i++ {
+		a1 := tmp[i] + tmp[8+i]
+		d1 := tmp[4+i] + tmp[12+i]
+		c1 := tmp[4+i] - tmp[12+i]
+		b1 := tmp[i] - tmp[8+i]
+		for j, v := range [4]int32{a1 + d1, b1 + c1, b1 - c1, a1 - d1} {
+			if v < 0 {
+				v++
+			}
+			out[4*j+i] = (v + 3) >> 3
+		}
+	}
+}
+
+// iwht mirrors the decoder's inverse Walsh-Hadamard transform, producing
+// the DC of each luma block in raster order.
+func iwht(in, out *[16]int32) {
+	var m [16]int32
+	for i := 0; i < 4; i++ {
+		a0 := in[i] + in[12+i]
+		a1 := in[4+i] + in[8+i]
+		a2 := in[4+i] - in[8+i]
+		a3 := in[i] - in[12+i]
+		m[i] = a0 + a1
+		m[8+i] = a0 - a1
+		m[4+i] = a3 + a2
+		m[12+i] = a3 - a2
+	}
+	for i := 0; i < 4; i++ {
+		dc := m[4*i] + 3
+		a0 := dc + m[4*i+3]
+		a1 := m[4*i+1] + m[4*i+2]
+		a2 := m[4*i+1] - m[4*i+2]
+		a3 := dc - m[4*i+3]
+		out[4*i+0] = (a0 + a1) >> 3
+		out[4*i+1] = (a3 + a2) >> 3
+		out[4*i+2] = (a0 - a1) >> 3
+		out[4*i+3] = (a3 - a2) >> 3
+	}
+}
+
+// idctAdd mirrors the decoder's inverse DCT, adding the residual in coeff
+// to the 4x4 block at dst.
+func idctAdd(coeff *[16]int32, dst []uint8, stride int) {
+	const (
+		c1 = 85627 // 65536 * cos(pi/8) * sqrt(2)
+		c2 = 35468 // 65536 * sin(pi/8) * sqrt(2)
+	)
+	var m [4][4]int32
+	for i := 0; i < 4; i++ {
+		a := coeff[i] + coeff[8+i]
+		b := coeff[i] - coeff[8+i]
+		c := (coeff[4+i] * c2 >> 16) - (coeff[12+i] * c1 >> 16)
+		d := (coeff[4+i] * c1 >> 16) + (coeff[12+i] * c2 >> 16)
+		m[i][0] = a + d
+		m[i][1] = b + c
+		m[i][2] = b - c
+		m[i][3] = a - d
+	}
+	for j := 0; j < 4; j++ {
+		dc := m[0][j] + 4
+		a := dc + m[2][j]
+		b := dc - m[2][j]
+		c := (m[1][j] * c2 >> 16) - (m[3][j] * c1 >> 16)
+		d := (m[1][j] * c1 >> 16) + (m[3][j] * c2 >> 16)
+		row := dst[j*stride:]
+		row[0] = clip8(int32(row[0]) + (a+d)>>3)
+		row[1] = clip8(int32(row[1]) + (b+c)>>3)
+		row[2] = clip8(int32(row[2]) + (b-c)>>3)
+		row[3] = clip8(int32(row[3]) + (a-d)>>3)
+	}
+}
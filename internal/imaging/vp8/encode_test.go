@@ -0,0 +1,128 @@
+package vp8
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	xvp8 "golang.org/x/image/vp8"
+)
+
+// testImage draws smooth gradients with a few hard edges, roughly what a
+// photo or screenshot looks like to the encoder.
+func testImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBA{
+				R: uint8(255 * x / w),
+				G: uint8(128 + 100*math.Sin(float64(x+y)/9)),
+				B: uint8(255 * y / h),
+				A: 255,
+			}
+			if (x/24+y/24)%5 == 0 {
+				c.R, c.G = 255-c.R, 30
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func decode(t *testing.T, data []byte) *image.YCbCr {
+	t.Helper()
+	d := xvp8.NewDecoder()
+	d.Init(bytes.NewReader(data), len(data))
+	_, err := d.DecodeFrameHeader()
+	require.NoError(t, err)
+	m, err := d.DecodeFrame()
+	require.NoError(t, err)
+	return m
+}
+
+// lumaPSNR compares the decoded luma plane with the encoder's own
+// conversion of the source.
+func lumaPSNR(src image.Image, m *image.YCbCr) float64 {
+	e := newEncoder(src, 0)
+	b := src.Bounds()
+	var sum float64
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			d := float64(e.srcY[y*e.yStride+x]) - float64(m.Y[y*m.YStride+x])
+			sum += d * d
+		}
+	}
+	mse := sum / float64(b.Dx()*b.Dy())
+	if mse == 0 {
+		return math.Inf(1)
+	}
+	return 10 * math.Log10(255*255/mse)
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	src := testImage(203, 117) // not a multiple of the macroblock size
+	for _, quality := range []int{1, 25, 50, 75, 90, 100} {
+		var buf bytes.Buffer
+		require.NoError(t, Encode(&buf, src, quality))
+
+		m := decode(t, buf.Bytes())
+		assert.Equal(t, 203, m.Bounds().Dx())
+		assert.Equal(t, 117, m.Bounds().Dy())
+
+		psnr := lumaPSNR(src, m)
+		t.Logf("quality %3d: %6d bytes, luma PSNR %.1f dB", quality, buf.Len(), psnr)
+		assert.Greater(t, psnr, 20.0, "quality %d", quality)
+		if quality >= 75 {
+			assert.Greater(t, psnr, 33.0, "quality %d", quality)
+		}
+	}
+}
+
+func TestEncode_QualityControlsSize(t *testing.T) {
+	src := testImage(256, 256)
+	var low, high bytes.Buffer
+	require.NoError(t, Encode(&low, src, 20))
+	require.NoError(t, Encode(&high, src, 90))
+	assert.Less(t, low.Len(), high.Len())
+}
+
+func TestEncode_FlatImage(t *testing.T) {
+	// Every macroblock after the first predicts perfectly and is skipped
+	img := image.NewUniform(color.NRGBA{R: 40, G: 90, B: 200, A: 255})
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, &croppedImage{img, image.Rect(0, 0, 640, 480)}, 75))
+	assert.Less(t, buf.Len(), 2000)
+
+	m := decode(t, buf.Bytes())
+	assert.InDelta(t, 91, int(m.Y[m.YStride*240+320]), 2)
+}
+
+func TestEncode_Invalid(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, Encode(&buf, testImage(8, 8), 0))
+	assert.Error(t, Encode(&buf, testImage(8, 8), 101))
+	assert.Error(t, Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 0, 0)), 75))
+	assert.Error(t, Encode(&buf, &croppedImage{image.Black, image.Rect(0, 0, MaxDimension+1, 1)}, 75))
+}
+
+func TestQualityToQuantizer(t *testing.T) {
+	assert.Equal(t, 127, QualityToQuantizer(0))
+	assert.Equal(t, 0, QualityToQuantizer(100))
+	prev := 128
+	for q := 1; q <= 100; q++ {
+		got := QualityToQuantizer(q)
+		assert.LessOrEqual(t, got, prev, "quality %d", q)
+		prev = got
+	}
+}
+
+type croppedImage struct {
+	image.Image
+	rect image.Rectangle
+}
+
+func (c *croppedImage) Bounds() image.Rectangle { return c.rect }
@@ -0,0 +1,358 @@
+package vp8
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Fixed mode probabilities for key frames (RFC 6386 section 11.2).
+const (
+	probIsY16   = 145
+	probSkipOff = 0
+)
+
+// nzContext tracks which neighbouring blocks had nonzero coefficients;
+// the count of nonzero neighbours selects the token probability context.
+type nzContext struct {
+	y  [4]uint8
+	u  [2]uint8
+	v  [2]uint8
+	y2 uint8
+}
+
+// frame assembles the complete key frame: the uncompressed header, the
+// first partition (frame header and modes) and a single token partition.
+func (e *encoder) frame(width, height int) []byte {
+	e.probs = defaultTokenProb
+
+	// Dry run to gather token statistics, then adapt the probabilities
+	e.stats = new([nPlane][nBand][nContext][nProb][2]uint32)
+	e.writeTokens()
+	updates := e.updateProbs()
+	e.stats = nil
+	e.skipProb = e.computeSkipProb()
+
+	tokens := e.writeTokens()
+	first := e.writeFirstPartition(updates)
+
+	out := make([]byte, 10, 10+len(first)+len(tokens))
+	tag := uint32(len(first))<<5 | 1<<4 // key frame, version 0, shown
+	out[0], out[1], out[2] = byte(tag), byte(tag>>8), byte(tag>>16)
+	out[3], out[4], out[5] = 0x9d, 0x01, 0x2a
+	binary.LittleEndian.PutUint16(out[6:], uint16(width))
+	binary.LittleEndian.PutUint16(out[8:], uint16(height))
+	out = append(out, first...)
+	return append(out, tokens...)
+}
+
+// filterLevel derives the loop filter strength from the quantizer: coarser
+// quantization leaves stronger block edges to smooth over.
+func (e *encoder) filterLevel() uint32 {
+	level := uint32(acTable[e.q]) * 17 / 100
+	if level > 63 {
+		level = 63
+	}
+	return level
+}
+
+func (e *encoder) writeFirstPartition(updates *[nPlane][nBand][nContext][nProb]bool) []byte {
+	bw := newBoolEncoder()
+	bw.putFlag(false) // color space: YUV
+	bw.putFlag(false) // clamping required
+	bw.putFlag(false) // no segmentation
+
+	// Loop filter: normal type, no per-mode adjustments
+	bw.putFlag(false)
+	bw.putLiteral(e.filterLevel(), 6)
+	bw.putLiteral(0, 3) // sharpness
+	bw.putFlag(false)
+
+	bw.putLiteral(0, 2) // one token partition
+
+	// Quantizer index with no per-plane deltas
+	bw.putLiteral(uint32(e.q), 7)
+	for i := 0; i < 5; i++ {
+		bw.putFlag(false)
+	}
+
+	bw.putFlag(false) // refresh entropy probs
+
+	for i := range e.probs {
+		for j := range e.probs[i] {
+			for k := range e.probs[i][j] {
+				for l, p := range e.probs[i][j][k] {
+					update := updates[i][j][k][l]
+					bw.putBit(update, tokenProbUpdateProb[i][j][k][l])
+					if update {
+						bw.putLiteral(uint32(p), 8)
+					}
+				}
+			}
+		}
+	}
+
+	useSkip := e.skipProb != probSkipOff
+	bw.putFlag(useSkip)
+	if useSkip {
+		bw.putLiteral(uint32(e.skipProb), 8)
+	}
+
+	for i := range e.mbs {
+		mb := &e.mbs[i]
+		if useSkip {
+			bw.putBit(mb.skip, e.skipProb)
+		}
+		bw.putBit(true, probIsY16)
+		switch mb.yMode {
+		case predDC:
+			bw.putBit(false, 156)
+			bw.putBit(false, 163)
+		case predVE:
+			bw.putBit(false, 156)
+			bw.putBit(true, 163)
+		case predHE:
+			bw.putBit(true, 156)
+			bw.putBit(false, 128)
+		case predTM:
+			bw.putBit(true, 156)
+			bw.putBit(true, 128)
+		}
+		switch mb.uvMode {
+		case predDC:
+			bw.putBit(false, 142)
+		case predVE:
+			bw.putBit(true, 142)
+			bw.putBit(false, 114)
+		case predHE:
+			bw.putBit(true, 142)
+			bw.putBit(true, 114)
+			bw.putBit(false, 183)
+		case predTM:
+			bw.putBit(true, 142)
+			bw.putBit(true, 114)
+			bw.putBit(true, 183)
+		}
+	}
+	return bw.finish()
+}
+
+// computeSkipProb returns the probability of a macroblock having
+// coefficients, or probSkipOff when no macroblock can be skipped and the
+// per-macroblock flag would only waste bits.
+func (e *encoder) computeSkipProb() uint8 {
+	skipped := 0
+	for i := range e.mbs {
+		if e.mbs[i].skip {
+			skipped++
+		}
+	}
+	if skipped == 0 {
+		return probSkipOff
+	}
+	p := 255 * (len(e.mbs) - skipped) / len(e.mbs)
+	if p < 1 {
+		p = 1
+	}
+	return uint8(p)
+}
+
+// writeTokens codes the coefficients of every macroblock. While stats is
+// set it only counts the bits each probability would code.
+func (e *encoder) writeTokens() []byte {
+	e.tokens = newBoolEncoder()
+	up := make([]nzContext, e.mbw)
+	for mby := 0; mby < e.mbh; mby++ {
+		var left nzContext
+		for mbx := 0; mbx < e.mbw; mbx++ {
+			mb := &e.mbs[mby*e.mbw+mbx]
+			above := &up[mbx]
+			if mb.skip && e.skipProb != probSkipOff {
+				left, *above = nzContext{}, nzContext{}
+				continue
+			}
+
+			nz := e.putBlock(planeY2, left.y2+above.y2, &mb.y2, 0)
+			left.y2, above.y2 = nz, nz
+			for y := 0; y < 4; y++ {
+				for x := 0; x < 4; x++ {
+					nz := e.putBlock(planeY1WithY2, left.y[y]+above.y[x], &mb.y[4*y+x], 1)
+					left.y[y], above.y[x] = nz, nz
+				}
+			}
+			for c, ctx := range [2]struct{ l, a *[2]uint8 }{{&left.u, &above.u}, {&left.v, &above.v}} {
+				for y := 0; y < 2; y++ {
+					for x := 0; x < 2; x++ {
+						nz := e.putBlock(planeUV, ctx.l[y]+ctx.a[x], &mb.uv[4*c+2*y+x], 0)
+						ctx.l[y], ctx.a[x] = nz, nz
+					}
+				}
+			}
+		}
+	}
+	return e.tokens.finish()
+}
+
+// putToken codes one bit of the token tree with the adaptive probability
+// at index i of p, or records it during the statistics pass.
+func (e *encoder) putToken(bit bool, plane int, band uint8, ctx uint8, i int) {
+	if e.stats != nil {
+		e.stats[plane][band][ctx][i][btou(bit)]++
+		return
+	}
+	e.tokens.putBit(bit, e.probs[plane][band][ctx][i])
+}
+
+// putExtra codes a bit with a fixed probability.
+func (e *encoder) putExtra(bit bool, prob uint8) {
+	if e.stats == nil {
+		e.tokens.putBit(bit, prob)
+	}
+}
+
+// putBlock codes the levels of one 4x4 block starting at position first,
+// following the token tree of RFC 6386 section 13.2, and returns 1 if any
+// coefficient was coded.
+func (e *encoder) putBlock(plane int, ctx uint8, levels *[16]int16, first int) uint8 {
+	last := -1
+	for n := first; n < 16; n++ {
+		if levels[zigzag[n]] != 0 {
+			last = n
+		}
+	}
+
+	n := first
+	band := bands[n]
+	if last < 0 {
+		e.putToken(false, plane, band, ctx, 0) // EOB
+		return 0
+	}
+	e.putToken(true, plane, band, ctx, 0)
+
+	for n <= last {
+		v := int(levels[zigzag[n]])
+		n++
+		if v == 0 {
+			e.putToken(false, plane, band, ctx, 1)
+			band, ctx = bands[n], 0
+			continue
+		}
+		e.putToken(true, plane, band, ctx, 1)
+
+		sign := v < 0
+		if sign {
+			v = -v
+		}
+		if v == 1 {
+			e.putToken(false, plane, band, ctx, 2)
+		} else {
+			e.putToken(true, plane, band, ctx, 2)
+			e.putValue(v, plane, band, ctx)
+		}
+		e.putExtra(sign, 128)
+
+		next := uint8(2)
+		if v == 1 {
+			next = 1
+		}
+		band, ctx = bands[n], next
+		if n == 16 {
+			break
+		}
+		e.putToken(n <= last, plane, band, ctx, 0)
+	}
+	return 1
+}
+
+// putValue codes a magnitude of 2 or more (the tokens after ONE).
+func (e *encoder) putValue(v int, plane int, band, ctx uint8) {
+	switch {
+	case v <= 4:
+		e.putToken(false, plane, band, ctx, 3)
+		if v == 2 {
+			e.putToken(false, plane, band, ctx, 4)
+		} else {
+			e.putToken(true, plane, band, ctx, 4)
+			e.putToken(v == 4, plane, band, ctx, 5)
+		}
+	case v <= 10:
+		e.putToken(true, plane, band, ctx, 3)
+		e.putToken(false, plane, band, ctx, 6)
+		if v <= 6 {
+			e.putToken(false, plane, band, ctx, 7)
+			e.putExtra(v == 6, 159)
+		} else {
+			e.putToken(true, plane, band, ctx, 7)
+			e.putExtra((v-7)&2 != 0, 165)
+			e.putExtra((v-7)&1 != 0, 145)
+		}
+	default:
+		e.putToken(true, plane, band, ctx, 3)
+		e.putToken(true, plane, band, ctx, 6)
+		var cat int
+		switch {
+		case v < 19:
+			cat = 0
+		case v < 35:
+			cat = 1
+		case v < 67:
+			cat = 2
+		default:
+			cat = 3
+		}
+		e.putToken(cat >= 2, plane, band, ctx, 8)
+		e.putToken(cat&1 != 0, plane, band, ctx, 9+cat>>1)
+		extra := v - 3 - 8<<uint(cat)
+		probs := cat3456[cat][:]
+		nbits := 0
+		for probs[nbits] != 0 {
+			nbits++
+		}
+		for i, p := range probs[:nbits] {
+			e.putExtra(extra&(1<<uint(nbits-1-i)) != 0, p)
+		}
+	}
+}
+
+// updateProbs replaces each token probability whose update pays for
+// itself in the collected statistics, and reports which ones changed.
+func (e *encoder) updateProbs() *[nPlane][nBand][nContext][nProb]bool {
+	updates := new([nPlane][nBand][nContext][nProb]bool)
+	for i := range e.probs {
+		for j := range e.probs[i] {
+			for k := range e.probs[i][j] {
+				for l, old := range e.probs[i][j][k] {
+					n0, n1 := e.stats[i][j][k][l][0], e.stats[i][j][k][l][1]
+					if n0+n1 == 0 {
+						continue
+					}
+					p := 255 - 255*uint64(n1)/uint64(n0+n1)
+					if p < 1 {
+						p = 1
+					}
+					upd := tokenProbUpdateProb[i][j][k][l]
+					oldCost := bitCost(n0, n1, old) + bitCost(1, 0, upd)
+					newCost := bitCost(n0, n1, uint8(p)) + bitCost(0, 1, upd) + 8
+					if newCost < oldCost {
+						e.probs[i][j][k][l] = uint8(p)
+						updates[i][j][k][l] = true
+					}
+				}
+			}
+		}
+	}
+	return updates
+}
+
+// bitCost estimates the bits needed to code n0 zeros and n1 ones when a
+// zero has probability prob/256.
+func bitCost(n0, n1 uint32, prob uint8) float64 {
+	p0 := float64(prob) / 256
+	return -float64(n0)*math.Log2(p0) - float64(n1)*math.Log2(1-p0)
+}
+
+func btou(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,208 @@
+// Package imaging provides image processing operations using pure Go.
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/apresai/gimage/internal/imaging/vp8"
+)
+
+// encodeWebP encodes an image as WebP. A quality of 1-100 produces a lossy
+// (VP8) file; 0 keeps the lossless (VP8L) encoding.
+//
+// Lossy files with transparent pixels carry their alpha channel losslessly
+// in an ALPH chunk, so transparency survives compression.
+func encodeWebP(w io.Writer, img image.Image, quality int) error {
+	if quality <= 0 {
+		// Use nativewebp for pure Go lossless WebP encoding (VP8L)
+		return nativewebp.Encode(w, img, &nativewebp.Options{
+			UseExtendedFormat: false, // Basic VP8L format
+		})
+	}
+
+	chunks, alpha, err := encodeWebPFrame(img, quality)
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	if alpha {
+		// The extended header announces the alpha chunk
+		bounds := img.Bounds()
+		writeRIFFChunk(&body, "VP8X", webpVP8X(0x10, bounds.Dx(), bounds.Dy()))
+	}
+	body.Write(chunks)
+	_, err = w.Write(webpFile(body.Bytes()))
+	return err
+}
+
+// encodeWebPFrame encodes img as the image chunks of a WebP frame: VP8L at
+// quality 0, otherwise VP8 preceded by ALPH when img has transparency. It
+// reports whether the frame uses alpha.
+func encodeWebPFrame(img image.Image, quality int) ([]byte, bool, error) {
+	if quality <= 0 {
+		var lossless bytes.Buffer
+		if err := nativewebp.Encode(&lossless, img, nil); err != nil {
+			return nil, false, err
+		}
+		// Drop the RIFF header, keeping the VP8L chunk
+		data := lossless.Bytes()
+		if len(data) < 25 || string(data[12:16]) != "VP8L" {
+			return nil, false, fmt.Errorf("unexpected lossless WebP layout")
+		}
+		return data[12:], data[24]&0x10 != 0, nil
+	}
+
+	var chunks, frame bytes.Buffer
+	alpha := !isOpaque(img)
+	if alpha {
+		alph, err := encodeWebPAlpha(img)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to encode alpha channel: %w", err)
+		}
+		writeRIFFChunk(&chunks, "ALPH", alph)
+	}
+	if err := vp8.Encode(&frame, img, quality); err != nil {
+		return nil, false, err
+	}
+	writeRIFFChunk(&chunks, "VP8 ", frame.Bytes())
+	return chunks.Bytes(), alpha, nil
+}
+
+// webpVP8X returns the payload of a VP8X chunk
+func webpVP8X(flags byte, width, height int) []byte {
+	vp8x := make([]byte, 10)
+	vp8x[0] = flags
+	putUint24(vp8x[4:], uint32(width-1))
+	putUint24(vp8x[7:], uint32(height-1))
+	return vp8x
+}
+
+// webpFile wraps WebP chunks in the RIFF header
+func webpFile(body []byte) []byte {
+	out := make([]byte, 12, 12+len(body))
+	copy(out, "RIFF")
+	binary.LittleEndian.PutUint32(out[4:], uint32(4+len(body)))
+	copy(out[8:], "WEBP")
+	return append(out, body...)
+}
+
+// encodeWebPAlpha returns the payload of an ALPH chunk for img: the alpha
+// channel compressed as a headerless lossless (VP8L) image stream.
+func encodeWebPAlpha(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	mask := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			_, _, _, a := img.At(x, y).RGBA()
+			mask.Pix[(y-bounds.Min.Y)*mask.Stride+x-bounds.Min.X] = uint8(a >> 8)
+		}
+	}
+
+	// A VP8L file is RIFF(12) + chunk header(8) + a 5-byte stream header
+	// (signature, dimensions and flags); the alpha chunk keeps only the
+	// byte-aligned image stream that follows, whose green channel holds
+	// the alpha values.
+	var lossless bytes.Buffer
+	if err := nativewebp.Encode(&lossless, mask, nil); err != nil {
+		return nil, err
+	}
+	data := lossless.Bytes()
+	if len(data) < 25 || string(data[12:16]) != "VP8L" {
+		return nil, fmt.Errorf("unexpected lossless WebP layout")
+	}
+
+	// Header byte: no pre-processing, no filtering, lossless compression
+	return append([]byte{0x01}, data[25:]...), nil
+}
+
+// isOpaque reports whether every pixel of img is fully opaque. Unlike
+// hasTransparency it never samples, so small transparent regions keep
+// their alpha.
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a < 0xffff {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// webpToNRGBA converts a decoded lossy WebP frame to RGB. x/image/webp
+// returns VP8 frames as image.YCbCr, whose color model assumes full-range
+// (JPEG) values, but VP8 stores studio-range BT.601 as libwebp does, so
+// the stock conversion washes colors out. This uses libwebp's fixed-point
+// conversion instead. Lossless frames are already RGB and pass through.
+func webpToNRGBA(img image.Image) image.Image {
+	var m *image.YCbCr
+	var alpha *image.NYCbCrA
+	switch src := img.(type) {
+	case *image.YCbCr:
+		m = src
+	case *image.NYCbCrA:
+		m, alpha = &src.YCbCr, src
+	default:
+		return img
+	}
+
+	bounds := m.Bounds()
+	out := image.NewNRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			yy := int32(m.Y[m.YOffset(x, y)]) * 19077 >> 8
+			ci := m.COffset(x, y)
+			u, v := int32(m.Cb[ci]), int32(m.Cr[ci])
+			i := out.PixOffset(x, y)
+			out.Pix[i+0] = clipWebP(yy + v*26149>>8 - 14234)
+			out.Pix[i+1] = clipWebP(yy - u*6419>>8 - v*13320>>8 + 8708)
+			out.Pix[i+2] = clipWebP(yy + u*33050>>8 - 17685)
+			out.Pix[i+3] = 0xff
+			if alpha != nil {
+				out.Pix[i+3] = alpha.A[alpha.AOffset(x, y)]
+			}
+		}
+	}
+	return out
+}
+
+// clipWebP scales a 14-bit fixed-point channel value down to 8 bits
+func clipWebP(v int32) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v >= 1<<14 {
+		return 255
+	}
+	return uint8(v >> 6)
+}
+
+// writeRIFFChunk appends a RIFF chunk, padding odd-sized payloads.
+func writeRIFFChunk(buf *bytes.Buffer, fourCC string, payload []byte) {
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(payload)))
+	buf.WriteString(fourCC)
+	buf.Write(size[:])
+	buf.Write(payload)
+	if len(payload)%2 != 0 {
+		buf.WriteByte(0)
+	}
+}
+
+func putUint24(b []byte, v uint32) {
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
+
+func uint24(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+}
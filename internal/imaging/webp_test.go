@@ -0,0 +1,107 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// texturedImage returns an opaque image with enough detail for lossy
+// encoding to make a difference.
+func texturedImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * y), G: uint8(x ^ y), B: uint8(x + 3*y), A: 255})
+		}
+	}
+	return img
+}
+
+func TestEncodeWebP_Lossy(t *testing.T) {
+	img := texturedImage(200, 150)
+
+	var lossless, lossy bytes.Buffer
+	require.NoError(t, encodeImageQuality(&lossless, img, "webp", 0))
+	require.NoError(t, encodeImageQuality(&lossy, img, "webp", 75))
+	assert.Equal(t, "VP8L", string(lossless.Bytes()[12:16]))
+	assert.Equal(t, "VP8 ", string(lossy.Bytes()[12:16]))
+	assert.Less(t, lossy.Len(), lossless.Len())
+
+	decoded, format, err := image.Decode(bytes.NewReader(lossy.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, "webp", format)
+	assert.Equal(t, img.Bounds().Size(), decoded.Bounds().Size())
+}
+
+func TestEncodeWebP_LossyKeepsAlpha(t *testing.T) {
+	img := texturedImage(64, 48)
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 32; x++ {
+			img.Pix[img.PixOffset(x, y)+3] = uint8(4 * x)
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, encodeImageQuality(&buf, img, "webp", 80))
+	assert.Equal(t, "VP8X", string(buf.Bytes()[12:16]))
+
+	decoded, _, err := image.Decode(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	for _, x := range []int{0, 10, 31, 32, 63} {
+		_, _, _, a := decoded.At(x, 20).RGBA()
+		want := uint32(255)
+		if x < 32 {
+			want = uint32(4 * x)
+		}
+		assert.Equal(t, want, a>>8, "alpha at x=%d", x)
+	}
+}
+
+func TestConvertImageDataWithQuality(t *testing.T) {
+	var src bytes.Buffer
+	require.NoError(t, encodeImage(&src, texturedImage(120, 90), "png"))
+
+	// Same format without a quality is returned untouched
+	same, err := ConvertImageDataWithQuality(src.Bytes(), "png", 0)
+	require.NoError(t, err)
+	assert.Equal(t, src.Bytes(), same)
+
+	high, err := ConvertImageDataWithQuality(src.Bytes(), "webp", 90)
+	require.NoError(t, err)
+	low, err := ConvertImageDataWithQuality(src.Bytes(), "webp", 20)
+	require.NoError(t, err)
+	assert.Less(t, len(low), len(high))
+
+	// Re-encoding in the source format honors the quality too
+	webpAgain, err := ConvertImageDataWithQuality(high, "webp", 20)
+	require.NoError(t, err)
+	assert.NotEqual(t, high, webpAgain)
+
+	_, err = ConvertImageDataWithQuality(src.Bytes(), "webp", 101)
+	assert.Error(t, err)
+}
+
+func TestCompressImage_WebP(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, "in.png")
+	require.NoError(t, NewPipeline(texturedImage(160, 120), "png").Save(inputPath))
+
+	lowPath := filepath.Join(tmpDir, "low.webp")
+	highPath := filepath.Join(tmpDir, "high.webp")
+	require.NoError(t, CompressImage(context.Background(), inputPath, lowPath, 30, FileOptions{}))
+	require.NoError(t, CompressImage(context.Background(), inputPath, highPath, 90, FileOptions{}))
+
+	low, err := os.Stat(lowPath)
+	require.NoError(t, err)
+	high, err := os.Stat(highPath)
+	require.NoError(t, err)
+	assert.Less(t, low.Size(), high.Size())
+}
@@ -5,24 +5,54 @@ type GenerateRequest struct {
 	Prompt         string `json:"prompt"`
 	Model          string `json:"model,omitempty"`
 	Size           string `json:"size,omitempty"`
+	AspectRatio    string `json:"aspect_ratio,omitempty"` // e.g. "16:9"; alone, picks the provider's native size
+	Fit            string `json:"fit,omitempty"`          // "crop" (default), "resize" or "none"
 	Style          string `json:"style,omitempty"`
 	NegativePrompt string `json:"negative_prompt,omitempty"`
 	Seed           int64  `json:"seed,omitempty"`
+	Count          int    `json:"count,omitempty"`           // 1-10, more than 1 returns GenerateResponse
 	ResponseFormat string `json:"response_format,omitempty"` // "base64" or "s3_url"
+
+	// Options are provider-specific settings, e.g. {"cfgScale": 8} for Nova Canvas
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// EditRequest represents a request to edit an image from a text instruction
+type EditRequest struct {
+	Image          string  `json:"image"` // base64 encoded image or S3 key
+	Prompt         string  `json:"prompt"`
+	Model          string  `json:"model,omitempty"`       // "gemini", "nova-canvas" or "auto" (default)
+	Mode           string  `json:"mode,omitempty"`        // "variation" or "inpainting"
+	Mask           string  `json:"mask,omitempty"`        // base64 encoded mask image or S3 key
+	MaskPrompt     string  `json:"mask_prompt,omitempty"` // describe the area to edit instead of a mask
+	Strength       float64 `json:"strength,omitempty"`    // 0.2-1.0, variations only
+	Style          string  `json:"style,omitempty"`
+	NegativePrompt string  `json:"negative_prompt,omitempty"`
+	Seed           int64   `json:"seed,omitempty"`
+	ResponseFormat string  `json:"response_format,omitempty"` // "base64" or "s3_url"
 }
 
 // ResizeRequest represents a request to resize an image
 type ResizeRequest struct {
-	Image          string `json:"image"` // base64 encoded image or S3 key
-	Width          int    `json:"width"`
-	Height         int    `json:"height"`
-	ResponseFormat string `json:"response_format,omitempty"`
+	Image          string  `json:"image"` // base64 encoded image or S3 key
+	Width          int     `json:"width"`
+	Height         int     `json:"height"`
+	Mode           string  `json:"mode,omitempty"`       // "stretch" (default), "fit", "fill" or "pad"
+	Anchor         string  `json:"anchor,omitempty"`     // part kept by "fill", "center" by default
+	Background     string  `json:"background,omitempty"` // "pad" background: color name, hex or "blur"; "black" by default
+	Filter         string  `json:"filter,omitempty"`     // resampling filter, "lanczos" by default
+	Sharpen        float64 `json:"sharpen,omitempty"`    // post-resize sharpen amount, 0-10
+	Metadata       string  `json:"metadata,omitempty"`   // "color" (default), "keep", "strip-gps" or "strip"
+	ResponseFormat string  `json:"response_format,omitempty"`
 }
 
 // ScaleRequest represents a request to scale an image by a factor
 type ScaleRequest struct {
 	Image          string  `json:"image"` // base64 encoded image or S3 key
 	Factor         float64 `json:"factor"`
+	Filter         string  `json:"filter,omitempty"`   // resampling filter, "lanczos" by default
+	Sharpen        float64 `json:"sharpen,omitempty"`  // post-resize sharpen amount, 0-10
+	Metadata       string  `json:"metadata,omitempty"` // "color" (default), "keep", "strip-gps" or "strip"
 	ResponseFormat string  `json:"response_format,omitempty"`
 }
 
@@ -33,6 +63,9 @@ type CropRequest struct {
 	Y              int    `json:"y"`
 	Width          int    `json:"width"`
 	Height         int    `json:"height"`
+	Smart          bool   `json:"smart,omitempty"`    // pick the most interesting region and scale it to width x height; x and y are ignored
+	Filter         string `json:"filter,omitempty"`   // resampling filter of a smart crop, "lanczos" by default
+	Metadata       string `json:"metadata,omitempty"` // "color" (default), "keep", "strip-gps" or "strip"
 	ResponseFormat string `json:"response_format,omitempty"`
 }
 
@@ -40,7 +73,12 @@ type CropRequest struct {
 type CompressRequest struct {
 	Image          string `json:"image"` // base64 encoded image or S3 key
 	Quality        int    `json:"quality,omitempty"`
-	Format         string `json:"format,omitempty"` // jpg, png, webp
+	Format         string `json:"format,omitempty"`       // jpg, png, webp, avif
+	MaxSize        string `json:"max_size,omitempty"`     // byte budget, e.g. "200KB"; quality becomes the upper bound
+	MinQuality     int    `json:"min_quality,omitempty"`  // lowest quality tried with max_size
+	AllowResize    bool   `json:"allow_resize,omitempty"` // with max_size, step dimensions down if needed
+	Speed          int    `json:"speed,omitempty"`        // AVIF encoder speed 1-10
+	Metadata       string `json:"metadata,omitempty"`     // "color" (default), "keep", "strip-gps" or "strip"
 	ResponseFormat string `json:"response_format,omitempty"`
 }
 
@@ -48,9 +86,22 @@ type CompressRequest struct {
 type ConvertRequest struct {
 	Image          string `json:"image"` // base64 encoded image or S3 key
 	TargetFormat   string `json:"target_format"`
+	Quality        int    `json:"quality,omitempty"`  // lossy quality for jpg, webp and avif
+	Speed          int    `json:"speed,omitempty"`    // AVIF encoder speed 1-10
+	Metadata       string `json:"metadata,omitempty"` // "color" (default), "keep", "strip-gps" or "strip"
 	ResponseFormat string `json:"response_format,omitempty"`
 }
 
+// PipelineRequest represents a request to apply a chain of operations with a
+// single decode and encode
+type PipelineRequest struct {
+	Image          string   `json:"image"`              // base64 encoded image or S3 key
+	Steps          []string `json:"steps"`              // e.g. "crop:0,0,800,600", "resize:400x300", "convert:webp"
+	Filter         string   `json:"filter,omitempty"`   // resampling filter of resize, fit and scale steps
+	Metadata       string   `json:"metadata,omitempty"` // "color" (default), "keep", "strip-gps" or "strip"
+	ResponseFormat string   `json:"response_format,omitempty"`
+}
+
 // BatchOperation represents a single operation in a batch request
 type BatchOperation struct {
 	Operation string                 `json:"operation"` // "resize", "scale", "crop", "compress", "convert"
@@ -76,6 +127,12 @@ type ImageResponse struct {
 	Metadata  map[string]string `json:"metadata,omitempty"`
 }
 
+// GenerateResponse represents the response for a multi-image generate request
+type GenerateResponse struct {
+	Images []ImageResponse `json:"images"`
+	Count  int             `json:"count"`
+}
+
 // BatchResponse represents the response for batch operations
 type BatchResponse struct {
 	BatchID   string `json:"batch_id"`
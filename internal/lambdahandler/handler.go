@@ -47,6 +47,8 @@ func (h *Handler) Handle(ctx context.Context, req events.APIGatewayProxyRequest)
 	switch routeKey {
 	case "POST /generate":
 		return h.handleGenerate(ctx, []byte(req.Body))
+	case "POST /edit":
+		return h.handleEdit(ctx, []byte(req.Body))
 	case "POST /resize":
 		return h.handleResize(ctx, []byte(req.Body))
 	case "POST /scale":
@@ -57,6 +59,8 @@ func (h *Handler) Handle(ctx context.Context, req events.APIGatewayProxyRequest)
 		return h.handleCompress(ctx, []byte(req.Body))
 	case "POST /convert":
 		return h.handleConvert(ctx, []byte(req.Body))
+	case "POST /pipeline":
+		return h.handlePipeline(ctx, []byte(req.Body))
 	case "POST /batch":
 		return h.handleBatch(ctx, []byte(req.Body))
 	case "GET /health":
@@ -1,16 +1,11 @@
 package lambdahandler
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"image"
-	"image/gif"
-	"image/jpeg"
-	"image/png"
 	"log"
-	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -20,7 +15,6 @@ import (
 	"github.com/apresai/gimage/internal/generate"
 	gimageimaging "github.com/apresai/gimage/internal/imaging"
 	"github.com/apresai/gimage/pkg/models"
-	"github.com/disintegration/imaging"
 )
 
 // handleGenerate handles AI image generation requests
@@ -34,92 +28,184 @@ func (h *Handler) handleGenerate(ctx context.Context, body []byte) (events.APIGa
 	if req.Prompt == "" {
 		return errorResponse(400, "Prompt is required"), nil
 	}
+	if err := generate.ValidateImageCount(req.Count); err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
+	if err := generate.ValidateFitMode(req.Fit); err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
 
 	// Build generate options
 	options := models.GenerateOptions{
 		Model:          req.Model,
 		Size:           req.Size,
+		AspectRatio:    req.AspectRatio,
 		Style:          req.Style,
 		NegativePrompt: req.NegativePrompt,
 		Seed:           req.Seed,
+		Count:          req.Count,
 	}
 
 	// Set defaults
-	if options.Model == "" {
-		options.Model = generate.DefaultModel
-	}
-	if options.Size == "" {
+	if options.Size == "" && options.AspectRatio == "" {
 		options.Size = "1024x1024"
 	}
+	targetWidth, targetHeight, err := generate.RequestedSize(options)
+	if err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
 
-	log.Printf("Generating image with prompt: %s, model: %s", req.Prompt, options.Model)
+	// Resolve the model (or "auto") to a provider and create its client.
+	// Credentials come from env vars; Bedrock uses the Lambda execution role.
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return errorResponse(500, fmt.Sprintf("Failed to load config: %v", err)), nil
+	}
 
-	// Determine API to use
-	api, err := generate.DetectAPIFromModel(options.Model)
+	registry := generate.GetProviderRegistry()
+	provider, err := registry.SelectProvider(options.Model, cfg)
 	if err != nil {
+		if options.Model == "" || strings.EqualFold(options.Model, generate.AutoProvider) {
+			return errorResponse(503, fmt.Sprintf("No provider configured: %v", err)), nil
+		}
 		return errorResponse(400, fmt.Sprintf("Invalid model: %v", err)), nil
 	}
+	options.Model = provider.ModelID
 
-	var generatedImage *models.GeneratedImage
+	if options.Extra, err = provider.ValidateOptions(req.Options); err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
 
-	// Generate based on API
-	if api == "gemini" {
-		key, err := config.GetGeminiAPIKey("")
-		if err != nil {
-			return errorResponse(500, fmt.Sprintf("Gemini API key not configured: %v", err)), nil
-		}
+	// Request the provider's closest native size; FitImages restores the exact size
+	if options, err = provider.NativeOptions(options); err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
 
-		client, err := generate.NewGeminiRESTClient(key)
+	log.Printf("Generating image with prompt: %s, provider: %s", req.Prompt, provider.ID)
+
+	client, _, err := registry.ResolveClient(provider.ID, cfg)
+	if err != nil {
+		return errorResponse(500, fmt.Sprintf("Failed to create %s client: %v", provider.API, err)), nil
+	}
+	defer client.Close()
+
+	generatedImages, err := generate.GenerateImages(ctx, client, req.Prompt, options)
+	if err != nil {
+		return errorResponse(500, fmt.Sprintf("Failed to generate image: %v", err)), nil
+	}
+	if err := generate.FitImages(generatedImages, targetWidth, targetHeight, req.Fit); err != nil {
+		return errorResponse(500, err.Error()), nil
+	}
+
+	// Create response - a single image keeps the plain ImageResponse shape.
+	// Metadata records the provider that served it (it differs after a failover).
+	if len(generatedImages) == 1 {
+		image := generatedImages[0]
+		imgResp, err := h.buildImageResponse(ctx, image.Data, image.Format, image.Width, image.Height, req.ResponseFormat)
 		if err != nil {
-			return errorResponse(500, fmt.Sprintf("Failed to create Gemini client: %v", err)), nil
+			return errorResponse(500, err.Error()), nil
 		}
-		defer client.Close()
+		imgResp.Metadata = image.Metadata
+		return successResponse(200, imgResp), nil
+	}
 
-		generatedImage, err = client.GenerateImage(ctx, req.Prompt, options)
+	resp := GenerateResponse{Count: len(generatedImages)}
+	for _, image := range generatedImages {
+		imgResp, err := h.buildImageResponse(ctx, image.Data, image.Format, image.Width, image.Height, req.ResponseFormat)
 		if err != nil {
-			return errorResponse(500, fmt.Sprintf("Failed to generate image: %v", err)), nil
+			return errorResponse(500, err.Error()), nil
 		}
-	} else if api == "vertex" {
-		vertexAPIKey, _ := config.GetVertexAPIKey("")
-
-		// Load project and location from config or env
-		project := os.Getenv("VERTEX_PROJECT")
-		location := os.Getenv("VERTEX_LOCATION")
-		if location == "" {
-			location = "us-central1"
+		imgResp.Metadata = image.Metadata
+		resp.Images = append(resp.Images, imgResp)
+	}
+
+	return successResponse(200, resp), nil
+}
+
+// handleEdit handles AI image editing requests
+func (h *Handler) handleEdit(ctx context.Context, body []byte) (events.APIGatewayProxyResponse, error) {
+	var req EditRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return errorResponse(400, fmt.Sprintf("Invalid request body: %v", err)), nil
+	}
+
+	// Validate inputs
+	if req.Image == "" {
+		return errorResponse(400, "Image is required"), nil
+	}
+	if req.Prompt == "" {
+		return errorResponse(400, "Prompt is required"), nil
+	}
+
+	// Resolve the model (or "auto") the same way as generate
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return errorResponse(500, fmt.Sprintf("Failed to load config: %v", err)), nil
+	}
+
+	registry := generate.GetProviderRegistry()
+	provider, err := registry.SelectProvider(req.Model, cfg)
+	if err != nil {
+		if req.Model == "" || strings.EqualFold(req.Model, generate.AutoProvider) {
+			return errorResponse(503, fmt.Sprintf("No provider configured: %v", err)), nil
 		}
+		return errorResponse(400, fmt.Sprintf("Invalid model: %v", err)), nil
+	}
+	if !provider.Capabilities.SupportsEditing {
+		return errorResponse(400, fmt.Sprintf("Model %s does not support image editing", provider.ID)), nil
+	}
 
-		if vertexAPIKey != "" {
-			// Express Mode - REST client with API key
-			client, err := generate.NewVertexRESTClient(vertexAPIKey, project, location)
-			if err != nil {
-				return errorResponse(500, fmt.Sprintf("Failed to create Vertex REST client: %v", err)), nil
-			}
-			defer client.Close()
+	// Load source image and optional mask
+	imageData, err := LoadImageFromInput(ctx, h.s3Client, req.Image)
+	if err != nil {
+		return errorResponse(400, fmt.Sprintf("Failed to load image: %v", err)), nil
+	}
 
-			generatedImage, err = client.GenerateImage(ctx, req.Prompt, options)
-			if err != nil {
-				return errorResponse(500, fmt.Sprintf("Failed to generate image: %v", err)), nil
-			}
-		} else {
-			// Full Mode - SDK client with service account
-			client, err := generate.NewVertexSDKClient(ctx, project, location)
-			if err != nil {
-				return errorResponse(500, fmt.Sprintf("Failed to create Vertex SDK client: %v", err)), nil
-			}
-			defer client.Close()
+	edit := models.EditOptions{
+		Image:      imageData,
+		Mode:       req.Mode,
+		MaskPrompt: req.MaskPrompt,
+		Strength:   req.Strength,
+	}
 
-			generatedImage, err = client.GenerateImage(ctx, req.Prompt, options)
-			if err != nil {
-				return errorResponse(500, fmt.Sprintf("Failed to generate image: %v", err)), nil
-			}
+	if req.Mask != "" {
+		edit.Mask, err = LoadImageFromInput(ctx, h.s3Client, req.Mask)
+		if err != nil {
+			return errorResponse(400, fmt.Sprintf("Failed to load mask: %v", err)), nil
 		}
-	} else {
-		return errorResponse(400, fmt.Sprintf("Unsupported API: %s", api)), nil
 	}
 
-	// Create response
-	return h.createImageResponse(ctx, generatedImage.Data, generatedImage.Format, generatedImage.Width, generatedImage.Height, req.ResponseFormat)
+	// A mask implies inpainting
+	if edit.Mode == "" && (len(edit.Mask) > 0 || edit.MaskPrompt != "") {
+		edit.Mode = generate.EditModeInpainting
+	}
+
+	if err := generate.ValidateEditOptions(&edit); err != nil {
+		return errorResponse(400, fmt.Sprintf("Invalid edit options: %v", err)), nil
+	}
+
+	log.Printf("Editing image with prompt: %s, provider: %s, mode: %s", req.Prompt, provider.ID, edit.Mode)
+
+	client, _, err := registry.ResolveEditor(provider.ID, cfg)
+	if err != nil {
+		return errorResponse(500, fmt.Sprintf("Failed to create client: %v", err)), nil
+	}
+	defer client.Close()
+	editor := client.(generate.ImageEditor)
+
+	options := models.GenerateOptions{
+		Model:          provider.ModelID,
+		Style:          req.Style,
+		NegativePrompt: req.NegativePrompt,
+		Seed:           req.Seed,
+	}
+
+	editedImage, err := editor.EditImage(ctx, req.Prompt, edit, options)
+	if err != nil {
+		return errorResponse(500, fmt.Sprintf("Failed to edit image: %v", err)), nil
+	}
+
+	return h.createImageResponse(ctx, editedImage.Data, editedImage.Format, editedImage.Width, editedImage.Height, req.ResponseFormat)
 }
 
 // handleResize handles image resize requests
@@ -136,6 +222,27 @@ func (h *Handler) handleResize(ctx context.Context, body []byte) (events.APIGate
 	if req.Width <= 0 || req.Height <= 0 {
 		return errorResponse(400, "Width and height must be positive"), nil
 	}
+	mode, err := gimageimaging.ParseResizeMode(req.Mode)
+	if err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
+	step := gimageimaging.ResizeStep(req.Width, req.Height, gimageimaging.ResizeOptions{
+		Mode:       mode,
+		Anchor:     req.Anchor,
+		Background: req.Background,
+	})
+	if err := step.Validate(); err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
+	filter, err := gimageimaging.ParseFilter(req.Filter)
+	if err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
+
+	policy, err := gimageimaging.ParseMetadataPolicy(req.Metadata)
+	if err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
 
 	// Load image
 	imageData, err := LoadImageFromInput(ctx, h.s3Client, req.Image)
@@ -143,22 +250,23 @@ func (h *Handler) handleResize(ctx context.Context, body []byte) (events.APIGate
 		return errorResponse(400, fmt.Sprintf("Failed to load image: %v", err)), nil
 	}
 
-	// Decode image
-	img, format, err := image.Decode(bytes.NewReader(imageData))
+	// Decode image, upright according to its EXIF orientation
+	p, err := gimageimaging.DecodePipeline(imageData)
 	if err != nil {
 		return errorResponse(400, fmt.Sprintf("Failed to decode image: %v", err)), nil
 	}
 
-	// Resize using Lanczos resampling
-	resized := imaging.Resize(img, req.Width, req.Height, imaging.Lanczos)
-
-	// Encode to bytes
-	outputData, err := encodeImage(resized, format)
+	// Resize with the chosen filter, sharpen and encode
+	if err := applySharpen(p.Filter(filter).Apply(step), req.Sharpen); err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
+	outputData, err := p.Metadata(policy).Bytes()
 	if err != nil {
 		return errorResponse(500, fmt.Sprintf("Failed to encode image: %v", err)), nil
 	}
 
-	return h.createImageResponse(ctx, outputData, format, req.Width, req.Height, req.ResponseFormat)
+	width, height := p.Size()
+	return h.createImageResponse(ctx, outputData, p.Format(), width, height, req.ResponseFormat)
 }
 
 // handleScale handles image scaling requests
@@ -175,6 +283,15 @@ func (h *Handler) handleScale(ctx context.Context, body []byte) (events.APIGatew
 	if req.Factor <= 0 {
 		return errorResponse(400, "Factor must be positive"), nil
 	}
+	filter, err := gimageimaging.ParseFilter(req.Filter)
+	if err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
+
+	policy, err := gimageimaging.ParseMetadataPolicy(req.Metadata)
+	if err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
 
 	// Load image
 	imageData, err := LoadImageFromInput(ctx, h.s3Client, req.Image)
@@ -182,27 +299,23 @@ func (h *Handler) handleScale(ctx context.Context, body []byte) (events.APIGatew
 		return errorResponse(400, fmt.Sprintf("Failed to load image: %v", err)), nil
 	}
 
-	// Decode image
-	img, format, err := image.Decode(bytes.NewReader(imageData))
+	// Decode image, upright according to its EXIF orientation
+	p, err := gimageimaging.DecodePipeline(imageData)
 	if err != nil {
 		return errorResponse(400, fmt.Sprintf("Failed to decode image: %v", err)), nil
 	}
 
-	// Calculate new dimensions
-	bounds := img.Bounds()
-	newWidth := int(float64(bounds.Dx()) * req.Factor)
-	newHeight := int(float64(bounds.Dy()) * req.Factor)
-
-	// Scale
-	scaled := imaging.Resize(img, newWidth, newHeight, imaging.Lanczos)
-
-	// Encode
-	outputData, err := encodeImage(scaled, format)
+	// Scale (each side at least 1 pixel), sharpen and encode
+	if err := applySharpen(p.Filter(filter).Scale(req.Factor), req.Sharpen); err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
+	newWidth, newHeight := p.Size()
+	outputData, err := p.Metadata(policy).Bytes()
 	if err != nil {
 		return errorResponse(500, fmt.Sprintf("Failed to encode image: %v", err)), nil
 	}
 
-	return h.createImageResponse(ctx, outputData, format, newWidth, newHeight, req.ResponseFormat)
+	return h.createImageResponse(ctx, outputData, p.Format(), newWidth, newHeight, req.ResponseFormat)
 }
 
 // handleCrop handles image cropping requests
@@ -220,43 +333,55 @@ func (h *Handler) handleCrop(ctx context.Context, body []byte) (events.APIGatewa
 		return errorResponse(400, "Width and height must be positive"), nil
 	}
 
+	policy, err := gimageimaging.ParseMetadataPolicy(req.Metadata)
+	if err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
+	filter, err := gimageimaging.ParseFilter(req.Filter)
+	if err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
+
 	// Load image
 	imageData, err := LoadImageFromInput(ctx, h.s3Client, req.Image)
 	if err != nil {
 		return errorResponse(400, fmt.Sprintf("Failed to load image: %v", err)), nil
 	}
 
-	// Decode image
-	img, format, err := image.Decode(bytes.NewReader(imageData))
+	// Decode image, upright according to its EXIF orientation
+	p, err := gimageimaging.DecodePipeline(imageData)
 	if err != nil {
 		return errorResponse(400, fmt.Sprintf("Failed to decode image: %v", err)), nil
 	}
 
+	// A smart crop picks its own region
+	if req.Smart {
+		outputData, err := p.Filter(filter).SmartCrop(req.Width, req.Height).Metadata(policy).Bytes()
+		if err != nil {
+			return errorResponse(500, fmt.Sprintf("Failed to encode image: %v", err)), nil
+		}
+		return h.createImageResponse(ctx, outputData, p.Format(), req.Width, req.Height, req.ResponseFormat)
+	}
+
 	// Validate crop region
-	bounds := img.Bounds()
+	width, height := p.Size()
 	if req.X < 0 || req.Y < 0 {
 		return errorResponse(400, "X and Y coordinates must be non-negative"), nil
 	}
-	if req.X >= bounds.Dx() || req.Y >= bounds.Dy() {
+	if req.X >= width || req.Y >= height {
 		return errorResponse(400, "Crop region is outside image bounds"), nil
 	}
-	if req.X+req.Width > bounds.Dx() || req.Y+req.Height > bounds.Dy() {
+	if req.X+req.Width > width || req.Y+req.Height > height {
 		return errorResponse(400, "Crop region exceeds image dimensions"), nil
 	}
 
-	// Create crop rectangle
-	cropRect := image.Rect(req.X, req.Y, req.X+req.Width, req.Y+req.Height)
-
-	// Perform crop
-	cropped := imaging.Crop(img, cropRect)
-
-	// Encode
-	outputData, err := encodeImage(cropped, format)
+	// Crop and encode
+	outputData, err := p.Crop(req.X, req.Y, req.Width, req.Height).Metadata(policy).Bytes()
 	if err != nil {
 		return errorResponse(500, fmt.Sprintf("Failed to encode image: %v", err)), nil
 	}
 
-	return h.createImageResponse(ctx, outputData, format, req.Width, req.Height, req.ResponseFormat)
+	return h.createImageResponse(ctx, outputData, p.Format(), req.Width, req.Height, req.ResponseFormat)
 }
 
 // handleCompress handles image compression requests
@@ -271,6 +396,24 @@ func (h *Handler) handleCompress(ctx context.Context, body []byte) (events.APIGa
 		return errorResponse(400, "Image is required"), nil
 	}
 
+	// Optional byte budget; an explicit quality caps the search
+	var limit *gimageimaging.SizeLimit
+	if req.MaxSize != "" {
+		maxBytes, err := gimageimaging.ParseByteSize(req.MaxSize)
+		if err != nil {
+			return errorResponse(400, fmt.Sprintf("Invalid max_size: %v", err)), nil
+		}
+		limit = &gimageimaging.SizeLimit{
+			MaxBytes:    maxBytes,
+			MinQuality:  req.MinQuality,
+			MaxQuality:  req.Quality,
+			AllowResize: req.AllowResize,
+		}
+		if err := limit.Validate(); err != nil {
+			return errorResponse(400, err.Error()), nil
+		}
+	}
+
 	// Set defaults
 	if req.Quality == 0 {
 		req.Quality = 85
@@ -279,6 +422,11 @@ func (h *Handler) handleCompress(ctx context.Context, body []byte) (events.APIGa
 		return errorResponse(400, "Quality must be between 1 and 100"), nil
 	}
 
+	policy, err := gimageimaging.ParseMetadataPolicy(req.Metadata)
+	if err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
+
 	// Load image
 	imageData, err := LoadImageFromInput(ctx, h.s3Client, req.Image)
 	if err != nil {
@@ -286,25 +434,45 @@ func (h *Handler) handleCompress(ctx context.Context, body []byte) (events.APIGa
 	}
 
 	// Decode
-	img, format, err := image.Decode(bytes.NewReader(imageData))
+	p, err := gimageimaging.DecodePipeline(imageData)
 	if err != nil {
 		return errorResponse(400, fmt.Sprintf("Failed to decode image: %v", err)), nil
 	}
 
 	// Use target format if specified
-	targetFormat := format
 	if req.Format != "" {
-		targetFormat = req.Format
+		p.Convert(req.Format)
+	}
+	if err := p.Metadata(policy).Speed(req.Speed).Err(); err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
+
+	if limit != nil {
+		// Search for the highest quality that fits
+		outputData, result, err := p.EncodeToSize(*limit)
+		if err != nil {
+			return errorResponse(400, fmt.Sprintf("Failed to fit max_size: %v", err)), nil
+		}
+		resp, err := h.buildImageResponse(ctx, outputData, p.Format(), result.Width, result.Height, req.ResponseFormat)
+		if err != nil {
+			return errorResponse(500, err.Error()), nil
+		}
+		resp.Metadata = map[string]string{
+			"quality":        strconv.Itoa(result.Quality),
+			"max_size_bytes": strconv.FormatInt(limit.MaxBytes, 10),
+			"resized":        strconv.FormatBool(result.Resized),
+		}
+		return successResponse(200, resp), nil
 	}
 
-	// Encode with quality (for JPEG/WebP)
-	outputData, err := encodeImageWithQuality(img, targetFormat, req.Quality)
+	// Encode with quality (JPEG, or lossy WebP)
+	outputData, err := p.Compress(req.Quality).Bytes()
 	if err != nil {
-		return errorResponse(500, fmt.Sprintf("Failed to compress image: %v", err)), nil
+		return errorResponse(400, fmt.Sprintf("Failed to compress image: %v", err)), nil
 	}
 
-	bounds := img.Bounds()
-	return h.createImageResponse(ctx, outputData, targetFormat, bounds.Dx(), bounds.Dy(), req.ResponseFormat)
+	width, height := p.Size()
+	return h.createImageResponse(ctx, outputData, p.Format(), width, height, req.ResponseFormat)
 }
 
 // handleConvert handles image format conversion requests
@@ -322,26 +490,89 @@ func (h *Handler) handleConvert(ctx context.Context, body []byte) (events.APIGat
 		return errorResponse(400, "Target format is required"), nil
 	}
 
+	policy, err := gimageimaging.ParseMetadataPolicy(req.Metadata)
+	if err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
+
 	// Load image
 	imageData, err := LoadImageFromInput(ctx, h.s3Client, req.Image)
 	if err != nil {
 		return errorResponse(400, fmt.Sprintf("Failed to load image: %v", err)), nil
 	}
 
-	// Convert using existing function
-	convertedData, err := gimageimaging.ConvertImageData(imageData, req.TargetFormat)
+	// Decode, upright according to its EXIF orientation, and re-encode
+	p, err := gimageimaging.DecodePipeline(imageData)
+	if err != nil {
+		return errorResponse(400, fmt.Sprintf("Failed to decode image: %v", err)), nil
+	}
+	p.Convert(req.TargetFormat).Metadata(policy).Speed(req.Speed)
+	if req.Quality != 0 {
+		p.Compress(req.Quality)
+	}
+	convertedData, err := p.Bytes()
 	if err != nil {
 		return errorResponse(400, fmt.Sprintf("Failed to convert image: %v", err)), nil
 	}
 
-	// Get dimensions
-	img, _, err := image.Decode(bytes.NewReader(convertedData))
+	width, height := p.Size()
+	return h.createImageResponse(ctx, convertedData, req.TargetFormat, width, height, req.ResponseFormat)
+}
+
+// handlePipeline handles chained operation requests
+func (h *Handler) handlePipeline(ctx context.Context, body []byte) (events.APIGatewayProxyResponse, error) {
+	var req PipelineRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return errorResponse(400, fmt.Sprintf("Invalid request body: %v", err)), nil
+	}
+
+	// Validate inputs
+	if req.Image == "" {
+		return errorResponse(400, "Image is required"), nil
+	}
+	if len(req.Steps) == 0 {
+		return errorResponse(400, "At least one step is required"), nil
+	}
+	steps, err := gimageimaging.ParseSteps(req.Steps)
 	if err != nil {
-		return errorResponse(500, "Failed to decode converted image"), nil
+		return errorResponse(400, err.Error()), nil
 	}
 
-	bounds := img.Bounds()
-	return h.createImageResponse(ctx, convertedData, req.TargetFormat, bounds.Dx(), bounds.Dy(), req.ResponseFormat)
+	policy, err := gimageimaging.ParseMetadataPolicy(req.Metadata)
+	if err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
+
+	filter, err := gimageimaging.ParseFilter(req.Filter)
+	if err != nil {
+		return errorResponse(400, err.Error()), nil
+	}
+
+	// Load image
+	imageData, err := LoadImageFromInput(ctx, h.s3Client, req.Image)
+	if err != nil {
+		return errorResponse(400, fmt.Sprintf("Failed to load image: %v", err)), nil
+	}
+
+	// Decode once, apply every step, encode once
+	p, err := gimageimaging.DecodePipeline(imageData)
+	if err != nil {
+		return errorResponse(400, fmt.Sprintf("Failed to decode image: %v", err)), nil
+	}
+	p.Filter(filter)
+	for _, step := range steps {
+		if err := p.Apply(step).Err(); err != nil {
+			return errorResponse(400, fmt.Sprintf("Step %s failed: %v", step, err)), nil
+		}
+	}
+
+	outputData, err := p.Metadata(policy).Bytes()
+	if err != nil {
+		return errorResponse(500, fmt.Sprintf("Failed to encode image: %v", err)), nil
+	}
+
+	width, height := p.Size()
+	return h.createImageResponse(ctx, outputData, p.Format(), width, height, req.ResponseFormat)
 }
 
 // handleBatch handles batch processing requests
@@ -436,12 +667,43 @@ func (h *Handler) handleHealth(ctx context.Context) (events.APIGatewayProxyRespo
 		health.APIs["vertex"] = "not_configured"
 	}
 
+	// Check Bedrock API (execution role credentials are exposed as AWS_* env vars)
+	if config.HasBedrockCredentials() {
+		health.APIs["bedrock"] = "available"
+	} else {
+		health.APIs["bedrock"] = "not_configured"
+	}
+
+	// Check OpenAI-compatible API
+	if config.HasOpenAICredentials() {
+		health.APIs["openai"] = "available"
+	} else {
+		health.APIs["openai"] = "not_configured"
+	}
+
+	// Check self-hosted Stable Diffusion (Automatic1111 or ComfyUI)
+	if config.HasStableDiffusionCredentials() {
+		health.APIs["sd"] = "available"
+	} else {
+		health.APIs["sd"] = "not_configured"
+	}
+
 	return successResponse(200, health), nil
 }
 
 // Helper functions
 
 func (h *Handler) createImageResponse(ctx context.Context, data []byte, format string, width, height int, requestedFormat string) (events.APIGatewayProxyResponse, error) {
+	resp, err := h.buildImageResponse(ctx, data, format, width, height, requestedFormat)
+	if err != nil {
+		return errorResponse(500, err.Error()), nil
+	}
+
+	return successResponse(200, resp), nil
+}
+
+// buildImageResponse encodes image data as base64 or uploads it to S3, depending on size and requested format
+func (h *Handler) buildImageResponse(ctx context.Context, data []byte, format string, width, height int, requestedFormat string) (ImageResponse, error) {
 	responseFormat := DetermineResponseFormat(int64(len(data)), requestedFormat)
 
 	resp := ImageResponse{
@@ -460,19 +722,19 @@ func (h *Handler) createImageResponse(ctx context.Context, data []byte, format s
 		contentType := GetContentType(format)
 
 		if err := h.s3Client.Upload(ctx, s3Key, data, contentType); err != nil {
-			return errorResponse(500, fmt.Sprintf("Failed to upload to S3: %v", err)), nil
+			return ImageResponse{}, fmt.Errorf("Failed to upload to S3: %v", err)
 		}
 
 		presignedURL, err := h.s3Client.GeneratePresignedURL(ctx, s3Key, GetPresignedURLExpiration())
 		if err != nil {
-			return errorResponse(500, fmt.Sprintf("Failed to generate presigned URL: %v", err)), nil
+			return ImageResponse{}, fmt.Errorf("Failed to generate presigned URL: %v", err)
 		}
 
 		resp.S3URL = presignedURL
 		resp.S3Key = s3Key
 	}
 
-	return successResponse(200, resp), nil
+	return resp, nil
 }
 
 // Batch processing helpers
@@ -480,11 +742,23 @@ func (h *Handler) createImageResponse(ctx context.Context, data []byte, format s
 func (h *Handler) processBatchResize(ctx context.Context, op BatchOperation) (ImageResponse, error) {
 	width, _ := op.Params["width"].(float64)
 	height, _ := op.Params["height"].(float64)
+	mode, _ := op.Params["mode"].(string)
+	anchor, _ := op.Params["anchor"].(string)
+	background, _ := op.Params["background"].(string)
+	filter, _ := op.Params["filter"].(string)
+	sharpen, _ := op.Params["sharpen"].(float64)
+	metadata, _ := op.Params["metadata"].(string)
 
 	req := ResizeRequest{
 		Image:          op.Image,
 		Width:          int(width),
 		Height:         int(height),
+		Mode:           mode,
+		Anchor:         anchor,
+		Background:     background,
+		Filter:         filter,
+		Sharpen:        sharpen,
+		Metadata:       metadata,
 		ResponseFormat: "s3_url",
 	}
 
@@ -501,10 +775,16 @@ func (h *Handler) processBatchResize(ctx context.Context, op BatchOperation) (Im
 
 func (h *Handler) processBatchScale(ctx context.Context, op BatchOperation) (ImageResponse, error) {
 	factor, _ := op.Params["factor"].(float64)
+	filter, _ := op.Params["filter"].(string)
+	sharpen, _ := op.Params["sharpen"].(float64)
+	metadata, _ := op.Params["metadata"].(string)
 
 	req := ScaleRequest{
 		Image:          op.Image,
 		Factor:         factor,
+		Filter:         filter,
+		Sharpen:        sharpen,
+		Metadata:       metadata,
 		ResponseFormat: "s3_url",
 	}
 
@@ -524,6 +804,9 @@ func (h *Handler) processBatchCrop(ctx context.Context, op BatchOperation) (Imag
 	y, _ := op.Params["y"].(float64)
 	width, _ := op.Params["width"].(float64)
 	height, _ := op.Params["height"].(float64)
+	smart, _ := op.Params["smart"].(bool)
+	filter, _ := op.Params["filter"].(string)
+	metadata, _ := op.Params["metadata"].(string)
 
 	req := CropRequest{
 		Image:          op.Image,
@@ -531,6 +814,9 @@ func (h *Handler) processBatchCrop(ctx context.Context, op BatchOperation) (Imag
 		Y:              int(y),
 		Width:          int(width),
 		Height:         int(height),
+		Smart:          smart,
+		Filter:         filter,
+		Metadata:       metadata,
 		ResponseFormat: "s3_url",
 	}
 
@@ -548,11 +834,21 @@ func (h *Handler) processBatchCrop(ctx context.Context, op BatchOperation) (Imag
 func (h *Handler) processBatchCompress(ctx context.Context, op BatchOperation) (ImageResponse, error) {
 	quality, _ := op.Params["quality"].(float64)
 	format, _ := op.Params["format"].(string)
+	maxSize, _ := op.Params["max_size"].(string)
+	minQuality, _ := op.Params["min_quality"].(float64)
+	allowResize, _ := op.Params["allow_resize"].(bool)
+	speed, _ := op.Params["speed"].(float64)
+	metadata, _ := op.Params["metadata"].(string)
 
 	req := CompressRequest{
 		Image:          op.Image,
 		Quality:        int(quality),
 		Format:         format,
+		MaxSize:        maxSize,
+		MinQuality:     int(minQuality),
+		AllowResize:    allowResize,
+		Speed:          int(speed),
+		Metadata:       metadata,
 		ResponseFormat: "s3_url",
 	}
 
@@ -569,10 +865,16 @@ func (h *Handler) processBatchCompress(ctx context.Context, op BatchOperation) (
 
 func (h *Handler) processBatchConvert(ctx context.Context, op BatchOperation) (ImageResponse, error) {
 	targetFormat, _ := op.Params["target_format"].(string)
+	quality, _ := op.Params["quality"].(float64)
+	speed, _ := op.Params["speed"].(float64)
+	metadata, _ := op.Params["metadata"].(string)
 
 	req := ConvertRequest{
 		Image:          op.Image,
 		TargetFormat:   targetFormat,
+		Quality:        int(quality),
+		Speed:          int(speed),
+		Metadata:       metadata,
 		ResponseFormat: "s3_url",
 	}
 
@@ -587,34 +889,11 @@ func (h *Handler) processBatchConvert(ctx context.Context, op BatchOperation) (I
 	return imgResp, nil
 }
 
-// Image encoding helpers
-
-func encodeImage(img image.Image, format string) ([]byte, error) {
-	return encodeImageWithQuality(img, format, 90)
-}
-
-func encodeImageWithQuality(img image.Image, format string, quality int) ([]byte, error) {
-	var buf bytes.Buffer
-
-	switch strings.ToLower(format) {
-	case "jpg", "jpeg":
-		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
-			return nil, err
-		}
-	case "png":
-		if err := png.Encode(&buf, img); err != nil {
-			return nil, err
-		}
-	case "gif":
-		if err := gif.Encode(&buf, img, nil); err != nil {
-			return nil, err
-		}
-	default:
-		// Default to PNG
-		if err := png.Encode(&buf, img); err != nil {
-			return nil, err
-		}
+// applySharpen applies the optional post-resize sharpen of a request to p and
+// returns the pipeline's error, if any
+func applySharpen(p *gimageimaging.Pipeline, amount float64) error {
+	if amount != 0 {
+		p.Sharpen(amount)
 	}
-
-	return buf.Bytes(), nil
+	return p.Err()
 }
@@ -124,6 +124,10 @@ func GetContentType(format string) string {
 		return "image/gif"
 	case "webp":
 		return "image/webp"
+	case "avif":
+		return "image/avif"
+	case "heic", "heif":
+		return "image/heic"
 	case "tiff", "tif":
 		return "image/tiff"
 	case "bmp":
@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"net/url"
+	"path/filepath"
+)
+
+// Content block types of a tool result
+const (
+	ContentTypeText         = "text"
+	ContentTypeImage        = "image"
+	ContentTypeResourceLink = "resource_link"
+)
+
+// ResultContentKey is the result key under which a ToolHandler returns
+// content blocks ([]map[string]interface{}) to send after the text block.
+// The key is removed from the result before it is formatted as that text.
+const ResultContentKey = "_content"
+
+// TextContent returns a text content block
+func TextContent(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": ContentTypeText,
+		"text": text,
+	}
+}
+
+// ImageContent returns an image content block holding data, base64-encoded
+func ImageContent(data []byte, mimeType string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     ContentTypeImage,
+		"data":     base64.StdEncoding.EncodeToString(data),
+		"mimeType": mimeType,
+	}
+}
+
+// ResourceLinkContent returns a resource_link content block pointing at the
+// file at path. Files linked from a tool result can be listed and read as
+// resources for the rest of the session.
+func ResourceLinkContent(path, mimeType string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     ContentTypeResourceLink,
+		"uri":      FileURI(path),
+		"name":     filepath.Base(path),
+		"mimeType": mimeType,
+	}
+}
+
+// FileURI returns the file:// URI of path, made absolute
+func FileURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// filePath returns the local path of a file:// URI
+func filePath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" || u.Path == "" {
+		return "", false
+	}
+	return filepath.FromSlash(u.Path), true
+}
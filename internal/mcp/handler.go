@@ -2,8 +2,11 @@ package mcp
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/apresai/gimage/internal/observability"
@@ -21,6 +24,8 @@ func (s *MCPServer) HandleRequest(ctx context.Context, req *JSONRPCRequest) *JSO
 	switch req.Method {
 	case MethodInitialize:
 		return s.handleInitialize(ctx, req)
+	case MethodPing:
+		return &JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: map[string]interface{}{}}
 	case MethodListTools:
 		return s.handleListTools(ctx, req)
 	case MethodCallTool:
@@ -31,6 +36,8 @@ func (s *MCPServer) HandleRequest(ctx context.Context, req *JSONRPCRequest) *JSO
 		return s.handleGetPrompt(ctx, req)
 	case MethodListResources:
 		return s.handleListResources(ctx, req)
+	case MethodReadResource:
+		return s.handleReadResource(ctx, req)
 	default:
 		logger.Warn().
 			Str("method", req.Method).
@@ -42,8 +49,10 @@ func (s *MCPServer) HandleRequest(ctx context.Context, req *JSONRPCRequest) *JSO
 func (s *MCPServer) handleInitialize(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
 	logger := observability.LoggerWithComponent(ctx, "mcp-handler")
 
+	requested, _ := req.Params["protocolVersion"].(string)
 	logger.Info().
 		Str("protocol_version", ProtocolVersion).
+		Str("client_protocol_version", requested).
 		Str("server_name", s.name).
 		Str("server_version", s.version).
 		Msg("Initializing MCP connection")
@@ -64,6 +73,9 @@ func (s *MCPServer) handleInitialize(ctx context.Context, req *JSONRPCRequest) *
 				"prompts": map[string]interface{}{
 					"listChanged": false, // Prompts are static
 				},
+				"resources": map[string]interface{}{
+					"listChanged": false, // Files produced by tool calls, listed on request
+				},
 			},
 		},
 	}
@@ -85,6 +97,9 @@ func (s *MCPServer) handleListTools(ctx context.Context, req *JSONRPCRequest) *J
 		if tool.Annotations != nil {
 			toolInfo["annotations"] = tool.Annotations
 		}
+		if tool.OutputSchema != nil {
+			toolInfo["outputSchema"] = tool.OutputSchema
+		}
 
 		tools = append(tools, toolInfo)
 	}
@@ -132,9 +147,12 @@ func (s *MCPServer) handleCallTool(ctx context.Context, req *JSONRPCRequest) *JS
 		return s.errorResponse(req.ID, ErrorCodeMethodNotFound, fmt.Sprintf("Tool not found: %s", name))
 	}
 
+	// Report progress to the client if it passed a progress token
+	ctx = withProgress(ctx, req)
+
 	// Execute tool and track metrics
 	startTime := time.Now()
-	result, err := tool.Handler(arguments)
+	result, err := tool.Handler(ctx, arguments)
 	duration := time.Since(startTime)
 
 	// Record metrics
@@ -147,7 +165,7 @@ func (s *MCPServer) handleCallTool(ctx context.Context, req *JSONRPCRequest) *JS
 			Str("tool", name).
 			Int64("duration_ms", duration.Milliseconds()).
 			Msg("Tool execution failed")
-		return s.errorResponse(req.ID, -32603, fmt.Sprintf("Tool execution failed: %v", err))
+		return s.toolErrorResponse(req.ID, err)
 	}
 
 	logger.Info().
@@ -155,16 +173,62 @@ func (s *MCPServer) handleCallTool(ctx context.Context, req *JSONRPCRequest) *JS
 		Int64("duration_ms", duration.Milliseconds()).
 		Msg("Tool executed successfully")
 
+	// Images and resource links travel as their own blocks after the summary
+	extra, _ := result[ResultContentKey].([]map[string]interface{})
+	delete(result, ResultContentKey)
+	s.addResources(ctx, extra)
+
+	content := append([]map[string]interface{}{TextContent(formatToolResult(result))}, extra...)
+	response := map[string]interface{}{
+		"content": content,
+	}
+
+	// The result doubles as structuredContent when the tool declares its shape
+	if tool.OutputSchema != nil {
+		if err := validateOutput(tool.OutputSchema, result); err != nil {
+			logger.Error().
+				Err(err).
+				Str("tool", name).
+				Msg("Tool result does not match its output schema")
+			return s.toolErrorResponse(req.ID, NewToolError(ToolErrorOperationFailed, fmt.Errorf("tool %s returned invalid output: %w", name, err), ""))
+		}
+		response["structuredContent"] = result
+	}
+
 	return &JSONRPCResponse{
 		JSONRPC: "2.0",
 		ID:      req.ID,
+		Result:  response,
+	}
+}
+
+// toolErrorResponse reports a failed tool call as an isError result rather
+// than a protocol error, so the model sees what went wrong and can correct
+// the call. The code comes from a ToolError in err's chain.
+func (s *MCPServer) toolErrorResponse(id interface{}, err error) *JSONRPCResponse {
+	details := map[string]interface{}{
+		"code":    ToolErrorOperationFailed,
+		"message": err.Error(),
+	}
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		details["code"] = toolErr.Code
+		if toolErr.Hint != "" {
+			details["hint"] = toolErr.Hint
+		}
+	}
+	structured := map[string]interface{}{
+		"success": false,
+		"error":   details,
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      id,
 		Result: map[string]interface{}{
-			"content": []map[string]interface{}{
-				{
-					"type": "text",
-					"text": formatToolResult(result),
-				},
-			},
+			"content":           []map[string]interface{}{TextContent(formatToolResult(structured))},
+			"structuredContent": structured,
+			"isError":           true,
 		},
 	}
 }
@@ -267,14 +331,77 @@ func (s *MCPServer) handleGetPrompt(ctx context.Context, req *JSONRPCRequest) *J
 func (s *MCPServer) handleListResources(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
 	logger := observability.LoggerWithComponent(ctx, "mcp-handler")
 
-	// gimage doesn't expose resources, return empty list
-	logger.Debug().Msg("Listing resources (gimage has none)")
+	// Resources are the files tool calls produced in this session
+	resources := make([]map[string]interface{}, 0)
+	for _, resource := range s.Resources(ctx) {
+		info, err := os.Stat(resource.Path)
+		if err != nil {
+			continue // Removed since it was produced
+		}
+		resources = append(resources, map[string]interface{}{
+			"uri":      resource.URI,
+			"name":     resource.Name,
+			"mimeType": resource.MimeType,
+			"size":     info.Size(),
+		})
+	}
+
+	logger.Debug().
+		Int("resources_count", len(resources)).
+		Msg("Listing resources")
 
 	return &JSONRPCResponse{
 		JSONRPC: "2.0",
 		ID:      req.ID,
 		Result: map[string]interface{}{
-			"resources": []interface{}{},
+			"resources": resources,
+		},
+	}
+}
+
+func (s *MCPServer) handleReadResource(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	logger := observability.LoggerWithComponent(ctx, "mcp-handler")
+
+	uri, ok := req.Params["uri"].(string)
+	if !ok || uri == "" {
+		logger.Warn().Msg("Invalid params: missing resource uri")
+		return s.errorResponse(req.ID, ErrorCodeInvalidParams, "Invalid params: missing resource uri")
+	}
+
+	// Only files gimage produced are readable, not arbitrary paths
+	resource, ok := s.resource(ctx, uri)
+	if !ok {
+		logger.Warn().
+			Str("uri", uri).
+			Msg("Resource not found")
+		return s.errorResponse(req.ID, ErrorCodeResourceNotFound, fmt.Sprintf("Resource not found: %s", uri))
+	}
+
+	data, err := os.ReadFile(resource.Path)
+	if err != nil {
+		logger.Warn().
+			Err(err).
+			Str("uri", uri).
+			Msg("Failed to read resource")
+		return s.errorResponse(req.ID, ErrorCodeResourceNotFound, fmt.Sprintf("Failed to read resource %s: %v", uri, err))
+	}
+
+	logger.Debug().
+		Str("uri", uri).
+		Int("bytes", len(data)).
+		Msg("Reading resource")
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"contents": []map[string]interface{}{
+				{
+					"uri":      resource.URI,
+					"mimeType": resource.MimeType,
+					"blob":     base64.StdEncoding.EncodeToString(data),
+				},
+			},
 		},
 	}
 }
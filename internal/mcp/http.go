@@ -0,0 +1,418 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apresai/gimage/internal/observability"
+)
+
+// Streamable HTTP transport (MCP spec 2025-06-18). Clients POST JSON-RPC
+// messages to a single endpoint and get the responses back as JSON or as a
+// text/event-stream; initialize opens a session whose ID the client sends
+// on every later request.
+const (
+	// HeaderSessionID carries the session ID assigned by initialize
+	HeaderSessionID = "Mcp-Session-Id"
+
+	// HeaderProtocolVersion carries the protocol version negotiated by
+	// initialize on every later request
+	HeaderProtocolVersion = "Mcp-Protocol-Version"
+
+	// DefaultHTTPPath is the endpoint path of the HTTP transport
+	DefaultHTTPPath = "/mcp"
+
+	// maxHTTPBody caps the size of a POSTed message batch
+	maxHTTPBody = 16 << 20
+
+	// sessionIdleTimeout is how long a session survives without requests
+	sessionIdleTimeout = time.Hour
+)
+
+// HTTPOptions configures the Streamable HTTP transport
+type HTTPOptions struct {
+	// Addr is the address to listen on, such as ":8787" or "127.0.0.1:8787"
+	Addr string
+
+	// Path is the endpoint path (default DefaultHTTPPath)
+	Path string
+
+	// Authorize, when set, checks the bearer token of every request; an
+	// error rejects the request with 401 Unauthorized. The token is "" when
+	// the request has no Authorization header.
+	Authorize func(ctx context.Context, token string) error
+}
+
+// BearerToken returns an Authorize hook that accepts only token
+func BearerToken(token string) func(ctx context.Context, token string) error {
+	return func(_ context.Context, got string) error {
+		if got == "" {
+			return errors.New("missing bearer token")
+		}
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return errors.New("invalid bearer token")
+		}
+		return nil
+	}
+}
+
+// httpTransport serves the MCP endpoint and tracks its sessions
+type httpTransport struct {
+	server    *MCPServer
+	authorize func(ctx context.Context, token string) error
+
+	mu       sync.Mutex
+	sessions map[string]time.Time // session ID -> last request
+}
+
+// HTTPHandler returns an http.Handler serving the MCP endpoint with opts.
+// It answers on every path; StartHTTP mounts it at opts.Path.
+func (s *MCPServer) HTTPHandler(opts HTTPOptions) http.Handler {
+	return &httpTransport{
+		server:    s,
+		authorize: opts.Authorize,
+		sessions:  make(map[string]time.Time),
+	}
+}
+
+// IsLoopbackAddr reports whether addr only listens on the loopback
+// interface. An empty host listens on every interface.
+func IsLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// StartHTTP serves the MCP endpoint over Streamable HTTP until ctx is
+// cancelled. It refuses to listen beyond localhost without opts.Authorize.
+func (s *MCPServer) StartHTTP(ctx context.Context, opts HTTPOptions) error {
+	logger := observability.LoggerWithComponent(ctx, "mcp-server")
+
+	if opts.Authorize == nil && !IsLoopbackAddr(opts.Addr) {
+		return fmt.Errorf("refusing to listen on %s without authorization: the address is reachable beyond localhost", opts.Addr)
+	}
+
+	path := opts.Path
+	if path == "" {
+		path = DefaultHTTPPath
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, s.HTTPHandler(opts))
+
+	listener, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", opts.Addr, err)
+	}
+	httpServer := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	logger.Info().
+		Str("protocol_version", ProtocolVersion).
+		Str("addr", listener.Addr().String()).
+		Str("path", path).
+		Bool("auth", opts.Authorize != nil).
+		Int("tools_count", len(s.tools)).
+		Msg("MCP HTTP server starting")
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		logger.Info().Msg("Server shutting down (context cancelled)")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to shut down HTTP server: %w", err)
+		}
+		return ctx.Err()
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (t *httpTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !allowedOrigin(r) {
+		http.Error(w, "Forbidden: origin not allowed", http.StatusForbidden)
+		return
+	}
+	if t.authorize != nil {
+		token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if err := t.authorize(r.Context(), strings.TrimSpace(token)); err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gimage"`)
+			http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+	if version := r.Header.Get(HeaderProtocolVersion); version != "" && version != ProtocolVersion {
+		http.Error(w, fmt.Sprintf("Unsupported %s %q (supported: %s)", HeaderProtocolVersion, version, ProtocolVersion), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		t.handlePost(w, r)
+	case http.MethodDelete:
+		if !t.checkSession(w, r) {
+			return
+		}
+		id := r.Header.Get(HeaderSessionID)
+		t.mu.Lock()
+		delete(t.sessions, id)
+		t.mu.Unlock()
+		t.server.dropSession(id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		// No server-initiated stream: every response travels on its POST
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handlePost handles a POSTed JSON-RPC message or batch
+func (t *httpTransport) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxHTTPBody+1))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxHTTPBody {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	requests, batch, err := parseMessages(body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, t.server.errorResponse(nil, ErrorCodeParseError, fmt.Sprintf("Parse error: %v", err)))
+		return
+	}
+
+	initialize := len(requests) == 1 && requests[0].Method == MethodInitialize
+	if !initialize && !t.checkSession(w, r) {
+		return
+	}
+
+	// An SSE response can carry progress notifications ahead of the
+	// responses; a JSON one cannot
+	ctx := withSession(r.Context(), r.Header.Get(HeaderSessionID))
+	var stream *eventStream
+	if acceptsEventStream(r) {
+		stream = &eventStream{w: w}
+		ctx = withNotifier(ctx, stream.send)
+	}
+
+	var responses []*JSONRPCResponse
+	for _, request := range requests {
+		requestCtx := observability.WithRequestID(ctx, observability.GenerateRequestID())
+		// Notifications, and responses to server requests, get no reply
+		if request.ID == nil || request.Method == "" {
+			if request.Method != "" {
+				t.server.handleNotification(requestCtx, request)
+			}
+			continue
+		}
+		requestCtx, done := t.server.startRequest(requestCtx, request.ID)
+		response := t.server.HandleRequest(requestCtx, request)
+		cancelled := requestCtx.Err() != nil
+		done()
+		// A cancelled request gets no response (the client has moved on)
+		if !cancelled {
+			responses = append(responses, response)
+		}
+	}
+	if len(responses) == 0 {
+		if stream == nil || !stream.started() {
+			w.WriteHeader(http.StatusAccepted)
+		}
+		return
+	}
+
+	if initialize && responses[0].Error == nil {
+		id, err := t.newSession()
+		if err != nil {
+			http.Error(w, "Failed to create session: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set(HeaderSessionID, id)
+	}
+
+	if stream != nil {
+		for _, response := range responses {
+			stream.send(response)
+		}
+		return
+	}
+	if batch {
+		writeJSON(w, http.StatusOK, responses)
+		return
+	}
+	writeJSON(w, http.StatusOK, responses[0])
+}
+
+// checkSession reports whether the request carries a live session ID,
+// answering it with an error when it does not
+func (t *httpTransport) checkSession(w http.ResponseWriter, r *http.Request) bool {
+	id := r.Header.Get(HeaderSessionID)
+	if id == "" {
+		http.Error(w, "Bad Request: missing "+HeaderSessionID+" header", http.StatusBadRequest)
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lastSeen, ok := t.sessions[id]
+	if !ok || time.Since(lastSeen) > sessionIdleTimeout {
+		delete(t.sessions, id)
+		t.server.dropSession(id)
+		// 404 tells the client to initialize a new session
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return false
+	}
+	t.sessions[id] = time.Now()
+	return true
+}
+
+// newSession starts a session and returns its ID, dropping idle sessions
+func (t *httpTransport) newSession() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	id := hex.EncodeToString(buf)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for old, lastSeen := range t.sessions {
+		if time.Since(lastSeen) > sessionIdleTimeout {
+			delete(t.sessions, old)
+			t.server.dropSession(old)
+		}
+	}
+	t.sessions[id] = time.Now()
+	return id, nil
+}
+
+// parseMessages parses a single JSON-RPC message or a batch of them,
+// reporting whether it was a batch
+func parseMessages(body []byte) ([]*JSONRPCRequest, bool, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var requests []*JSONRPCRequest
+		if err := json.Unmarshal(body, &requests); err != nil {
+			return nil, true, err
+		}
+		if len(requests) == 0 {
+			return nil, true, errors.New("empty batch")
+		}
+		return requests, true, nil
+	}
+
+	var request JSONRPCRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, false, err
+	}
+	return []*JSONRPCRequest{&request}, false, nil
+}
+
+// acceptsEventStream reports whether the client accepts an SSE response
+func acceptsEventStream(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			mediaType, _, _ = strings.Cut(mediaType, ";")
+			if strings.TrimSpace(mediaType) == "text/event-stream" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allowedOrigin guards against DNS rebinding: browsers send an Origin
+// header, which must name the server itself or a loopback host
+func allowedOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if requestHost, _, err := net.SplitHostPort(r.Host); err == nil && host == requestHost {
+		return true
+	}
+	if host == r.Host || host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// writeJSON writes v as a JSON response with status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// eventStream writes JSON-RPC messages as SSE message events, starting
+// the stream on the first one. It is safe for concurrent use.
+type eventStream struct {
+	w http.ResponseWriter
+
+	mu     sync.Mutex
+	opened bool
+}
+
+// send writes message as an event and flushes it to the client
+func (e *eventStream) send(message interface{}) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.opened {
+		e.w.Header().Set("Content-Type", "text/event-stream")
+		e.w.Header().Set("Cache-Control", "no-cache")
+		e.w.WriteHeader(http.StatusOK)
+		e.opened = true
+	}
+	if _, err := fmt.Fprintf(e.w, "event: message\ndata: %s\n\n", data); err != nil {
+		return err
+	}
+	if flusher, ok := e.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// started reports whether any event has been written
+func (e *eventStream) started() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.opened
+}
@@ -0,0 +1,302 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apresai/gimage/internal/config"
+)
+
+// newHTTPTestServer starts the HTTP transport of a server with one echo tool
+func newHTTPTestServer(t *testing.T, opts HTTPOptions) *httptest.Server {
+	t.Helper()
+	server := NewMCPServer("test", "1.0.0", &config.Config{}, false)
+	server.RegisterTool(Tool{
+		Name:        "echo",
+		Description: "Echo the text argument",
+		InputSchema: map[string]interface{}{"type": "object"},
+		Handler: func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"text": args["text"]}, nil
+		},
+	})
+	ts := httptest.NewServer(server.HTTPHandler(opts))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// post sends body to the test server with the given headers
+func post(t *testing.T, url, body string, headers map[string]string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// initializeSession initializes a session and returns its ID
+func initializeSession(t *testing.T, url string, headers map[string]string) string {
+	t.Helper()
+	resp := post(t, url, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`, headers)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 from initialize, got %d", resp.StatusCode)
+	}
+	id := resp.Header.Get(HeaderSessionID)
+	if id == "" {
+		t.Fatal("Expected a session ID from initialize")
+	}
+	return id
+}
+
+func TestHTTPTransport_Session(t *testing.T) {
+	ts := newHTTPTestServer(t, HTTPOptions{})
+
+	resp := post(t, ts.URL, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected application/json, got %s", ct)
+	}
+	var initResp JSONRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&initResp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if initResp.Result["protocolVersion"] != ProtocolVersion {
+		t.Errorf("Expected protocol version %s, got %v", ProtocolVersion, initResp.Result["protocolVersion"])
+	}
+	session := resp.Header.Get(HeaderSessionID)
+	if len(session) != 32 {
+		t.Fatalf("Expected a 32-character session ID, got %q", session)
+	}
+
+	// The initialized notification is accepted without a body
+	resp = post(t, ts.URL, `{"jsonrpc":"2.0","method":"notifications/initialized"}`, map[string]string{HeaderSessionID: session})
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected 202 for a notification, got %d", resp.StatusCode)
+	}
+
+	// Requests need the session
+	list := `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`
+	if resp := post(t, ts.URL, list, nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a session, got %d", resp.StatusCode)
+	}
+	if resp := post(t, ts.URL, list, map[string]string{HeaderSessionID: "unknown"}); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown session, got %d", resp.StatusCode)
+	}
+
+	resp = post(t, ts.URL, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+		map[string]string{HeaderSessionID: session})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	var callResp JSONRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&callResp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if callResp.Error != nil || callResp.ID != float64(3) {
+		t.Errorf("Unexpected tools/call response: %+v", callResp)
+	}
+
+	// Later requests may name the negotiated version, but no other
+	headers := map[string]string{HeaderSessionID: session, HeaderProtocolVersion: ProtocolVersion}
+	if resp := post(t, ts.URL, list, headers); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 with the negotiated protocol version, got %d", resp.StatusCode)
+	}
+	headers[HeaderProtocolVersion] = "2024-11-05"
+	if resp := post(t, ts.URL, list, headers); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unsupported protocol version, got %d", resp.StatusCode)
+	}
+
+	// DELETE ends the session
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL, nil)
+	req.Header.Set(HeaderSessionID, session)
+	delResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	delResp.Body.Close()
+	if delResp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected 204 from DELETE, got %d", delResp.StatusCode)
+	}
+	if resp := post(t, ts.URL, list, map[string]string{HeaderSessionID: session}); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 after DELETE, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPTransport_EventStreamAndBatch(t *testing.T) {
+	ts := newHTTPTestServer(t, HTTPOptions{})
+	session := initializeSession(t, ts.URL, nil)
+
+	resp := post(t, ts.URL, `{"jsonrpc":"2.0","id":"a","method":"tools/list"}`, map[string]string{
+		HeaderSessionID: session,
+		"Accept":        "application/json, text/event-stream",
+	})
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected text/event-stream, got %s", ct)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.HasPrefix(string(body), "event: message\ndata: {") || !strings.HasSuffix(string(body), "}\n\n") {
+		t.Errorf("Unexpected event stream: %q", body)
+	}
+	if !strings.Contains(string(body), `"name":"echo"`) {
+		t.Errorf("Expected the echo tool in the stream: %s", body)
+	}
+
+	// A batch gets an array of responses, skipping its notifications
+	resp = post(t, ts.URL, `[
+		{"jsonrpc":"2.0","id":1,"method":"tools/list"},
+		{"jsonrpc":"2.0","method":"notifications/initialized"},
+		{"jsonrpc":"2.0","id":2,"method":"no/such/method"}
+	]`, map[string]string{HeaderSessionID: session})
+	var responses []JSONRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&responses); err != nil {
+		t.Fatalf("Failed to decode batch response: %v", err)
+	}
+	if len(responses) != 2 {
+		t.Fatalf("Expected 2 responses, got %d", len(responses))
+	}
+	if responses[1].Error == nil || responses[1].Error.Code != ErrorCodeMethodNotFound {
+		t.Errorf("Expected method not found, got %+v", responses[1])
+	}
+
+	// Malformed JSON is a JSON-RPC parse error
+	resp = post(t, ts.URL, `{"jsonrpc":`, map[string]string{HeaderSessionID: session})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for malformed JSON, got %d", resp.StatusCode)
+	}
+	var parseResp JSONRPCResponse
+	json.NewDecoder(resp.Body).Decode(&parseResp)
+	if parseResp.Error == nil || parseResp.Error.Code != ErrorCodeParseError {
+		t.Errorf("Expected a parse error, got %+v", parseResp)
+	}
+}
+
+func TestHTTPTransport_BearerToken(t *testing.T) {
+	ts := newHTTPTestServer(t, HTTPOptions{Authorize: BearerToken("s3cret")})
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`
+
+	resp := post(t, ts.URL, initialize, nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", resp.StatusCode)
+	}
+	if !strings.HasPrefix(resp.Header.Get("WWW-Authenticate"), "Bearer") {
+		t.Errorf("Expected a Bearer challenge, got %q", resp.Header.Get("WWW-Authenticate"))
+	}
+	if resp := post(t, ts.URL, initialize, map[string]string{"Authorization": "Bearer wrong"}); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 with a wrong token, got %d", resp.StatusCode)
+	}
+	initializeSession(t, ts.URL, map[string]string{"Authorization": "Bearer s3cret"})
+}
+
+func TestHTTPTransport_MethodsAndOrigin(t *testing.T) {
+	ts := newHTTPTestServer(t, HTTPOptions{})
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", resp.StatusCode)
+	}
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`
+	if resp := post(t, ts.URL, initialize, map[string]string{"Origin": "https://evil.example"}); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected 403 for a foreign origin, got %d", resp.StatusCode)
+	}
+	initializeSession(t, ts.URL, map[string]string{"Origin": "http://localhost:3000"})
+}
+
+func TestStartHTTP_Shutdown(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0", &config.Config{}, false)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.StartHTTP(ctx, HTTPOptions{Addr: "127.0.0.1:0"})
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("StartHTTP did not return after cancellation")
+	}
+
+	if err := server.StartHTTP(context.Background(), HTTPOptions{Addr: "bad address"}); err == nil {
+		t.Error("Expected an error for a bad address")
+	}
+	if err := server.StartHTTP(context.Background(), HTTPOptions{Addr: ":0"}); err == nil || !strings.Contains(err.Error(), "without authorization") {
+		t.Errorf("Expected a refusal to listen on all interfaces without a token, got %v", err)
+	}
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"localhost:8787", true},
+		{"127.0.0.1:8787", true},
+		{"[::1]:8787", true},
+		{":8787", false},
+		{"0.0.0.0:8787", false},
+		{"192.168.1.10:8787", false},
+		{"example.com:8787", false},
+		{"bad address", false},
+	}
+	for _, tt := range tests {
+		if got := IsLoopbackAddr(tt.addr); got != tt.want {
+			t.Errorf("IsLoopbackAddr(%q) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPTransport_Cancelled(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0", &config.Config{}, false)
+	started, release, cancelled := make(chan struct{}, 1), make(chan struct{}), make(chan error, 1)
+	registerBlockingTool(server, started, release, cancelled)
+	ts := httptest.NewServer(server.HTTPHandler(HTTPOptions{}))
+	t.Cleanup(ts.Close)
+	session := initializeSession(t, ts.URL, nil)
+	headers := map[string]string{HeaderSessionID: session}
+
+	statuses := make(chan int, 1)
+	go func() {
+		resp := post(t, ts.URL, `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"block"}}`, headers)
+		statuses <- resp.StatusCode
+	}()
+	<-started
+
+	// The cancellation arrives on its own POST, in the same session
+	if resp := post(t, ts.URL, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7}}`, headers); resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected 202 for the notification, got %d", resp.StatusCode)
+	}
+	if err := <-cancelled; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if status := <-statuses; status != http.StatusAccepted {
+		t.Errorf("Expected 202 without a response for the cancelled call, got %d", status)
+	}
+}
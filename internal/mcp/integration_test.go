@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"strings"
 	"testing"
@@ -35,12 +36,12 @@ func TestMCPProtocolIntegration(t *testing.T) {
 			},
 			"required": []string{"message"},
 		},
-		Handler: func(args map[string]interface{}) (map[string]interface{}, error) {
+		Handler: func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) {
 			// Validate required parameter
 			message, ok := args["message"]
 			if !ok || message == nil {
 				return nil, &ToolError{
-					Code:    ErrorCodeInvalidParams,
+					Code:    ToolErrorInvalidInput,
 					Message: "missing required parameter: message",
 				}
 			}
@@ -80,7 +81,7 @@ func TestMCPProtocolIntegration(t *testing.T) {
 		// Step 1: Initialize
 		t.Run("Initialize", func(t *testing.T) {
 			initResp := client.SendRequest(t, "initialize", map[string]interface{}{
-				"protocolVersion": "2024-11-05",
+				"protocolVersion": "2025-06-18",
 				"clientInfo": map[string]interface{}{
 					"name":    "test-client",
 					"version": "1.0.0",
@@ -93,7 +94,7 @@ func TestMCPProtocolIntegration(t *testing.T) {
 			// Verify protocol version
 			protocolVersion, ok := initResp.Result["protocolVersion"].(string)
 			require.True(t, ok, "Response should contain protocolVersion")
-			assert.Equal(t, ProtocolVersion, protocolVersion)
+			assert.Equal(t, "2025-06-18", protocolVersion)
 
 			// Verify server info
 			serverInfo, ok := initResp.Result["serverInfo"].(map[string]interface{})
@@ -171,8 +172,14 @@ func TestMCPProtocolIntegration(t *testing.T) {
 				},
 			})
 
-			// Should return error (either protocol error or tool execution error)
-			require.NotNil(t, callResp.Error, "Should return error for missing parameter")
+			// A tool execution error is a result the model can act on
+			require.Nil(t, callResp.Error, "Tool errors should not be protocol errors")
+			assert.Equal(t, true, callResp.Result["isError"])
+			structured, ok := callResp.Result["structuredContent"].(map[string]interface{})
+			require.True(t, ok, "Result should contain structuredContent")
+			details, _ := structured["error"].(map[string]interface{})
+			assert.Equal(t, string(ToolErrorInvalidInput), details["code"])
+			assert.Contains(t, details["message"], "missing required parameter")
 		})
 
 		// Step 5: Call non-existent tool
@@ -256,13 +263,10 @@ func TestMCPProtocolErrorHandling(t *testing.T) {
 				},
 			},
 		},
-		Handler: func(args map[string]interface{}) (map[string]interface{}, error) {
+		Handler: func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) {
 			shouldFail, _ := args["should_fail"].(bool)
 			if shouldFail {
-				return nil, &ToolError{
-					Code:    ErrorCodeInternalError,
-					Message: "Tool intentionally failed",
-				}
+				return nil, errors.New("Tool intentionally failed")
 			}
 			return map[string]interface{}{"success": true}, nil
 		},
@@ -294,9 +298,12 @@ func TestMCPProtocolErrorHandling(t *testing.T) {
 			},
 		})
 
-		require.NotNil(t, resp.Error, "Should return error")
-		assert.Equal(t, ErrorCodeInternalError, resp.Error.Code)
-		assert.Contains(t, resp.Error.Message, "intentionally failed")
+		require.Nil(t, resp.Error, "Should return an isError result")
+		assert.Equal(t, true, resp.Result["isError"])
+		content, _ := resp.Result["content"].([]interface{})
+		require.Len(t, content, 1)
+		assert.Contains(t, content[0].(map[string]interface{})["text"], "intentionally failed")
+		assert.Contains(t, content[0].(map[string]interface{})["text"], string(ToolErrorOperationFailed))
 	})
 
 	t.Run("Malformed JSON", func(t *testing.T) {
@@ -335,7 +342,7 @@ func TestMCPProtocolConcurrency(t *testing.T) {
 				},
 			},
 		},
-		Handler: func(args map[string]interface{}) (map[string]interface{}, error) {
+		Handler: func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) {
 			id, _ := args["id"].(float64)
 			time.Sleep(50 * time.Millisecond)
 			return map[string]interface{}{
@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/apresai/gimage/internal/progress"
+)
+
+// progressInterval throttles progress notifications; the final update of
+// an operation is always sent
+const progressInterval = 100 * time.Millisecond
+
+type notifierKey struct{}
+
+// withNotifier returns a context carrying the function that sends
+// notifications to the client of the current request: a line on stdout, or
+// an event on the request's SSE stream
+func withNotifier(ctx context.Context, notify func(message interface{}) error) context.Context {
+	return context.WithValue(ctx, notifierKey{}, notify)
+}
+
+// notifierFromContext returns the function set by withNotifier, or nil when
+// the request's transport cannot carry notifications
+func notifierFromContext(ctx context.Context) func(message interface{}) error {
+	notify, _ := ctx.Value(notifierKey{}).(func(message interface{}) error)
+	return notify
+}
+
+// progressToken returns the _meta.progressToken of a request, by which the
+// client asks for notifications/progress while it is handled
+func progressToken(req *JSONRPCRequest) (interface{}, bool) {
+	meta, _ := req.Params["_meta"].(map[string]interface{})
+	token, ok := meta["progressToken"]
+	if !ok || token == nil {
+		return nil, false
+	}
+	switch token.(type) {
+	case string, float64:
+		return token, true
+	default:
+		return nil, false
+	}
+}
+
+// withProgress attaches a progress reporter for req to ctx when the client
+// asked for progress and the transport can deliver it, so that tools and
+// the imaging functions they call report to the client
+func withProgress(ctx context.Context, req *JSONRPCRequest) context.Context {
+	token, ok := progressToken(req)
+	if !ok {
+		return ctx
+	}
+	notify := notifierFromContext(ctx)
+	if notify == nil {
+		return ctx
+	}
+	return progress.WithReporter(ctx, &progressReporter{token: token, notify: notify})
+}
+
+// progressReporter is a progress.ProgressReporter that sends
+// notifications/progress for one request. Progress only ever increases:
+// updates that do not advance it are dropped, as are updates closer than
+// progressInterval to the previous one, except the last of an operation.
+type progressReporter struct {
+	token  interface{}
+	notify func(message interface{}) error
+
+	mu       sync.Mutex
+	sent     bool
+	last     int64
+	lastTime time.Time
+}
+
+// Start implements progress.ProgressReporter; the first Update follows
+func (r *progressReporter) Start(ctx context.Context, operation string) {}
+
+// Update implements progress.ProgressReporter
+func (r *progressReporter) Update(current, total int64, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sent && current <= r.last {
+		return
+	}
+	final := total > 0 && current >= total
+	if r.sent && !final && time.Since(r.lastTime) < progressInterval {
+		return
+	}
+
+	params := map[string]interface{}{
+		"progressToken": r.token,
+		"progress":      current,
+	}
+	if total > 0 {
+		params["total"] = total
+	}
+	if message != "" {
+		params["message"] = message
+	}
+	// Best effort: a client that went away just misses the update
+	r.notify(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  NotificationProgress,
+		"params":  params,
+	})
+	r.sent, r.last, r.lastTime = true, current, time.Now()
+}
+
+// Complete implements progress.ProgressReporter; the response follows
+func (r *progressReporter) Complete(result interface{}) {}
+
+// Error implements progress.ProgressReporter; the error response follows
+func (r *progressReporter) Error(err error) {}
@@ -0,0 +1,172 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apresai/gimage/internal/config"
+	"github.com/apresai/gimage/internal/progress"
+)
+
+// newProgressServer returns a server with a tool reporting two steps of
+// progress
+func newProgressServer() *MCPServer {
+	server := NewMCPServer("test", "1.0.0", &config.Config{}, false)
+	server.RegisterTool(Tool{
+		Name:        "steps",
+		Description: "Report progress",
+		InputSchema: map[string]interface{}{"type": "object"},
+		Handler: func(ctx context.Context, args map[string]interface{}) (map[string]interface{}, error) {
+			reporter := progress.FromContext(ctx)
+			reporter.Start(ctx, "Stepping")
+			reporter.Update(1, 2, "first step")
+			reporter.Update(2, 2, "second step")
+			reporter.Complete(nil)
+			return map[string]interface{}{"success": true}, nil
+		},
+	})
+	return server
+}
+
+// messages parses the JSON-RPC messages of newline-separated output
+func messages(t *testing.T, output string) []map[string]interface{} {
+	t.Helper()
+	var parsed []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var message map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &message); err != nil {
+			t.Fatalf("Failed to parse message %q: %v", line, err)
+		}
+		parsed = append(parsed, message)
+	}
+	return parsed
+}
+
+func TestProgressToken(t *testing.T) {
+	tests := []struct {
+		params string
+		want   interface{}
+		ok     bool
+	}{
+		{`{"_meta":{"progressToken":"abc"}}`, "abc", true},
+		{`{"_meta":{"progressToken":7}}`, float64(7), true},
+		{`{"_meta":{}}`, nil, false},
+		{`{"_meta":{"progressToken":{"x":1}}}`, nil, false},
+		{`{}`, nil, false},
+	}
+	for _, tt := range tests {
+		token, ok := progressToken(&JSONRPCRequest{Params: parseParams(tt.params)})
+		if token != tt.want || ok != tt.ok {
+			t.Errorf("progressToken(%s) = %v, %v; want %v, %v", tt.params, token, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestProgressReporter(t *testing.T) {
+	var sent []map[string]interface{}
+	reporter := &progressReporter{token: "t", notify: func(message interface{}) error {
+		sent = append(sent, message.(map[string]interface{})["params"].(map[string]interface{}))
+		return nil
+	}}
+
+	reporter.Update(1, 10, "one")
+	reporter.Update(1, 10, "again") // does not advance
+	reporter.Update(2, 10, "two")   // too soon after one
+	reporter.Update(10, 10, "done") // final, always sent
+	reporter.lastTime = time.Time{}
+	reporter.Update(11, 0, "") // later, without total or message
+
+	if len(sent) != 3 {
+		t.Fatalf("Expected 3 notifications, got %v", sent)
+	}
+	if sent[0]["progressToken"] != "t" || sent[0]["progress"] != int64(1) || sent[0]["total"] != int64(10) || sent[0]["message"] != "one" {
+		t.Errorf("Unexpected first notification: %v", sent[0])
+	}
+	if sent[1]["progress"] != int64(10) || sent[1]["message"] != "done" {
+		t.Errorf("Expected the final update, got %v", sent[1])
+	}
+	if _, ok := sent[2]["total"]; ok {
+		t.Errorf("Expected no total, got %v", sent[2])
+	}
+}
+
+func TestServerStart_Progress(t *testing.T) {
+	run := func(request string) []map[string]interface{} {
+		server := newProgressServer()
+		stdout := &bytes.Buffer{}
+		server.stdin = strings.NewReader(request + "\n")
+		server.stdout = stdout
+		if err := server.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		return messages(t, stdout.String())
+	}
+
+	// Progress notifications precede the response
+	output := run(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"steps","_meta":{"progressToken":"tok"}}}`)
+	if len(output) != 3 {
+		t.Fatalf("Expected two notifications and a response, got %v", output)
+	}
+	for _, notification := range output[:2] {
+		params, _ := notification["params"].(map[string]interface{})
+		if notification["method"] != NotificationProgress || params["progressToken"] != "tok" {
+			t.Errorf("Unexpected notification: %v", notification)
+		}
+	}
+	if output[2]["id"] != float64(1) || output[2]["result"] == nil {
+		t.Errorf("Expected the response last, got %v", output[2])
+	}
+
+	// Without a token there is no progress
+	if output := run(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"steps"}}`); len(output) != 1 {
+		t.Errorf("Expected only the response, got %v", output)
+	}
+}
+
+func TestHTTPTransport_Progress(t *testing.T) {
+	ts := httptest.NewServer(newProgressServer().HTTPHandler(HTTPOptions{}))
+	t.Cleanup(ts.Close)
+	session := initializeSession(t, ts.URL, nil)
+	call := `{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"steps","_meta":{"progressToken":1}}}`
+
+	// An event stream carries the progress ahead of the response
+	resp := post(t, ts.URL, call, map[string]string{
+		HeaderSessionID: session,
+		"Accept":        "application/json, text/event-stream",
+	})
+	body, _ := io.ReadAll(resp.Body)
+	events := strings.Split(strings.TrimSpace(string(body)), "\n\n")
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %q", body)
+	}
+	for i, event := range events {
+		data, ok := strings.CutPrefix(event, "event: message\ndata: ")
+		if !ok {
+			t.Fatalf("Unexpected event: %q", event)
+		}
+		message := messages(t, data)[0]
+		if i < 2 && message["method"] != NotificationProgress {
+			t.Errorf("Expected progress in event %d, got %v", i, message)
+		}
+		if i == 2 && message["id"] != float64(5) {
+			t.Errorf("Expected the response last, got %v", message)
+		}
+	}
+
+	// A JSON response has no room for progress
+	resp = post(t, ts.URL, call, map[string]string{HeaderSessionID: session})
+	var response JSONRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil || response.ID != float64(5) {
+		t.Errorf("Expected the JSON response, got %+v (%v)", response, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", resp.StatusCode)
+	}
+}
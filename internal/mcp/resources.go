@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+)
+
+// Resource is a file gimage produced during a session, readable through
+// resources/read
+type Resource struct {
+	URI      string
+	Name     string
+	MimeType string
+	Path     string
+}
+
+type sessionKey struct{}
+
+// withSession returns a context carrying the transport session ID. Each
+// session sees only the resources its own tool calls produced; the stdio
+// transport has a single session with the empty ID.
+func withSession(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, id)
+}
+
+// sessionFromContext returns the session ID set by withSession
+func sessionFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(sessionKey{}).(string)
+	return id
+}
+
+// addResources records the file resources linked from content for the
+// session of ctx
+func (s *MCPServer) addResources(ctx context.Context, content []map[string]interface{}) {
+	session := sessionFromContext(ctx)
+
+	s.resourcesMu.Lock()
+	defer s.resourcesMu.Unlock()
+	for _, block := range content {
+		if block["type"] != ContentTypeResourceLink {
+			continue
+		}
+		uri, _ := block["uri"].(string)
+		path, ok := filePath(uri)
+		if !ok {
+			continue
+		}
+		if s.resources[session] == nil {
+			s.resources[session] = make(map[string]Resource)
+		}
+		name, _ := block["name"].(string)
+		mimeType, _ := block["mimeType"].(string)
+		s.resources[session][uri] = Resource{URI: uri, Name: name, MimeType: mimeType, Path: path}
+	}
+}
+
+// Resources returns the resources of the session of ctx, sorted by URI
+func (s *MCPServer) Resources(ctx context.Context) []Resource {
+	s.resourcesMu.Lock()
+	defer s.resourcesMu.Unlock()
+
+	session := s.resources[sessionFromContext(ctx)]
+	resources := make([]Resource, 0, len(session))
+	for _, resource := range session {
+		resources = append(resources, resource)
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].URI < resources[j].URI })
+	return resources
+}
+
+// resource returns the resource with uri in the session of ctx
+func (s *MCPServer) resource(ctx context.Context, uri string) (Resource, bool) {
+	s.resourcesMu.Lock()
+	defer s.resourcesMu.Unlock()
+	resource, ok := s.resources[sessionFromContext(ctx)][uri]
+	return resource, ok
+}
+
+// dropSession forgets the resources of a closed session
+func (s *MCPServer) dropSession(id string) {
+	s.resourcesMu.Lock()
+	defer s.resourcesMu.Unlock()
+	delete(s.resources, id)
+}
@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apresai/gimage/internal/config"
+)
+
+// newFileServer returns a server with a write_file tool that writes its
+// text argument to path and links the file
+func newFileServer(t *testing.T, path string) *MCPServer {
+	t.Helper()
+	server := NewMCPServer("test", "1.0.0", &config.Config{}, false)
+	server.RegisterTool(Tool{
+		Name:        "write_file",
+		Description: "Write a file",
+		InputSchema: map[string]interface{}{"type": "object"},
+		Handler: func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) {
+			text, _ := args["text"].(string)
+			if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{
+				"output_path": path,
+				ResultContentKey: []map[string]interface{}{
+					ImageContent([]byte(text), "image/png"),
+					ResourceLinkContent(path, "image/png"),
+				},
+			}, nil
+		},
+	})
+	return server
+}
+
+func TestHandleCallTool_Content(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.png")
+	server := newFileServer(t, path)
+
+	response := server.HandleRequest(context.Background(), &JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  MethodCallTool,
+		Params:  parseParams(`{"name":"write_file","arguments":{"text":"pixels"}}`),
+	})
+	if response.Error != nil {
+		t.Fatalf("Unexpected error: %v", response.Error)
+	}
+
+	content := response.Result["content"].([]map[string]interface{})
+	if len(content) != 3 {
+		t.Fatalf("Expected text, image and resource_link blocks, got %d", len(content))
+	}
+	if content[0]["type"] != ContentTypeText || content[0]["text"] != formatToolResult(map[string]interface{}{"output_path": path}) {
+		t.Errorf("Expected the summary without the content key, got %v", content[0])
+	}
+	if content[1]["type"] != ContentTypeImage || content[1]["data"] != base64.StdEncoding.EncodeToString([]byte("pixels")) {
+		t.Errorf("Unexpected image block: %v", content[1])
+	}
+	if content[2]["type"] != ContentTypeResourceLink || content[2]["uri"] != "file://"+filepath.ToSlash(path) {
+		t.Errorf("Unexpected resource link: %v", content[2])
+	}
+}
+
+func TestResources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.png")
+	server := newFileServer(t, path)
+	ctx := context.Background()
+	uri := FileURI(path)
+
+	list := func(ctx context.Context) []map[string]interface{} {
+		response := server.HandleRequest(ctx, &JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: MethodListResources})
+		if response.Error != nil {
+			t.Fatalf("Unexpected error: %v", response.Error)
+		}
+		return response.Result["resources"].([]map[string]interface{})
+	}
+	read := func(ctx context.Context, uri string) *JSONRPCResponse {
+		return server.HandleRequest(ctx, &JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      2,
+			Method:  MethodReadResource,
+			Params:  map[string]interface{}{"uri": uri},
+		})
+	}
+
+	if resources := list(ctx); len(resources) != 0 {
+		t.Errorf("Expected no resources before a tool call, got %v", resources)
+	}
+	if response := read(ctx, uri); response.Error == nil || response.Error.Code != ErrorCodeResourceNotFound {
+		t.Errorf("Expected resource not found before a tool call, got %+v", response)
+	}
+
+	server.HandleRequest(ctx, &JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      3,
+		Method:  MethodCallTool,
+		Params:  parseParams(`{"name":"write_file","arguments":{"text":"pixels"}}`),
+	})
+
+	resources := list(ctx)
+	if len(resources) != 1 {
+		t.Fatalf("Expected 1 resource, got %d", len(resources))
+	}
+	if resources[0]["uri"] != uri || resources[0]["name"] != "out.png" ||
+		resources[0]["mimeType"] != "image/png" || resources[0]["size"] != int64(6) {
+		t.Errorf("Unexpected resource: %v", resources[0])
+	}
+
+	response := read(ctx, uri)
+	if response.Error != nil {
+		t.Fatalf("Unexpected error: %v", response.Error)
+	}
+	contents := response.Result["contents"].([]map[string]interface{})
+	if len(contents) != 1 || contents[0]["blob"] != base64.StdEncoding.EncodeToString([]byte("pixels")) {
+		t.Errorf("Unexpected contents: %v", contents)
+	}
+
+	// Other sessions do not see the file, and arbitrary files are not readable
+	other := withSession(ctx, "other")
+	if resources := list(other); len(resources) != 0 {
+		t.Errorf("Expected no resources in another session, got %v", resources)
+	}
+	if response := read(other, uri); response.Error == nil {
+		t.Error("Expected another session not to read the file")
+	}
+	if response := read(ctx, FileURI("/etc/passwd")); response.Error == nil {
+		t.Error("Expected an unlinked file not to be readable")
+	}
+	if response := read(ctx, ""); response.Error == nil || response.Error.Code != ErrorCodeInvalidParams {
+		t.Errorf("Expected invalid params for a missing uri, got %+v", response)
+	}
+
+	// A removed file drops out of the list
+	os.Remove(path)
+	if resources := list(ctx); len(resources) != 0 {
+		t.Errorf("Expected a removed file to be unlisted, got %v", resources)
+	}
+}
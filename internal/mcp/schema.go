@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// validateOutput checks a tool result against the tool's output schema. Both
+// go through JSON first, so Go types such as []string or int64 compare as
+// the client will see them.
+func validateOutput(schema map[string]interface{}, result map[string]interface{}) error {
+	var s, v interface{}
+	if err := roundTrip(schema, &s); err != nil {
+		return fmt.Errorf("invalid output schema: %w", err)
+	}
+	if err := roundTrip(result, &v); err != nil {
+		return fmt.Errorf("result is not JSON: %w", err)
+	}
+	schemaMap, _ := s.(map[string]interface{})
+	return validateSchema(schemaMap, v, "result")
+}
+
+func roundTrip(in interface{}, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// validateSchema validates value against the subset of JSON Schema the tool
+// schemas use: type, properties, required, additionalProperties, items and
+// enum. path names value in errors.
+func validateSchema(schema map[string]interface{}, value interface{}, path string) error {
+	if schema == nil {
+		return nil
+	}
+
+	if typ, ok := schema["type"].(string); ok && !hasType(value, typ) {
+		return fmt.Errorf("%s must be of type %s, got %s", path, typ, jsonType(value))
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			if allowed == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s must be one of %v, got %v", path, enum, value)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		required, _ := schema["required"].([]interface{})
+		for _, name := range required {
+			if key, _ := name.(string); key != "" {
+				if _, ok := v[key]; !ok {
+					return fmt.Errorf("%s.%s is required", path, key)
+				}
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys) // Report the same error every time
+		for _, key := range keys {
+			if property, ok := properties[key].(map[string]interface{}); ok {
+				if err := validateSchema(property, v[key], path+"."+key); err != nil {
+					return err
+				}
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					return fmt.Errorf("%s.%s is not allowed", path, key)
+				}
+			case map[string]interface{}:
+				if err := validateSchema(additional, v[key], path+"."+key); err != nil {
+					return err
+				}
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				if err := validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// hasType reports whether a JSON-decoded value is of JSON Schema type typ
+func hasType(value interface{}, typ string) bool {
+	switch typ {
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	default:
+		return jsonType(value) == typ
+	}
+}
+
+// jsonType returns the JSON Schema type name of a JSON-decoded value
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
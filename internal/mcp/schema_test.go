@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/apresai/gimage/internal/config"
+)
+
+func TestValidateOutput(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"success":      map[string]interface{}{"type": "boolean"},
+			"output_path":  map[string]interface{}{"type": "string"},
+			"frames":       map[string]interface{}{"type": "integer"},
+			"output_paths": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			"format":       map[string]interface{}{"type": "string", "enum": []string{"png", "jpeg"}},
+		},
+		"required":             []string{"success", "output_path"},
+		"additionalProperties": false,
+	}
+
+	tests := []struct {
+		name    string
+		result  map[string]interface{}
+		wantErr string
+	}{
+		{"valid", map[string]interface{}{"success": true, "output_path": "/a.png", "frames": 3, "output_paths": []string{"/a.png"}, "format": "png"}, ""},
+		{"int64 is an integer", map[string]interface{}{"success": true, "output_path": "/a.png", "frames": int64(3)}, ""},
+		{"missing required", map[string]interface{}{"success": true}, "result.output_path is required"},
+		{"wrong type", map[string]interface{}{"success": "yes", "output_path": "/a.png"}, "result.success must be of type boolean, got string"},
+		{"fraction for integer", map[string]interface{}{"success": true, "output_path": "/a.png", "frames": 1.5}, "result.frames must be of type integer"},
+		{"wrong item type", map[string]interface{}{"success": true, "output_path": "/a.png", "output_paths": []int{1}}, "result.output_paths[0] must be of type string"},
+		{"not in enum", map[string]interface{}{"success": true, "output_path": "/a.png", "format": "gif"}, "result.format must be one of"},
+		{"unknown property", map[string]interface{}{"success": true, "output_path": "/a.png", "extra": 1}, "result.extra is not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOutput(schema, tt.result)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestHandleCallTool_StructuredContent(t *testing.T) {
+	var result map[string]interface{}
+	server := NewMCPServer("test", "1.0.0", &config.Config{}, false)
+	server.RegisterTool(Tool{
+		Name:        "structured",
+		Description: "Return a structured result",
+		InputSchema: map[string]interface{}{"type": "object"},
+		OutputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"size": map[string]interface{}{"type": "integer"},
+			},
+			"required": []string{"size"},
+		},
+		Handler: func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) {
+			return result, nil
+		},
+	})
+	call := func() *JSONRPCResponse {
+		return server.HandleRequest(context.Background(), &JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  MethodCallTool,
+			Params:  parseParams(`{"name":"structured"}`),
+		})
+	}
+
+	// The result is returned as structuredContent alongside the text
+	result = map[string]interface{}{"size": 42}
+	response := call()
+	if response.Error != nil {
+		t.Fatalf("Unexpected error: %v", response.Error)
+	}
+	structured, ok := response.Result["structuredContent"].(map[string]interface{})
+	if !ok || structured["size"] != 42 {
+		t.Errorf("Expected the result as structuredContent, got %v", response.Result["structuredContent"])
+	}
+	if _, ok := response.Result["isError"]; ok {
+		t.Errorf("Expected no isError on success, got %v", response.Result["isError"])
+	}
+
+	// A result not matching the schema is a server bug, reported as a
+	// failed tool call
+	result = map[string]interface{}{"size": "large"}
+	response = call()
+	if response.Error != nil {
+		t.Fatalf("Expected an isError result, got error: %v", response.Error)
+	}
+	details, _ := response.Result["structuredContent"].(map[string]interface{})["error"].(map[string]interface{})
+	if response.Result["isError"] != true || details["code"] != ToolErrorOperationFailed || !strings.Contains(details["message"].(string), "result.size") {
+		t.Errorf("Expected an operation_failed error naming result.size, got %+v", response.Result)
+	}
+
+	// tools/list advertises the schema
+	list := server.HandleRequest(context.Background(), &JSONRPCRequest{JSONRPC: "2.0", ID: 2, Method: MethodListTools})
+	tools := list.Result["tools"].([]map[string]interface{})
+	if len(tools) != 1 || tools[0]["outputSchema"] == nil {
+		t.Errorf("Expected the output schema in tools/list, got %v", tools)
+	}
+}
@@ -7,11 +7,15 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sync"
 
 	"github.com/apresai/gimage/internal/config"
 	"github.com/apresai/gimage/internal/observability"
 )
 
+// DefaultWorkers is how many requests the stdio transport handles at once
+const DefaultWorkers = 8
+
 // MCPServer implements the Model Context Protocol server
 type MCPServer struct {
 	name    string
@@ -23,6 +27,15 @@ type MCPServer struct {
 	tools   map[string]Tool
 	prompts map[string]Prompt
 	verbose bool
+	workers int // requests handled at once
+
+	writeMu sync.Mutex // serializes writes to stdout
+
+	inflightMu sync.Mutex
+	inflight   map[string]*inflightRequest // requestKey -> request being handled
+
+	resourcesMu sync.Mutex
+	resources   map[string]map[string]Resource // session ID -> URI -> resource
 }
 
 // NewMCPServer creates a new MCP server instance
@@ -31,15 +44,18 @@ func NewMCPServer(name, version string, cfg *config.Config, verbose bool) *MCPSe
 	observability.Initialize(verbose)
 
 	return &MCPServer{
-		name:    name,
-		version: version,
-		config:  cfg,
-		stdin:   os.Stdin,
-		stdout:  os.Stdout,
-		stderr:  os.Stderr,
-		tools:   make(map[string]Tool),
-		prompts: make(map[string]Prompt),
-		verbose: verbose,
+		name:      name,
+		version:   version,
+		config:    cfg,
+		stdin:     os.Stdin,
+		stdout:    os.Stdout,
+		stderr:    os.Stderr,
+		tools:     make(map[string]Tool),
+		prompts:   make(map[string]Prompt),
+		verbose:   verbose,
+		workers:   DefaultWorkers,
+		inflight:  make(map[string]*inflightRequest),
+		resources: make(map[string]map[string]Resource),
 	}
 }
 
@@ -53,6 +69,11 @@ func (s *MCPServer) RegisterTool(tool Tool) {
 		Msg("Registered tool")
 }
 
+// ToolCount returns the number of registered tools
+func (s *MCPServer) ToolCount() int {
+	return len(s.tools)
+}
+
 // GetTool returns a tool by name, or nil if not found
 func (s *MCPServer) GetTool(name string) *Tool {
 	if tool, exists := s.tools[name]; exists {
@@ -61,27 +82,54 @@ func (s *MCPServer) GetTool(name string) *Tool {
 	return nil
 }
 
-// Start begins listening for MCP protocol messages
+// Start begins listening for MCP protocol messages on stdin. Requests are
+// handled concurrently, at most s.workers at a time, so a slow tool call
+// does not hold up the calls behind it and responses may arrive out of
+// order. Cancelling ctx cancels the requests in flight; Start returns once
+// they have ended.
 func (s *MCPServer) Start(ctx context.Context) error {
 	logger := observability.LoggerWithComponent(ctx, "mcp-server")
 
 	logger.Info().
 		Str("protocol_version", ProtocolVersion).
 		Int("tools_count", len(s.tools)).
+		Int("workers", s.workers).
 		Msg("MCP server starting")
 
-	scanner := bufio.NewScanner(s.stdin)
+	// Read stdin in the background so that shutdown is not stuck behind a
+	// blocking read
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(s.stdin)
+		for scanner.Scan() {
+			select {
+			case lines <- append([]byte(nil), scanner.Bytes()...):
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+		close(lines)
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	workers := make(chan struct{}, s.workers)
 
-	for scanner.Scan() {
+	for {
+		var line []byte
 		select {
 		case <-ctx.Done():
 			logger.Info().Msg("Server shutting down (context cancelled)")
 			return ctx.Err()
-		default:
+		case next, ok := <-lines:
+			if !ok {
+				return <-readErr
+			}
+			line = next
 		}
 
-		line := scanner.Bytes()
-
 		logger.Debug().
 			Str("raw_request", string(line)).
 			Msg("Received request")
@@ -101,7 +149,9 @@ func (s *MCPServer) Start(ctx context.Context) error {
 		reqLogger := observability.LoggerWithComponent(requestCtx, "mcp-server")
 
 		// CRITICAL: Detect notifications vs requests
-		// Notifications have NO id field and must NOT receive responses
+		// Notifications have NO id field and must NOT receive responses.
+		// They are handled right away so that a cancellation reaches a
+		// request still waiting for a worker.
 		if request.ID == nil {
 			reqLogger.Debug().
 				Str("method", request.Method).
@@ -116,25 +166,104 @@ func (s *MCPServer) Start(ctx context.Context) error {
 			Interface("id", request.ID).
 			Msg("Received request")
 
-		// This is a request (has ID), send a response
-		response := s.HandleRequest(requestCtx, &request)
+		// This is a request (has ID), send a response from a worker.
+		// Progress notifications go out on stdout alongside responses.
+		requestCtx, done := s.startRequest(withNotifier(requestCtx, s.writeMessage), request.ID)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer done()
 
-		responseBytes, err := json.Marshal(response)
-		if err != nil {
-			reqLogger.Error().
-				Err(err).
-				Msg("Failed to marshal response")
-			continue
-		}
+			select {
+			case workers <- struct{}{}:
+				defer func() { <-workers }()
+			case <-requestCtx.Done():
+				return
+			}
 
-		reqLogger.Debug().
-			Str("response", string(responseBytes)).
-			Msg("Sending response")
+			response := s.HandleRequest(requestCtx, &request)
+
+			// A cancelled request gets no response (the client has moved on)
+			if requestCtx.Err() != nil {
+				reqLogger.Info().
+					Str("method", request.Method).
+					Interface("id", request.ID).
+					Msg("Request cancelled, dropping response")
+				return
+			}
+
+			if err := s.writeMessage(response); err != nil {
+				reqLogger.Error().
+					Err(err).
+					Msg("Failed to send response")
+			}
+		}()
+	}
+}
 
-		fmt.Fprintln(s.stdout, string(responseBytes))
+// writeMessage writes a JSON-RPC message to stdout as a single line. Writes
+// are serialized so that concurrent responses never interleave.
+func (s *MCPServer) writeMessage(message interface{}) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
-	return scanner.Err()
+	logger := observability.Logger(context.Background())
+	logger.Debug().
+		Str("message", string(data)).
+		Msg("Sending message")
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_, err = fmt.Fprintln(s.stdout, string(data))
+	return err
+}
+
+// startRequest returns a context for the request with id that
+// notifications/cancelled can cancel, and a function to call once the
+// request has ended
+func (s *MCPServer) startRequest(ctx context.Context, id interface{}) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	key := requestKey(ctx, id)
+	request := &inflightRequest{cancel: cancel}
+
+	s.inflightMu.Lock()
+	s.inflight[key] = request
+	s.inflightMu.Unlock()
+
+	return ctx, func() {
+		s.inflightMu.Lock()
+		if s.inflight[key] == request {
+			delete(s.inflight, key)
+		}
+		s.inflightMu.Unlock()
+		cancel()
+	}
+}
+
+// cancelRequest cancels the request with id in the session of ctx,
+// reporting whether it was still in flight
+func (s *MCPServer) cancelRequest(ctx context.Context, id interface{}) bool {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+	request, ok := s.inflight[requestKey(ctx, id)]
+	if ok {
+		request.cancel()
+	}
+	return ok
+}
+
+// inflightRequest is a request being handled, which the client may cancel
+type inflightRequest struct {
+	cancel context.CancelFunc
+}
+
+// requestKey identifies a request by its session and JSON-RPC ID; the ID
+// is kept in its JSON form so that 1 and "1" stay distinct
+func requestKey(ctx context.Context, id interface{}) string {
+	data, _ := json.Marshal(id)
+	return sessionFromContext(ctx) + "/" + string(data)
 }
 
 // handleNotification processes MCP notifications (messages with no ID that expect no response)
@@ -142,11 +271,28 @@ func (s *MCPServer) handleNotification(ctx context.Context, req *JSONRPCRequest)
 	logger := observability.LoggerWithComponent(ctx, "mcp-server")
 
 	// According to MCP spec, notifications are fire-and-forget
-	// We log them but take no action
-	logger.Info().
-		Str("method", req.Method).
-		Msg("Notification received (no response sent)")
 	// No response is sent for notifications
+	switch req.Method {
+	case NotificationCancelled:
+		// The client no longer wants the result of an earlier request
+		requestID := req.Params["requestId"]
+		reason, _ := req.Params["reason"].(string)
+		if s.cancelRequest(ctx, requestID) {
+			logger.Info().
+				Interface("request_id", requestID).
+				Str("reason", reason).
+				Msg("Request cancelled by client")
+		} else {
+			logger.Debug().
+				Interface("request_id", requestID).
+				Msg("Cancellation for a request not in flight (already finished)")
+		}
+	default:
+		// We log other notifications but take no action
+		logger.Info().
+			Str("method", req.Method).
+			Msg("Notification received (no response sent)")
+	}
 }
 
 // NotifyToolsListChanged sends a notification to the client that the tool list has changed
@@ -159,17 +305,14 @@ func (s *MCPServer) NotifyToolsListChanged() error {
 		"method":  NotificationToolsListChanged,
 	}
 
-	notificationBytes, err := json.Marshal(notification)
-	if err != nil {
-		logger.Error().
-			Err(err).
-			Msg("Failed to marshal tools/list_changed notification")
-		return fmt.Errorf("failed to marshal notification: %w", err)
-	}
-
 	logger.Debug().
 		Msg("Sending tools/list_changed notification")
 
-	fmt.Fprintln(s.stdout, string(notificationBytes))
+	if err := s.writeMessage(notification); err != nil {
+		logger.Error().
+			Err(err).
+			Msg("Failed to send tools/list_changed notification")
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
 	return nil
 }
@@ -1,9 +1,12 @@
 package mcp
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"strings"
 	"testing"
 	"time"
@@ -44,7 +47,7 @@ func TestRegisterTool(t *testing.T) {
 		InputSchema: map[string]interface{}{
 			"type": "object",
 		},
-		Handler: func(args map[string]interface{}) (map[string]interface{}, error) {
+		Handler: func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) {
 			return map[string]interface{}{"success": true}, nil
 		},
 	}
@@ -55,6 +58,10 @@ func TestRegisterTool(t *testing.T) {
 		t.Errorf("Expected 1 tool, got %d", len(server.tools))
 	}
 
+	if server.ToolCount() != 1 {
+		t.Errorf("Expected ToolCount 1, got %d", server.ToolCount())
+	}
+
 	if server.tools["test_tool"].Name != "test_tool" {
 		t.Error("Tool not registered with correct name")
 	}
@@ -98,13 +105,13 @@ func TestHandleListTools(t *testing.T) {
 		Name:        "tool1",
 		Description: "First tool",
 		InputSchema: map[string]interface{}{"type": "object"},
-		Handler:     func(args map[string]interface{}) (map[string]interface{}, error) { return nil, nil },
+		Handler:     func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) { return nil, nil },
 	})
 	server.RegisterTool(Tool{
 		Name:        "tool2",
 		Description: "Second tool",
 		InputSchema: map[string]interface{}{"type": "object"},
-		Handler:     func(args map[string]interface{}) (map[string]interface{}, error) { return nil, nil },
+		Handler:     func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) { return nil, nil },
 	})
 
 	request := &JSONRPCRequest{
@@ -161,7 +168,7 @@ func TestHandleCallTool(t *testing.T) {
 				},
 			},
 		},
-		Handler: func(args map[string]interface{}) (map[string]interface{}, error) {
+		Handler: func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) {
 			return map[string]interface{}{
 				"echo": args["message"],
 			}, nil
@@ -341,11 +348,12 @@ func TestToolHandlerError(t *testing.T) {
 		Name:        "error_tool",
 		Description: "A tool that errors",
 		InputSchema: map[string]interface{}{"type": "object"},
-		Handler: func(args map[string]interface{}) (map[string]interface{}, error) {
-			return nil, &ToolError{
-				Code:    ErrorCodeInternalError,
+		Handler: func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) {
+			return nil, fmt.Errorf("wrapped: %w", &ToolError{
+				Code:    ToolErrorInvalidPath,
 				Message: "Tool error occurred",
-			}
+				Hint:    "Check the path",
+			})
 		},
 	})
 
@@ -358,15 +366,212 @@ func TestToolHandlerError(t *testing.T) {
 
 	response := server.HandleRequest(context.Background(), request)
 
-	if response.Error == nil {
-		t.Error("Expected error from tool handler, got nil")
+	// Tool errors are results the model sees, not protocol errors
+	if response.Error != nil {
+		t.Fatalf("Expected an isError result, got error: %v", response.Error)
 	}
 
-	if response.Error.Code != ErrorCodeInternalError {
-		t.Errorf("Expected error code %d, got %d", ErrorCodeInternalError, response.Error.Code)
+	if response.Result["isError"] != true {
+		t.Errorf("Expected isError true, got %v", response.Result["isError"])
+	}
+
+	structured := response.Result["structuredContent"].(map[string]interface{})
+	details := structured["error"].(map[string]interface{})
+	if details["code"] != ToolErrorInvalidPath || details["hint"] != "Check the path" {
+		t.Errorf("Expected the code and hint of the wrapped ToolError, got %v", details)
+	}
+
+	if details["message"] != "wrapped: Tool error occurred" {
+		t.Errorf("Expected the full error message, got: %v", details["message"])
+	}
+
+	content := response.Result["content"].([]map[string]interface{})
+	if len(content) != 1 || !strings.Contains(content[0]["text"].(string), "Tool error occurred") {
+		t.Errorf("Expected the error as text content, got %v", content)
+	}
+}
+
+// pipeServer starts server on pipes and returns a function sending a line
+// to its stdin, a function reading the next response from its stdout, and
+// the channel Start returns on
+func pipeServer(t *testing.T, ctx context.Context, server *MCPServer) (func(string), func() JSONRPCResponse, chan error) {
+	t.Helper()
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	server.stdin = stdinReader
+	server.stdout = stdoutWriter
+	t.Cleanup(func() {
+		stdinWriter.Close()
+		stdoutReader.Close()
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Start(ctx)
+	}()
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(stdoutReader)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	send := func(line string) {
+		if _, err := fmt.Fprintln(stdinWriter, line); err != nil {
+			t.Fatalf("Failed to write request: %v", err)
+		}
+	}
+	receive := func() JSONRPCResponse {
+		select {
+		case line := <-lines:
+			var response JSONRPCResponse
+			if err := json.Unmarshal([]byte(line), &response); err != nil {
+				t.Fatalf("Failed to parse response %q: %v", line, err)
+			}
+			return response
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a response")
+			return JSONRPCResponse{}
+		}
+	}
+	return send, receive, done
+}
+
+// registerBlockingTool registers a tool that blocks until release is closed
+// or its context is done, reporting its context error on cancelled
+func registerBlockingTool(server *MCPServer, started chan<- struct{}, release <-chan struct{}, cancelled chan<- error) {
+	server.RegisterTool(Tool{
+		Name:        "block",
+		Description: "Block until released",
+		InputSchema: map[string]interface{}{"type": "object"},
+		Handler: func(ctx context.Context, args map[string]interface{}) (map[string]interface{}, error) {
+			started <- struct{}{}
+			select {
+			case <-release:
+				return map[string]interface{}{"released": true}, nil
+			case <-ctx.Done():
+				cancelled <- ctx.Err()
+				return nil, ctx.Err()
+			}
+		},
+	})
+}
+
+func TestServerStart_Concurrent(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0", &config.Config{}, false)
+	started, release, cancelled := make(chan struct{}, 1), make(chan struct{}), make(chan error, 1)
+	registerBlockingTool(server, started, release, cancelled)
+
+	send, receive, _ := pipeServer(t, t.Context(), server)
+
+	// A ping is answered while a slow tool call is still running
+	send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"block"}}`)
+	<-started
+	send(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	response := receive()
+	if response.ID != float64(2) || response.Error != nil || response.Result == nil {
+		t.Fatalf("Expected the ping response first, got %+v", response)
+	}
+
+	close(release)
+	if response := receive(); response.ID != float64(1) || response.Error != nil {
+		t.Errorf("Expected the tool response, got %+v", response)
+	}
+}
+
+func TestServerStart_Cancelled(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0", &config.Config{}, false)
+	started, release, cancelled := make(chan struct{}, 1), make(chan struct{}), make(chan error, 1)
+	registerBlockingTool(server, started, release, cancelled)
+
+	send, receive, _ := pipeServer(t, t.Context(), server)
+
+	send(`{"jsonrpc":"2.0","id":"gen","method":"tools/call","params":{"name":"block"}}`)
+	<-started
+	send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"gen","reason":"user stopped"}}`)
+	select {
+	case err := <-cancelled:
+		if err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Tool context was not cancelled")
+	}
+
+	// The cancelled call gets no response; the next request does
+	send(`{"jsonrpc":"2.0","id":3,"method":"ping"}`)
+	if response := receive(); response.ID != float64(3) {
+		t.Errorf("Expected only the ping response, got %+v", response)
+	}
+}
+
+func TestServerStart_ShutdownCancelsRequests(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0", &config.Config{}, false)
+	started, release, cancelled := make(chan struct{}, 1), make(chan struct{}), make(chan error, 1)
+	registerBlockingTool(server, started, release, cancelled)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	send, _, done := pipeServer(t, ctx, server)
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"block"}}`)
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start did not return after cancellation")
+	}
+	// Start waited for the tool, which saw the shutdown
+	select {
+	case <-cancelled:
+	default:
+		t.Error("Expected the tool context to be cancelled on shutdown")
+	}
+}
+
+func TestServerStart_WorkerLimit(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0", &config.Config{}, false)
+	server.workers = 1
+	started, release, cancelled := make(chan struct{}, 2), make(chan struct{}), make(chan error, 2)
+	registerBlockingTool(server, started, release, cancelled)
+
+	send, receive, _ := pipeServer(t, t.Context(), server)
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"block"}}`)
+	send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"block"}}`)
+	<-started
+	select {
+	case <-started:
+		t.Fatal("Expected the second call to wait for the only worker")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-started
+	ids := map[interface{}]bool{receive().ID: true, receive().ID: true}
+	if !ids[float64(1)] || !ids[float64(2)] {
+		t.Errorf("Expected responses to both calls, got %v", ids)
+	}
+}
+
+func TestJSONRPCResponse_EmptyResult(t *testing.T) {
+	data, err := json.Marshal(&JSONRPCResponse{JSONRPC: "2.0", ID: 1, Result: map[string]interface{}{}})
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if string(data) != `{"jsonrpc":"2.0","id":1,"result":{}}` {
+		t.Errorf("Expected an empty result, got %s", data)
 	}
 
-	if !strings.Contains(response.Error.Message, "Tool error occurred") {
-		t.Errorf("Expected error message to contain 'Tool error occurred', got: %s", response.Error.Message)
+	data, _ = json.Marshal(&JSONRPCResponse{JSONRPC: "2.0", ID: 1, Error: &JSONRPCError{Code: ErrorCodeInternalError, Message: "boom"}})
+	if string(data) != `{"jsonrpc":"2.0","id":1,"error":{"code":-32603,"message":"boom"}}` {
+		t.Errorf("Expected an error without result, got %s", data)
 	}
 }
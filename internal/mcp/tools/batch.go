@@ -1,21 +1,25 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
 
+	gimaging "github.com/apresai/gimage/internal/imaging"
 	"github.com/apresai/gimage/internal/mcp"
-	"github.com/disintegration/imaging"
+	"github.com/apresai/gimage/internal/progress"
 )
 
 // RegisterBatchResizeTool registers the batch_resize tool
 func RegisterBatchResizeTool(server *mcp.MCPServer) {
 	tool := mcp.Tool{
 		Name:        "batch_resize",
-		Description: "Resize multiple images in a directory concurrently. Processes all image files (PNG, JPG, WebP, GIF, TIFF, BMP) in the input directory and saves resized versions to the output directory. Uses parallel workers for fast processing of large batches.",
+		Description: "Resize multiple images in a directory concurrently. Processes all image files (PNG, JPG, WebP, AVIF, HEIC, GIF, TIFF, BMP) in the input directory and saves resized versions to the output directory. Uses parallel workers for fast processing of large batches; filter linear or catmullrom makes large thumbnail runs faster.",
 		InputSchema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -44,11 +48,18 @@ func RegisterBatchResizeTool(server *mcp.MCPServer) {
 					"maximum":     16,
 					"default":     runtime.NumCPU(),
 				},
+				"mode":       resizeModeProperty(),
+				"anchor":     anchorProperty(),
+				"background": backgroundProperty(),
+				"filter":     filterProperty(),
+				"sharpen":    sharpenProperty(),
+				"metadata":   metadataProperty(),
 			},
 			"required": []string{"input_dir", "width", "height", "output_dir"},
 		},
-		Handler: func(args map[string]interface{}) (map[string]interface{}, error) {
-			return batchProcessImages(args, "resize")
+		OutputSchema: batchOutputSchema(),
+		Handler: func(ctx context.Context, args map[string]interface{}) (map[string]interface{}, error) {
+			return batchProcessImages(ctx, args, "resize")
 		},
 	}
 
@@ -90,11 +101,13 @@ func RegisterBatchCompressTool(server *mcp.MCPServer) {
 					"maximum":     16,
 					"default":     runtime.NumCPU(),
 				},
+				"metadata": metadataProperty(),
 			},
 			"required": []string{"input_dir", "output_dir"},
 		},
-		Handler: func(args map[string]interface{}) (map[string]interface{}, error) {
-			return batchProcessImages(args, "compress")
+		OutputSchema: batchOutputSchema(),
+		Handler: func(ctx context.Context, args map[string]interface{}) (map[string]interface{}, error) {
+			return batchProcessImages(ctx, args, "compress")
 		},
 	}
 
@@ -115,7 +128,7 @@ func RegisterBatchConvertTool(server *mcp.MCPServer) {
 				},
 				"format": map[string]interface{}{
 					"type":        "string",
-					"enum":        []string{"png", "jpg", "jpeg", "webp", "gif", "tiff", "bmp"},
+					"enum":        []string{"png", "jpg", "jpeg", "webp", "avif", "gif", "tiff", "bmp"},
 					"description": "Target image format for all images",
 				},
 				"output_dir": map[string]interface{}{
@@ -129,18 +142,40 @@ func RegisterBatchConvertTool(server *mcp.MCPServer) {
 					"maximum":     16,
 					"default":     runtime.NumCPU(),
 				},
+				"metadata": metadataProperty(),
 			},
 			"required": []string{"input_dir", "format", "output_dir"},
 		},
-		Handler: func(args map[string]interface{}) (map[string]interface{}, error) {
-			return batchProcessImages(args, "convert")
+		OutputSchema: batchOutputSchema(),
+		Handler: func(ctx context.Context, args map[string]interface{}) (map[string]interface{}, error) {
+			return batchProcessImages(ctx, args, "convert")
 		},
 	}
 
 	server.RegisterTool(tool)
 }
 
-func batchProcessImages(args map[string]interface{}, operation string) (map[string]interface{}, error) {
+// batchOutputSchema is the output schema of the batch tools
+func batchOutputSchema() map[string]interface{} {
+	return outputSchema(map[string]interface{}{
+		"success":    outputProperty("boolean", "Whether every image was processed"),
+		"processed":  outputProperty("integer", "Number of images written"),
+		"failed":     outputProperty("integer", "Number of images that failed"),
+		"total":      outputProperty("integer", "Number of images found"),
+		"output_dir": outputProperty("string", "Directory the images were written to"),
+		"errors": map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string"},
+			"description": "One entry per failed image, file: error",
+		},
+		"total_original_size": outputProperty("string", "Combined input size, batch_compress only"),
+		"total_new_size":      outputProperty("string", "Combined output size, batch_compress only"),
+		"total_savings":       outputProperty("string", "Combined bytes saved, batch_compress only"),
+		"savings_percent":     outputProperty("string", "Share of the input size saved, batch_compress only"),
+	}, "success", "processed", "failed", "total", "output_dir")
+}
+
+func batchProcessImages(ctx context.Context, args map[string]interface{}, operation string) (map[string]interface{}, error) {
 	// Validate input directory
 	inputDirArg, err := validateString(args["input_dir"], "input_dir")
 	if err != nil {
@@ -161,6 +196,28 @@ func batchProcessImages(args map[string]interface{}, operation string) (map[stri
 		return nil, fmt.Errorf("output directory validation failed: %w", err)
 	}
 
+	policy, err := metadataPolicyArg(args)
+	if err != nil {
+		return nil, err
+	}
+	fileOpts := gimaging.FileOptions{Metadata: policy}
+
+	var opts gimaging.ResizeOptions
+	if operation == "resize" {
+		if opts, err = resizeOptionsArg(args); err != nil {
+			return nil, err
+		}
+		filter, err := filterArg(args)
+		if err != nil {
+			return nil, err
+		}
+		sharpen, err := sharpenArg(args)
+		if err != nil {
+			return nil, err
+		}
+		fileOpts.Filter, fileOpts.Sharpen = filter, sharpen
+	}
+
 	// Determine number of workers
 	workers := runtime.NumCPU()
 	if workersVal, ok := args["workers"].(float64); ok {
@@ -173,7 +230,7 @@ func batchProcessImages(args map[string]interface{}, operation string) (map[stri
 	}
 
 	// Find all image files
-	imageExtensions := []string{".png", ".jpg", ".jpeg", ".webp", ".gif", ".tiff", ".bmp"}
+	imageExtensions := []string{".png", ".jpg", ".jpeg", ".webp", ".avif", ".heic", ".heif", ".gif", ".tiff", ".bmp"}
 	var files []string
 
 	err = filepath.Walk(inputDir, func(path string, info os.FileInfo, err error) error {
@@ -184,7 +241,7 @@ func batchProcessImages(args map[string]interface{}, operation string) (map[stri
 			return nil
 		}
 
-		ext := filepath.Ext(path)
+		ext := strings.ToLower(filepath.Ext(path)) // iPhones write .HEIC
 		for _, validExt := range imageExtensions {
 			if ext == validExt || ext == "."+validExt {
 				files = append(files, path)
@@ -199,7 +256,7 @@ func batchProcessImages(args map[string]interface{}, operation string) (map[stri
 	}
 
 	if len(files) == 0 {
-		return nil, fmt.Errorf("no image files found in %s", inputDir)
+		return nil, invalidPath(hintDirectory, "no image files found in %s", inputDir)
 	}
 
 	// Process images concurrently
@@ -209,16 +266,25 @@ func batchProcessImages(args map[string]interface{}, operation string) (map[stri
 	processed := 0
 	failed := 0
 	var errors []string
+	var outputs []string
 	var totalOriginalSize int64
 	var totalNewSize int64
 
-	for _, file := range files {
+	// Report the batch as one operation that each image advances
+	batch := newBatchProgress(ctx, fmt.Sprintf("Batch %s of %d images", operation, len(files)), len(files))
+
+	for i, file := range files {
 		wg.Add(1)
-		go func(inputPath string) {
+		go func(index int, inputPath string) {
 			defer wg.Done()
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
+			// Leave the rest of the batch once the call is cancelled
+			if ctx.Err() != nil {
+				return
+			}
+
 			// Determine output path
 			relPath, _ := filepath.Rel(inputDir, inputPath)
 			var outputPath string
@@ -237,19 +303,20 @@ func batchProcessImages(args map[string]interface{}, operation string) (map[stri
 			os.MkdirAll(outputSubdir, 0755)
 
 			// Process based on operation
+			ctx := progress.WithReporter(ctx, batch.image(index, filepath.Base(inputPath)))
 			var err error
 			switch operation {
 			case "resize":
 				width, _ := validatePositiveInt(args["width"], "width")
 				height, _ := validatePositiveInt(args["height"], "height")
-				err = processResize(inputPath, outputPath, width, height)
+				err = processResize(ctx, inputPath, outputPath, width, height, opts, fileOpts)
 
 			case "compress":
 				quality := 85
 				if qualityVal, ok := args["quality"].(float64); ok {
 					quality = int(qualityVal)
 				}
-				err = processCompress(inputPath, outputPath, quality)
+				err = processCompress(ctx, inputPath, outputPath, quality, fileOpts)
 				if err == nil {
 					// Track savings
 					origSize, _ := getFileSize(inputPath)
@@ -261,7 +328,7 @@ func batchProcessImages(args map[string]interface{}, operation string) (map[stri
 				}
 
 			case "convert":
-				err = processConvert(inputPath, outputPath)
+				err = processConvert(ctx, inputPath, outputPath, fileOpts)
 			}
 
 			mu.Lock()
@@ -270,12 +337,16 @@ func batchProcessImages(args map[string]interface{}, operation string) (map[stri
 				errors = append(errors, fmt.Sprintf("%s: %v", filepath.Base(inputPath), err))
 			} else {
 				processed++
+				outputs = append(outputs, outputPath)
 			}
 			mu.Unlock()
-		}(file)
+		}(i, file)
 	}
 
 	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("batch %s cancelled after %d of %d images: %w", operation, processed+failed, len(files), err)
+	}
 
 	result := map[string]interface{}{
 		"success":    failed == 0,
@@ -298,30 +369,87 @@ func batchProcessImages(args map[string]interface{}, operation string) (map[stri
 		result["savings_percent"] = fmt.Sprintf("%.1f%%", savingsPercent)
 	}
 
-	return result, nil
+	// Link every output; a batch can be too large to return its images
+	sort.Strings(outputs)
+	return withOutputContent(result, returnImageNone, outputs...), nil
 }
 
-func processResize(input, output string, width, height int) error {
-	img, err := loadImage(input)
-	if err != nil {
-		return err
+func processResize(ctx context.Context, input, output string, width, height int, opts gimaging.ResizeOptions, fileOpts gimaging.FileOptions) error {
+	return gimaging.ResizeWithOptions(ctx, input, output, width, height, opts, fileOpts)
+}
+
+func processCompress(ctx context.Context, input, output string, quality int, fileOpts gimaging.FileOptions) error {
+	return gimaging.CompressImage(ctx, input, output, quality, fileOpts)
+}
+
+func processConvert(ctx context.Context, input, output string, fileOpts gimaging.FileOptions) error {
+	return gimaging.ConvertImageFile(ctx, input, output, fileOpts)
+}
+
+// batchProgressScale is how many progress units each image of a batch is
+// worth, so that progress advances within an image and not only between
+const batchProgressScale = 100
+
+// batchProgress reports a batch to the progress reporter of its context
+// as one operation, fed by the per-image reporters of the imaging calls
+type batchProgress struct {
+	reporter progress.ProgressReporter
+	total    int64
+
+	mu    sync.Mutex
+	units []int64 // progress of each image, up to batchProgressScale
+	sum   int64
+}
+
+// newBatchProgress starts reporting operation over count images
+func newBatchProgress(ctx context.Context, operation string, count int) *batchProgress {
+	reporter := progress.FromContext(ctx)
+	reporter.Start(ctx, operation)
+	return &batchProgress{
+		reporter: reporter,
+		total:    int64(count) * batchProgressScale,
+		units:    make([]int64, count),
 	}
-	resized := imaging.Resize(img, width, height, imaging.Lanczos)
-	return saveImage(resized, output)
 }
 
-func processCompress(input, output string, quality int) error {
-	img, err := loadImage(input)
-	if err != nil {
-		return err
+// image returns the reporter for the imaging calls on image index
+func (b *batchProgress) image(index int, name string) progress.ProgressReporter {
+	return &batchImageReporter{batch: b, index: index, name: name}
+}
+
+// advance moves image index to units of progress; images only move forward
+func (b *batchProgress) advance(index int, units int64, message string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if units <= b.units[index] {
+		return
 	}
-	return imaging.Save(img, output, imaging.JPEGQuality(quality))
+	b.sum += units - b.units[index]
+	b.units[index] = units
+	b.reporter.Update(b.sum, b.total, message)
 }
 
-func processConvert(input, output string) error {
-	img, err := loadImage(input)
-	if err != nil {
-		return err
+// batchImageReporter maps the progress of one image onto its batch
+type batchImageReporter struct {
+	batch *batchProgress
+	index int
+	name  string
+}
+
+func (r *batchImageReporter) Start(ctx context.Context, operation string) {}
+
+// Update counts the image as done only once Complete or Error follows the
+// last step
+func (r *batchImageReporter) Update(current, total int64, message string) {
+	if total > 0 {
+		r.batch.advance(r.index, current*batchProgressScale/(total+1), fmt.Sprintf("%s: %s", r.name, message))
 	}
-	return saveImage(img, output)
+}
+
+func (r *batchImageReporter) Complete(result interface{}) {
+	r.batch.advance(r.index, batchProgressScale, fmt.Sprintf("%s: done", r.name))
+}
+
+func (r *batchImageReporter) Error(err error) {
+	r.batch.advance(r.index, batchProgressScale, fmt.Sprintf("%s: failed", r.name))
 }
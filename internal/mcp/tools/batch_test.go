@@ -1,10 +1,17 @@
 package tools
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"image/color"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/apresai/gimage/internal/mcp"
+	"github.com/apresai/gimage/internal/progress"
 )
 
 func TestRegisterBatchResizeTool(t *testing.T) {
@@ -306,7 +313,7 @@ func TestBatchProcessImages_ValidationErrors(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := tool.Handler(tt.args)
+			result, err := tool.Handler(context.Background(), tt.args)
 
 			if tt.wantError {
 				if err == nil {
@@ -440,3 +447,75 @@ func TestBatchCompress_QualityDefault(t *testing.T) {
 		t.Errorf("Expected quality 75, got %d", quality)
 	}
 }
+
+func TestBatchProcessImages_Cancelled(t *testing.T) {
+	inputDir := t.TempDir()
+	for i := 0; i < 3; i++ {
+		writeTestPNG(t, filepath.Join(inputDir, fmt.Sprintf("image%d.png", i)), 40, 40, color.NRGBA{10, 20, 30, 255})
+	}
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	// A cancelled call stops before processing the rest of the batch
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := batchProcessImages(ctx, map[string]interface{}{
+		"input_dir":  inputDir,
+		"output_dir": outputDir,
+		"width":      20.0,
+		"height":     20.0,
+	}, "resize")
+	if err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Fatalf("Expected a cancellation error, got %v", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the error to wrap context.Canceled, got %v", err)
+	}
+}
+
+func TestBatchProcessImages_Progress(t *testing.T) {
+	inputDir := t.TempDir()
+	for i := 0; i < 4; i++ {
+		writeTestPNG(t, filepath.Join(inputDir, fmt.Sprintf("image%d.png", i)), 40, 40, color.NRGBA{10, 20, 30, 255})
+	}
+
+	var updates []int64
+	var last string
+	reporter := progress.NewTUIReporter(nil, func(current, total int64, message string, percentage float64) {
+		updates = append(updates, current)
+		last = message
+		if total != 4*batchProgressScale {
+			t.Errorf("total = %d, want %d", total, 4*batchProgressScale)
+		}
+	}, nil, nil)
+
+	ctx := progress.WithReporter(context.Background(), reporter)
+	result, err := batchProcessImages(ctx, map[string]interface{}{
+		"input_dir":  inputDir,
+		"output_dir": filepath.Join(t.TempDir(), "out"),
+		"width":      20.0,
+		"height":     20.0,
+		"workers":    2.0,
+	}, "resize")
+	if err != nil {
+		t.Fatalf("batchProcessImages() error = %v", err)
+	}
+	if result["processed"] != 4 {
+		t.Fatalf("processed = %v, want 4", result["processed"])
+	}
+
+	// Progress rises within and across images and ends at the total
+	if len(updates) <= 4 {
+		t.Errorf("Expected updates within images, got %v", updates)
+	}
+	for i := 1; i < len(updates); i++ {
+		if updates[i] <= updates[i-1] {
+			t.Errorf("Progress went from %d to %d", updates[i-1], updates[i])
+		}
+	}
+	if len(updates) > 0 && updates[len(updates)-1] != 4*batchProgressScale {
+		t.Errorf("Final progress = %d, want %d", updates[len(updates)-1], 4*batchProgressScale)
+	}
+	if !strings.HasSuffix(last, ": done") {
+		t.Errorf("Last message = %q, want an image done", last)
+	}
+}
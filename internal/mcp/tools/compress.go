@@ -1,18 +1,19 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 
+	gimaging "github.com/apresai/gimage/internal/imaging"
 	"github.com/apresai/gimage/internal/mcp"
-	"github.com/disintegration/imaging"
 )
 
 // RegisterCompressImageTool registers the compress_image tool
 func RegisterCompressImageTool(server *mcp.MCPServer) {
 	tool := mcp.Tool{
 		Name:        "compress_image",
-		Description: "Compress an image to reduce file size while maintaining visual quality. Quality ranges from 1 (lowest quality, smallest file) to 100 (highest quality, largest file). Default is 90 which provides excellent quality with good compression. Most effective on JPEG images. PNG images are compressed losslessly.",
+		Description: "Compress an image to reduce file size while maintaining visual quality. Quality ranges from 1 (lowest quality, smallest file) to 100 (highest quality, largest file). Default is 90 which provides excellent quality with good compression. Applies to JPEG, WebP and AVIF output; WebP and AVIF are encoded lossy at the given quality, which usually beats JPEG on size (AVIF most of all). The output format follows the output extension, so a .webp or .avif output converts any input. PNG images are compressed losslessly. Set max_size (e.g. \"200KB\") to fit a byte budget instead: the highest quality that fits is found automatically, optionally shrinking the image (allow_resize), and the chosen quality is reported.",
 		InputSchema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -27,14 +28,45 @@ func RegisterCompressImageTool(server *mcp.MCPServer) {
 					"maximum":     100,
 					"default":     90,
 				},
+				"max_size": map[string]interface{}{
+					"type":        "string",
+					"description": "Largest acceptable output size, e.g. \"200KB\", \"1.5MB\" or \"204800\" (KB/MB are powers of 1000, KiB/MiB powers of 1024). Searches for the highest quality that fits; quality, if given, is the upper bound.",
+				},
+				"min_quality": map[string]interface{}{
+					"type":        "integer",
+					"description": "Lowest quality to try with max_size. Default is 10.",
+					"minimum":     1,
+					"maximum":     100,
+				},
+				"allow_resize": map[string]interface{}{
+					"type":        "boolean",
+					"description": "With max_size, step the dimensions down when the minimum quality is still too large. Default is false.",
+				},
 				"output": map[string]interface{}{
 					"type":        "string",
-					"description": "Output file path. If not provided, generates filename like input_compressed.ext",
+					"description": "Output file path; the extension selects the format (e.g. .webp for lossy WebP). If not provided, generates filename like input_compressed.ext",
 				},
+				"speed":        speedProperty(),
+				"metadata":     metadataProperty(),
+				"return_image": returnImageProperty(),
 			},
 			"required": []string{"input"},
 		},
-		Handler: func(args map[string]interface{}) (map[string]interface{}, error) {
+		OutputSchema: imageOutputSchema(map[string]interface{}{
+			"quality":               outputProperty("integer", "Encoder quality used"),
+			"format":                outputProperty("string", "Output format"),
+			"original_size_bytes":   outputProperty("integer", "Input file size in bytes"),
+			"compressed_size_bytes": outputProperty("integer", "Output file size in bytes"),
+			"compression_ratio":     outputProperty("string", "Output size divided by input size"),
+			"savings_bytes":         outputProperty("integer", "Bytes saved; negative if the output is larger"),
+			"savings_percent":       outputProperty("string", "Share of the input size saved"),
+			"original_size_human":   outputProperty("string", "Input file size, human readable"),
+			"compressed_size_human": outputProperty("string", "Output file size, human readable"),
+			"max_size_bytes":        outputProperty("integer", "Byte budget, with max_size only"),
+			"resized":               outputProperty("boolean", "Whether the image was downscaled to fit max_size"),
+			"new_size":              outputProperty("string", "Output dimensions with max_size, WIDTHxHEIGHT"),
+		}),
+		Handler: func(ctx context.Context, args map[string]interface{}) (map[string]interface{}, error) {
 			// Validate input
 			inputArg, err := validateString(args["input"], "input")
 			if err != nil {
@@ -47,13 +79,48 @@ func RegisterCompressImageTool(server *mcp.MCPServer) {
 
 			// Validate quality (default 90)
 			quality := 90
-			if qualityVal, ok := args["quality"].(float64); ok {
+			qualityVal, hasQuality := args["quality"].(float64)
+			if hasQuality {
 				quality = int(qualityVal)
 				if quality < 1 || quality > 100 {
-					return nil, fmt.Errorf("quality must be between 1 and 100")
+					return nil, invalidInput("quality must be between 1 and 100")
 				}
 			}
 
+			// Optional byte budget
+			var limit *gimaging.SizeLimit
+			if maxSize, ok := args["max_size"].(string); ok && maxSize != "" {
+				maxBytes, err := gimaging.ParseByteSize(maxSize)
+				if err != nil {
+					return nil, invalidInput("invalid max_size: %w", err)
+				}
+				limit = &gimaging.SizeLimit{MaxBytes: maxBytes}
+				if hasQuality {
+					limit.MaxQuality = quality
+				}
+				if minQuality, ok := args["min_quality"].(float64); ok {
+					limit.MinQuality = int(minQuality)
+				}
+				limit.AllowResize, _ = args["allow_resize"].(bool)
+				if err := limit.Validate(); err != nil {
+					return nil, invalidInput("%w", err)
+				}
+			}
+
+			policy, err := metadataPolicyArg(args)
+			if err != nil {
+				return nil, err
+			}
+			returnImage, err := returnImageArg(args)
+			if err != nil {
+				return nil, err
+			}
+			speed, err := speedArg(args)
+			if err != nil {
+				return nil, err
+			}
+			fileOpts := gimaging.FileOptions{Metadata: policy, Speed: speed}
+
 			// Determine output path
 			outputArg, _ := args["output"].(string)
 			defaultFilename := generateOutputPath(input, "compressed")
@@ -69,16 +136,20 @@ func RegisterCompressImageTool(server *mcp.MCPServer) {
 				return nil, fmt.Errorf("failed to get original file size: %w", err)
 			}
 
-			// Load image
-			img, err := loadImage(input)
-			if err != nil {
-				return nil, fmt.Errorf("failed to load image: %w", err)
-			}
-
-			// Save with quality setting
-			err = imaging.Save(img, output, imaging.JPEGQuality(quality))
-			if err != nil {
-				return nil, fmt.Errorf("failed to save compressed image: %w", err)
+			// Re-encode in the output's format, at the given quality or the
+			// highest one that fits the budget
+			var sizeResult gimaging.SizeResult
+			if limit != nil {
+				sizeResult, err = gimaging.CompressImageToSize(ctx, input, output, *limit, fileOpts)
+				if err != nil {
+					return nil, fmt.Errorf("failed to fit max_size: %w", err)
+				}
+				quality = sizeResult.Quality
+			} else {
+				err = gimaging.CompressImage(ctx, input, output, quality, fileOpts)
+				if err != nil {
+					return nil, fmt.Errorf("failed to save compressed image: %w", err)
+				}
 			}
 
 			// Get new file size
@@ -99,6 +170,7 @@ func RegisterCompressImageTool(server *mcp.MCPServer) {
 				"success":               true,
 				"output_path":           absPath,
 				"quality":               quality,
+				"format":                gimaging.ExtractFormatFromPath(output),
 				"original_size_bytes":   originalSize,
 				"compressed_size_bytes": newSize,
 				"compression_ratio":     fmt.Sprintf("%.2f", ratio),
@@ -107,10 +179,15 @@ func RegisterCompressImageTool(server *mcp.MCPServer) {
 				"original_size_human":   formatBytes(originalSize),
 				"compressed_size_human": formatBytes(newSize),
 			}
+			if limit != nil {
+				result["max_size_bytes"] = limit.MaxBytes
+				result["resized"] = sizeResult.Resized
+				result["new_size"] = fmt.Sprintf("%dx%d", sizeResult.Width, sizeResult.Height)
+			}
 			if pathResult.Warning != "" {
 				result["warning"] = pathResult.Warning
 			}
-			return result, nil
+			return withOutputContent(result, returnImage, absPath), nil
 		},
 	}
 
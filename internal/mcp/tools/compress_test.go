@@ -1,11 +1,14 @@
 package tools
 
 import (
+	"context"
+	"fmt"
 	"image"
 	"image/color"
 	"image/png"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/apresai/gimage/internal/config"
@@ -114,7 +117,7 @@ func TestCompressImageTool(t *testing.T) {
 				t.Fatal("compress_image tool not registered")
 			}
 
-			result, err := tool.Handler(tt.args)
+			result, err := tool.Handler(context.Background(), tt.args)
 
 			if tt.wantError {
 				if err == nil {
@@ -259,3 +262,136 @@ func TestCompressImageToolSchema(t *testing.T) {
 		t.Error("Expected maximum constraint on quality")
 	}
 }
+
+func TestCompressImageTool_WebP(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{}
+
+	// A textured image, where lossy WebP has something to discard
+	testImagePath := filepath.Join(tmpDir, "photo.png")
+	img := image.NewRGBA(image.Rect(0, 0, 320, 240))
+	for y := 0; y < 240; y++ {
+		for x := 0; x < 320; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * y), uint8(x ^ y), uint8(x + 3*y), 255})
+		}
+	}
+	file, err := os.Create(testImagePath)
+	if err != nil {
+		t.Fatalf("Failed to create test image: %v", err)
+	}
+	png.Encode(file, img)
+	file.Close()
+
+	server := mcp.NewMCPServer("test", "1.0.0", cfg, false)
+	RegisterCompressImageTool(server)
+	tool := server.GetTool("compress_image")
+
+	sizes := map[int]int64{}
+	for _, quality := range []int{30, 90} {
+		output := filepath.Join(tmpDir, fmt.Sprintf("photo_q%d.webp", quality))
+		result, err := tool.Handler(context.Background(), map[string]interface{}{
+			"input":   testImagePath,
+			"quality": float64(quality),
+			"output":  output,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error at quality %d: %v", quality, err)
+		}
+		if result["format"] != "webp" {
+			t.Errorf("format = %v, want webp", result["format"])
+		}
+
+		f, err := os.Open(output)
+		if err != nil {
+			t.Fatalf("Failed to open output: %v", err)
+		}
+		cfg, format, err := image.DecodeConfig(f)
+		f.Close()
+		if err != nil || format != "webp" {
+			t.Fatalf("Output is not a WebP image: format %q, err %v", format, err)
+		}
+		if cfg.Width != 320 || cfg.Height != 240 {
+			t.Errorf("Output size = %dx%d, want 320x240", cfg.Width, cfg.Height)
+		}
+		sizes[quality] = result["compressed_size_bytes"].(int64)
+	}
+
+	if sizes[30] >= sizes[90] {
+		t.Errorf("Quality 30 (%d bytes) should be smaller than quality 90 (%d bytes)", sizes[30], sizes[90])
+	}
+}
+
+func TestCompressImageTool_MaxSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{}
+
+	testImagePath := filepath.Join(tmpDir, "photo.png")
+	img := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 400; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * y), uint8(x ^ y), uint8(x + 3*y), 255})
+		}
+	}
+	file, err := os.Create(testImagePath)
+	if err != nil {
+		t.Fatalf("Failed to create test image: %v", err)
+	}
+	png.Encode(file, img)
+	file.Close()
+
+	server := mcp.NewMCPServer("test", "1.0.0", cfg, false)
+	RegisterCompressImageTool(server)
+	tool := server.GetTool("compress_image")
+
+	// Quality search alone
+	result, err := tool.Handler(context.Background(), map[string]interface{}{
+		"input":    testImagePath,
+		"max_size": "30KB",
+		"output":   filepath.Join(tmpDir, "fit.jpg"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if size := result["compressed_size_bytes"].(int64); size > 30000 {
+		t.Errorf("compressed_size_bytes = %d, want <= 30000", size)
+	}
+	if q := result["quality"].(int); q < 10 || q >= 100 {
+		t.Errorf("quality = %d, want a searched value", q)
+	}
+	if result["max_size_bytes"] != int64(30000) || result["resized"] != false {
+		t.Errorf("max_size_bytes = %v, resized = %v", result["max_size_bytes"], result["resized"])
+	}
+
+	// Too small without resizing
+	_, err = tool.Handler(context.Background(), map[string]interface{}{
+		"input":    testImagePath,
+		"max_size": "2KB",
+		"output":   filepath.Join(tmpDir, "tiny.jpg"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "allow resizing") {
+		t.Errorf("Expected allow resizing hint, got %v", err)
+	}
+
+	// Resizing makes it fit
+	result, err = tool.Handler(context.Background(), map[string]interface{}{
+		"input":        testImagePath,
+		"max_size":     "2KB",
+		"allow_resize": true,
+		"output":       filepath.Join(tmpDir, "tiny.jpg"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result["resized"] != true || result["new_size"] == "400x300" {
+		t.Errorf("resized = %v, new_size = %v", result["resized"], result["new_size"])
+	}
+
+	// Invalid sizes
+	_, err = tool.Handler(context.Background(), map[string]interface{}{
+		"input":    testImagePath,
+		"max_size": "lots",
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid max_size") {
+		t.Errorf("Expected invalid max_size error, got %v", err)
+	}
+}
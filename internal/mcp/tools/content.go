@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+
+	gimaging "github.com/apresai/gimage/internal/imaging"
+	"github.com/apresai/gimage/internal/mcp"
+)
+
+// return_image values: how much of each output image a tool result carries
+const (
+	returnImageNone    = "none"    // resource link only
+	returnImagePreview = "preview" // downscaled copy, the default
+	returnImageFull    = "full"    // the file itself
+)
+
+// previewSize is the longest side of an image preview in pixels
+const previewSize = 512
+
+// maxImageContent caps the size of a full image in a tool result; larger
+// files, and formats clients cannot display, are sent as a preview
+const maxImageContent = 4 << 20
+
+// displayableTypes are the image types MCP clients render inline
+var displayableTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// returnImageProperty returns the schema for the common return_image argument
+func returnImageProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": fmt.Sprintf("Image content to return so you can see the result: preview (default, longest side %dpx), full (the file itself, up to %s) or none (only a resource link to the file)", previewSize, formatBytes(maxImageContent)),
+		"enum":        []string{returnImagePreview, returnImageFull, returnImageNone},
+		"default":     returnImagePreview,
+	}
+}
+
+// returnImageArg parses the common return_image argument
+func returnImageArg(args map[string]interface{}) (string, error) {
+	value, _ := args["return_image"].(string)
+	switch value {
+	case "":
+		return returnImagePreview, nil
+	case returnImagePreview, returnImageFull, returnImageNone:
+		return value, nil
+	default:
+		return "", invalidInput("invalid return_image: %s (supported: preview, full, none)", value)
+	}
+}
+
+// withOutputContent attaches content blocks for the files a tool wrote to
+// result: the image itself or a preview according to mode, then a resource
+// link to each file. An image that cannot be read back is only linked.
+func withOutputContent(result map[string]interface{}, mode string, paths ...string) map[string]interface{} {
+	var content []map[string]interface{}
+	for _, path := range paths {
+		mimeType := imageMimeType(path)
+		if mode != returnImageNone {
+			if block, err := imageContent(path, mimeType, mode); err == nil {
+				content = append(content, block)
+			}
+		}
+		content = append(content, mcp.ResourceLinkContent(path, mimeType))
+	}
+	result[mcp.ResultContentKey] = content
+	return result
+}
+
+// imageContent returns an image content block for the file at path
+func imageContent(path, mimeType, mode string) (map[string]interface{}, error) {
+	if mode == returnImageFull && displayableTypes[mimeType] {
+		if info, err := os.Stat(path); err == nil && info.Size() <= maxImageContent {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			return mcp.ImageContent(data, mimeType), nil
+		}
+	}
+
+	// The preview shows the first frame, as JPEG unless it has transparency
+	p, err := gimaging.OpenPipeline(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Frame(0).ResizeFit(previewSize, previewSize).Err(); err != nil {
+		return nil, err
+	}
+	format := "jpeg"
+	if img, ok := p.Image().(interface{ Opaque() bool }); ok && !img.Opaque() {
+		format = "png"
+	}
+	data, err := p.Metadata(gimaging.MetadataStrip).Convert(format).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return mcp.ImageContent(data, "image/"+format), nil
+}
+
+// imageMimeType returns the MIME type of an image file from its extension
+func imageMimeType(path string) string {
+	return "image/" + gimaging.ExtractFormatFromPath(path)
+}
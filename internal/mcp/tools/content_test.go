@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apresai/gimage/internal/config"
+	"github.com/apresai/gimage/internal/mcp"
+)
+
+// writeTestPNG writes a width x height PNG filled with c
+func writeTestPNG(t *testing.T, path string, width, height int, c color.NRGBA) {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write test image: %v", err)
+	}
+}
+
+// decodeImageBlock decodes the image of an image content block
+func decodeImageBlock(t *testing.T, block map[string]interface{}) (image.Image, string) {
+	t.Helper()
+	if block["type"] != mcp.ContentTypeImage {
+		t.Fatalf("Expected an image block, got %v", block["type"])
+	}
+	data, err := base64.StdEncoding.DecodeString(block["data"].(string))
+	if err != nil {
+		t.Fatalf("Invalid base64 image data: %v", err)
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to decode image block: %v", err)
+	}
+	return img, format
+}
+
+func TestWithOutputContent(t *testing.T) {
+	tmpDir := t.TempDir()
+	opaque := filepath.Join(tmpDir, "opaque.png")
+	writeTestPNG(t, opaque, 1200, 600, color.NRGBA{200, 100, 50, 255})
+	transparent := filepath.Join(tmpDir, "transparent.png")
+	writeTestPNG(t, transparent, 300, 300, color.NRGBA{200, 100, 50, 128})
+
+	// The default preview is downscaled, and JPEG unless transparent
+	content := withOutputContent(map[string]interface{}{}, returnImagePreview, opaque, transparent)[mcp.ResultContentKey].([]map[string]interface{})
+	if len(content) != 4 {
+		t.Fatalf("Expected an image and a link per file, got %d blocks", len(content))
+	}
+	img, format := decodeImageBlock(t, content[0])
+	if format != "jpeg" || content[0]["mimeType"] != "image/jpeg" {
+		t.Errorf("Expected a JPEG preview, got %s (%v)", format, content[0]["mimeType"])
+	}
+	if img.Bounds().Dx() != previewSize || img.Bounds().Dy() != previewSize/2 {
+		t.Errorf("Expected a %dx%d preview, got %v", previewSize, previewSize/2, img.Bounds().Size())
+	}
+	if content[1]["type"] != mcp.ContentTypeResourceLink || content[1]["uri"] != mcp.FileURI(opaque) || content[1]["mimeType"] != "image/png" {
+		t.Errorf("Unexpected resource link: %v", content[1])
+	}
+	img, format = decodeImageBlock(t, content[2])
+	if format != "png" || img.Bounds().Dx() != 300 {
+		t.Errorf("Expected a 300px PNG preview of the transparent image, got %s %v", format, img.Bounds().Size())
+	}
+
+	// full sends the file itself
+	content = withOutputContent(map[string]interface{}{}, returnImageFull, opaque)[mcp.ResultContentKey].([]map[string]interface{})
+	data, _ := os.ReadFile(opaque)
+	if content[0]["data"] != base64.StdEncoding.EncodeToString(data) || content[0]["mimeType"] != "image/png" {
+		t.Error("Expected the full file as image content")
+	}
+
+	// none only links, as does an unreadable file
+	content = withOutputContent(map[string]interface{}{}, returnImageNone, opaque)[mcp.ResultContentKey].([]map[string]interface{})
+	if len(content) != 1 || content[0]["type"] != mcp.ContentTypeResourceLink {
+		t.Errorf("Expected only a resource link, got %v", content)
+	}
+	content = withOutputContent(map[string]interface{}{}, returnImagePreview, filepath.Join(tmpDir, "missing.png"))[mcp.ResultContentKey].([]map[string]interface{})
+	if len(content) != 1 || content[0]["type"] != mcp.ContentTypeResourceLink {
+		t.Errorf("Expected only a resource link for a missing file, got %v", content)
+	}
+}
+
+func TestReturnImageArg(t *testing.T) {
+	for value, want := range map[string]string{"": returnImagePreview, "full": returnImageFull, "none": returnImageNone} {
+		got, err := returnImageArg(map[string]interface{}{"return_image": value})
+		if err != nil || got != want {
+			t.Errorf("returnImageArg(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := returnImageArg(map[string]interface{}{"return_image": "thumbnail"}); err == nil {
+		t.Error("Expected an error for an invalid return_image")
+	}
+}
+
+func TestResizeImageTool_Content(t *testing.T) {
+	tmpDir := t.TempDir()
+	input := filepath.Join(tmpDir, "input.png")
+	writeTestPNG(t, input, 200, 100, color.NRGBA{10, 20, 30, 255})
+	output := filepath.Join(tmpDir, "output.png")
+
+	server := mcp.NewMCPServer("test", "1.0.0", &config.Config{}, false)
+	RegisterResizeImageTool(server)
+	RegisterBatchResizeTool(server)
+
+	response := server.HandleRequest(t.Context(), &mcp.JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  mcp.MethodCallTool,
+		Params: map[string]interface{}{
+			"name":      "resize_image",
+			"arguments": map[string]interface{}{"input": input, "output": output, "width": 50.0, "height": 25.0},
+		},
+	})
+	if response.Error != nil {
+		t.Fatalf("Unexpected error: %v", response.Error)
+	}
+	content := response.Result["content"].([]map[string]interface{})
+	if len(content) != 3 || content[0]["type"] != mcp.ContentTypeText {
+		t.Fatalf("Expected text, image and resource_link blocks, got %v", content)
+	}
+	if img, _ := decodeImageBlock(t, content[1]); img.Bounds().Dx() != 50 {
+		t.Errorf("Expected a preview of the 50px output, got %v", img.Bounds().Size())
+	}
+	if content[2]["uri"] != mcp.FileURI(output) {
+		t.Errorf("Expected a link to %s, got %v", output, content[2]["uri"])
+	}
+
+	// The output is now a resource of the session
+	resources := server.Resources(t.Context())
+	if len(resources) != 1 || resources[0].Path != output {
+		t.Errorf("Expected the output as a resource, got %v", resources)
+	}
+
+	// Batch results link every output without images
+	outputDir := filepath.Join(tmpDir, "batch")
+	response = server.HandleRequest(t.Context(), &mcp.JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      2,
+		Method:  mcp.MethodCallTool,
+		Params: map[string]interface{}{
+			"name":      "batch_resize",
+			"arguments": map[string]interface{}{"input_dir": tmpDir, "output_dir": outputDir, "width": 20.0, "height": 10.0},
+		},
+	})
+	if response.Error != nil {
+		t.Fatalf("Unexpected error: %v", response.Error)
+	}
+	content = response.Result["content"].([]map[string]interface{})
+	if len(content) != 3 || content[1]["type"] != mcp.ContentTypeResourceLink || content[2]["type"] != mcp.ContentTypeResourceLink {
+		t.Errorf("Expected a resource link per output, got %v", content)
+	}
+}
@@ -1,10 +1,12 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
 
+	gimaging "github.com/apresai/gimage/internal/imaging"
 	"github.com/apresai/gimage/internal/mcp"
 )
 
@@ -12,7 +14,7 @@ import (
 func RegisterConvertImageTool(server *mcp.MCPServer) {
 	tool := mcp.Tool{
 		Name:        "convert_image",
-		Description: "Convert an image between different formats (PNG, JPG/JPEG, WebP, GIF, TIFF, BMP). Useful for web optimization (converting to WebP), compatibility (PNG to JPG), or specific application requirements. Format detection is automatic based on file extension.",
+		Description: "Convert an image between different formats (PNG, JPG/JPEG, WebP, AVIF, GIF, TIFF, BMP). HEIC/HEIF photos (e.g. from iPhones) and AVIF files are accepted as input. Useful for web optimization (converting to WebP or AVIF), compatibility (HEIC or PNG to JPG), or specific application requirements. Format detection is automatic based on file extension. Animated GIF and WebP stay animated when converted to GIF or WebP (e.g. GIF to a much smaller animated WebP); other formats get the first frame, or the one chosen with frame.",
 		InputSchema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -22,17 +24,33 @@ func RegisterConvertImageTool(server *mcp.MCPServer) {
 				},
 				"format": map[string]interface{}{
 					"type":        "string",
-					"enum":        []string{"png", "jpg", "jpeg", "webp", "gif", "tiff", "bmp"},
-					"description": "Target image format. WebP recommended for web, PNG for lossless, JPG for photos.",
+					"enum":        []string{"png", "jpg", "jpeg", "webp", "avif", "gif", "tiff", "bmp"},
+					"description": "Target image format. WebP recommended for web, AVIF for the smallest files, PNG for lossless, JPG for photos.",
 				},
+				"quality": map[string]interface{}{
+					"type":        "integer",
+					"description": "Lossy quality (1-100) for jpg, webp and avif. Default: jpg 90, webp lossless, avif 60.",
+					"minimum":     1,
+					"maximum":     100,
+				},
+				"speed": speedProperty(),
 				"output": map[string]interface{}{
 					"type":        "string",
 					"description": "Output file path. If not provided, generates filename with new extension (e.g., image.webp)",
 				},
+				"frame":        frameProperty(),
+				"metadata":     metadataProperty(),
+				"return_image": returnImageProperty(),
 			},
 			"required": []string{"input", "format"},
 		},
-		Handler: func(args map[string]interface{}) (map[string]interface{}, error) {
+		OutputSchema: imageOutputSchema(map[string]interface{}{
+			"original_format": outputProperty("string", "Input format"),
+			"new_format":      outputProperty("string", "Output format"),
+			"original_size":   outputProperty("string", "Input file size, human readable"),
+			"new_size":        outputProperty("string", "Output file size, human readable"),
+		}),
+		Handler: func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) {
 			// Validate input
 			inputArg, err := validateString(args["input"], "input")
 			if err != nil {
@@ -53,10 +71,36 @@ func RegisterConvertImageTool(server *mcp.MCPServer) {
 			format = strings.ToLower(format)
 			validFormats := map[string]bool{
 				"png": true, "jpg": true, "jpeg": true,
-				"webp": true, "gif": true, "tiff": true, "bmp": true,
+				"webp": true, "avif": true, "gif": true, "tiff": true, "bmp": true,
 			}
 			if !validFormats[format] {
-				return nil, fmt.Errorf("invalid format: %s (must be one of: png, jpg, jpeg, webp, gif, tiff, bmp)", format)
+				return nil, invalidInput("invalid format: %s (must be one of: png, jpg, jpeg, webp, avif, gif, tiff, bmp)", format)
+			}
+
+			// Optional encoder settings
+			quality := 0
+			if qualityVal, ok := args["quality"].(float64); ok {
+				quality = int(qualityVal)
+				if quality < 1 || quality > 100 {
+					return nil, invalidInput("quality must be between 1 and 100")
+				}
+			}
+			speed, err := speedArg(args)
+			if err != nil {
+				return nil, err
+			}
+
+			policy, err := metadataPolicyArg(args)
+			if err != nil {
+				return nil, err
+			}
+			returnImage, err := returnImageArg(args)
+			if err != nil {
+				return nil, err
+			}
+			frame, hasFrame, err := frameArg(args)
+			if err != nil {
+				return nil, err
 			}
 
 			// Determine output path
@@ -79,14 +123,21 @@ func RegisterConvertImageTool(server *mcp.MCPServer) {
 			originalExt := filepath.Ext(input)
 			originalFormat := strings.TrimPrefix(strings.ToLower(originalExt), ".")
 
-			// Load image
-			img, err := loadImage(input)
+			// Load image, upright according to its EXIF orientation
+			p, err := gimaging.OpenPipeline(input)
 			if err != nil {
 				return nil, fmt.Errorf("failed to load image: %w", err)
 			}
+			if hasFrame {
+				p.Frame(frame)
+			}
 
 			// Save in new format
-			err = saveImage(img, output)
+			p.Metadata(policy).Speed(speed)
+			if quality > 0 {
+				p.Compress(quality)
+			}
+			err = p.Save(output)
 			if err != nil {
 				return nil, fmt.Errorf("failed to save converted image: %w", err)
 			}
@@ -106,10 +157,13 @@ func RegisterConvertImageTool(server *mcp.MCPServer) {
 				"original_size":   formatBytes(originalSize),
 				"new_size":        formatBytes(newSize),
 			}
+			if p.Animated() {
+				result["frames"] = p.FrameCount()
+			}
 			if pathResult.Warning != "" {
 				result["warning"] = pathResult.Warning
 			}
-			return result, nil
+			return withOutputContent(result, returnImage, absPath), nil
 		},
 	}
 
@@ -1,6 +1,12 @@
 package tools
 
 import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -149,7 +155,7 @@ func TestConvertImageTool_ValidationErrors(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := tool.Handler(tt.args)
+			result, err := tool.Handler(context.Background(), tt.args)
 
 			if tt.wantError {
 				if err == nil {
@@ -264,3 +270,55 @@ func TestConvertImageTool_DefaultOutputPath(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertImageTool_AVIF(t *testing.T) {
+	tmpDir := t.TempDir()
+	input := filepath.Join(tmpDir, "photo.png")
+	img := image.NewRGBA(image.Rect(0, 0, 48, 32))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+	}
+	file, err := os.Create(input)
+	if err != nil {
+		t.Fatalf("Failed to create test image: %v", err)
+	}
+	png.Encode(file, img)
+	file.Close()
+
+	server := mcp.NewMCPServer("test", "1.0.0", nil, false)
+	RegisterConvertImageTool(server)
+	tool := server.GetTool("convert_image")
+
+	output := filepath.Join(tmpDir, "photo.avif")
+	_, err = tool.Handler(context.Background(), map[string]interface{}{
+		"input":   input,
+		"format":  "avif",
+		"quality": 50.0,
+		"speed":   10.0,
+		"output":  output,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Output is not a decodable image: %v", err)
+	}
+	if format != "avif" || config.Width != 48 || config.Height != 32 {
+		t.Errorf("Expected 48x32 avif, got %dx%d %s", config.Width, config.Height, format)
+	}
+
+	_, err = tool.Handler(context.Background(), map[string]interface{}{
+		"input":  input,
+		"format": "avif",
+		"speed":  11.0,
+	})
+	if err == nil || !strings.Contains(err.Error(), "speed must be between") {
+		t.Errorf("Expected speed error, got %v", err)
+	}
+}
@@ -1,19 +1,19 @@
 package tools
 
 import (
+	"context"
 	"fmt"
-	"image"
 	"path/filepath"
 
+	gimaging "github.com/apresai/gimage/internal/imaging"
 	"github.com/apresai/gimage/internal/mcp"
-	"github.com/disintegration/imaging"
 )
 
 // RegisterCropImageTool registers the crop_image tool
 func RegisterCropImageTool(server *mcp.MCPServer) {
 	tool := mcp.Tool{
 		Name:        "crop_image",
-		Description: "Crop an image to a specific rectangular region. Specify coordinates and dimensions to extract. Example: crop_image(input='photo.png', x=0, y=100, width=800, height=600, output='cropped.png') extracts an 800x600 region starting at position (0,100). IMPORTANT: All parameters (x, y, width, height) are positional integers, not flags. Coordinates start at (0,0) in the top-left corner. Useful for creating hero images, removing borders, or focusing on specific areas. TIP: Use get_image_info first to check actual image dimensions before cropping.",
+		Description: "Crop an image to a specific rectangular region. Specify coordinates and dimensions to extract. Example: crop_image(input='photo.png', x=0, y=100, width=800, height=600, output='cropped.png') extracts an 800x600 region starting at position (0,100). IMPORTANT: All parameters (x, y, width, height) are positional integers, not flags. Coordinates start at (0,0) in the top-left corner. Useful for creating hero images, removing borders, or focusing on specific areas. TIP: Use get_image_info first to check actual image dimensions before cropping. Set smart=true to let the tool pick the most interesting region instead (faces, products, detail) for a width x height thumbnail; x and y are then ignored, so pass 0. Animated GIF and WebP images are cropped frame by frame.",
 		InputSchema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -45,10 +45,23 @@ func RegisterCropImageTool(server *mcp.MCPServer) {
 					"type":        "string",
 					"description": "Output file path. If not provided, generates filename like input_cropped.ext",
 				},
+				"smart": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Pick the most interesting region with the aspect ratio of width x height, as large as the image allows, and scale it to exactly width x height. x and y are ignored. Default is false.",
+				},
+				"frame":        frameProperty(),
+				"metadata":     metadataProperty(),
+				"return_image": returnImageProperty(),
 			},
 			"required": []string{"input", "x", "y", "width", "height"},
 		},
-		Handler: func(args map[string]interface{}) (map[string]interface{}, error) {
+		OutputSchema: imageOutputSchema(map[string]interface{}{
+			"crop_region":   outputProperty("string", "Cropped region, (x,y,width,height)"),
+			"crop_size":     outputProperty("string", "Output dimensions, WIDTHxHEIGHT"),
+			"original_size": outputProperty("string", "Source dimensions, WIDTHxHEIGHT (smart crop only)"),
+			"smart":         outputProperty("boolean", "Whether the region was chosen by smart crop"),
+		}),
+		Handler: func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) {
 			// Validate input
 			inputArg, err := validateString(args["input"], "input")
 			if err != nil {
@@ -59,29 +72,30 @@ func RegisterCropImageTool(server *mcp.MCPServer) {
 				return nil, fmt.Errorf("input validation failed: %w", err)
 			}
 
-			// Validate coordinates and dimensions
+			// Validate coordinates and dimensions; a smart crop picks its own
+			smart, _ := args["smart"].(bool)
 			x, err := validatePositiveInt(args["x"], "x")
-			if err != nil {
+			if err != nil && !smart {
 				// x can be 0, so check differently
 				xVal, ok := args["x"].(float64)
 				if !ok {
-					return nil, fmt.Errorf("x must be a number")
+					return nil, invalidInput("x must be a number")
 				}
 				if xVal < 0 {
-					return nil, fmt.Errorf("x must be non-negative")
+					return nil, invalidInput("x must be non-negative")
 				}
 				x = int(xVal)
 			}
 
 			y, err := validatePositiveInt(args["y"], "y")
-			if err != nil {
+			if err != nil && !smart {
 				// y can be 0, so check differently
 				yVal, ok := args["y"].(float64)
 				if !ok {
-					return nil, fmt.Errorf("y must be a number")
+					return nil, invalidInput("y must be a number")
 				}
 				if yVal < 0 {
-					return nil, fmt.Errorf("y must be non-negative")
+					return nil, invalidInput("y must be non-negative")
 				}
 				y = int(yVal)
 			}
@@ -96,6 +110,19 @@ func RegisterCropImageTool(server *mcp.MCPServer) {
 				return nil, err
 			}
 
+			policy, err := metadataPolicyArg(args)
+			if err != nil {
+				return nil, err
+			}
+			returnImage, err := returnImageArg(args)
+			if err != nil {
+				return nil, err
+			}
+			frame, hasFrame, err := frameArg(args)
+			if err != nil {
+				return nil, err
+			}
+
 			// Determine output path
 			outputArg, _ := args["output"].(string)
 			defaultFilename := generateOutputPath(input, "cropped")
@@ -105,32 +132,35 @@ func RegisterCropImageTool(server *mcp.MCPServer) {
 			}
 			output := pathResult.Path
 
-			// Load image
-			img, err := loadImage(input)
+			// Load image, upright according to its EXIF orientation
+			p, err := gimaging.OpenPipeline(input)
 			if err != nil {
 				return nil, fmt.Errorf("failed to load image: %w", err)
 			}
+			if hasFrame {
+				p.Frame(frame)
+			}
 
-			// Get image bounds
-			bounds := img.Bounds()
+			if smart {
+				result, err := smartCrop(p, policy, width, height, output, pathResult.Warning)
+				if err != nil {
+					return nil, err
+				}
+				return withOutputContent(result, returnImage, result["output_path"].(string)), nil
+			}
 
 			// Validate crop region is within image bounds
+			imgWidth, imgHeight := p.Size()
 			if x < 0 || y < 0 {
-				return nil, fmt.Errorf("x and y coordinates must be non-negative")
+				return nil, invalidInput("x and y coordinates must be non-negative")
 			}
-			if x+width > bounds.Dx() || y+height > bounds.Dy() {
-				return nil, fmt.Errorf("crop region (%d,%d,%d,%d) extends beyond image bounds (%dx%d)",
-					x, y, width, height, bounds.Dx(), bounds.Dy())
+			if x+width > imgWidth || y+height > imgHeight {
+				return nil, invalidInput("crop region (%d,%d,%d,%d) extends beyond image bounds (%dx%d)",
+					x, y, width, height, imgWidth, imgHeight)
 			}
 
-			// Create crop rectangle
-			cropRect := image.Rect(x, y, x+width, y+height)
-
-			// Crop image
-			cropped := imaging.Crop(img, cropRect)
-
-			// Save cropped image
-			err = saveImage(cropped, output)
+			// Crop image and save it
+			err = p.Crop(x, y, width, height).Metadata(policy).Save(output)
 			if err != nil {
 				return nil, fmt.Errorf("failed to save cropped image: %w", err)
 			}
@@ -144,12 +174,42 @@ func RegisterCropImageTool(server *mcp.MCPServer) {
 				"crop_region": fmt.Sprintf("(%d,%d,%d,%d)", x, y, width, height),
 				"crop_size":   fmt.Sprintf("%dx%d", width, height),
 			}
+			if p.Animated() {
+				result["frames"] = p.FrameCount()
+			}
 			if pathResult.Warning != "" {
 				result["warning"] = pathResult.Warning
 			}
-			return result, nil
+			return withOutputContent(result, returnImage, absPath), nil
 		},
 	}
 
 	server.RegisterTool(tool)
 }
+
+// smartCrop crops the most interesting width x height region of p, saves it
+// to output and describes the result
+func smartCrop(p *gimaging.Pipeline, policy gimaging.MetadataPolicy, width, height int, output, warning string) (map[string]interface{}, error) {
+	origWidth, origHeight := p.Size()
+	region := gimaging.SmartCropRegion(p.Image(), width, height)
+	if err := p.SmartCrop(width, height).Metadata(policy).Save(output); err != nil {
+		return nil, fmt.Errorf("failed to save cropped image: %w", err)
+	}
+
+	absPath, _ := filepath.Abs(output)
+	result := map[string]interface{}{
+		"success":       true,
+		"output_path":   absPath,
+		"original_size": fmt.Sprintf("%dx%d", origWidth, origHeight),
+		"crop_region":   fmt.Sprintf("(%d,%d,%d,%d)", region.Min.X, region.Min.Y, region.Dx(), region.Dy()),
+		"crop_size":     fmt.Sprintf("%dx%d", width, height),
+		"smart":         true,
+	}
+	if p.Animated() {
+		result["frames"] = p.FrameCount()
+	}
+	if warning != "" {
+		result["warning"] = warning
+	}
+	return result, nil
+}
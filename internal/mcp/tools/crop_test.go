@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"image"
 	"image/color"
 	"image/png"
@@ -250,7 +251,7 @@ func TestCropImageTool(t *testing.T) {
 				t.Fatal("crop_image tool not registered")
 			}
 
-			result, err := tool.Handler(tt.args)
+			result, err := tool.Handler(context.Background(), tt.args)
 
 			if tt.wantError {
 				if err == nil {
@@ -407,3 +408,67 @@ func TestCropImageToolSchema(t *testing.T) {
 		}
 	}
 }
+
+func TestCropImageTool_Smart(t *testing.T) {
+	tmpDir := t.TempDir()
+	server := mcp.NewMCPServer("test", "1.0.0", &config.Config{}, false)
+	RegisterCropImageTool(server)
+	tool := server.GetTool("crop_image")
+
+	// A plain 400x200 image with a detailed patch near the right edge
+	inputPath := filepath.Join(tmpDir, "wide.png")
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			c := color.RGBA{128, 128, 128, 255}
+			if x >= 320 && x < 360 && y >= 80 && y < 120 && (x/4+y/4)%2 == 0 {
+				c = color.RGBA{255, 255, 255, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	file, err := os.Create(inputPath)
+	if err != nil {
+		t.Fatalf("Failed to create test image: %v", err)
+	}
+	png.Encode(file, img)
+	file.Close()
+
+	outputPath := filepath.Join(tmpDir, "thumb.png")
+	result, err := tool.Handler(context.Background(), map[string]interface{}{
+		"input":  inputPath,
+		"x":      0.0,
+		"y":      0.0,
+		"width":  100.0,
+		"height": 100.0,
+		"smart":  true,
+		"output": outputPath,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result["crop_region"] != "(200,0,200,200)" {
+		t.Errorf("Expected crop_region (200,0,200,200), got %v", result["crop_region"])
+	}
+	width, height, err := getImageDimensions(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to get dimensions: %v", err)
+	}
+	if width != 100 || height != 100 {
+		t.Errorf("Expected 100x100, got %dx%d", width, height)
+	}
+
+	// x and y are not checked against the image for a smart crop
+	_, err = tool.Handler(context.Background(), map[string]interface{}{
+		"input":  inputPath,
+		"x":      -1.0,
+		"y":      500.0,
+		"width":  640.0,
+		"height": 480.0,
+		"smart":  true,
+		"output": outputPath,
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apresai/gimage/internal/config"
+	"github.com/apresai/gimage/internal/generate"
+	"github.com/apresai/gimage/internal/mcp"
+	"github.com/apresai/gimage/pkg/models"
+)
+
+// RegisterEditImageTool registers the edit_image tool
+func RegisterEditImageTool(server *mcp.MCPServer) {
+	tool := mcp.Tool{
+		Name:        "edit_image",
+		Description: "Edit an existing image from a text instruction (e.g., 'make the sky purple'). Gemini 2.5 Flash is FREE with conversational editing; by default the provider is picked from the configuration like generate_image. Use model='nova-canvas' for AWS Bedrock Nova Canvas: mode='variation' regenerates the whole image guided by the source (strength controls similarity), mode='inpainting' changes only the region described by mask_prompt or painted black in a mask image. The edited image is saved to a new file; the input is never modified.",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: false, // Writes a new file, input is untouched
+			IdempotentHint:  false, // Each call produces a different edit
+			ReadOnlyHint:    false, // Writes files to disk
+		},
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"input": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to the source image (PNG or JPEG recommended)",
+				},
+				"prompt": map[string]interface{}{
+					"type":        "string",
+					"description": "Edit instruction describing the change (e.g., 'make the sky purple', 'add a hot air balloon')",
+				},
+				"output": map[string]interface{}{
+					"type":        "string",
+					"description": "Output file path (default: input_edited.ext). Supports tilde (~) expansion.",
+				},
+				"model": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"auto", "gemini", "gemini/flash-2.5", "nova-canvas", "bedrock/nova-canvas"},
+					"description": "Provider to use: 'gemini' (FREE) or 'nova-canvas' ($0.08/image, supports masks). 'auto' (default) picks the provider like generate_image does, from the config default_api and the configured credentials.",
+					"default":     "auto",
+				},
+				"mode": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{generate.EditModeVariation, generate.EditModeInpainting},
+					"description": "Edit mode (Nova Canvas). Default: inpainting if a mask is given, otherwise variation.",
+				},
+				"mask": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a mask image for inpainting (black = area to edit). Nova Canvas only.",
+				},
+				"mask_prompt": map[string]interface{}{
+					"type":        "string",
+					"description": "Natural language description of the area to edit (e.g., 'the sky'). Alternative to a mask image.",
+				},
+				"strength": map[string]interface{}{
+					"type":        "number",
+					"minimum":     0.2,
+					"maximum":     1.0,
+					"description": "Similarity to the source image for variations (0.2-1.0). Nova Canvas only.",
+				},
+				"negative": map[string]interface{}{
+					"type":        "string",
+					"description": "Negative prompt - describe what you DON'T want in the result",
+				},
+				"seed": map[string]interface{}{
+					"type":        "integer",
+					"description": "Random seed for reproducible edits (Nova Canvas)",
+				},
+				"return_image": returnImageProperty(),
+			},
+			"required": []string{"input", "prompt"},
+		},
+		OutputSchema: imageOutputSchema(map[string]interface{}{
+			"input_path":    outputProperty("string", "Path of the edited image"),
+			"size":          outputProperty("string", "Output dimensions, WIDTHxHEIGHT"),
+			"model":         outputProperty("string", "Provider that edited the image"),
+			"model_display": outputProperty("string", "Display name of the provider"),
+			"pricing":       outputProperty("string", "Cost of the edit"),
+			"prompt":        outputProperty("string", "Edit instruction"),
+			"mode":          outputProperty("string", "Edit mode, when one applied"),
+			"message":       outputProperty("string", "Summary of the edit"),
+		}),
+		Handler: func(ctx context.Context, args map[string]interface{}) (map[string]interface{}, error) {
+			// Validate input file path
+			inputArg, err := validateString(args["input"], "input")
+			if err != nil {
+				return nil, err
+			}
+			input, err := ValidateInputPath(inputArg)
+			if err != nil {
+				return nil, fmt.Errorf("input validation failed: %w", err)
+			}
+
+			prompt, err := validateString(args["prompt"], "prompt")
+			if err != nil {
+				return nil, err
+			}
+			returnImage, err := returnImageArg(args)
+			if err != nil {
+				return nil, err
+			}
+
+			// Validate and fix output path before calling the provider
+			outputArg, _ := args["output"].(string)
+			defaultFilename := generateOutputPath(input, "edited")
+			pathResult, pathErr := ValidateAndFixOutputPath(outputArg, defaultFilename)
+			if pathErr != nil {
+				return nil, fmt.Errorf("output path validation failed: %w", pathErr)
+			}
+			output := pathResult.Path
+
+			imageData, err := os.ReadFile(input)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read input image: %w", err)
+			}
+
+			edit := models.EditOptions{
+				Image: imageData,
+			}
+			edit.Mode, _ = args["mode"].(string)
+			edit.MaskPrompt, _ = args["mask_prompt"].(string)
+			edit.Strength, _ = args["strength"].(float64)
+
+			if maskArg, _ := args["mask"].(string); maskArg != "" {
+				maskPath, err := ValidateInputPath(maskArg)
+				if err != nil {
+					return nil, fmt.Errorf("mask validation failed: %w", err)
+				}
+				edit.Mask, err = os.ReadFile(maskPath)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read mask image: %w", err)
+				}
+			}
+
+			// A mask implies inpainting
+			if edit.Mode == "" && (len(edit.Mask) > 0 || edit.MaskPrompt != "") {
+				edit.Mode = generate.EditModeInpainting
+			}
+
+			modelName, _ := args["model"].(string)
+			if modelName == "" {
+				modelName = generate.AutoProvider
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load config: %w", err)
+			}
+
+			client, provider, err := generate.GetProviderRegistry().ResolveEditor(modelName, cfg)
+			if err != nil {
+				if provider != nil && !provider.Capabilities.SupportsEditing {
+					return nil, mcp.NewToolError(mcp.ToolErrorInvalidInput, err, hintModels)
+				}
+				return nil, clientError(fmt.Errorf("failed to create client: %w", err), provider, modelName)
+			}
+			defer client.Close()
+			editor := client.(generate.ImageEditor)
+
+			negative, _ := args["negative"].(string)
+
+			var seed int64
+			if seedVal, ok := args["seed"].(float64); ok {
+				seed = int64(seedVal)
+			}
+
+			opts := models.GenerateOptions{
+				Model:          provider.ModelID,
+				NegativePrompt: negative,
+				Seed:           seed,
+			}
+
+			editedImage, err := editor.EditImage(ctx, prompt, edit, opts)
+			if err != nil {
+				return nil, providerError(fmt.Errorf("image edit failed: %w", err))
+			}
+
+			if err := generate.SaveImage(editedImage, output); err != nil {
+				return nil, fmt.Errorf("failed to save image: %w", err)
+			}
+
+			absOutput, err := filepath.Abs(output)
+			if err != nil {
+				absOutput = output
+			}
+
+			pricingInfo := "Variable"
+			if provider.Pricing.FreeTier {
+				pricingInfo = fmt.Sprintf("FREE (%s)", provider.Pricing.FreeTierLimit)
+			} else if provider.Pricing.CostPerImage != nil {
+				pricingInfo = fmt.Sprintf("$%.4f/image", *provider.Pricing.CostPerImage)
+			}
+
+			result := map[string]interface{}{
+				"success":       true,
+				"input_path":    input,
+				"output_path":   absOutput,
+				"size":          fmt.Sprintf("%dx%d", editedImage.Width, editedImage.Height),
+				"model":         provider.ID,
+				"model_display": provider.Name,
+				"pricing":       pricingInfo,
+				"prompt":        prompt,
+				"message":       fmt.Sprintf("Edited using %s (%s)", provider.Name, pricingInfo),
+			}
+
+			if editMode := editedImage.Metadata["edit_mode"]; editMode != "" {
+				result["mode"] = editMode
+			}
+
+			// Add warning if path was adjusted
+			if pathResult.Warning != "" {
+				result["warning"] = pathResult.Warning
+			}
+
+			return withOutputContent(result, returnImage, absOutput), nil
+		},
+	}
+
+	server.RegisterTool(tool)
+}
@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apresai/gimage/internal/mcp"
+)
+
+func TestRegisterEditImageTool(t *testing.T) {
+	server := mcp.NewMCPServer("test", "1.0.0", nil, false)
+
+	// Register the tool
+	RegisterEditImageTool(server)
+
+	// Verify tool is registered
+	tool := server.GetTool("edit_image")
+	if tool == nil {
+		t.Fatal("edit_image tool not registered")
+	}
+
+	if tool.Description == "" {
+		t.Error("Tool description is empty")
+	}
+
+	if tool.Handler == nil {
+		t.Error("Tool handler is nil")
+	}
+
+	// Verify annotations
+	if tool.Annotations == nil {
+		t.Fatal("Tool annotations are nil")
+	}
+
+	if tool.Annotations.DestructiveHint {
+		t.Error("edit_image should not be destructive (input is never modified)")
+	}
+
+	if tool.Annotations.ReadOnlyHint {
+		t.Error("edit_image should not be read-only (writes files)")
+	}
+}
+
+func TestEditImageTool_InputSchema(t *testing.T) {
+	server := mcp.NewMCPServer("test", "1.0.0", nil, false)
+	RegisterEditImageTool(server)
+
+	tool := server.GetTool("edit_image")
+	if tool == nil {
+		t.Fatal("edit_image tool not registered")
+	}
+
+	required, ok := tool.InputSchema["required"].([]string)
+	if !ok {
+		t.Fatal("required field is not a string slice")
+	}
+
+	if len(required) != 2 || required[0] != "input" || required[1] != "prompt" {
+		t.Errorf("Expected required=['input', 'prompt'], got %v", required)
+	}
+
+	properties, ok := tool.InputSchema["properties"].(map[string]interface{})
+	if !ok {
+		t.Fatal("properties field is not a map")
+	}
+
+	optionalProps := []string{"output", "model", "mode", "mask", "mask_prompt", "strength", "negative", "seed"}
+	for _, prop := range optionalProps {
+		if _, exists := properties[prop]; !exists {
+			t.Errorf("optional property '%s' missing", prop)
+		}
+	}
+}
+
+func TestEditImageTool_ValidationErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Create a small test image
+	testImagePath := filepath.Join(tmpDir, "test.png")
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, color.RGBA{100, 150, 200, 255})
+		}
+	}
+	file, err := os.Create(testImagePath)
+	if err != nil {
+		t.Fatalf("Failed to create test image: %v", err)
+	}
+	png.Encode(file, img)
+	file.Close()
+
+	server := mcp.NewMCPServer("test", "1.0.0", nil, false)
+	RegisterEditImageTool(server)
+
+	tool := server.GetTool("edit_image")
+	if tool == nil {
+		t.Fatal("edit_image tool not registered")
+	}
+
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		errorMsg string
+	}{
+		{
+			name:     "missing input",
+			args:     map[string]interface{}{"prompt": "make the sky purple"},
+			errorMsg: "input is required",
+		},
+		{
+			name: "nonexistent input",
+			args: map[string]interface{}{
+				"input":  filepath.Join(tmpDir, "missing.png"),
+				"prompt": "make the sky purple",
+			},
+			errorMsg: "input validation failed",
+		},
+		{
+			name:     "missing prompt",
+			args:     map[string]interface{}{"input": testImagePath},
+			errorMsg: "prompt is required",
+		},
+		{
+			name: "provider without editing support",
+			args: map[string]interface{}{
+				"input":  testImagePath,
+				"prompt": "make the sky purple",
+				"model":  "imagen-4",
+				"output": filepath.Join(tmpDir, "edited.png"),
+			},
+			errorMsg: "does not support image editing",
+		},
+		{
+			name: "unknown model",
+			args: map[string]interface{}{
+				"input":  testImagePath,
+				"prompt": "make the sky purple",
+				"model":  "not-a-model",
+				"output": filepath.Join(tmpDir, "edited.png"),
+			},
+			errorMsg: "no provider found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Handler(context.Background(), tt.args)
+			if err == nil {
+				t.Fatalf("Expected error containing '%s', got nil", tt.errorMsg)
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error containing '%s', got '%v'", tt.errorMsg, err)
+			}
+			if result != nil {
+				t.Errorf("Expected nil result on error, got %v", result)
+			}
+		})
+	}
+}
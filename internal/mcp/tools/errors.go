@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/apresai/gimage/internal/generate"
+	"github.com/apresai/gimage/internal/mcp"
+)
+
+// Hints returned with tool errors, telling the model how to fix the call
+const (
+	hintInputPath  = "Check the path exists; relative paths resolve against the server's working directory, so prefer absolute paths"
+	hintOutputPath = "Pass an output path in a writable directory, e.g. ~/Desktop/image.png"
+	hintDirectory  = "Check the directory exists and is writable; prefer absolute paths"
+	hintModels     = "Call list_models to see the available models"
+	hintQuota      = "Wait a minute before retrying, or pick another model from list_models"
+	hintProvider   = "Retry later, or pick another model from list_models"
+	hintContent    = "Rephrase the prompt; the provider's safety filters rejected it"
+)
+
+// invalidInput returns an invalid_input tool error for a missing or out of
+// range argument
+func invalidInput(format string, a ...interface{}) error {
+	return mcp.NewToolError(mcp.ToolErrorInvalidInput, fmt.Errorf(format, a...), "")
+}
+
+// invalidPath returns an invalid_path tool error with hint
+func invalidPath(hint, format string, a ...interface{}) error {
+	return mcp.NewToolError(mcp.ToolErrorInvalidPath, fmt.Errorf(format, a...), hint)
+}
+
+// providerError classifies a failed provider call, so the client can tell
+// a quota error worth retrying later from a provider that is down and from
+// a prompt the provider refused. Cancellation and unrecognized errors are
+// returned as they are.
+func providerError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	var toolErr *mcp.ToolError
+	if errors.As(err, &toolErr) {
+		return err
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case containsAny(msg, "quota", "429", "rate limit", "resource_exhausted", "resource exhausted", "too many requests", "throttl"):
+		return mcp.NewToolError(mcp.ToolErrorQuotaExceeded, err, hintQuota)
+	case containsAny(msg, "safety", "content blocked", "content policy"):
+		return mcp.NewToolError(mcp.ToolErrorContentRejected, err, hintContent)
+	case generate.IsFailoverError(err):
+		return mcp.NewToolError(mcp.ToolErrorProviderUnavailable, err, hintProvider)
+	}
+	return err
+}
+
+// clientError classifies a failure to create a provider client: no provider
+// matched model, or the provider is not set up
+func clientError(err error, provider *generate.Provider, model string) error {
+	if provider == nil && model != generate.AutoProvider {
+		return mcp.NewToolError(mcp.ToolErrorInvalidInput, err, hintModels)
+	}
+	hint := "Configure a provider with: gimage auth setup <provider>; list_models shows the providers"
+	if provider != nil {
+		hint = fmt.Sprintf("Configure the provider with: gimage auth setup %s, or pick another model from list_models", provider.ID)
+	}
+	return mcp.NewToolError(mcp.ToolErrorProviderUnavailable, err, hint)
+}
+
+func containsAny(s string, substrings ...string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/apresai/gimage/internal/config"
+	"github.com/apresai/gimage/internal/mcp"
+)
+
+func TestProviderError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want mcp.ToolErrorCode
+	}{
+		{errors.New("API error 429: quota exceeded"), mcp.ToolErrorQuotaExceeded},
+		{errors.New("RESOURCE_EXHAUSTED: too many requests"), mcp.ToolErrorQuotaExceeded},
+		{errors.New("content blocked by safety filters"), mcp.ToolErrorContentRejected},
+		{errors.New("API error 503: service unavailable"), mcp.ToolErrorProviderUnavailable},
+		{fmt.Errorf("request timeout: %w", context.DeadlineExceeded), mcp.ToolErrorProviderUnavailable},
+		{errors.New("invalid image data"), ""},
+		{fmt.Errorf("generation: %w", context.Canceled), ""},
+	}
+
+	for _, tt := range tests {
+		err := providerError(tt.err)
+		var toolErr *mcp.ToolError
+		if !errors.As(err, &toolErr) {
+			if tt.want != "" {
+				t.Errorf("providerError(%q) = %v, want code %s", tt.err, err, tt.want)
+			}
+			continue
+		}
+		if toolErr.Code != tt.want || toolErr.Hint == "" || !errors.Is(err, tt.err) {
+			t.Errorf("providerError(%q) = %s (%q), want code %s with a hint wrapping the error", tt.err, toolErr.Code, toolErr.Hint, tt.want)
+		}
+	}
+}
+
+func TestToolErrorCodes(t *testing.T) {
+	t.Setenv("GIMAGE_MOCK_ERROR", "")
+
+	tmpDir := t.TempDir()
+	server := mcp.NewMCPServer("test", "1.0.0", &config.Config{}, false)
+	RegisterCompressImageTool(server)
+	RegisterGenerateImageTool(server)
+	RegisterBatchResizeTool(server)
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]interface{}
+		want mcp.ToolErrorCode
+	}{
+		{"missing input", "compress_image", map[string]interface{}{"input": filepath.Join(tmpDir, "missing.png")}, mcp.ToolErrorInvalidPath},
+		{"missing argument", "compress_image", map[string]interface{}{}, mcp.ToolErrorInvalidInput},
+		{"empty directory", "batch_resize", map[string]interface{}{"input_dir": tmpDir, "output_dir": filepath.Join(tmpDir, "out"), "width": 10.0, "height": 10.0}, mcp.ToolErrorInvalidPath},
+		{"bad count", "generate_image", map[string]interface{}{"prompt": "test", "count": 11.0, "output": filepath.Join(tmpDir, "a.png")}, mcp.ToolErrorInvalidInput},
+		{"unknown model", "generate_image", map[string]interface{}{"prompt": "test", "model": "no-such-model", "output": filepath.Join(tmpDir, "b.png")}, mcp.ToolErrorInvalidInput},
+		{"quota", "generate_image", map[string]interface{}{"prompt": "test [mock:quota]", "model": "local/mock", "output": filepath.Join(tmpDir, "c.png")}, mcp.ToolErrorQuotaExceeded},
+		{"safety", "generate_image", map[string]interface{}{"prompt": "test [mock:safety]", "model": "local/mock", "output": filepath.Join(tmpDir, "d.png")}, mcp.ToolErrorContentRejected},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := server.HandleRequest(t.Context(), &mcp.JSONRPCRequest{
+				JSONRPC: "2.0",
+				ID:      i,
+				Method:  mcp.MethodCallTool,
+				Params:  map[string]interface{}{"name": tt.tool, "arguments": tt.args},
+			})
+			if response.Error != nil {
+				t.Fatalf("Expected an isError result, got error: %v", response.Error)
+			}
+			if response.Result["isError"] != true {
+				t.Fatalf("Expected isError, got %v", response.Result)
+			}
+			details := response.Result["structuredContent"].(map[string]interface{})["error"].(map[string]interface{})
+			if details["code"] != tt.want {
+				t.Errorf("code = %v, want %s (%v)", details["code"], tt.want, details["message"])
+			}
+		})
+	}
+}
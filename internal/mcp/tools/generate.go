@@ -9,6 +9,7 @@ import (
 	"github.com/apresai/gimage/internal/config"
 	"github.com/apresai/gimage/internal/generate"
 	"github.com/apresai/gimage/internal/mcp"
+	"github.com/apresai/gimage/internal/progress"
 	"github.com/apresai/gimage/pkg/models"
 )
 
@@ -35,15 +36,29 @@ func RegisterGenerateImageTool(server *mcp.MCPServer) {
 				},
 				"size": map[string]interface{}{
 					"type":        "string",
-					"enum":        []string{"256x256", "512x512", "1024x1024", "1024x1792", "1792x1024", "2048x2048"},
-					"description": "Image dimensions (WIDTHxHEIGHT). Default: 1024x1024. Provider limits: gemini/flash-2.5 up to 1024x1024, vertex/imagen-4 up to 2048x2048, bedrock/nova-canvas up to 1408x1408. Examples: '1024x1024' (square), '1792x1024' (16:9 landscape), '1024x1792' (9:16 portrait), '2048x2048' (ultra HD with imagen-4).",
+					"pattern":     "^[0-9]+x[0-9]+$",
+					"description": "Image dimensions (WIDTHxHEIGHT), any size. Default: 1024x1024 (omit when using aspect_ratio). The size is snapped to the nearest native size of the chosen provider, which renders it, then the image is fitted to exactly this size (see fit). Provider limits: gemini/flash-2.5 up to 1536x672, vertex/imagen-4 up to 2816x1536, bedrock/nova-canvas up to 2048x2048. Examples: '1024x1024' (square), '1920x1080' (16:9 hero image), '1024x1792' (9:16 portrait), '2048x2048' (ultra HD with imagen-4).",
 					"default":     "1024x1024",
 				},
+				"aspect_ratio": map[string]interface{}{
+					"type":        "string",
+					"description": "Aspect ratio such as '16:9', '4:3' or '9:16'. Without size, the provider's native size for the closest ratio is used (list_models shows each provider's aspect_ratios). With size, it must match the size.",
+				},
+				"fit": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{generate.FitCrop, generate.FitResize, generate.FitNone},
+					"description": "How to reach the exact size when the provider can't render it natively: 'crop' (default) scales to cover and center-crops, 'resize' stretches, 'none' keeps the provider's native size.",
+					"default":     generate.FitCrop,
+				},
 				"model": map[string]interface{}{
 					"type": "string",
 					"enum": []string{
+						"auto",
+						"gemini/flash-2.5",
+						"vertex/imagen-4",
+						"vertex/imagen-3",
+						"bedrock/nova-canvas",
 						"gemini-2.5-flash-image",
-						"gemini-2.0-flash-preview-image-generation",
 						"imagen-3.0-generate-002",
 						"imagen-4",
 						"gemini",
@@ -51,9 +66,14 @@ func RegisterGenerateImageTool(server *mcp.MCPServer) {
 						"imagen",
 						"nova-canvas",
 						"amazon.nova-canvas-v1:0",
+						"openai/images",
+						"openai",
+						"sd/a1111",
+						"sd/comfyui",
+						"local/mock",
 					},
-					"description": "Provider/model to use. Call list_models to see all options with pricing. Common choices: 'gemini' (FREE 500/day, gemini/flash-2.5 provider, up to 1024x1024), 'imagen-4' ($0.04/image, vertex/imagen-4 provider, up to 2048x2048, highest quality), 'nova-canvas' ($0.08/image, bedrock/nova-canvas provider, AWS integration). Aliases automatically resolve to correct provider. Falls back to gemini if invalid. TIP: Use gemini for iterations, imagen-4 for final production.",
-					"default":     "gemini-2.5-flash-image",
+					"description": "Provider ID, alias, or 'auto'. Call list_models to see all options with pricing. Common choices: 'gemini' (FREE 500/day, gemini/flash-2.5 provider, up to 1024x1024), 'imagen-4' ($0.04/image, vertex/imagen-4 provider, up to 2048x2048, highest quality), 'nova-canvas' ($0.08/image, bedrock/nova-canvas provider, AWS integration), 'openai' (openai/images provider, any OpenAI-compatible endpoint), 'sd/a1111' and 'sd/comfyui' (FREE, self-hosted Stable Diffusion; prompts stay on the local network), 'local/mock' (offline placeholder images for testing). 'auto' (default) picks a provider from the configured credentials, preferring the config default_api when several are set up. TIP: Use gemini for iterations, imagen-4 for final production.",
+					"default":     "auto",
 				},
 				"style": map[string]interface{}{
 					"type":        "string",
@@ -68,14 +88,40 @@ func RegisterGenerateImageTool(server *mcp.MCPServer) {
 					"type":        "integer",
 					"description": "Random seed for reproducible generation. Use the same seed to get the same image.",
 				},
+				"count": map[string]interface{}{
+					"type":        "integer",
+					"minimum":     1,
+					"maximum":     generate.MaxImageCount,
+					"description": "Number of candidate images to generate (default: 1). With count > 1, files are saved as name_1.png ... name_N.png, each with a .json metadata sidecar. Imagen and Nova Canvas return several images per API call; Gemini makes parallel calls. Paid providers charge per image.",
+					"default":     1,
+				},
+				"options": map[string]interface{}{
+					"type":                 "object",
+					"additionalProperties": true,
+					"description":          "Provider-specific options; list_models shows the keys each provider accepts. Examples: {\"cfgScale\": 8, \"quality\": \"premium\"} for nova-canvas, {\"personGeneration\": \"dont_allow\", \"addWatermark\": false} for Imagen, {\"sampler\": \"Euler a\", \"steps\": 30} for Stable Diffusion. Unsupported keys are rejected.",
+				},
+				"return_image": returnImageProperty(),
 			},
 			"required": []string{"prompt"},
 		},
-		Handler: func(args map[string]interface{}) (map[string]interface{}, error) {
+		OutputSchema: imageOutputSchema(map[string]interface{}{
+			"output_paths":  outputProperty("array", "Absolute paths of all images, with count > 1"),
+			"count":         outputProperty("integer", "Number of images, with count > 1"),
+			"size":          outputProperty("string", "Output dimensions, WIDTHxHEIGHT"),
+			"model":         outputProperty("string", "Model ID used"),
+			"provider":      outputProperty("string", "Provider that served the request"),
+			"model_display": outputProperty("string", "Display name of the provider"),
+			"api":           outputProperty("string", "API the provider uses"),
+			"pricing":       outputProperty("string", "Cost per image"),
+			"prompt":        outputProperty("string", "Prompt the images were generated from"),
+			"message":       outputProperty("string", "Summary of the generation"),
+			"failover_from": outputProperty("string", "Requested provider, when it was unavailable and another served the request"),
+		}),
+		Handler: func(ctx context.Context, args map[string]interface{}) (map[string]interface{}, error) {
 			// Extract and validate prompt
 			prompt, ok := args["prompt"].(string)
 			if !ok || prompt == "" {
-				return nil, fmt.Errorf("prompt is required and must be a non-empty string")
+				return nil, invalidInput("prompt is required and must be a non-empty string")
 			}
 
 			// Extract optional parameters
@@ -86,7 +132,7 @@ func RegisterGenerateImageTool(server *mcp.MCPServer) {
 			defaultFilename := fmt.Sprintf("generated_%d.png", time.Now().Unix())
 			pathResult, pathErr := ValidateAndFixOutputPath(outputArg, defaultFilename)
 			if pathErr != nil {
-				return nil, fmt.Errorf("output path validation failed: %w\n\nTIP: Try specifying an explicit output path like ~/Desktop/image.png or ~/Documents/image.png", pathErr)
+				return nil, invalidPath(hintOutputPath, "output path validation failed: %w", pathErr)
 			}
 			output := pathResult.Path
 
@@ -96,25 +142,25 @@ func RegisterGenerateImageTool(server *mcp.MCPServer) {
 				pathWarning = pathResult.Warning
 			}
 
+			returnImage, err := returnImageArg(args)
+			if err != nil {
+				return nil, err
+			}
+
 			size, _ := args["size"].(string)
-			if size == "" {
+			aspect, _ := args["aspect_ratio"].(string)
+			if size == "" && aspect == "" {
 				size = "1024x1024"
 			}
 
-			modelName, _ := args["model"].(string)
-			if modelName == "" {
-				modelName = "gemini-2.5-flash-image"
+			fit, _ := args["fit"].(string)
+			if err := generate.ValidateFitMode(fit); err != nil {
+				return nil, invalidInput("%w", err)
 			}
 
-			// Resolve model aliases to exact names (e.g., "gemini" -> "gemini-2.5-flash-image")
-			modelName = generate.ResolveModelName(modelName)
-
-			// Validate provider/model exists, fallback to default if not
-			registry := generate.GetProviderRegistry()
-			_, providerErr := registry.ResolveProvider(modelName)
-			if providerErr != nil {
-				// Provider not found, fallback to default free model
-				modelName = "gemini-2.5-flash-image"
+			modelName, _ := args["model"].(string)
+			if modelName == "" {
+				modelName = generate.AutoProvider
 			}
 
 			style, _ := args["style"].(string)
@@ -125,141 +171,154 @@ func RegisterGenerateImageTool(server *mcp.MCPServer) {
 				seed = int64(seedVal)
 			}
 
-			// Create generate options
-			opts := models.GenerateOptions{
-				Model:          modelName,
-				Size:           size,
-				Style:          style,
-				NegativePrompt: negative,
-				Seed:           seed,
+			count := 1
+			if countVal, ok := args["count"].(float64); ok {
+				count = int(countVal)
 			}
-
-			// Determine which backend to use based on model
-			selectedAPI := "gemini" // default
-			if isVertexModel(modelName) {
-				selectedAPI = "vertex"
+			if err := generate.ValidateImageCount(count); err != nil {
+				return nil, invalidInput("%w", err)
 			}
 
-			// Create context for API calls
-			ctx := context.Background()
-
-			// Generate based on backend
-			var generatedImage *models.GeneratedImage
-			var err error
-
-			if selectedAPI == "gemini" {
-				// Use Gemini REST client
-				apiKey, apiErr := config.GetGeminiAPIKey("")
-				if apiErr != nil {
-					return nil, fmt.Errorf("Gemini API key not configured: %w\nPlease run: gimage auth gemini", apiErr)
-				}
+			// Resolve the provider and create a client with the shared config
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load config: %w", err)
+			}
 
-				client, err := generate.NewGeminiRESTClient(apiKey)
-				if err != nil {
-					return nil, fmt.Errorf("failed to create Gemini client: %w", err)
-				}
-				defer client.Close()
+			registry := generate.GetProviderRegistry()
+			client, provider, err := registry.ResolveClient(modelName, cfg)
+			if err != nil {
+				return nil, clientError(fmt.Errorf("failed to create client: %w", err), provider, modelName)
+			}
+			defer client.Close()
 
-				generatedImage, err = client.GenerateImage(ctx, prompt, opts)
-				if err != nil {
-					return nil, fmt.Errorf("image generation failed: %w", err)
-				}
-			} else {
-				// Use Vertex AI
-				// Load config to get project and location
-				cfg, err := config.LoadConfig()
-				if err != nil {
-					return nil, fmt.Errorf("failed to load config: %w", err)
+			var extra map[string]interface{}
+			if optionsArg, ok := args["options"]; ok && optionsArg != nil {
+				optionsMap, ok := optionsArg.(map[string]interface{})
+				if !ok {
+					return nil, invalidInput("options must be an object of provider-specific settings")
 				}
-
-				project := cfg.VertexProject
-				location := cfg.VertexLocation
-				if location == "" {
-					location = "us-central1"
+				if extra, err = provider.ValidateOptions(optionsMap); err != nil {
+					return nil, invalidInput("%w", err)
 				}
+			}
 
-				// Check for Express Mode (API key) first
-				vertexAPIKey, _ := config.GetVertexAPIKey("")
-
-				if vertexAPIKey != "" {
-					// Express Mode - Use REST client
-					client, err := generate.NewVertexRESTClient(vertexAPIKey, project, location)
-					if err != nil {
-						return nil, fmt.Errorf("failed to create Vertex AI REST client: %w", err)
-					}
-					defer client.Close()
-
-					generatedImage, err = client.GenerateImage(ctx, prompt, opts)
-					if err != nil {
-						return nil, fmt.Errorf("image generation failed: %w", err)
-					}
-				} else {
-					// Full Mode - Use SDK client
-					client, err := generate.NewVertexSDKClient(ctx, project, location)
-					if err != nil {
-						return nil, fmt.Errorf("failed to create Vertex AI SDK client: %w\nPlease run: gimage auth vertex", err)
-					}
-					defer client.Close()
+			// Create generate options
+			opts := models.GenerateOptions{
+				Model:          provider.ModelID,
+				Size:           size,
+				AspectRatio:    aspect,
+				Style:          style,
+				NegativePrompt: negative,
+				Seed:           seed,
+				Count:          count,
+				Extra:          extra,
+			}
 
-					generatedImage, err = client.GenerateImage(ctx, prompt, opts)
-					if err != nil {
-						return nil, fmt.Errorf("image generation failed: %w", err)
-					}
-				}
+			// Request the closest native size, then fit to the exact size
+			targetWidth, targetHeight, err := generate.RequestedSize(opts)
+			if err != nil {
+				return nil, invalidInput("%w", err)
+			}
+			if opts, err = provider.NativeOptions(opts); err != nil {
+				return nil, invalidInput("%w", err)
 			}
 
-			// Save the generated image
-			if err := generate.SaveImage(generatedImage, output); err != nil {
-				return nil, fmt.Errorf("failed to save image: %w", err)
+			// Report the provider call, which takes most of the time, and
+			// the save that follows it
+			reporter := progress.FromContext(ctx)
+			reporter.Start(ctx, "Generating image")
+			reporter.Update(1, 3, fmt.Sprintf("Generating %d image(s) with %s", max(count, 1), provider.Name))
+			generatedImages, err := generate.GenerateImages(ctx, client, prompt, opts)
+			if err != nil {
+				err = providerError(fmt.Errorf("image generation failed: %w", err))
+				reporter.Error(err)
+				return nil, err
+			}
+			reporter.Update(2, 3, "Fitting generated images")
+			if err := generate.FitImages(generatedImages, targetWidth, targetHeight, fit); err != nil {
+				reporter.Error(err)
+				return nil, err
+			}
+			if first := generatedImages[0]; first.Width > 0 && first.Height > 0 {
+				size = fmt.Sprintf("%dx%d", first.Width, first.Height)
 			}
 
-			// Get absolute output path
-			absOutput, err := filepath.Abs(output)
+			// Save the generated images (name_1.png ... name_N.png when count > 1)
+			reporter.Update(3, 3, fmt.Sprintf("Saving %d image(s)", len(generatedImages)))
+			paths, err := generate.SaveImages(generatedImages, output)
 			if err != nil {
-				absOutput = output
+				err = fmt.Errorf("failed to save image: %w", err)
+				reporter.Error(err)
+				return nil, err
 			}
+			reporter.Complete(paths)
 
-			// Get provider info for pricing display
-			provider, _ := registry.ResolveProvider(modelName)
-			var modelDisplayName string
-			var pricingInfo string
+			// Get absolute output paths
+			absPaths := make([]string, len(paths))
+			for i, path := range paths {
+				absPath, err := filepath.Abs(path)
+				if err != nil {
+					absPath = path
+				}
+				absPaths[i] = absPath
+			}
+			absOutput := absPaths[0]
 
-			if provider != nil {
-				modelDisplayName = provider.Name
-				if provider.Pricing.FreeTier {
-					pricingInfo = fmt.Sprintf("FREE (%s)", provider.Pricing.FreeTierLimit)
-				} else if provider.Pricing.CostPerImage != nil {
-					pricingInfo = fmt.Sprintf("$%.4f/image", *provider.Pricing.CostPerImage)
-				} else {
-					pricingInfo = "Variable"
+			// Report the provider that actually served the request after a failover
+			requestedProvider := provider.ID
+			if servedBy := generatedImages[0].Metadata["provider"]; servedBy != "" && servedBy != provider.ID {
+				if served, err := registry.Get(servedBy); err == nil {
+					provider = served
 				}
-			} else {
-				modelDisplayName = modelName
-				pricingInfo = "Unknown"
 			}
 
+			// Pricing display
+			pricingInfo := "Variable"
+			if provider.Pricing.FreeTier {
+				pricingInfo = fmt.Sprintf("FREE (%s)", provider.Pricing.FreeTierLimit)
+			} else if provider.Pricing.CostPerImage != nil {
+				pricingInfo = fmt.Sprintf("$%.4f/image", *provider.Pricing.CostPerImage)
+			}
+			modelDisplayName := provider.Name
+
 			// Build result with comprehensive information
 			result := map[string]interface{}{
 				"success":       true,
 				"output_path":   absOutput,
 				"size":          size,
-				"model":         modelName,
+				"model":         provider.ModelID,
+				"provider":      provider.ID,
 				"model_display": modelDisplayName,
-				"api":           selectedAPI,
+				"api":           provider.API,
 				"pricing":       pricingInfo,
 				"prompt":        prompt,
 			}
 
+			if len(absPaths) > 1 {
+				result["output_paths"] = absPaths
+				result["count"] = len(absPaths)
+			}
+
 			// Create user-friendly message
 			msg := fmt.Sprintf("Generated using %s (%s)", modelDisplayName, pricingInfo)
+			if len(absPaths) > 1 {
+				msg = fmt.Sprintf("Generated %d images using %s (%s)", len(absPaths), modelDisplayName, pricingInfo)
+			}
 			result["message"] = msg
 
+			if provider.ID != requestedProvider {
+				result["failover_from"] = generatedImages[0].Metadata["failover_from"]
+				msg = fmt.Sprintf("%s - %s was unavailable", msg, requestedProvider)
+				result["message"] = msg
+			}
+
 			// Add warning if we had to fall back to a different location
 			if pathWarning != "" {
 				result["warning"] = pathWarning
 			}
 
-			return result, nil
+			return withOutputContent(result, returnImage, absPaths...), nil
 		},
 	}
 
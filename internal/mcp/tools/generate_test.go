@@ -1,9 +1,15 @@
 package tools
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/apresai/gimage/internal/mcp"
+	"github.com/apresai/gimage/internal/progress"
 )
 
 func TestRegisterGenerateImageTool(t *testing.T) {
@@ -82,12 +88,21 @@ func TestGenerateImageTool_InputSchema(t *testing.T) {
 	}
 
 	// Verify optional properties
-	optionalProps := []string{"output", "size", "model", "style", "negative", "seed"}
+	optionalProps := []string{"output", "size", "model", "style", "negative", "seed", "count", "options", "aspect_ratio", "fit"}
 	for _, prop := range optionalProps {
 		if _, exists := properties[prop]; !exists {
 			t.Errorf("optional property '%s' missing", prop)
 		}
 	}
+
+	// Any WIDTHxHEIGHT is accepted and snapped per provider
+	size := properties["size"].(map[string]interface{})
+	if _, exists := size["enum"]; exists {
+		t.Errorf("size must accept any WIDTHxHEIGHT, got enum %v", size["enum"])
+	}
+	if !regexp.MustCompile(size["pattern"].(string)).MatchString("1920x1080") {
+		t.Errorf("size pattern %v rejects 1920x1080", size["pattern"])
+	}
 }
 
 func TestGenerateImageTool_ValidationErrors(t *testing.T) {
@@ -127,11 +142,21 @@ func TestGenerateImageTool_ValidationErrors(t *testing.T) {
 			wantError: true,
 			errorMsg:  "prompt is required",
 		},
+		{
+			name: "count above maximum",
+			args: map[string]interface{}{
+				"prompt": "test prompt",
+				"output": filepath.Join(t.TempDir(), "image.png"),
+				"count":  11.0,
+			},
+			wantError: true,
+			errorMsg:  "invalid count",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := tool.Handler(tt.args)
+			result, err := tool.Handler(context.Background(), tt.args)
 
 			if tt.wantError {
 				if err == nil {
@@ -236,3 +261,124 @@ func TestIsVertexModel(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateImageTool_MockProvider(t *testing.T) {
+	t.Setenv("GIMAGE_MOCK_ERROR", "")
+
+	server := mcp.NewMCPServer("test", "1.0.0", nil, false)
+	RegisterGenerateImageTool(server)
+	tool := server.GetTool("generate_image")
+
+	output := filepath.Join(t.TempDir(), "mock.png")
+	result, err := tool.Handler(context.Background(), map[string]interface{}{
+		"prompt": "offline test",
+		"model":  "local/mock",
+		"size":   "256x256",
+		"output": output,
+		"count":  2.0,
+	})
+	if err != nil {
+		t.Fatalf("Handler() error = %v", err)
+	}
+
+	if result["provider"] != "local/mock" {
+		t.Errorf("provider = %v, want local/mock", result["provider"])
+	}
+	paths, ok := result["output_paths"].([]string)
+	if !ok || len(paths) != 2 {
+		t.Fatalf("output_paths = %v, want 2 paths", result["output_paths"])
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("output not written: %v", err)
+		}
+	}
+}
+
+func TestGenerateImageTool_Progress(t *testing.T) {
+	t.Setenv("GIMAGE_MOCK_ERROR", "")
+
+	server := mcp.NewMCPServer("test", "1.0.0", nil, false)
+	RegisterGenerateImageTool(server)
+	tool := server.GetTool("generate_image")
+
+	var updates []int64
+	var messages []string
+	reporter := progress.NewTUIReporter(nil, func(current, total int64, message string, percentage float64) {
+		updates = append(updates, current)
+		messages = append(messages, message)
+		if total != 3 {
+			t.Errorf("total = %d, want 3", total)
+		}
+	}, nil, nil)
+
+	_, err := tool.Handler(progress.WithReporter(context.Background(), reporter), map[string]interface{}{
+		"prompt": "offline test",
+		"model":  "local/mock",
+		"size":   "256x256",
+		"output": filepath.Join(t.TempDir(), "mock.png"),
+	})
+	if err != nil {
+		t.Fatalf("Handler() error = %v", err)
+	}
+	if len(updates) != 3 || updates[0] != 1 || updates[2] != 3 {
+		t.Errorf("updates = %v, want 1, 2, 3", updates)
+	}
+	if len(messages) > 0 && !strings.Contains(messages[0], "Generating 1 image(s)") {
+		t.Errorf("first message = %q, want the provider call", messages[0])
+	}
+}
+
+func TestGenerateImageTool_UnsupportedOption(t *testing.T) {
+	t.Setenv("GIMAGE_MOCK_ERROR", "")
+
+	server := mcp.NewMCPServer("test", "1.0.0", nil, false)
+	RegisterGenerateImageTool(server)
+	tool := server.GetTool("generate_image")
+
+	output := filepath.Join(t.TempDir(), "mock.png")
+	_, err := tool.Handler(context.Background(), map[string]interface{}{
+		"prompt":  "offline test",
+		"model":   "local/mock",
+		"output":  output,
+		"options": map[string]interface{}{"cfgScale": 8.0},
+	})
+	if err == nil || !strings.Contains(err.Error(), "unsupported option \"cfgScale\"") {
+		t.Fatalf("Handler() error = %v, want unsupported option error", err)
+	}
+	if _, statErr := os.Stat(output); statErr == nil {
+		t.Error("no image should be generated when options are invalid")
+	}
+}
+
+func TestGenerateImageTool_AspectRatio(t *testing.T) {
+	t.Setenv("GIMAGE_MOCK_ERROR", "")
+
+	server := mcp.NewMCPServer("test", "1.0.0", nil, false)
+	RegisterGenerateImageTool(server)
+	tool := server.GetTool("generate_image")
+
+	result, err := tool.Handler(context.Background(), map[string]interface{}{
+		"prompt":       "offline test",
+		"model":        "local/mock",
+		"aspect_ratio": "16:9",
+		"output":       filepath.Join(t.TempDir(), "wide.png"),
+	})
+	if err != nil {
+		t.Fatalf("Handler() error = %v", err)
+	}
+	if result["size"] != "1344x768" {
+		t.Errorf("size = %v, want the ~1 megapixel 16:9 size 1344x768", result["size"])
+	}
+
+	_, err = tool.Handler(context.Background(), map[string]interface{}{
+		"prompt":       "offline test",
+		"model":        "local/mock",
+		"size":         "1024x1024",
+		"aspect_ratio": "16:9",
+		"output":       filepath.Join(t.TempDir(), "bad.png"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not match aspect ratio") {
+		t.Errorf("Handler() error = %v, want size/aspect mismatch", err)
+	}
+}
@@ -54,10 +54,10 @@ func getFileSize(path string) (int64, error) {
 func validatePositiveInt(value interface{}, name string) (int, error) {
 	num, ok := value.(float64) // JSON numbers are float64
 	if !ok {
-		return 0, fmt.Errorf("%s must be a number", name)
+		return 0, invalidInput("%s must be a number", name)
 	}
 	if num < 1 {
-		return 0, fmt.Errorf("%s must be positive", name)
+		return 0, invalidInput("%s must be positive", name)
 	}
 	return int(num), nil
 }
@@ -66,14 +66,14 @@ func validatePositiveInt(value interface{}, name string) (int, error) {
 func validateString(value interface{}, name string) (string, error) {
 	str, ok := value.(string)
 	if !ok || str == "" {
-		return "", fmt.Errorf("%s is required", name)
+		return "", invalidInput("%s is required", name)
 	}
 	return str, nil
 }
 
-// loadImage loads an image from a file
+// loadImage loads an image from a file, upright according to its EXIF orientation
 func loadImage(path string) (image.Image, error) {
-	return imaging.Open(path)
+	return imaging.Open(path, imaging.AutoOrientation(true))
 }
 
 // saveImage saves an image to a file
@@ -91,6 +91,155 @@ func saveImage(img image.Image, path string) error {
 	return imaging.Save(img, path)
 }
 
+// metadataProperty is the input schema of the metadata argument shared by
+// the image processing tools
+func metadataProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"enum":        gimaging.MetadataPolicyNames(),
+		"description": "Which source metadata to keep: color (default, ICC color profile only), keep (EXIF, XMP and color profile), strip-gps (EXIF without GPS, plus color profile) or strip (nothing)",
+		"default":     string(gimaging.MetadataColor),
+	}
+}
+
+// metadataPolicyArg parses the optional metadata argument
+func metadataPolicyArg(args map[string]interface{}) (gimaging.MetadataPolicy, error) {
+	value, _ := args["metadata"].(string)
+	policy, err := gimaging.ParseMetadataPolicy(value)
+	if err != nil {
+		return "", invalidInput("%w", err)
+	}
+	return policy, nil
+}
+
+// speedProperty is the input schema of the AVIF encoder speed argument
+func speedProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "integer",
+		"description": fmt.Sprintf("AVIF encoder speed (1-%d). Slower speeds give smaller files at the same quality but take longer. Default is %d.", gimaging.MaxAVIFSpeed, gimaging.DefaultAVIFSpeed),
+		"minimum":     1,
+		"maximum":     gimaging.MaxAVIFSpeed,
+	}
+}
+
+// speedArg returns the optional speed argument (0 when absent)
+func speedArg(args map[string]interface{}) (int, error) {
+	value, ok := args["speed"].(float64)
+	if !ok {
+		return 0, nil
+	}
+	speed := int(value)
+	if speed < 1 || speed > gimaging.MaxAVIFSpeed {
+		return 0, invalidInput("speed must be between 1 and %d", gimaging.MaxAVIFSpeed)
+	}
+	return speed, nil
+}
+
+// frameProperty is the input schema of the animation frame argument
+func frameProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "integer",
+		"description": "Write only this frame (counting from 0) of an animated GIF or WebP. By default animated images keep all frames when the output is GIF or WebP, and other formats get the first frame.",
+		"minimum":     0,
+	}
+}
+
+// frameArg returns the optional frame argument and whether it was given
+func frameArg(args map[string]interface{}) (int, bool, error) {
+	value, ok := args["frame"].(float64)
+	if !ok {
+		return 0, false, nil
+	}
+	if value < 0 {
+		return 0, false, invalidInput("frame must be 0 or more")
+	}
+	return int(value), true, nil
+}
+
+// filterProperty is the input schema of the resampling filter argument
+func filterProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"enum":        gimaging.FilterNames(),
+		"description": "Resampling filter: nearest (keeps the hard edges of pixel art), box, linear (fast), mitchell, catmullrom (fast and sharp) or lanczos (default, sharpest and slowest)",
+		"default":     string(gimaging.FilterLanczos),
+	}
+}
+
+// filterArg parses the optional filter argument
+func filterArg(args map[string]interface{}) (gimaging.Filter, error) {
+	value, _ := args["filter"].(string)
+	filter, err := gimaging.ParseFilter(value)
+	if err != nil {
+		return "", invalidInput("%w", err)
+	}
+	return filter, nil
+}
+
+// sharpenProperty is the input schema of the post-resize sharpen argument
+func sharpenProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "number",
+		"description": fmt.Sprintf("Sharpen the result with an unsharp mask of this strength (0-%g, default 0 = off). 0.5-1.5 restores edge contrast after a large downscale.", gimaging.MaxSharpen),
+		"minimum":     0,
+		"maximum":     gimaging.MaxSharpen,
+	}
+}
+
+// sharpenArg returns the optional sharpen argument (0 when absent)
+func sharpenArg(args map[string]interface{}) (float64, error) {
+	amount, _ := args["sharpen"].(float64)
+	if amount < 0 || amount > gimaging.MaxSharpen {
+		return 0, invalidInput("sharpen must be between 0 and %g", gimaging.MaxSharpen)
+	}
+	return amount, nil
+}
+
+// resizeModeProperty is the input schema of the resize mode argument
+func resizeModeProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"enum":        gimaging.ResizeModeNames(),
+		"description": "How to treat the aspect ratio: stretch (default, exactly width x height), fit (fit within width x height, preserving aspect ratio), fill (cover width x height and crop the overflow at anchor) or pad (fit inside width x height and letterbox on background)",
+		"default":     string(gimaging.ResizeModeStretch),
+	}
+}
+
+// anchorProperty is the input schema of the fill anchor argument
+func anchorProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"enum":        append(gimaging.AnchorNames(), gimaging.AnchorSmart),
+		"description": "Part of the image kept by mode fill (default: center). Use top for portraits so heads are not cut off, or smart to keep the most interesting part (faces, products, detail).",
+		"default":     "center",
+	}
+}
+
+// backgroundProperty is the input schema of the pad background argument
+func backgroundProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "Letterbox background for mode pad: black (default), white, gray, transparent, a hex color such as #1e90ff, or blur for a blurred copy of the image",
+		"default":     gimaging.DefaultBackground,
+	}
+}
+
+// resizeOptionsArg parses the optional mode, anchor and background arguments
+func resizeOptionsArg(args map[string]interface{}) (gimaging.ResizeOptions, error) {
+	value, _ := args["mode"].(string)
+	mode, err := gimaging.ParseResizeMode(value)
+	if err != nil {
+		return gimaging.ResizeOptions{}, invalidInput("%w", err)
+	}
+	anchor, _ := args["anchor"].(string)
+	background, _ := args["background"].(string)
+	opts := gimaging.ResizeOptions{Mode: mode, Anchor: anchor, Background: background}
+	if err := gimaging.ResizeStep(1, 1, opts).Validate(); err != nil {
+		return gimaging.ResizeOptions{}, invalidInput("%w", err)
+	}
+	return opts, nil
+}
+
 // isVertexModel checks if a model is a Vertex AI model
 func isVertexModel(model string) bool {
 	vertexModels := []string{
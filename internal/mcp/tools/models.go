@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/apresai/gimage/internal/generate"
@@ -16,11 +17,31 @@ func RegisterListModelsTool(server *mcp.MCPServer) {
 			"type":       "object",
 			"properties": map[string]interface{}{},
 		},
-		Handler: func(args map[string]interface{}) (map[string]interface{}, error) {
+		OutputSchema: outputSchema(map[string]interface{}{
+			"providers": map[string]interface{}{
+				"type":        "array",
+				"description": "Image generation providers with pricing, capabilities and availability",
+				"items": outputSchema(map[string]interface{}{
+					"provider_id":     outputProperty("string", "ID to pass as model"),
+					"name":            outputProperty("string", "Display name"),
+					"available":       outputProperty("boolean", "Whether credentials are configured"),
+					"pricing_summary": outputProperty("string", "Cost per image or free tier"),
+				}, "provider_id", "name", "available"),
+			},
+			"total":            outputProperty("integer", "Number of providers"),
+			"configured":       outputProperty("integer", "Number of providers with credentials"),
+			"default_provider": outputProperty("object", "Provider used when no model is given"),
+			"pricing_note":     outputProperty("string", "How to read the pricing"),
+			"recommendations":  outputProperty("object", "Suggested providers by use case"),
+		}, "providers", "total", "configured"),
+		Handler: func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) {
 			// Use the Provider system (single source of truth)
 			registry := generate.GetProviderRegistry()
 			statuses := registry.GetAuthStatus()
 
+			// The local mock only renders placeholders - don't offer it to agents picking a model
+			statuses = filterOutLocalProviders(statuses)
+
 			// Build provider list with availability
 			providers := []map[string]interface{}{}
 			for _, status := range statuses {
@@ -67,6 +88,40 @@ func RegisterListModelsTool(server *mcp.MCPServer) {
 					"max_prompt_length":        p.Capabilities.MaxPromptLength,
 				}
 
+				// Native sizes; other sizes snap to the closest and are then fitted
+				if len(p.Capabilities.Sizes) > 0 {
+					sizes := make([]map[string]interface{}, 0, len(p.Capabilities.Sizes))
+					for _, size := range p.Capabilities.Sizes {
+						sizes = append(sizes, map[string]interface{}{
+							"size":         size.String(),
+							"aspect_ratio": size.AspectRatio,
+						})
+					}
+					providerData["native_sizes"] = sizes
+					providerData["aspect_ratios"] = p.AspectRatios()
+				}
+
+				// Provider-specific keys accepted by generate_image "options"
+				if len(p.Capabilities.Options) > 0 {
+					options := make([]map[string]interface{}, 0, len(p.Capabilities.Options))
+					for _, option := range p.Capabilities.Options {
+						optionData := map[string]interface{}{
+							"name":        option.Name,
+							"type":        option.Type,
+							"description": option.Description,
+						}
+						if len(option.Values) > 0 {
+							optionData["values"] = option.Values
+						}
+						if option.Min != 0 || option.Max != 0 {
+							optionData["minimum"] = option.Min
+							optionData["maximum"] = option.Max
+						}
+						options = append(options, optionData)
+					}
+					providerData["options"] = options
+				}
+
 				providers = append(providers, providerData)
 			}
 
@@ -119,3 +174,14 @@ func RegisterListModelsTool(server *mcp.MCPServer) {
 
 	server.RegisterTool(tool)
 }
+
+// filterOutLocalProviders removes offline placeholder providers (local/mock)
+func filterOutLocalProviders(statuses []generate.AuthStatus) []generate.AuthStatus {
+	filtered := make([]generate.AuthStatus, 0, len(statuses))
+	for _, status := range statuses {
+		if status.Provider.API != "local" {
+			filtered = append(filtered, status)
+		}
+	}
+	return filtered
+}
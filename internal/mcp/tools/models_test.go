@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"testing"
 
 	"github.com/apresai/gimage/internal/mcp"
@@ -65,7 +66,7 @@ func TestListModelsTool_ResponseStructure(t *testing.T) {
 	}
 
 	// Call the handler with empty args
-	result, err := tool.Handler(map[string]interface{}{})
+	result, err := tool.Handler(context.Background(), map[string]interface{}{})
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -114,7 +115,7 @@ func TestListModelsTool_ProviderStructure(t *testing.T) {
 		t.Fatal("list_models tool not registered")
 	}
 
-	result, err := tool.Handler(map[string]interface{}{})
+	result, err := tool.Handler(context.Background(), map[string]interface{}{})
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -154,7 +155,7 @@ func TestListModelsTool_PricingStructure(t *testing.T) {
 		t.Fatal("list_models tool not registered")
 	}
 
-	result, err := tool.Handler(map[string]interface{}{})
+	result, err := tool.Handler(context.Background(), map[string]interface{}{})
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -193,7 +194,7 @@ func TestListModelsTool_ConfiguredCount(t *testing.T) {
 		t.Fatal("list_models tool not registered")
 	}
 
-	result, err := tool.Handler(map[string]interface{}{})
+	result, err := tool.Handler(context.Background(), map[string]interface{}{})
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -235,7 +236,7 @@ func TestListModelsTool_DefaultProviderStructure(t *testing.T) {
 		t.Fatal("list_models tool not registered")
 	}
 
-	result, err := tool.Handler(map[string]interface{}{})
+	result, err := tool.Handler(context.Background(), map[string]interface{}{})
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -263,7 +264,7 @@ func TestListModelsTool_RecommendationsStructure(t *testing.T) {
 		t.Fatal("list_models tool not registered")
 	}
 
-	result, err := tool.Handler(map[string]interface{}{})
+	result, err := tool.Handler(context.Background(), map[string]interface{}{})
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -298,7 +299,7 @@ func TestListModelsTool_ProviderAvailability(t *testing.T) {
 		t.Fatal("list_models tool not registered")
 	}
 
-	result, err := tool.Handler(map[string]interface{}{})
+	result, err := tool.Handler(context.Background(), map[string]interface{}{})
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -344,7 +345,7 @@ func TestListModelsTool_PricingNote(t *testing.T) {
 		t.Fatal("list_models tool not registered")
 	}
 
-	result, err := tool.Handler(map[string]interface{}{})
+	result, err := tool.Handler(context.Background(), map[string]interface{}{})
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -373,7 +374,7 @@ func TestListModelsTool_APITypes(t *testing.T) {
 		t.Fatal("list_models tool not registered")
 	}
 
-	result, err := tool.Handler(map[string]interface{}{})
+	result, err := tool.Handler(context.Background(), map[string]interface{}{})
 	if err != nil {
 		t.Fatalf("Handler returned error: %v", err)
 	}
@@ -400,7 +401,7 @@ func TestListModelsTool_APITypes(t *testing.T) {
 	}
 
 	// Valid API types
-	validAPIs := map[string]bool{"gemini": true, "vertex": true, "bedrock": true}
+	validAPIs := map[string]bool{"gemini": true, "vertex": true, "bedrock": true, "openai": true, "sd": true}
 	for api := range apiTypes {
 		if !validAPIs[api] {
 			t.Errorf("Invalid API type: %s", api)
@@ -0,0 +1,29 @@
+package tools
+
+// outputSchema returns the output schema of a tool whose result map has the
+// given properties, of which required are always present
+func outputSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// outputProperty returns the schema of one property of a tool result
+func outputProperty(typ, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        typ,
+		"description": description,
+	}
+}
+
+// imageOutputSchema returns the output schema of a tool writing one image:
+// success, output_path, frames and warning, plus the tool's own properties
+func imageOutputSchema(properties map[string]interface{}) map[string]interface{} {
+	properties["success"] = outputProperty("boolean", "Whether the operation succeeded")
+	properties["output_path"] = outputProperty("string", "Absolute path of the written image")
+	properties["frames"] = outputProperty("integer", "Number of frames, for animated output only")
+	properties["warning"] = outputProperty("string", "Set when the output was written somewhere other than requested")
+	return outputSchema(properties, "success", "output_path")
+}
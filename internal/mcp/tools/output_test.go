@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apresai/gimage/internal/config"
+	"github.com/apresai/gimage/internal/mcp"
+)
+
+// TestToolOutputSchemas runs the tools through the server, which rejects
+// results not matching their output schema
+func TestToolOutputSchemas(t *testing.T) {
+	t.Setenv("GIMAGE_MOCK_ERROR", "")
+
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "in")
+	if err := os.Mkdir(inputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(inputDir, "input.png")
+	writeTestPNG(t, input, 64, 48, color.NRGBA{10, 20, 30, 255})
+	out := func(name string) string { return filepath.Join(tmpDir, name) }
+
+	server := mcp.NewMCPServer("test", "1.0.0", &config.Config{}, false)
+	for _, register := range []func(*mcp.MCPServer){
+		RegisterResizeImageTool, RegisterScaleImageTool, RegisterCropImageTool,
+		RegisterCompressImageTool, RegisterConvertImageTool, RegisterProcessPipelineTool,
+		RegisterGenerateImageTool, RegisterEditImageTool, RegisterListModelsTool,
+		RegisterBatchResizeTool, RegisterBatchCompressTool, RegisterBatchConvertTool,
+	} {
+		register(server)
+	}
+	for _, name := range []string{"resize_image", "scale_image", "crop_image", "compress_image", "convert_image", "process_pipeline",
+		"generate_image", "edit_image", "list_models", "batch_resize", "batch_compress", "batch_convert"} {
+		if tool := server.GetTool(name); tool == nil || tool.OutputSchema == nil {
+			t.Errorf("%s declares no output schema", name)
+		}
+	}
+
+	tests := []struct {
+		tool string
+		args map[string]interface{}
+	}{
+		{"resize_image", map[string]interface{}{"input": input, "width": 32.0, "height": 24.0, "output": out("resized.png")}},
+		{"scale_image", map[string]interface{}{"input": input, "factor": 0.5, "output": out("scaled.png")}},
+		{"crop_image", map[string]interface{}{"input": input, "x": 0.0, "y": 0.0, "width": 10.0, "height": 10.0, "output": out("cropped.png")}},
+		{"crop_image", map[string]interface{}{"input": input, "smart": true, "width": 10.0, "height": 10.0, "output": out("smart.png")}},
+		{"compress_image", map[string]interface{}{"input": input, "quality": 80.0, "output": out("compressed.jpg")}},
+		{"compress_image", map[string]interface{}{"input": input, "max_size": "50KB", "output": out("budget.jpg")}},
+		{"convert_image", map[string]interface{}{"input": input, "format": "webp", "output": out("converted.webp")}},
+		{"process_pipeline", map[string]interface{}{"input": input, "steps": []interface{}{"resize:32x24", "convert:jpeg"}, "output": out("processed.jpg")}},
+		{"generate_image", map[string]interface{}{"prompt": "offline test", "model": "local/mock", "size": "256x256", "count": 2.0, "output": out("generated.png")}},
+		{"list_models", map[string]interface{}{}},
+		{"batch_resize", map[string]interface{}{"input_dir": inputDir, "output_dir": out("resize"), "width": 16.0, "height": 12.0}},
+		{"batch_compress", map[string]interface{}{"input_dir": inputDir, "output_dir": out("compress"), "quality": 80.0}},
+		{"batch_convert", map[string]interface{}{"input_dir": inputDir, "output_dir": out("convert"), "format": "jpeg"}},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			response := server.HandleRequest(t.Context(), &mcp.JSONRPCRequest{
+				JSONRPC: "2.0",
+				ID:      i,
+				Method:  mcp.MethodCallTool,
+				Params:  map[string]interface{}{"name": tt.tool, "arguments": tt.args},
+			})
+			if response.Error != nil {
+				t.Fatalf("Unexpected error: %v", response.Error)
+			}
+			if response.Result["isError"] == true {
+				t.Fatalf("Unexpected tool error: %v", response.Result["structuredContent"])
+			}
+			if _, ok := response.Result["structuredContent"].(map[string]interface{}); !ok {
+				t.Errorf("Expected structuredContent, got %v", response.Result)
+			}
+		})
+	}
+}
@@ -54,11 +54,11 @@ func ValidateAndFixOutputPath(path, defaultFilename string) (*PathValidationResu
 	// Current directory is read-only, try home directory
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return nil, fmt.Errorf("cannot determine home directory and current directory is read-only: %w", err)
+		return nil, invalidPath(hintOutputPath, "cannot determine home directory and current directory is read-only: %w", err)
 	}
 
 	if !isDirectoryWritable(homeDir) {
-		return nil, fmt.Errorf("home directory is not writable: %s", homeDir)
+		return nil, invalidPath(hintOutputPath, "home directory is not writable: %s", homeDir)
 	}
 
 	result.Path = filepath.Join(homeDir, defaultFilename)
@@ -75,7 +75,7 @@ func ValidateAndFixOutputPath(path, defaultFilename string) (*PathValidationResu
 // ValidateInputPath validates that an input file exists and is readable
 func ValidateInputPath(path string) (string, error) {
 	if path == "" {
-		return "", fmt.Errorf("input path cannot be empty")
+		return "", invalidPath(hintInputPath, "input path cannot be empty")
 	}
 
 	expanded := expandTilde(path)
@@ -84,14 +84,14 @@ func ValidateInputPath(path string) (string, error) {
 	info, err := os.Stat(expanded)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return "", fmt.Errorf("file does not exist: %s", path)
+			return "", invalidPath(hintInputPath, "file does not exist: %s", path)
 		}
-		return "", fmt.Errorf("cannot access file %s: %w", path, err)
+		return "", invalidPath(hintInputPath, "cannot access file %s: %w", path, err)
 	}
 
 	// Check if it's a regular file (not a directory)
 	if info.IsDir() {
-		return "", fmt.Errorf("path is a directory, not a file: %s", path)
+		return "", invalidPath(hintInputPath, "path is a directory, not a file: %s", path)
 	}
 
 	return expanded, nil
@@ -101,7 +101,7 @@ func ValidateInputPath(path string) (string, error) {
 // If createIfMissing is true, creates the directory if it doesn't exist
 func ValidateDirectoryPath(path string, createIfMissing bool) (string, error) {
 	if path == "" {
-		return "", fmt.Errorf("directory path cannot be empty")
+		return "", invalidPath(hintDirectory, "directory path cannot be empty")
 	}
 
 	expanded := expandTilde(path)
@@ -113,23 +113,23 @@ func ValidateDirectoryPath(path string, createIfMissing bool) (string, error) {
 			if createIfMissing {
 				// Create directory with standard permissions
 				if err := os.MkdirAll(expanded, 0755); err != nil {
-					return "", fmt.Errorf("failed to create directory %s: %w", path, err)
+					return "", invalidPath(hintDirectory, "failed to create directory %s: %w", path, err)
 				}
 				return expanded, nil
 			}
-			return "", fmt.Errorf("directory does not exist: %s", path)
+			return "", invalidPath(hintDirectory, "directory does not exist: %s", path)
 		}
-		return "", fmt.Errorf("cannot access directory %s: %w", path, err)
+		return "", invalidPath(hintDirectory, "cannot access directory %s: %w", path, err)
 	}
 
 	// Check if it's actually a directory
 	if !info.IsDir() {
-		return "", fmt.Errorf("path is a file, not a directory: %s", path)
+		return "", invalidPath(hintDirectory, "path is a file, not a directory: %s", path)
 	}
 
 	// Check if directory is writable
 	if !isDirectoryWritable(expanded) {
-		return "", fmt.Errorf("directory is not writable: %s", path)
+		return "", invalidPath(hintDirectory, "directory is not writable: %s", path)
 	}
 
 	return expanded, nil
@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	gimaging "github.com/apresai/gimage/internal/imaging"
+	"github.com/apresai/gimage/internal/mcp"
+)
+
+// RegisterProcessPipelineTool registers the process_pipeline tool
+func RegisterProcessPipelineTool(server *mcp.MCPServer) {
+	tool := mcp.Tool{
+		Name:        "process_pipeline",
+		Description: "Apply a chain of operations to an image in one pass: the image is decoded once, every step runs in memory, and the result is encoded once. Prefer this over calling crop_image, resize_image and compress_image in sequence, which re-encodes (and loses quality) at every step. Steps run in order; see the steps parameter for the syntax.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"input": map[string]interface{}{
+					"type":        "string",
+					"description": "Input image file path (absolute or relative path)",
+				},
+				"steps": map[string]interface{}{
+					"type":        "array",
+					"description": "Operations to apply in order: crop:X,Y,W,H (region), crop:WxH or crop:WxH@ANCHOR (center or top, bottom, left, right, top-left, top-right, bottom-left, bottom-right), resize:WxH (exact), fit:WxH (preserve aspect ratio), fill:WxH or fill:WxH@ANCHOR (cover and crop to exactly WxH; anchor smart keeps the most interesting part), pad:WxH or pad:WxH@BACKGROUND (letterbox to exactly WxH on black, white, gray, transparent, a hex color or blur), scale:FACTOR, sharpen:AMOUNT (unsharp mask, 0.5-1.5 after a downscale), compress:QUALITY (1-100; JPEG, lossy WebP or AVIF), convert:FORMAT (png, jpg, webp, avif, gif, tiff, bmp), frame:N (keep only frame N, from 0, of an animated GIF or WebP). Animated inputs keep all frames when written as GIF or WebP. Example: [\"crop:0,0,1600,900\", \"resize:800x450\", \"convert:webp\"]",
+					"items": map[string]interface{}{
+						"type": "string",
+					},
+					"minItems": 1,
+				},
+				"output": map[string]interface{}{
+					"type":        "string",
+					"description": "Output file path. If not provided, generates filename like input_processed.ext (with the convert step's extension, if any)",
+				},
+				"filter":       filterProperty(),
+				"speed":        speedProperty(),
+				"metadata":     metadataProperty(),
+				"return_image": returnImageProperty(),
+			},
+			"required": []string{"input", "steps"},
+		},
+		OutputSchema: imageOutputSchema(map[string]interface{}{
+			"steps":           outputProperty("array", "Steps applied, in order"),
+			"original_size":   outputProperty("string", "Source dimensions, WIDTHxHEIGHT"),
+			"new_size":        outputProperty("string", "Output dimensions, WIDTHxHEIGHT"),
+			"original_format": outputProperty("string", "Input format"),
+			"new_format":      outputProperty("string", "Output format"),
+			"file_size_bytes": outputProperty("integer", "Output file size in bytes"),
+			"file_size_human": outputProperty("string", "Output file size, human readable"),
+		}),
+		Handler: func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) {
+			// Validate input file path
+			inputArg, err := validateString(args["input"], "input")
+			if err != nil {
+				return nil, err
+			}
+			input, err := ValidateInputPath(inputArg)
+			if err != nil {
+				return nil, fmt.Errorf("input validation failed: %w", err)
+			}
+
+			// Validate steps
+			rawSteps, ok := args["steps"].([]interface{})
+			if !ok || len(rawSteps) == 0 {
+				return nil, invalidInput("steps is required (e.g. [\"resize:800x600\"])")
+			}
+			specs := make([]string, 0, len(rawSteps))
+			for i, raw := range rawSteps {
+				spec, ok := raw.(string)
+				if !ok {
+					return nil, invalidInput("steps[%d] must be a string", i)
+				}
+				specs = append(specs, spec)
+			}
+			steps, err := gimaging.ParseSteps(specs)
+			if err != nil {
+				return nil, invalidInput("%w", err)
+			}
+
+			policy, err := metadataPolicyArg(args)
+			if err != nil {
+				return nil, err
+			}
+			returnImage, err := returnImageArg(args)
+			if err != nil {
+				return nil, err
+			}
+			speed, err := speedArg(args)
+			if err != nil {
+				return nil, err
+			}
+			filter, err := filterArg(args)
+			if err != nil {
+				return nil, err
+			}
+
+			// Default output uses the convert step's extension, if any
+			defaultFilename := generateOutputPath(input, "processed")
+			for _, step := range steps {
+				if step.Op == gimaging.StepConvert {
+					base := strings.TrimSuffix(defaultFilename, filepath.Ext(defaultFilename))
+					defaultFilename = base + "." + gimaging.FormatExtension(step.Format)
+				}
+			}
+
+			// Validate and fix output path
+			outputArg, _ := args["output"].(string)
+			pathResult, pathErr := ValidateAndFixOutputPath(outputArg, defaultFilename)
+			if pathErr != nil {
+				return nil, fmt.Errorf("output path validation failed: %w", pathErr)
+			}
+			output := pathResult.Path
+
+			// Decode once, apply every step, encode once
+			p, err := gimaging.OpenPipeline(input)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load image: %w", err)
+			}
+			p.Speed(speed).Filter(filter)
+			for _, step := range steps {
+				if err := p.Apply(step).Err(); err != nil {
+					return nil, fmt.Errorf("step %s failed: %w", step, err)
+				}
+			}
+			if err := p.Metadata(policy).Save(output); err != nil {
+				return nil, fmt.Errorf("failed to save processed image: %w", err)
+			}
+
+			newSize, err := getFileSize(output)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get output file size: %w", err)
+			}
+
+			// Get absolute path for response
+			absPath, _ := filepath.Abs(output)
+
+			sourceWidth, sourceHeight := p.SourceSize()
+			width, height := p.Size()
+			result := map[string]interface{}{
+				"success":         true,
+				"output_path":     absPath,
+				"steps":           p.Steps(),
+				"original_size":   fmt.Sprintf("%dx%d", sourceWidth, sourceHeight),
+				"new_size":        fmt.Sprintf("%dx%d", width, height),
+				"original_format": p.SourceFormat(),
+				"new_format":      gimaging.ExtractFormatFromPath(output),
+				"file_size_bytes": newSize,
+				"file_size_human": formatBytes(newSize),
+			}
+
+			// Add warning if path was adjusted
+			if pathResult.Warning != "" {
+				result["warning"] = pathResult.Warning
+			}
+
+			return withOutputContent(result, returnImage, absPath), nil
+		},
+	}
+
+	server.RegisterTool(tool)
+}
@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apresai/gimage/internal/config"
+	"github.com/apresai/gimage/internal/mcp"
+)
+
+func TestProcessPipelineTool(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{}
+
+	// Create a test image (400x300)
+	testImagePath := filepath.Join(tmpDir, "test.png")
+	img := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 400; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 200, 255})
+		}
+	}
+	file, err := os.Create(testImagePath)
+	if err != nil {
+		t.Fatalf("Failed to create test image: %v", err)
+	}
+	png.Encode(file, img)
+	file.Close()
+
+	// Create server and register tool
+	server := mcp.NewMCPServer("test", "1.0.0", cfg, false)
+	RegisterProcessPipelineTool(server)
+
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		wantError string
+		wantSize  string
+	}{
+		{
+			name: "crop, resize and compress to jpeg",
+			args: map[string]interface{}{
+				"input":  testImagePath,
+				"steps":  []interface{}{"crop:0,0,200,150", "resize:100x75", "compress:80"},
+				"output": filepath.Join(tmpDir, "out.jpg"),
+			},
+			wantSize: "100x75",
+		},
+		{
+			name: "anchored crop then scale",
+			args: map[string]interface{}{
+				"input":  testImagePath,
+				"steps":  []interface{}{"crop:300x300@left", "scale:0.5"},
+				"output": filepath.Join(tmpDir, "out.png"),
+			},
+			wantSize: "150x150",
+		},
+		{
+			name: "missing steps",
+			args: map[string]interface{}{
+				"input":  testImagePath,
+				"output": filepath.Join(tmpDir, "missing.png"),
+			},
+			wantError: "steps is required",
+		},
+		{
+			name: "invalid step",
+			args: map[string]interface{}{
+				"input":  testImagePath,
+				"steps":  []interface{}{"resize:100"},
+				"output": filepath.Join(tmpDir, "invalid.png"),
+			},
+			wantError: "expected WIDTHxHEIGHT",
+		},
+		{
+			name: "crop outside image",
+			args: map[string]interface{}{
+				"input":  testImagePath,
+				"steps":  []interface{}{"crop:350,0,100,100"},
+				"output": filepath.Join(tmpDir, "outside.png"),
+			},
+			wantError: "exceeds image width 400",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := server.GetTool("process_pipeline")
+			if tool == nil {
+				t.Fatal("process_pipeline tool not registered")
+			}
+
+			result, err := tool.Handler(context.Background(), tt.args)
+
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			outputPath, _ := result["output_path"].(string)
+			width, height, err := getImageDimensions(outputPath)
+			if err != nil {
+				t.Fatalf("Failed to read output: %v", err)
+			}
+			if got := formatDimensions(width, height); got != tt.wantSize {
+				t.Errorf("Output size = %s, want %s", got, tt.wantSize)
+			}
+			if result["new_size"] != tt.wantSize || result["original_size"] != "400x300" {
+				t.Errorf("new_size = %v, original_size = %v", result["new_size"], result["original_size"])
+			}
+			if steps, _ := result["steps"].([]string); len(steps) != len(tt.args["steps"].([]interface{})) {
+				t.Errorf("steps = %v", result["steps"])
+			}
+		})
+	}
+}
+
+func TestProcessPipelineTool_ConvertDefaultsOutputExtension(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{}
+
+	testImagePath := filepath.Join(tmpDir, "photo.png")
+	file, err := os.Create(testImagePath)
+	if err != nil {
+		t.Fatalf("Failed to create test image: %v", err)
+	}
+	png.Encode(file, image.NewRGBA(image.Rect(0, 0, 64, 64)))
+	file.Close()
+
+	server := mcp.NewMCPServer("test", "1.0.0", cfg, false)
+	RegisterProcessPipelineTool(server)
+
+	// Run from tmpDir so the default output lands there
+	wd, _ := os.Getwd()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	result, err := server.GetTool("process_pipeline").Handler(context.Background(), map[string]interface{}{
+		"input": testImagePath,
+		"steps": []interface{}{"fit:32x32", "convert:jpeg"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	outputPath, _ := result["output_path"].(string)
+	if filepath.Base(outputPath) != "photo_processed.jpg" {
+		t.Errorf("output_path = %s, want photo_processed.jpg", outputPath)
+	}
+	if result["new_format"] != "jpeg" || result["original_format"] != "png" {
+		t.Errorf("original_format = %v, new_format = %v", result["original_format"], result["new_format"])
+	}
+}
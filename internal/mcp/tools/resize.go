@@ -1,18 +1,19 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 
+	gimaging "github.com/apresai/gimage/internal/imaging"
 	"github.com/apresai/gimage/internal/mcp"
-	"github.com/disintegration/imaging"
 )
 
 // RegisterResizeImageTool registers the resize_image tool
 func RegisterResizeImageTool(server *mcp.MCPServer) {
 	tool := mcp.Tool{
 		Name:        "resize_image",
-		Description: "Resize an image to specific dimensions using high-quality Lanczos resampling. This changes both width and height to exact pixel values. Note: Aspect ratio is NOT preserved unless dimensions match original ratio. Use scale_image if you want to maintain aspect ratio.",
+		Description: "Resize an image to specific dimensions using high-quality Lanczos resampling. By default this changes both width and height to exact pixel values, so the aspect ratio is NOT preserved unless the dimensions match the original ratio; use mode fit to fit within width x height instead, mode fill to crop to an exact size such as a 1200x630 social card, mode pad to letterbox to an exact size, or scale_image to scale by a factor. Use filter nearest for pixel art, linear or catmullrom for fast thumbnails, and sharpen after large downscales. Animated GIF and WebP images keep all frames, delays and loop count.",
 		InputSchema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -34,10 +35,22 @@ func RegisterResizeImageTool(server *mcp.MCPServer) {
 					"type":        "string",
 					"description": "Output file path. If not provided, generates filename like input_resized.ext",
 				},
+				"mode":         resizeModeProperty(),
+				"anchor":       anchorProperty(),
+				"background":   backgroundProperty(),
+				"filter":       filterProperty(),
+				"sharpen":      sharpenProperty(),
+				"frame":        frameProperty(),
+				"metadata":     metadataProperty(),
+				"return_image": returnImageProperty(),
 			},
 			"required": []string{"input", "width", "height"},
 		},
-		Handler: func(args map[string]interface{}) (map[string]interface{}, error) {
+		OutputSchema: imageOutputSchema(map[string]interface{}{
+			"original_size": outputProperty("string", "Source dimensions, WIDTHxHEIGHT"),
+			"new_size":      outputProperty("string", "Output dimensions, WIDTHxHEIGHT"),
+		}),
+		Handler: func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) {
 			// Validate input file path
 			inputArg, err := validateString(args["input"], "input")
 			if err != nil {
@@ -59,6 +72,31 @@ func RegisterResizeImageTool(server *mcp.MCPServer) {
 				return nil, err
 			}
 
+			opts, err := resizeOptionsArg(args)
+			if err != nil {
+				return nil, err
+			}
+			filter, err := filterArg(args)
+			if err != nil {
+				return nil, err
+			}
+			sharpen, err := sharpenArg(args)
+			if err != nil {
+				return nil, err
+			}
+			policy, err := metadataPolicyArg(args)
+			if err != nil {
+				return nil, err
+			}
+			returnImage, err := returnImageArg(args)
+			if err != nil {
+				return nil, err
+			}
+			frame, hasFrame, err := frameArg(args)
+			if err != nil {
+				return nil, err
+			}
+
 			// Validate and fix output path
 			outputArg, _ := args["output"].(string)
 			defaultFilename := generateOutputPath(input, "resized")
@@ -68,23 +106,22 @@ func RegisterResizeImageTool(server *mcp.MCPServer) {
 			}
 			output := pathResult.Path
 
-			// Get original dimensions
-			origWidth, origHeight, err := getImageDimensions(input)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read input image: %w", err)
-			}
-
-			// Load image
-			img, err := loadImage(input)
+			// Load image, upright according to its EXIF orientation
+			p, err := gimaging.OpenPipeline(input)
 			if err != nil {
 				return nil, fmt.Errorf("failed to load image: %w", err)
 			}
+			if hasFrame {
+				p.Frame(frame)
+			}
+			origWidth, origHeight := p.SourceSize()
 
-			// Resize image using Lanczos resampling
-			resized := imaging.Resize(img, width, height, imaging.Lanczos)
-
-			// Save resized image
-			err = saveImage(resized, output)
+			// Resize image with the chosen filter and save it
+			p.Filter(filter).Apply(gimaging.ResizeStep(width, height, opts))
+			if sharpen > 0 {
+				p.Sharpen(sharpen)
+			}
+			err = p.Metadata(policy).Save(output)
 			if err != nil {
 				return nil, fmt.Errorf("failed to save resized image: %w", err)
 			}
@@ -92,19 +129,23 @@ func RegisterResizeImageTool(server *mcp.MCPServer) {
 			// Get absolute path for response
 			absPath, _ := filepath.Abs(output)
 
+			newWidth, newHeight := p.Size()
 			result := map[string]interface{}{
 				"success":       true,
 				"output_path":   absPath,
 				"original_size": fmt.Sprintf("%dx%d", origWidth, origHeight),
-				"new_size":      fmt.Sprintf("%dx%d", width, height),
+				"new_size":      fmt.Sprintf("%dx%d", newWidth, newHeight),
 			}
 
 			// Add warning if path was adjusted
+			if p.Animated() {
+				result["frames"] = p.FrameCount()
+			}
 			if pathResult.Warning != "" {
 				result["warning"] = pathResult.Warning
 			}
 
-			return result, nil
+			return withOutputContent(result, returnImage, absPath), nil
 		},
 	}
 
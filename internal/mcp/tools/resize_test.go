@@ -1,15 +1,23 @@
 package tools
 
 import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
 	"fmt"
+	"hash/crc32"
 	"image"
 	"image/color"
+	"image/gif"
 	"image/png"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/apresai/gimage/internal/config"
+	gimaging "github.com/apresai/gimage/internal/imaging"
 	"github.com/apresai/gimage/internal/mcp"
 )
 
@@ -115,7 +123,7 @@ func TestResizeImageTool(t *testing.T) {
 				t.Fatal("resize_image tool not registered")
 			}
 
-			result, err := tool.Handler(tt.args)
+			result, err := tool.Handler(context.Background(), tt.args)
 
 			if tt.wantError {
 				if err == nil {
@@ -225,12 +233,218 @@ func TestResizeImageToolSchema(t *testing.T) {
 		}
 	}
 
-	// Verify optional output field exists
-	if _, exists := properties["output"]; !exists {
-		t.Error("Optional 'output' property not defined in schema")
+	// Verify optional fields exist
+	for _, field := range []string{"output", "mode", "filter", "sharpen"} {
+		if _, exists := properties[field]; !exists {
+			t.Errorf("Optional '%s' property not defined in schema", field)
+		}
+	}
+}
+
+func TestResizeImageTool_ModeAndFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	server := mcp.NewMCPServer("test", "1.0.0", &config.Config{}, false)
+	RegisterResizeImageTool(server)
+	tool := server.GetTool("resize_image")
+
+	inputPath := filepath.Join(tmpDir, "wide.png")
+	file, err := os.Create(inputPath)
+	if err != nil {
+		t.Fatalf("Failed to create test image: %v", err)
+	}
+	png.Encode(file, image.NewRGBA(image.Rect(0, 0, 400, 200)))
+	file.Close()
+
+	result, err := tool.Handler(context.Background(), map[string]interface{}{
+		"input":   inputPath,
+		"width":   100.0,
+		"height":  100.0,
+		"mode":    "fit",
+		"filter":  "catmullrom",
+		"sharpen": 0.8,
+		"output":  filepath.Join(tmpDir, "fit.png"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result["new_size"] != "100x50" {
+		t.Errorf("Expected new_size 100x50 for fit, got %v", result["new_size"])
+	}
+
+	for _, extra := range []map[string]interface{}{
+		{"mode": "fill", "anchor": "left"},
+		{"mode": "pad", "background": "blur"},
+		{"mode": "pad", "background": "#ffffff"},
+	} {
+		args := map[string]interface{}{"input": inputPath, "width": 120.0, "height": 63.0, "output": filepath.Join(tmpDir, "card.png")}
+		for k, v := range extra {
+			args[k] = v
+		}
+		result, err := tool.Handler(context.Background(), args)
+		if err != nil {
+			t.Fatalf("Unexpected error for %v: %v", extra, err)
+		}
+		if result["new_size"] != "120x63" {
+			t.Errorf("Expected new_size 120x63 for %v, got %v", extra, result["new_size"])
+		}
+	}
+
+	invalid := []map[string]interface{}{
+		{"mode": "squash"},
+		{"mode": "fill", "anchor": "middle"},
+		{"mode": "pad", "background": "purple"},
+		{"filter": "sinc"},
+		{"sharpen": -1.0},
+		{"sharpen": 11.0},
+	}
+	for _, extra := range invalid {
+		args := map[string]interface{}{"input": inputPath, "width": 100.0, "height": 100.0}
+		for k, v := range extra {
+			args[k] = v
+		}
+		if _, err := tool.Handler(context.Background(), args); err == nil {
+			t.Errorf("Expected error for %v", extra)
+		}
+	}
+}
+
+func TestResizeImageToolMetadata(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{}
+
+	// PNG with an embedded ICC profile
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 40)))
+	profile := bytes.Repeat([]byte("icc profile "), 50)
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(profile)
+	zw.Close()
+	chunk := append([]byte("iCCP"), append([]byte("test\x00\x00"), compressed.Bytes()...)...)
+	data := append([]byte{}, buf.Bytes()[:33]...) // signature and IHDR
+	data = binary.BigEndian.AppendUint32(data, uint32(len(chunk)-4))
+	data = append(data, chunk...)
+	data = binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(chunk))
+	data = append(data, buf.Bytes()[33:]...)
+	testImagePath := filepath.Join(tmpDir, "profile.png")
+	if err := os.WriteFile(testImagePath, data, 0644); err != nil {
+		t.Fatalf("Failed to create test image: %v", err)
+	}
+
+	server := mcp.NewMCPServer("test", "1.0.0", cfg, false)
+	RegisterResizeImageTool(server)
+	tool := server.GetTool("resize_image")
+
+	resize := func(metadata string) gimaging.Metadata {
+		t.Helper()
+		output := filepath.Join(tmpDir, "out_"+metadata+".png")
+		_, err := tool.Handler(context.Background(), map[string]interface{}{
+			"input":    testImagePath,
+			"width":    20.0,
+			"height":   20.0,
+			"output":   output,
+			"metadata": metadata,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error for metadata %q: %v", metadata, err)
+		}
+		out, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		return gimaging.ReadMetadata(out)
+	}
+
+	if got := resize("color"); !bytes.Equal(got.ICC, profile) {
+		t.Error("Expected the color profile to be kept by default")
+	}
+	if got := resize("strip"); got.ICC != nil {
+		t.Error("Expected the color profile to be stripped")
+	}
+
+	_, err := tool.Handler(context.Background(), map[string]interface{}{
+		"input":    testImagePath,
+		"width":    20.0,
+		"height":   20.0,
+		"metadata": "everything",
+	})
+	if err == nil || !strings.Contains(err.Error(), "unknown metadata policy") {
+		t.Errorf("Expected unknown metadata policy error, got %v", err)
 	}
 }
 
 func formatDimensions(width, height int) string {
 	return fmt.Sprintf("%dx%d", width, height)
 }
+
+func TestResizeImageTool_Animated(t *testing.T) {
+	tmpDir := t.TempDir()
+	server := mcp.NewMCPServer("test", "1.0.0", &config.Config{}, false)
+	RegisterResizeImageTool(server)
+	tool := server.GetTool("resize_image")
+
+	// Three solid frames
+	palette := color.Palette{color.Black, color.White}
+	anim := &gif.GIF{}
+	for i := 0; i < 3; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, 40, 40), palette)
+		for j := range frame.Pix {
+			frame.Pix[j] = uint8(i % 2)
+		}
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, 10)
+	}
+	inputPath := filepath.Join(tmpDir, "anim.gif")
+	file, err := os.Create(inputPath)
+	if err != nil {
+		t.Fatalf("Failed to create test image: %v", err)
+	}
+	if err := gif.EncodeAll(file, anim); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	file.Close()
+
+	result, err := tool.Handler(context.Background(), map[string]interface{}{
+		"input":  inputPath,
+		"width":  20.0,
+		"height": 20.0,
+		"output": filepath.Join(tmpDir, "small.gif"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if frames, _ := result["frames"].(int); frames != 3 {
+		t.Errorf("Expected frames 3, got %v", result["frames"])
+	}
+	data, err := os.ReadFile(filepath.Join(tmpDir, "small.gif"))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if n := gimaging.CountFrames(data); n != 3 {
+		t.Errorf("Expected 3 frames in output, got %d", n)
+	}
+
+	result, err = tool.Handler(context.Background(), map[string]interface{}{
+		"input":  inputPath,
+		"width":  20.0,
+		"height": 20.0,
+		"frame":  1.0,
+		"output": filepath.Join(tmpDir, "frame.png"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := result["frames"]; ok {
+		t.Errorf("Expected no frames in a single frame result, got %v", result["frames"])
+	}
+
+	_, err = tool.Handler(context.Background(), map[string]interface{}{
+		"input":  inputPath,
+		"width":  20.0,
+		"height": 20.0,
+		"frame":  5.0,
+	})
+	if err == nil {
+		t.Error("Expected error for out of range frame")
+	}
+}
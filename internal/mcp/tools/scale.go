@@ -1,18 +1,19 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 
+	gimaging "github.com/apresai/gimage/internal/imaging"
 	"github.com/apresai/gimage/internal/mcp"
-	"github.com/disintegration/imaging"
 )
 
 // RegisterScaleImageTool registers the scale_image tool
 func RegisterScaleImageTool(server *mcp.MCPServer) {
 	tool := mcp.Tool{
 		Name:        "scale_image",
-		Description: "Scale an image by a factor while preserving aspect ratio. Use this when you want to make an image larger or smaller proportionally. For example, factor 0.5 makes image half size, factor 2.0 makes it double size. Uses high-quality Lanczos resampling.",
+		Description: "Scale an image by a factor while preserving aspect ratio. Use this when you want to make an image larger or smaller proportionally. For example, factor 0.5 makes image half size, factor 2.0 makes it double size. Uses high-quality Lanczos resampling unless filter picks another (nearest for pixel art, linear or catmullrom for speed); sharpen restores edge contrast after large downscales. Animated GIF and WebP images keep all frames.",
 		InputSchema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -30,10 +31,20 @@ func RegisterScaleImageTool(server *mcp.MCPServer) {
 					"type":        "string",
 					"description": "Output file path. If not provided, generates filename like input_scaled.ext",
 				},
+				"filter":       filterProperty(),
+				"sharpen":      sharpenProperty(),
+				"frame":        frameProperty(),
+				"metadata":     metadataProperty(),
+				"return_image": returnImageProperty(),
 			},
 			"required": []string{"input", "factor"},
 		},
-		Handler: func(args map[string]interface{}) (map[string]interface{}, error) {
+		OutputSchema: imageOutputSchema(map[string]interface{}{
+			"scale_factor":  outputProperty("number", "Factor the image was scaled by"),
+			"original_size": outputProperty("string", "Source dimensions, WIDTHxHEIGHT"),
+			"new_size":      outputProperty("string", "Output dimensions, WIDTHxHEIGHT"),
+		}),
+		Handler: func(_ context.Context, args map[string]interface{}) (map[string]interface{}, error) {
 			// Validate input file path
 			inputArg, err := validateString(args["input"], "input")
 			if err != nil {
@@ -47,10 +58,31 @@ func RegisterScaleImageTool(server *mcp.MCPServer) {
 			// Validate factor
 			factorVal, ok := args["factor"].(float64)
 			if !ok {
-				return nil, fmt.Errorf("factor must be a number")
+				return nil, invalidInput("factor must be a number")
 			}
 			if factorVal < 0.1 || factorVal > 10.0 {
-				return nil, fmt.Errorf("factor must be between 0.1 and 10.0")
+				return nil, invalidInput("factor must be between 0.1 and 10.0")
+			}
+
+			filter, err := filterArg(args)
+			if err != nil {
+				return nil, err
+			}
+			sharpen, err := sharpenArg(args)
+			if err != nil {
+				return nil, err
+			}
+			policy, err := metadataPolicyArg(args)
+			if err != nil {
+				return nil, err
+			}
+			returnImage, err := returnImageArg(args)
+			if err != nil {
+				return nil, err
+			}
+			frame, hasFrame, err := frameArg(args)
+			if err != nil {
+				return nil, err
 			}
 
 			// Validate and fix output path
@@ -62,31 +94,30 @@ func RegisterScaleImageTool(server *mcp.MCPServer) {
 			}
 			output := pathResult.Path
 
-			// Get original dimensions
-			origWidth, origHeight, err := getImageDimensions(input)
+			// Load image, upright according to its EXIF orientation
+			p, err := gimaging.OpenPipeline(input)
 			if err != nil {
-				return nil, fmt.Errorf("failed to read input image: %w", err)
+				return nil, fmt.Errorf("failed to load image: %w", err)
+			}
+			if hasFrame {
+				p.Frame(frame)
 			}
+			origWidth, origHeight := p.SourceSize()
 
 			// Calculate new dimensions
 			newWidth := int(float64(origWidth) * factorVal)
 			newHeight := int(float64(origHeight) * factorVal)
 
 			if newWidth < 1 || newHeight < 1 {
-				return nil, fmt.Errorf("resulting dimensions would be too small (less than 1 pixel)")
+				return nil, invalidInput("resulting dimensions would be too small (less than 1 pixel)")
 			}
 
-			// Load image
-			img, err := loadImage(input)
-			if err != nil {
-				return nil, fmt.Errorf("failed to load image: %w", err)
+			// Resize image with the chosen filter and save it
+			p.Filter(filter).Resize(newWidth, newHeight)
+			if sharpen > 0 {
+				p.Sharpen(sharpen)
 			}
-
-			// Resize image using Lanczos resampling
-			scaled := imaging.Resize(img, newWidth, newHeight, imaging.Lanczos)
-
-			// Save scaled image
-			err = saveImage(scaled, output)
+			err = p.Metadata(policy).Save(output)
 			if err != nil {
 				return nil, fmt.Errorf("failed to save scaled image: %w", err)
 			}
@@ -103,11 +134,14 @@ func RegisterScaleImageTool(server *mcp.MCPServer) {
 			}
 
 			// Add warning if path was adjusted
+			if p.Animated() {
+				result["frames"] = p.FrameCount()
+			}
 			if pathResult.Warning != "" {
 				result["warning"] = pathResult.Warning
 			}
 
-			return result, nil
+			return withOutputContent(result, returnImage, absPath), nil
 		},
 	}
 
@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"image"
 	"image/color"
 	"image/png"
@@ -117,7 +118,7 @@ func TestScaleImageTool(t *testing.T) {
 				t.Fatal("scale_image tool not registered")
 			}
 
-			result, err := tool.Handler(tt.args)
+			result, err := tool.Handler(context.Background(), tt.args)
 
 			if tt.wantError {
 				if err == nil {
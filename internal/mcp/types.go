@@ -1,5 +1,10 @@
 package mcp
 
+import (
+	"context"
+	"encoding/json"
+)
+
 // JSONRPCRequest represents a JSON-RPC 2.0 request
 type JSONRPCRequest struct {
 	JSONRPC string                 `json:"jsonrpc"`
@@ -16,6 +21,24 @@ type JSONRPCResponse struct {
 	Error   *JSONRPCError          `json:"error,omitempty"`
 }
 
+// MarshalJSON writes a successful response with its result even when the
+// result is empty, as for ping: JSON-RPC requires either result or error
+func (r JSONRPCResponse) MarshalJSON() ([]byte, error) {
+	if r.Error != nil {
+		type response JSONRPCResponse // without this method
+		return json.Marshal(response(r))
+	}
+	result := r.Result
+	if result == nil {
+		result = map[string]interface{}{}
+	}
+	return json.Marshal(struct {
+		JSONRPC string                 `json:"jsonrpc"`
+		ID      interface{}            `json:"id"`
+		Result  map[string]interface{} `json:"result"`
+	}{r.JSONRPC, r.ID, result})
+}
+
 // JSONRPCError represents a JSON-RPC 2.0 error
 type JSONRPCError struct {
 	Code    int                    `json:"code"`
@@ -26,6 +49,7 @@ type JSONRPCError struct {
 // MCP Protocol Methods
 const (
 	MethodInitialize    = "initialize"
+	MethodPing          = "ping"
 	MethodListTools     = "tools/list"
 	MethodCallTool      = "tools/call"
 	MethodListResources = "resources/list"
@@ -37,10 +61,15 @@ const (
 // MCP Protocol Notifications
 const (
 	NotificationToolsListChanged = "notifications/tools/list_changed"
+	NotificationCancelled        = "notifications/cancelled"
+	NotificationProgress         = "notifications/progress"
 )
 
-// MCP Protocol Version
-const ProtocolVersion = "2024-11-05"
+// MCP Protocol Version. It is the only version the server speaks: output
+// schemas, structuredContent, resource links and the Streamable HTTP
+// transport are not in earlier ones, so initialize answers every client
+// with it and the client decides whether it can go on.
+const ProtocolVersion = "2025-06-18"
 
 // JSON-RPC Error Codes
 const (
@@ -49,18 +78,48 @@ const (
 	ErrorCodeMethodNotFound = -32601
 	ErrorCodeInvalidParams  = -32602
 	ErrorCodeInternalError  = -32603
+
+	// ErrorCodeResourceNotFound is the MCP error for an unknown resource URI
+	ErrorCodeResourceNotFound = -32002
+)
+
+// ToolErrorCode is a machine-readable reason for a failed tool call
+type ToolErrorCode string
+
+// Tool error codes, reported in the structuredContent of isError results
+const (
+	ToolErrorInvalidInput        ToolErrorCode = "invalid_input"        // An argument is missing or out of range
+	ToolErrorInvalidPath         ToolErrorCode = "invalid_path"         // An input is missing or an output is not writable
+	ToolErrorQuotaExceeded       ToolErrorCode = "quota_exceeded"       // The provider is rate limiting or out of quota
+	ToolErrorProviderUnavailable ToolErrorCode = "provider_unavailable" // The provider is down or not set up
+	ToolErrorContentRejected     ToolErrorCode = "content_rejected"     // The provider's safety filters blocked the request
+	ToolErrorOperationFailed     ToolErrorCode = "operation_failed"     // Any other failure, e.g. an undecodable image
 )
 
-// ToolError represents an error from tool execution
+// ToolError is a tool failure the client can act on: Code says what went
+// wrong and Hint, when set, what to change before calling again. Handlers
+// return it, possibly wrapped; other errors are reported as
+// ToolErrorOperationFailed.
 type ToolError struct {
-	Code    int
+	Code    ToolErrorCode
 	Message string
+	Hint    string
+	Err     error // Underlying error, if any
+}
+
+// NewToolError returns a ToolError with the message of err
+func NewToolError(code ToolErrorCode, err error, hint string) *ToolError {
+	return &ToolError{Code: code, Message: err.Error(), Hint: hint, Err: err}
 }
 
 func (e *ToolError) Error() string {
 	return e.Message
 }
 
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
 // ToolAnnotations provides hints to LLMs about tool behavior (MCP spec 2025-06-18)
 type ToolAnnotations struct {
 	// DestructiveHint indicates if the tool makes destructive changes (deletes, overwrites)
@@ -79,8 +138,15 @@ type Tool struct {
 	Description string
 	InputSchema map[string]interface{}
 	Annotations *ToolAnnotations // Optional tool annotations (MCP spec 2025-06-18)
-	Handler     ToolHandler
+
+	// OutputSchema describes the result map of a successful call, which is
+	// then returned as structuredContent (MCP spec 2025-06-18). Optional.
+	OutputSchema map[string]interface{}
+	Handler      ToolHandler
 }
 
-// ToolHandler is a function that handles tool execution
-type ToolHandler func(args map[string]interface{}) (map[string]interface{}, error)
+// ToolHandler is a function that handles tool execution. ctx is cancelled
+// when the client cancels the call (notifications/cancelled) or the server
+// shuts down; long-running handlers should pass it to provider calls and
+// stop early once it is done.
+type ToolHandler func(ctx context.Context, args map[string]interface{}) (map[string]interface{}, error)
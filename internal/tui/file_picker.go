@@ -185,7 +185,7 @@ func (fp *FilePicker) Count() int {
 func isImageExtension(ext string) bool {
 	ext = strings.ToLower(ext)
 	switch ext {
-	case ".png", ".jpg", ".jpeg", ".gif", ".bmp", ".tiff", ".tif", ".webp":
+	case ".png", ".jpg", ".jpeg", ".gif", ".bmp", ".tiff", ".tif", ".webp", ".avif", ".heic", ".heif":
 		return true
 	default:
 		return false
@@ -1281,8 +1281,22 @@ func (m *GenerateFlowModel) generateImageCmd() tea.Cmd {
 		}
 		defer client.Close()
 
+		// Request the provider's closest native size; the result is cropped to the chosen size
+		targetWidth, targetHeight, err := generate.RequestedSize(options)
+		if err != nil {
+			return generationCompleteMsg{err: err}
+		}
+		if p, err := m.providerRegistry.Get(provider.id); err == nil {
+			if options, err = p.NativeOptions(options); err != nil {
+				return generationCompleteMsg{err: err}
+			}
+		}
+
 		// Generate the image
 		result, err := client.GenerateImage(ctx, m.promptTextarea.Value(), options)
+		if err == nil {
+			err = generate.FitImages([]*models.GeneratedImage{result}, targetWidth, targetHeight, generate.FitCrop)
+		}
 		if err != nil {
 			errMsg := fmt.Sprintf("Generation failed: %v", err)
 			logger.LogError("%s", errMsg)
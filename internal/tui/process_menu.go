@@ -86,7 +86,7 @@ func NewProcessMenuModel() *ProcessMenuModel {
 	fp, _ := NewFilePicker(desktopPath)
 	if fp != nil {
 		// Filter for image files
-		fp.SetFilter([]string{".png", ".jpg", ".jpeg", ".gif", ".bmp", ".tiff", ".webp"})
+		fp.SetFilter([]string{".png", ".jpg", ".jpeg", ".gif", ".bmp", ".tiff", ".webp", ".avif", ".heic", ".heif"})
 		fp.Refresh()
 	}
 
@@ -477,7 +477,7 @@ func (m *ProcessMenuModel) viewConfigure() string {
 			FormatKeyValue("Output", m.outputInput.View())
 	case OpConvert:
 		configContent = FormatKeyValue("Target Format", m.formatInput.View()) + "\n\n" +
-			MutedStyle.Render("Options: png, jpg, webp, gif, tiff, bmp") + "\n\n" +
+			MutedStyle.Render("Options: png, jpg, webp, avif, gif, tiff, bmp") + "\n\n" +
 			FormatKeyValue("Output", m.outputInput.View())
 	}
 
@@ -592,26 +592,26 @@ func (m *ProcessMenuModel) processImageCmd() tea.Cmd {
 		case OpResize:
 			width, _ := strconv.Atoi(m.widthInput.Value())
 			height, _ := strconv.Atoi(m.heightInput.Value())
-			err = imaging.ResizeImage(ctx, m.selectedFile.Path, outputPath, width, height)
+			err = imaging.ResizeImage(ctx, m.selectedFile.Path, outputPath, width, height, imaging.FileOptions{})
 
 		case OpScale:
 			factor, _ := strconv.ParseFloat(m.scaleInput.Value(), 64)
-			err = imaging.ScaleImage(ctx, m.selectedFile.Path, outputPath, factor)
+			err = imaging.ScaleImage(ctx, m.selectedFile.Path, outputPath, factor, imaging.FileOptions{})
 
 		case OpCrop:
 			x, _ := strconv.Atoi(m.cropXInput.Value())
 			y, _ := strconv.Atoi(m.cropYInput.Value())
 			width, _ := strconv.Atoi(m.cropWInput.Value())
 			height, _ := strconv.Atoi(m.cropHInput.Value())
-			err = imaging.CropImage(ctx, m.selectedFile.Path, outputPath, x, y, width, height)
+			err = imaging.CropImage(ctx, m.selectedFile.Path, outputPath, x, y, width, height, imaging.FileOptions{})
 
 		case OpCompress:
 			quality, _ := strconv.Atoi(m.qualityInput.Value())
-			err = imaging.CompressImage(ctx, m.selectedFile.Path, outputPath, quality)
+			err = imaging.CompressImage(ctx, m.selectedFile.Path, outputPath, quality, imaging.FileOptions{})
 
 		case OpConvert:
 			// Convert uses ConvertImageFile which auto-detects format from extension
-			err = imaging.ConvertImageFile(ctx, m.selectedFile.Path, outputPath)
+			err = imaging.ConvertImageFile(ctx, m.selectedFile.Path, outputPath, imaging.FileOptions{})
 		}
 
 		if err != nil {
@@ -8,6 +8,18 @@ type GenerateOptions struct {
 	Style          string
 	NegativePrompt string
 	Seed           int64
+	Count          int            // Number of images to generate (0 or 1 = single image)
+	Extra          map[string]any // Provider-specific options (e.g., sampler and steps for Stable Diffusion)
+}
+
+// EditOptions contains the source image and settings for AI image editing
+type EditOptions struct {
+	Image      []byte  // Source image data (PNG or JPEG)
+	MimeType   string  // MIME type of the source image (detected if empty)
+	Mode       string  // "variation" (default) or "inpainting" - Nova Canvas only
+	Mask       []byte  // Optional mask image for inpainting (black = area to edit)
+	MaskPrompt string  // Optional natural language description of the area to edit
+	Strength   float64 // Similarity to the source image for variations (0.2-1.0, 0 = provider default)
 }
 
 // GeneratedImage represents the result of an AI image generation request
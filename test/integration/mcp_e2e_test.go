@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"image/color"
@@ -422,7 +423,7 @@ func callTool(server *mcp.MCPServer, toolName string, args map[string]interface{
 	}
 
 	// Call the tool handler directly
-	result, err := tool.Handler(args)
+	result, err := tool.Handler(context.Background(), args)
 	if err != nil {
 		return nil, err
 	}
@@ -297,7 +297,7 @@ func TestOutputPathHandling(t *testing.T) {
 				}
 
 				ctx := context.Background()
-				err := imaging.ResizeImage(ctx, fixturePath, outputPath, 256, 256)
+				err := imaging.ResizeImage(ctx, fixturePath, outputPath, 256, 256, imaging.FileOptions{})
 				if err != nil {
 					t.Errorf("Resize failed with %s path: %v", tc.pathType, err)
 					return
@@ -335,7 +335,7 @@ func TestOutputPathHandling(t *testing.T) {
 				}
 
 				ctx := context.Background()
-				err := imaging.ScaleImage(ctx, fixturePath, outputPath, 0.5)
+				err := imaging.ScaleImage(ctx, fixturePath, outputPath, 0.5, imaging.FileOptions{})
 				if err != nil {
 					t.Errorf("Scale failed with %s path: %v", tc.pathType, err)
 					return
@@ -364,7 +364,7 @@ func TestOutputPathHandling(t *testing.T) {
 				}
 
 				ctx := context.Background()
-				err := imaging.ConvertImageFile(ctx, fixturePath, outputPath)
+				err := imaging.ConvertImageFile(ctx, fixturePath, outputPath, imaging.FileOptions{})
 				if err != nil {
 					t.Errorf("Convert failed with %s path: %v", tc.pathType, err)
 					return
@@ -391,7 +391,7 @@ func TestOutputPathHandling(t *testing.T) {
 				}
 
 				ctx := context.Background()
-				err := imaging.CropImage(ctx, fixturePath, outputPath, 0, 0, 128, 128)
+				err := imaging.CropImage(ctx, fixturePath, outputPath, 0, 0, 128, 128, imaging.FileOptions{})
 				if err != nil {
 					t.Errorf("Crop failed with %s path: %v", tc.pathType, err)
 					return
@@ -43,7 +43,7 @@ func TestWebPEncoding(t *testing.T) {
 
 	// Test 1: Convert PNG to WebP using ConvertImageFile
 	webpPath := filepath.Join(tmpDir, "test.webp")
-	err = imaging.ConvertImageFile(context.Background(), pngPath, webpPath)
+	err = imaging.ConvertImageFile(context.Background(), pngPath, webpPath, imaging.FileOptions{})
 	if err != nil {
 		t.Fatalf("ConvertImageFile failed: %v", err)
 	}
@@ -105,7 +105,7 @@ func TestWebPWithTransparency(t *testing.T) {
 
 	// Convert to WebP
 	webpPath := filepath.Join(tmpDir, "transparent.webp")
-	err = imaging.ConvertImageFile(context.Background(), pngPath, webpPath)
+	err = imaging.ConvertImageFile(context.Background(), pngPath, webpPath, imaging.FileOptions{})
 	if err != nil {
 		t.Fatalf("ConvertImageFile with transparency failed: %v", err)
 	}
@@ -205,7 +205,7 @@ func TestWebPFormats(t *testing.T) {
 
 			// Convert to WebP
 			webpPath := filepath.Join(tmpDir, "output_"+tt.format+".webp")
-			err = imaging.ConvertImageFile(context.Background(), sourcePath, webpPath)
+			err = imaging.ConvertImageFile(context.Background(), sourcePath, webpPath, imaging.FileOptions{})
 
 			if tt.shouldWork {
 				if err != nil {
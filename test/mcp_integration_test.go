@@ -279,8 +279,19 @@ func TestMCPServerErrorHandling(t *testing.T) {
 
 		response := server.HandleRequest(context.Background(), &request)
 
-		if response.Error == nil {
-			t.Error("Expected error for invalid parameters")
+		// Tool failures are isError results, not protocol errors
+		if response.Error != nil {
+			t.Fatalf("Expected an isError result, got error: %v", response.Error)
+		}
+
+		if response.Result["isError"] != true {
+			t.Fatal("Expected isError for invalid parameters")
+		}
+
+		structured := response.Result["structuredContent"].(map[string]interface{})
+		details := structured["error"].(map[string]interface{})
+		if details["code"] != mcp.ToolErrorInvalidPath {
+			t.Errorf("Expected code %s for the missing input, got %v", mcp.ToolErrorInvalidPath, details["code"])
 		}
 	})
 